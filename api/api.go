@@ -17,7 +17,9 @@ import (
 	"github.com/danielgtaylor/huma/v2/adapters/humachi"
 
 	"github.com/KiloProjects/kilonova"
+	"github.com/KiloProjects/kilonova/internal/auth"
 	"github.com/KiloProjects/kilonova/internal/util"
+	"github.com/KiloProjects/kilonova/net/llm"
 	"github.com/KiloProjects/kilonova/sudoapi"
 	"github.com/go-chi/chi/v5"
 	"github.com/gorilla/schema"
@@ -31,6 +33,9 @@ type API struct {
 
 	signupLock      sync.Mutex
 	testArchiveLock sync.Mutex
+
+	// llmProvider overrides the configured LLM provider, for tests
+	llmProvider llm.Provider
 }
 
 // New declares a new API instance
@@ -68,23 +73,93 @@ func (s *API) HandlerV2() http.Handler {
 		OperationID: "get-problems",
 		Method:      http.MethodPost,
 		Path:        "/problems",
-		Security:    []map[string][]string{},
+		Security:    scoped(),
 	}, s.problemGet)
 
 	huma.Register(api, huma.Operation{
 		OperationID: "get-contests",
 		Method:      http.MethodPost,
 		Path:        "/contests",
-		Security:    []map[string][]string{},
+		Security:    scoped(),
 	}, s.contestGet)
 
+	huma.Register(api, huma.Operation{
+		OperationID: "get-submissions",
+		Method:      http.MethodPost,
+		Path:        "/submissions",
+		Security:    scoped(auth.ScopeSubmissionsRead),
+	}, s.submissionsGet)
+
 	huma.Register(api, huma.Operation{
 		OperationID: "get-submission",
 		Method:      http.MethodGet,
 		Path:        "/submissions/{subID}",
-		Security:    []map[string][]string{},
+		Security:    scoped(auth.ScopeSubmissionsRead),
 	}, s.submissionGet)
 
+	huma.Register(api, huma.Operation{
+		OperationID: "get-submission-subtests",
+		Method:      http.MethodGet,
+		Path:        "/submissions/{subID}/subtests",
+		Security:    scoped(auth.ScopeSubmissionsRead),
+	}, s.submissionSubTestsGet)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "get-submission-subtasks",
+		Method:      http.MethodGet,
+		Path:        "/submissions/{subID}/subtasks",
+		Security:    scoped(auth.ScopeSubmissionsRead),
+	}, s.submissionSubTasksGet)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "get-problem-lists",
+		Method:      http.MethodGet,
+		Path:        "/problemLists",
+		Security:    scoped(),
+	}, s.problemListsGet)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "get-problem-list-by-id",
+		Method:      http.MethodGet,
+		Path:        "/problemLists/{listID}",
+		Security:    scoped(),
+	}, s.problemListSingleGet)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "get-tags",
+		Method:      http.MethodGet,
+		Path:        "/tags",
+		Security:    scoped(),
+	}, s.tagsGet)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "get-tag-by-id",
+		Method:      http.MethodGet,
+		Path:        "/tags/{tagID}",
+		Security:    scoped(),
+	}, s.tagSingleGet)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "get-user-by-id",
+		Method:      http.MethodGet,
+		Path:        "/users/{userID}",
+		Security:    scoped(),
+	}, s.userGet)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "get-user-by-name",
+		Method:      http.MethodGet,
+		Path:        "/users/byName/{username}",
+		Security:    scoped(),
+	}, s.userByNameGet)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "get-user-solved-problems",
+		Method:      http.MethodGet,
+		Path:        "/users/{userID}/solvedProblems",
+		Security:    scoped(),
+	}, s.userSolvedProblemsGet)
+
 	problemsGroup := huma.NewGroup(api, "/problems")
 	problemsGroup.UseMiddleware(s.validateProblemIDv2(problemsGroup))
 	problemsGroup.UseSimpleModifier(func(o *huma.Operation) {
@@ -100,29 +175,31 @@ func (s *API) HandlerV2() http.Handler {
 		OperationID: "get-problem-by-id",
 		Method:      http.MethodGet,
 		Path:        "/{problemID}",
-		Security:    []map[string][]string{},
+		Security:    scoped(),
 	}, s.problemSingleGet)
 
 	huma.Register(problemsGroup, huma.Operation{
 		OperationID: "get-problem-languages",
 		Method:      http.MethodGet,
 		Path:        "/{problemID}/languages",
-		Security:    []map[string][]string{},
+		Security:    scoped(),
 	}, s.problemLanguagesV2)
 
 	huma.Register(problemsGroup, huma.Operation{
 		OperationID: "get-problem-statement-variants",
 		Method:      http.MethodGet,
 		Path:        "/{problemID}/statements",
-		Security:    []map[string][]string{},
+		Security:    scoped(),
 	}, s.statementVariants)
 
 	huma.Register(problemsGroup, huma.Operation{
 		OperationID: "create-submission",
 		Method:      http.MethodPost,
 		Path:        "/{problemID}/submit",
-		Security:    []map[string][]string{},
-		Middlewares: huma.Middlewares{s.MustBeAuthedV2(problemsGroup)},
+		Security:    scoped(auth.ScopeSubmissionsWrite),
+		// Output only archives can be much larger than source code. CreateSubmission enforces the actual limits
+		MaxBodyBytes: 256 * 1024 * 1024,
+		Middlewares:  huma.Middlewares{s.MustBeAuthedV2(problemsGroup)},
 	}, s.createSubmissionV2)
 
 	contestsGroup := huma.NewGroup(api, "/contests")
@@ -140,9 +217,74 @@ func (s *API) HandlerV2() http.Handler {
 		OperationID: "get-contest-by-id",
 		Method:      http.MethodGet,
 		Path:        "/{contestID}",
-		Security:    []map[string][]string{},
+		Security:    scoped(),
 	}, s.contestSingleGet)
 
+	huma.Register(contestsGroup, huma.Operation{
+		OperationID: "get-contest-leaderboard",
+		Method:      http.MethodGet,
+		Path:        "/{contestID}/leaderboard",
+		Security:    scoped(),
+	}, s.contestLeaderboardV2)
+
+	huma.Register(contestsGroup, huma.Operation{
+		OperationID: "get-contest-announcements",
+		Method:      http.MethodGet,
+		Path:        "/{contestID}/announcements",
+		Security:    scoped(),
+	}, s.contestAnnouncementsV2)
+
+	huma.Register(contestsGroup, huma.Operation{
+		OperationID: "get-contest-questions",
+		Method:      http.MethodGet,
+		Path:        "/{contestID}/questions",
+		Summary:     "Get the user's questions",
+		Security:    scoped(),
+	}, s.contestUserQuestionsV2)
+
+	huma.Register(contestsGroup, huma.Operation{
+		OperationID: "get-contest-all-questions",
+		Method:      http.MethodGet,
+		Path:        "/{contestID}/questions/all",
+		Summary:     "Get every contestant's questions",
+		Security:    scoped(auth.ScopeContestsManage),
+		Middlewares: huma.Middlewares{s.validateContestEditorV2(contestsGroup)},
+	}, s.contestAllQuestionsV2)
+
+	huma.Register(contestsGroup, huma.Operation{
+		OperationID: "create-contest-question",
+		Method:      http.MethodPost,
+		Path:        "/{contestID}/questions",
+		Security:    scoped(auth.ScopeContestsParticipate),
+		Middlewares: huma.Middlewares{s.MustBeAuthedV2(contestsGroup), s.validateContestParticipantV2(contestsGroup)},
+	}, s.askContestQuestionV2)
+
+	huma.Register(contestsGroup, huma.Operation{
+		OperationID: "get-contest-registrations",
+		Method:      http.MethodGet,
+		Path:        "/{contestID}/registrations",
+		Security:    scoped(auth.ScopeContestsManage),
+		Middlewares: huma.Middlewares{s.validateContestEditorV2(contestsGroup)},
+	}, s.contestRegistrationsV2)
+
+	huma.Register(contestsGroup, huma.Operation{
+		OperationID: "get-contest-registration",
+		Method:      http.MethodGet,
+		Path:        "/{contestID}/registration",
+		Summary:     "Get the user's registration",
+		Security:    scoped(),
+		Middlewares: huma.Middlewares{s.MustBeAuthedV2(contestsGroup)},
+	}, s.contestRegistrationV2)
+
+	huma.Register(contestsGroup, huma.Operation{
+		OperationID:   "register-for-contest",
+		Method:        http.MethodPost,
+		Path:          "/{contestID}/register",
+		DefaultStatus: http.StatusNoContent,
+		Security:      scoped(auth.ScopeContestsParticipate),
+		Middlewares:   huma.Middlewares{s.MustBeAuthedV2(contestsGroup)},
+	}, s.registerForContestV2)
+
 	return r
 }
 
@@ -150,9 +292,10 @@ func (s *API) HandlerV2() http.Handler {
 func (s *API) HandlerV1() http.Handler {
 	r := chi.NewRouter()
 	r.Use(s.SetupSession)
+	r.Use(s.requireScopes())
 	r.Use(s.filterUserAgent)
 
-	r.With(s.MustBeAdmin).Route("/admin", func(r chi.Router) {
+	r.With(s.MustBeAdmin, s.requireScopes(auth.ScopeAdmin)).Route("/admin", func(r chi.Router) {
 
 		r.Post("/setAdmin", s.setAdmin)
 		r.Post("/setProposer", s.setProposer)
@@ -211,6 +354,7 @@ func (s *API) HandlerV1() http.Handler {
 	})
 
 	r.Route("/auth", func(r chi.Router) {
+		r.Use(s.MustUseSession)
 		r.Mount("/captcha", s.base.CaptchaImageHandler())
 
 		r.With(s.MustBeAuthed).Post("/logout", s.logout)
@@ -226,9 +370,9 @@ func (s *API) HandlerV1() http.Handler {
 		r.Post("/get", webWrapper(s.getProblems))
 		r.Post("/search", webWrapper(s.searchProblems))
 
-		r.With(s.MustBeProposer).Post("/create", s.initProblem)
+		r.With(s.MustBeProposer, s.requireScopes(auth.ScopeProblemsEdit)).Post("/create", s.initProblem)
 
-		r.With(s.MustBeProposer).Post("/import", s.importProblemArchive)
+		r.With(s.MustBeProposer, s.requireScopes(auth.ScopeProblemsEdit)).Post("/import", s.importProblemArchive)
 
 		r.Route("/{problemID}", func(r chi.Router) {
 			r.Use(s.validateProblemID)
@@ -242,6 +386,7 @@ func (s *API) HandlerV1() http.Handler {
 
 			r.Group(func(r chi.Router) {
 				r.Use(s.validateProblemEditor)
+				r.Use(s.requireScopes(auth.ScopeProblemsEdit))
 				r.Route("/update", func(r chi.Router) {
 					r.Post("/", webMessageWrapper("Updated problem", s.updateProblem))
 
@@ -265,6 +410,7 @@ func (s *API) HandlerV1() http.Handler {
 					r.Post("/bulkUpdateAttachmentInfo", s.bulkUpdateAttachmentInfo)
 
 					r.Post("/translateStatement", s.translateProblemStatement())
+					r.Post("/reviewStatement", webMessageWrapper("Marked translation as reviewed", s.reviewStatement))
 					r.Post("/transcribeStatement", s.transcribeProblemPDF())
 
 					r.Post("/bulkDeleteTests", s.bulkDeleteTests)
@@ -281,6 +427,8 @@ func (s *API) HandlerV1() http.Handler {
 					return s.base.ResetProblemSubmissions(context.WithoutCancel(ctx), util.ProblemContext(ctx))
 				}))
 
+				r.Post("/plagiarismCheck", webWrapper(s.checkSubmissionPlagiarism))
+
 				r.Post("/delete", s.deleteProblem)
 			})
 
@@ -302,7 +450,7 @@ func (s *API) HandlerV1() http.Handler {
 	r.Route("/blogPosts", func(r chi.Router) {
 		r.Get("/fromUser", s.userBlogPosts)
 		r.Get("/bySlug", s.blogPostBySlug)
-		r.With(s.MustBeProposer).Post("/create", s.createBlogPost)
+		r.With(s.MustBeProposer, s.requireScopes(auth.ScopeProblemsEdit)).Post("/create", s.createBlogPost)
 		r.Route("/{bpID}", func(r chi.Router) {
 			r.Use(s.validateBlogPostID)
 			r.Use(s.validateBlogPostVisible)
@@ -310,6 +458,7 @@ func (s *API) HandlerV1() http.Handler {
 
 			r.Route("/update", func(r chi.Router) {
 				r.Use(s.validateBlogPostEditor)
+				r.Use(s.requireScopes(auth.ScopeProblemsEdit))
 				r.Post("/", s.updateBlogPost)
 
 				r.Post("/addAttachment", s.createAttachment)
@@ -325,14 +474,15 @@ func (s *API) HandlerV1() http.Handler {
 				r.With(s.validateAttachmentID).Get("/attachment/{aID}", webWrapper(s.getFullAttachment))
 				r.With(s.validateAttachmentName).Get("/attachmentByName/{aName}", webWrapper(s.getFullAttachment))
 			})
-			r.With(s.validateBlogPostEditor).Post("/delete", webMessageWrapper("Removed blog post", s.deleteBlogPost))
+			r.With(s.validateBlogPostEditor, s.requireScopes(auth.ScopeProblemsEdit)).Post("/delete", webMessageWrapper("Removed blog post", s.deleteBlogPost))
 		})
 	})
 	r.Route("/submissions", func(r chi.Router) {
-		r.Get("/get", s.filterSubs())
-		r.Get("/getByID", s.getSubmissionByID())
+		r.With(s.requireScopes(auth.ScopeSubmissionsRead)).Get("/get", s.filterSubs())
+		r.With(s.requireScopes(auth.ScopeSubmissionsRead)).Get("/getByID", s.getSubmissionByID())
+		r.With(s.requireScopes(auth.ScopeSubmissionsRead)).Get("/events", s.submissionEvents)
 
-		r.With(s.MustBeAuthed).Post("/submit", s.createSubmission)
+		r.With(s.MustBeAuthed, s.requireScopes(auth.ScopeSubmissionsWrite)).Post("/submit", s.createSubmission)
 	})
 	r.Route("/paste/{pasteID}", func(r chi.Router) {
 		r.Get("/", s.getPaste)
@@ -350,7 +500,7 @@ func (s *API) HandlerV1() http.Handler {
 		}) (*kilonova.Tag, error) {
 			return s.base.TagByName(ctx, args.Name)
 		}))
-		r.With(s.MustBeAdmin).Post("/delete", webMessageWrapper("Deleted tag", func(ctx context.Context, args struct {
+		r.With(s.MustBeAdmin, s.requireScopes(auth.ScopeProblemsEdit)).Post("/delete", webMessageWrapper("Deleted tag", func(ctx context.Context, args struct {
 			ID int `json:"id"`
 		}) error {
 			tag, err := s.base.TagByID(ctx, args.ID)
@@ -360,69 +510,88 @@ func (s *API) HandlerV1() http.Handler {
 			return s.base.DeleteTag(ctx, tag)
 		}))
 
-		r.With(s.MustBeProposer).Post("/create", s.createTag)
-		r.With(s.MustBeAdmin).Post("/merge", webMessageWrapper("Merged tags", func(ctx context.Context, args struct {
+		r.With(s.MustBeProposer, s.requireScopes(auth.ScopeProblemsEdit)).Post("/create", s.createTag)
+		r.With(s.MustBeAdmin, s.requireScopes(auth.ScopeProblemsEdit)).Post("/merge", webMessageWrapper("Merged tags", func(ctx context.Context, args struct {
 			ToKeep    int `json:"to_keep"`
 			ToReplace int `json:"to_replace"`
 		}) error {
 			return s.base.MergeTags(ctx, args.ToKeep, []int{args.ToReplace}) // TODO: Many tags
 		}))
-		r.With(s.MustBeProposer).Post("/update", s.updateTag)
+		r.With(s.MustBeProposer, s.requireScopes(auth.ScopeProblemsEdit)).Post("/update", s.updateTag)
 	})
 	r.Route("/user", func(r chi.Router) {
 
-		r.With(s.MustBeAuthed).Post("/resendEmail", s.resendVerificationEmail)
+		r.With(s.MustBeAuthed, s.MustUseSession).Post("/resendEmail", s.resendVerificationEmail)
 
 		userRouter := chi.NewMux()
 		userRouter.Get("/", func(w http.ResponseWriter, r *http.Request) { returnData(w, user.ContentUserBrief(r)) })
 		userRouter.Get("/solvedProblems", s.getSolvedProblems)
-		userRouter.With(s.selfOrAdmin).Post("/deauthAll", s.deauthAllSessions)
+		userRouter.With(s.selfOrAdmin, s.requireScopes(auth.ScopeProfileWrite)).Post("/deauthAll", s.deauthAllSessions)
+		userRouter.With(s.selfOrAdmin, s.MustUseSession).Post("/revokeOAuthClient", webMessageWrapper("Revoked application access", s.revokeOAuthClient))
+		userRouter.With(s.selfOrAdmin, s.MustUseSession).Post("/revokeAPIToken", webMessageWrapper("Revoked API token", s.revokeAPIToken))
 
-		userRouter.With(s.selfOrAdmin).Post("/setBio", s.setBio())
-		userRouter.With(s.selfOrAdmin).Post("/setAvatarType", s.setAvatarType())
-		userRouter.With(s.selfOrAdmin).Post("/setPreferredLanguage", s.setPreferredLanguage())
-		userRouter.With(s.selfOrAdmin).Post("/setPreferredTheme", s.setPreferredTheme())
+		userRouter.With(s.selfOrAdmin, s.requireScopes(auth.ScopeProfileRead)).Get("/webhooks", webWrapper(s.userWebhooks))
+		userRouter.With(s.selfOrAdmin, s.requireScopes(auth.ScopeProfileWrite)).Post("/createWebhook", webWrapper(s.createUserWebhook))
+		userRouter.With(s.selfOrAdmin, s.requireScopes(auth.ScopeProfileWrite)).Post("/deleteWebhook", webMessageWrapper("Deleted webhook", s.deleteUserWebhook))
+
+		userRouter.With(s.selfOrAdmin, s.requireScopes(auth.ScopeProfileWrite)).Post("/setBio", s.setBio())
+		userRouter.With(s.selfOrAdmin, s.requireScopes(auth.ScopeProfileWrite)).Post("/setAvatarType", s.setAvatarType())
+		userRouter.With(s.selfOrAdmin, s.requireScopes(auth.ScopeProfileWrite)).Post("/setPreferredLanguage", s.setPreferredLanguage())
+		userRouter.With(s.selfOrAdmin, s.requireScopes(auth.ScopeProfileWrite)).Post("/setPreferredTheme", s.setPreferredTheme())
 
 		userRouter.Route("/moderation", func(r chi.Router) {
 			r.Use(s.MustBeAdmin)
 			r.Post("/manage", s.manageUser)
 			r.Post("/deleteUser", s.deleteUser)
 			r.Post("/refreshPassword", webWrapper(s.refreshPassword))
+			r.Post("/plagiarismCheck", webWrapper(s.checkUserPlagiarism))
 		})
 
-		r.With(s.MustBeAuthed, s.authedContentUser).Mount("/self", userRouter)
+		r.With(s.MustBeAuthed, s.requireScopes(auth.ScopeProfileRead), s.authedContentUser).Mount("/self", userRouter)
 		r.With(s.validateUserID).Mount("/byID/{cUID}", userRouter)
 		r.With(s.validateUsername).Mount("/byName/{cUName}", userRouter)
 
-		r.With(s.MustBeAuthed).Post("/updateName", s.updateUsername)
+		r.With(s.MustBeAuthed, s.MustUseSession).Post("/updateName", s.updateUsername)
+		r.With(s.MustBeAuthed, s.MustUseSession).Post("/createAPIToken", webWrapper(s.createAPIToken))
 
 		r.With(s.MustBeAdmin).Post("/generateUser", s.generateUser)
 
 		// TODO: Make this secure and maybe with email stuff
-		r.With(s.MustBeAuthed).Post("/changeEmail", s.changeEmail)
-		r.With(s.MustBeAuthed).Post("/changePassword", s.changePassword)
+		r.With(s.MustBeAuthed, s.MustUseSession).Post("/changeEmail", s.changeEmail)
+		r.With(s.MustBeAuthed, s.MustUseSession).Post("/changePassword", s.changePassword)
+	})
+	r.Route("/team", func(r chi.Router) {
+		r.Use(s.MustBeAuthed)
+
+		r.With(s.requireScopes(auth.ScopeProfileRead)).Get("/mine", webWrapper(s.userTeams))
+		r.With(s.requireScopes(auth.ScopeProfileWrite)).Post("/create", webWrapper(s.createTeam))
+		r.With(s.requireScopes(auth.ScopeProfileWrite)).Post("/join", webWrapper(s.joinTeam))
+		r.With(s.requireScopes(auth.ScopeProfileWrite)).Post("/rename", webMessageWrapper("Renamed team", s.renameTeam))
+		r.With(s.requireScopes(auth.ScopeProfileWrite)).Post("/leave", webMessageWrapper("Left team", s.leaveTeam))
+		r.With(s.requireScopes(auth.ScopeProfileWrite)).Post("/removeMember", webMessageWrapper("Removed team member", s.removeTeamMember))
+		r.With(s.requireScopes(auth.ScopeProfileWrite)).Post("/delete", webMessageWrapper("Deleted team", s.deleteTeam))
 	})
 	r.Route("/problemList", func(r chi.Router) {
 		r.Get("/filter", s.problemLists)
 		r.Get("/byName", s.problemListByName)
-		r.With(s.MustBeProposer).Post("/create", s.initProblemList)
+		r.With(s.MustBeProposer, s.requireScopes(auth.ScopeProblemsEdit)).Post("/create", s.initProblemList)
 
 		r.Route("/{pblistID}", func(r chi.Router) {
 			r.Use(s.validateProblemListID)
 			r.Get("/", webWrapper(s.getProblemList))
 
-			r.With(s.MustBeAuthed).Post("/update", s.updateProblemList)
-			r.With(s.MustBeAuthed).Post("/delete", s.deleteProblemList)
+			r.With(s.MustBeAuthed, s.requireScopes(auth.ScopeProblemsEdit)).Post("/update", s.updateProblemList)
+			r.With(s.MustBeAuthed, s.requireScopes(auth.ScopeProblemsEdit)).Post("/delete", s.deleteProblemList)
 
-			r.With(s.MustBeAdmin).Post("/toggleProblems", s.togglePblistProblems)
+			r.With(s.MustBeAdmin, s.requireScopes(auth.ScopeProblemsEdit)).Post("/toggleProblems", s.togglePblistProblems)
 		})
 	})
 
 	r.Route("/contest", func(r chi.Router) {
-		r.With(s.MustBeAuthed).Post("/create", s.createContest)
+		r.With(s.MustBeAuthed, s.requireScopes(auth.ScopeContestsManage)).Post("/create", s.createContest)
 
-		r.With(s.MustBeAuthed).Post("/acceptInvitation", webMessageWrapper("Registered for contest", s.acceptContestInvitation))
-		r.With(s.MustBeAuthed).Post("/updateInvitation", webMessageWrapper("Updated invitation", s.updateContestInvitation))
+		r.With(s.MustBeAuthed, s.requireScopes(auth.ScopeContestsParticipate)).Post("/acceptInvitation", webMessageWrapper("Registered for contest", s.acceptContestInvitation))
+		r.With(s.MustBeAuthed, s.requireScopes(auth.ScopeContestsManage)).Post("/updateInvitation", webMessageWrapper("Updated invitation", s.updateContestInvitation))
 
 		r.Route("/{contestID}", func(r chi.Router) {
 			r.Use(s.validateContestID)
@@ -433,45 +602,62 @@ func (s *API) HandlerV1() http.Handler {
 			r.With(s.MustBeAuthed).Get("/problemRemainingCount", s.getRemainingSubmissionCount)
 
 			r.Get("/leaderboard", s.contestLeaderboard)
+			r.Get("/events", s.contestEvents)
 
 			r.Get("/questions", webWrapper(s.contestUserQuestions))
-			r.With(s.validateContestEditor).Get("/allQuestions", webWrapper(s.contestAllQuestions))
-			r.With(s.validateContestParticipant).Post("/askQuestion", s.askContestQuestion)
-			r.With(s.validateContestEditor).Post("/answerQuestion", s.answerContestQuestion)
+			r.With(s.validateContestEditor, s.requireScopes(auth.ScopeContestsManage)).Get("/allQuestions", webWrapper(s.contestAllQuestions))
+			r.With(s.validateContestParticipant, s.requireScopes(auth.ScopeContestsParticipate)).Post("/askQuestion", s.askContestQuestion)
+			r.With(s.validateContestEditor, s.requireScopes(auth.ScopeContestsManage)).Post("/answerQuestion", s.answerContestQuestion)
 
 			r.Get("/announcements", webWrapper(s.contestAnnouncements))
-			r.With(s.validateContestEditor).Post("/createAnnouncement", webMessageWrapper("Created announcement", s.createContestAnnouncement))
-			r.With(s.validateContestEditor).Post("/updateAnnouncement", webMessageWrapper("Updated announcement", s.updateContestAnnouncement))
-			r.With(s.validateContestEditor).Post("/deleteAnnouncement", webMessageWrapper("Removed announcement", s.deleteContestAnnouncement))
-
-			r.With(s.MustBeAuthed).Post("/register", s.registerForContest)
-			r.With(s.MustBeAuthed).Post("/startRegistration", s.startContestRegistration)
-			r.With(s.validateContestEditor).Post("/runMOSS", webMessageWrapper("Sent submissions to MOSS. It should be done soon", s.runMOSS))
-
-			r.With(s.validateContestEditor).Get("/invitations", webWrapper(func(ctx context.Context, _ struct{}) ([]*kilonova.ContestInvitation, error) {
+			r.With(s.validateContestEditor, s.requireScopes(auth.ScopeContestsManage)).Post("/createAnnouncement", webMessageWrapper("Created announcement", s.createContestAnnouncement))
+			r.With(s.validateContestEditor, s.requireScopes(auth.ScopeContestsManage)).Post("/updateAnnouncement", webMessageWrapper("Updated announcement", s.updateContestAnnouncement))
+			r.With(s.validateContestEditor, s.requireScopes(auth.ScopeContestsManage)).Post("/deleteAnnouncement", webMessageWrapper("Removed announcement", s.deleteContestAnnouncement))
+
+			r.With(s.MustBeAuthed, s.requireScopes(auth.ScopeContestsParticipate)).Post("/register", s.registerForContest)
+			r.With(s.MustBeAuthed, s.requireScopes(auth.ScopeContestsParticipate)).Post("/registerTeam", webMessageWrapper("Registered team for contest", s.registerTeamForContest))
+			r.Get("/teams", webWrapper(s.contestTeams))
+			r.With(s.MustBeAuthed, s.requireScopes(auth.ScopeContestsParticipate)).Post("/startRegistration", s.startContestRegistration)
+			r.With(s.MustBeAuthed, s.requireScopes(auth.ScopeContestsParticipate)).Post("/startVirtual", webWrapper(s.startVirtualParticipation))
+			r.With(s.MustBeAuthed).Get("/virtualStanding", webWrapper(s.virtualStanding))
+			r.With(s.validateContestEditor, s.requireScopes(auth.ScopeContestsManage)).Post("/runMOSS", webMessageWrapper("Started plagiarism check. It should be done soon", s.runMOSS))
+
+			r.With(s.validateContestEditor, s.requireScopes(auth.ScopeContestsManage)).Get("/webhooks", webWrapper(s.contestWebhooks))
+			r.With(s.validateContestEditor, s.requireScopes(auth.ScopeContestsManage)).Post("/createWebhook", webWrapper(s.createContestWebhook))
+			r.With(s.validateContestEditor, s.requireScopes(auth.ScopeContestsManage)).Post("/deleteWebhook", webMessageWrapper("Deleted webhook", s.deleteContestWebhook))
+
+			r.With(s.validateContestEditor, s.requireScopes(auth.ScopeContestsManage)).Get("/invitations", webWrapper(func(ctx context.Context, _ struct{}) ([]*kilonova.ContestInvitation, error) {
 				return s.base.ContestInvitations(ctx, util.ContestContext(ctx).ID)
 			}))
-			r.With(s.validateContestEditor).Post("/createInvitation", webWrapper(func(ctx context.Context, args struct {
-				MaxUses int `json:"max_uses"`
+			r.With(s.validateContestEditor, s.requireScopes(auth.ScopeContestsManage)).Post("/createInvitation", webWrapper(func(ctx context.Context, args struct {
+				MaxUses    int  `json:"max_uses"`
+				CategoryID *int `json:"category_id"`
 			}) (string, error) {
 				var cnt *int
 				if args.MaxUses > 0 {
 					cnt = &args.MaxUses
 				}
-				return s.base.CreateContestInvitation(ctx, util.ContestContext(ctx).ID, user.UserBriefContext(ctx), cnt)
+				return s.base.CreateContestInvitation(ctx, util.ContestContext(ctx).ID, user.UserBriefContext(ctx), cnt, args.CategoryID)
 			}))
 
+			r.Get("/categories", webWrapper(s.contestCategories))
+			r.With(s.validateContestEditor, s.requireScopes(auth.ScopeContestsManage)).Post("/createCategory", webWrapper(s.createContestCategory))
+			r.With(s.validateContestEditor, s.requireScopes(auth.ScopeContestsManage)).Post("/updateCategory", webMessageWrapper("Updated category", s.updateContestCategory))
+			r.With(s.validateContestEditor, s.requireScopes(auth.ScopeContestsManage)).Post("/deleteCategory", webMessageWrapper("Deleted category", s.deleteContestCategory))
+			r.With(s.validateContestEditor, s.requireScopes(auth.ScopeContestsManage)).Post("/setRegistrationCategory", webMessageWrapper("Updated registration category", s.setRegistrationCategory))
+
 			r.With(s.MustBeAuthed).Get("/checkRegistration", webWrapper(s.checkRegistration))
-			r.With(s.validateContestEditor).Get("/registrations", s.contestRegistrations)
-			r.With(s.validateContestEditor).Post("/kickUser", s.stripContestRegistration)
-			r.With(s.MustBeAdmin).Post("/forceRegister", s.forceRegisterForContest)
-			r.With(s.validateContestEditor).Post("/clone", s.cloneContest)
-			r.With(s.validateContestEditor).Post("/delete", webMessageWrapper("Deleted contest", func(ctx context.Context, _ struct{}) error {
+			r.With(s.validateContestEditor, s.requireScopes(auth.ScopeContestsManage)).Get("/registrations", s.contestRegistrations)
+			r.With(s.validateContestEditor, s.requireScopes(auth.ScopeContestsManage)).Post("/kickUser", s.stripContestRegistration)
+			r.With(s.MustBeAdmin, s.requireScopes(auth.ScopeContestsManage)).Post("/forceRegister", s.forceRegisterForContest)
+			r.With(s.validateContestEditor, s.requireScopes(auth.ScopeContestsManage)).Post("/clone", s.cloneContest)
+			r.With(s.validateContestEditor, s.requireScopes(auth.ScopeContestsManage)).Post("/delete", webMessageWrapper("Deleted contest", func(ctx context.Context, _ struct{}) error {
 				return s.base.DeleteContest(ctx, util.ContestContext(ctx))
 			}))
 
 			r.Route("/update", func(r chi.Router) {
 				r.Use(s.validateContestEditor)
+				r.Use(s.requireScopes(auth.ScopeContestsManage))
 
 				r.Post("/", s.updateContest)
 				r.Post("/problems", s.updateContestProblems)
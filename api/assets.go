@@ -5,6 +5,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/csv"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"image"
@@ -18,6 +19,7 @@ import (
 	"os"
 	"path"
 	"strconv"
+	"strings"
 	"sync"
 	"time"
 
@@ -27,8 +29,10 @@ import (
 	"github.com/KiloProjects/kilonova"
 	"github.com/KiloProjects/kilonova/domain/archive/test"
 	"github.com/KiloProjects/kilonova/domain/user"
+	"github.com/KiloProjects/kilonova/internal/clics"
 	"github.com/KiloProjects/kilonova/internal/util"
 	"github.com/KiloProjects/kilonova/sudoapi"
+	"github.com/KiloProjects/kilonova/util/slicealg"
 	"github.com/Yiling-J/theine-go"
 	"github.com/disintegration/gift"
 	"github.com/go-chi/chi/v5"
@@ -84,8 +88,11 @@ func (s *Assets) AssetsRouter() http.Handler {
 	r.With(s.api.MustBeAdmin).Get("/submissions/export", s.ExportSubmissions())
 
 	r.With(s.api.MustBeProposer).Get("/subtest/{subtestID}", s.ServeSubtest)
+	r.With(s.api.MustBeProposer).Get("/subtest/{subtestID}/transcript", s.ServeSubtestTranscript)
 
 	r.With(s.api.validateContestID).Get("/contest/{contestID}/leaderboard.csv", s.ServeContestLeaderboard)
+	r.With(s.api.validateContestID, s.api.validateContestEditor).Get("/contest/{contestID}/event-feed.ndjson", s.ServeContestEventFeed)
+	r.With(s.api.validateContestID, s.api.validateContestEditor).Get("/contest/{contestID}/contest.json", s.ServeContestPackage)
 
 	return r
 }
@@ -208,6 +215,8 @@ func (s *Assets) ServeContestLeaderboard(w http.ResponseWriter, r *http.Request)
 	var buf bytes.Buffer
 	wr := csv.NewWriter(&buf)
 
+	teamContest := util.Contest(r).TeamContest()
+
 	var hasDisplayName bool
 	for _, entry := range ld.Entries {
 		if entry.User != nil && entry.User.DisplayName != "" {
@@ -218,9 +227,16 @@ func (s *Assets) ServeContestLeaderboard(w http.ResponseWriter, r *http.Request)
 
 	// Header
 	header := []string{"username"}
-	if hasDisplayName {
+	if teamContest {
+		// Team contests list the team and its members instead
+		header = []string{"team", "members"}
+	} else if hasDisplayName {
 		header = append(header, "display_name")
 	}
+	header = append([]string{"rank"}, header...)
+	if len(ld.Categories) > 0 {
+		header = append(header, "category")
+	}
 	for _, pb := range ld.ProblemOrder {
 		name, ok := ld.ProblemNames[pb]
 		if !ok {
@@ -243,9 +259,22 @@ func (s *Assets) ServeContestLeaderboard(w http.ResponseWriter, r *http.Request)
 	}
 	for _, entry := range ld.Entries {
 		line := []string{entry.User.Name}
-		if hasDisplayName {
+		if teamContest {
+			line = []string{entry.User.Name, entry.User.Name}
+			if entry.Team != nil {
+				line = []string{entry.Team.Name, strings.Join(slicealg.Map(entry.Members, func(member *kilonova.UserBrief) string { return member.Name }), " ")}
+			}
+		} else if hasDisplayName {
 			line = append(line, entry.User.DisplayName)
 		}
+		rank := "-"
+		if entry.Rank != nil {
+			rank = strconv.Itoa(*entry.Rank)
+		}
+		line = append([]string{rank}, line...)
+		if len(ld.Categories) > 0 {
+			line = append(line, csvCategoryName(ld.Categories, entry.CategoryID))
+		}
 		if util.Contest(r).LeaderboardStyle == kilonova.LeaderboardTypeICPC {
 			for _, pb := range ld.ProblemOrder {
 				score, ok := entry.ProblemScores[pb]
@@ -302,26 +331,92 @@ func (s *Assets) ServeContestLeaderboard(w http.ResponseWriter, r *http.Request)
 	http.ServeContent(w, r, "leaderboard.csv", time.Now(), bytes.NewReader(buf.Bytes()))
 }
 
-func (s *Assets) ServeSubtest(w http.ResponseWriter, r *http.Request) {
+func csvCategoryName(categories []*kilonova.ContestCategory, id *int) string {
+	if id == nil {
+		return ""
+	}
+	for _, category := range categories {
+		if category.ID == *id {
+			return category.Name
+		}
+	}
+	return ""
+}
+
+// contestPackage exports the contest in the CLICS format. The export is frozen only if asked for,
+// since it's mostly used to feed the ICPC Resolver, which does the unfreezing by itself.
+func (s *Assets) contestPackage(r *http.Request) (*clics.Package, error) {
+	var args struct {
+		Frozen bool `json:"frozen"`
+	}
+	if err := parseRequest(r, &args); err != nil {
+		return nil, kilonova.Statusf(400, "Can't decode parameters")
+	}
+	contest := util.Contest(r)
+	return s.base.ContestPackage(r.Context(), contest, s.base.UserContestFreezeTime(user.UserBrief(r), contest, args.Frozen))
+}
+
+func (s *Assets) ServeContestEventFeed(w http.ResponseWriter, r *http.Request) {
+	pkg, err := s.contestPackage(r)
+	if err != nil {
+		http.Error(w, err.Error(), kilonova.ErrorCode(err))
+		return
+	}
+	var buf bytes.Buffer
+	if err := pkg.WriteEventFeed(&buf); err != nil {
+		slog.WarnContext(r.Context(), "Could not write event feed", slog.Any("err", err))
+		http.Error(w, "Couldn't write event feed", 500)
+		return
+	}
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	http.ServeContent(w, r, "event-feed.ndjson", time.Now(), bytes.NewReader(buf.Bytes()))
+}
+
+func (s *Assets) ServeContestPackage(w http.ResponseWriter, r *http.Request) {
+	pkg, err := s.contestPackage(r)
+	if err != nil {
+		http.Error(w, err.Error(), kilonova.ErrorCode(err))
+		return
+	}
+	data, err := json.Marshal(pkg)
+	if err != nil {
+		slog.WarnContext(r.Context(), "Could not encode contest package", slog.Any("err", err))
+		http.Error(w, "Couldn't encode contest", 500)
+		return
+	}
+	http.ServeContent(w, r, "contest.json", time.Now(), bytes.NewReader(data))
+}
+
+// editableSubtest returns the subtest from the request's path, if the user is an editor of its problem.
+// Otherwise, it writes the error and returns nil.
+func (s *Assets) editableSubtest(w http.ResponseWriter, r *http.Request) *kilonova.SubTest {
 	id, err := strconv.Atoi(r.PathValue("subtestID"))
 	if err != nil {
 		http.Error(w, "Bad ID", 400)
-		return
+		return nil
 	}
 	subtest, err := s.base.SubTest(r.Context(), id)
 	if err != nil {
 		http.Error(w, "Invalid subtest", 400)
-		return
+		return nil
 	}
 	sub, err := s.base.Submission(r.Context(), subtest.SubmissionID, user.UserBrief(r))
 	if err != nil {
 		slog.WarnContext(r.Context(), "Error loading submission", slog.Any("err", err))
 		http.Error(w, "Couldn't get submission", 500)
-		return
+		return nil
 	}
 
 	if !s.base.IsProblemEditor(user.UserBrief(r), sub.Problem) {
 		http.Error(w, "You aren't allowed to do that!", http.StatusUnauthorized)
+		return nil
+	}
+	return subtest
+}
+
+func (s *Assets) ServeSubtest(w http.ResponseWriter, r *http.Request) {
+	subtest := s.editableSubtest(w, r)
+	if subtest == nil {
 		return
 	}
 
@@ -335,6 +430,23 @@ func (s *Assets) ServeSubtest(w http.ResponseWriter, r *http.Request) {
 	io.Copy(w, rc)
 }
 
+func (s *Assets) ServeSubtestTranscript(w http.ResponseWriter, r *http.Request) {
+	subtest := s.editableSubtest(w, r)
+	if subtest == nil {
+		return
+	}
+
+	rc, err := s.base.SubtestTranscriptReader(subtest.ID)
+	if err != nil {
+		http.Error(w, "The transcript may have been purged as a routine data-saving process, or it was never recorded", 404)
+		return
+	}
+	defer rc.Close()
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.WriteHeader(200)
+	io.Copy(w, rc)
+}
+
 func (s *Assets) ServeTestInput(w http.ResponseWriter, r *http.Request) {
 	rr, err := s.base.TestInput(util.Test(r).ID)
 	if err != nil {
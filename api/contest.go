@@ -84,6 +84,24 @@ func (s *API) updateContest(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if args.MaxTeamSize != nil && *args.MaxTeamSize != util.Contest(r).MaxTeamSize {
+		if *args.MaxTeamSize < 0 {
+			errorData(w, "Team size can't be negative.", 400)
+			return
+		}
+		if (*args.MaxTeamSize > 0) != util.Contest(r).TeamContest() {
+			cnt, err := s.base.ContestRegistrationCount(r.Context(), util.Contest(r).ID)
+			if err != nil {
+				statusError(w, err)
+				return
+			}
+			if cnt > 0 {
+				errorData(w, "Contests with registrations can't switch between individual and team participation.", 400)
+				return
+			}
+		}
+	}
+
 	if err := s.base.UpdateContest(r.Context(), util.Contest(r).ID, args); err != nil {
 		statusError(w, err)
 		return
@@ -179,6 +197,9 @@ type contestLeaderboardParams struct {
 	Frozen bool `json:"frozen"`
 
 	Generated *bool `json:"generated_acc"`
+
+	CategoryID   *int `json:"category_id"`
+	OfficialOnly bool `json:"official_only"`
 }
 
 func (s *API) leaderboard(ctx context.Context, contest *kilonova.Contest, lookingUser *kilonova.UserBrief, args *contestLeaderboardParams) (*kilonova.ContestLeaderboard, error) {
@@ -189,7 +210,11 @@ func (s *API) leaderboard(ctx context.Context, contest *kilonova.Contest, lookin
 	return s.base.ContestLeaderboard(
 		ctx, contest,
 		s.base.UserContestFreezeTime(lookingUser, contest, args.Frozen),
-		args.Generated,
+		kilonova.LeaderboardFilter{
+			Generated:    args.Generated,
+			CategoryID:   args.CategoryID,
+			OfficialOnly: args.OfficialOnly,
+		},
 	)
 }
 
@@ -399,6 +424,8 @@ func (s *API) answerContestQuestion(w http.ResponseWriter, r *http.Request) {
 
 func (s *API) acceptContestInvitation(ctx context.Context, args struct {
 	InviteID string `json:"invite_id"`
+	// TeamID is required for team contests
+	TeamID *int `json:"team_id"`
 }) error {
 	inv, err := s.base.ContestInvitation(ctx, args.InviteID)
 	if err != nil {
@@ -420,7 +447,17 @@ func (s *API) acceptContestInvitation(ctx context.Context, args struct {
 	if !contest.RegisterDuringContest && contest.Running() {
 		return kilonova.Statusf(400, "Cannot register while contest is running")
 	}
-	return s.base.RegisterContestUser(ctx, contest, user.UserBriefContext(ctx).ID, &inv.ID, true)
+	if contest.TeamContest() {
+		if args.TeamID == nil {
+			return kilonova.Statusf(400, "Team contests must be joined with a team")
+		}
+		team, err := s.team(ctx, *args.TeamID)
+		if err != nil {
+			return err
+		}
+		return s.base.RegisterContestTeam(ctx, contest, team, &inv.ID, inv.CategoryID, true)
+	}
+	return s.base.RegisterContestUser(ctx, contest, user.UserBriefContext(ctx).ID, &inv.ID, inv.CategoryID, true)
 }
 
 func (s *API) updateContestInvitation(ctx context.Context, args struct {
@@ -442,7 +479,14 @@ func (s *API) updateContestInvitation(ctx context.Context, args struct {
 }
 
 func (s *API) registerForContest(w http.ResponseWriter, r *http.Request) {
-	if err := s.base.RegisterContestUser(r.Context(), util.Contest(r), user.UserBrief(r).ID, nil, false); err != nil {
+	var args struct {
+		CategoryID *int `json:"category_id"`
+	}
+	if err := parseRequest(r, &args); err != nil {
+		errorData(w, err, 400)
+		return
+	}
+	if err := s.base.RegisterContestUser(r.Context(), util.Contest(r), user.UserBrief(r).ID, nil, args.CategoryID, false); err != nil {
 		statusError(w, err)
 		return
 	}
@@ -459,7 +503,8 @@ func (s *API) startContestRegistration(w http.ResponseWriter, r *http.Request) {
 
 func (s *API) forceRegisterForContest(w http.ResponseWriter, r *http.Request) {
 	var args struct {
-		Username string `json:"name"`
+		Username   string `json:"name"`
+		CategoryID *int   `json:"category_id"`
 	}
 	if err := parseRequest(r, &args); err != nil {
 		errorData(w, err, 400)
@@ -477,7 +522,7 @@ func (s *API) forceRegisterForContest(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := s.base.RegisterContestUser(r.Context(), util.Contest(r), userBrief.ID, nil, true); err != nil {
+	if err := s.base.RegisterContestUser(r.Context(), util.Contest(r), userBrief.ID, nil, args.CategoryID, true); err != nil {
 		statusError(w, err)
 		return
 	}
@@ -488,6 +533,22 @@ func (s *API) checkRegistration(ctx context.Context, _ struct{}) (*kilonova.Cont
 	return s.base.ContestRegistration(ctx, util.ContestContext(ctx).ID, user.UserBriefContext(ctx).ID)
 }
 
+func (s *API) startVirtualParticipation(ctx context.Context, _ struct{}) (*kilonova.VirtualParticipation, error) {
+	return s.base.StartVirtualParticipation(ctx, util.ContestContext(ctx), user.UserBriefContext(ctx))
+}
+
+func (s *API) virtualStanding(ctx context.Context, _ struct{}) (*kilonova.VirtualStanding, error) {
+	contest := util.ContestContext(ctx)
+	vp, err := s.base.VirtualParticipation(ctx, contest, user.UserBriefContext(ctx).ID)
+	if err != nil {
+		return nil, err
+	}
+	if vp == nil {
+		return nil, kilonova.Statusf(404, "You don't have a virtual participation in this contest")
+	}
+	return s.base.VirtualStanding(ctx, contest, vp, user.UserBriefContext(ctx))
+}
+
 func (s *API) stripContestRegistration(w http.ResponseWriter, r *http.Request) {
 	var args struct {
 		Username string `json:"name"`
@@ -533,16 +594,30 @@ func (s *API) contestRegistrations(w http.ResponseWriter, r *http.Request) {
 		args.Limit = 50
 	}
 
-	regs, err := s.base.ContestRegistrations(r.Context(), util.Contest(r).ID, args.FuzzyName, args.InvitationID, args.Limit, args.Offset)
+	rez, cnt, err := s.registrationsWithUsers(r.Context(), util.Contest(r).ID, args.FuzzyName, args.InvitationID, args.Limit, args.Offset)
 	if err != nil {
 		statusError(w, err)
 		return
 	}
 
-	cnt, err := s.base.ContestRegistrationCount(r.Context(), util.Contest(r).ID)
+	returnData(w, registrationsResult{Registrations: rez, Count: cnt})
+}
+
+type registrationsResult struct {
+	Registrations []regRez `json:"registrations"`
+
+	Count int `json:"total_count"`
+}
+
+func (s *API) registrationsWithUsers(ctx context.Context, contestID int, fuzzyName, invitationID *string, limit, offset uint64) ([]regRez, int, error) {
+	regs, err := s.base.ContestRegistrations(ctx, contestID, fuzzyName, invitationID, limit, offset)
 	if err != nil {
-		statusError(w, err)
-		return
+		return nil, -1, err
+	}
+
+	cnt, err := s.base.ContestRegistrationCount(ctx, contestID)
+	if err != nil {
+		return nil, -1, err
 	}
 
 	regMap := make(map[int]*kilonova.ContestRegistration)
@@ -556,39 +631,34 @@ func (s *API) contestRegistrations(w http.ResponseWriter, r *http.Request) {
 		ids = append(ids, reg.UserID)
 	}
 
-	users, err := s.base.UsersBrief(r.Context(), kilonova.UserFilter{
+	users, err := s.base.UsersBrief(ctx, kilonova.UserFilter{
 		IDs: ids,
 	})
 	if err != nil {
-		statusError(w, err)
-		return
+		return nil, -1, err
 	}
 
 	var rez = make([]regRez, 0, len(users))
 	if len(users) != len(regs) {
-		slog.WarnContext(r.Context(), "mismatched user and reg length", slog.Int("users_len", len(users)), slog.Int("regs_len", len(regs)))
+		slog.WarnContext(ctx, "mismatched user and reg length", slog.Int("users_len", len(users)), slog.Int("regs_len", len(regs)))
 	}
 
 	for _, userBrief := range users {
 		val, ok := regMap[userBrief.ID]
 		if !ok {
-			slog.WarnContext(r.Context(), "Couldn't find user in registrations", slog.Any("user", userBrief))
+			slog.WarnContext(ctx, "Couldn't find user in registrations", slog.Any("user", userBrief))
 		}
 		rez = append(rez, regRez{User: userBrief, Reg: val})
 	}
 
-	returnData(w, struct {
-		Registrations []regRez `json:"registrations"`
-
-		Count int `json:"total_count"`
-	}{Registrations: rez, Count: cnt})
+	return rez, cnt, nil
 }
 
-func (s *API) runMOSS(ctx context.Context, _ struct{}) error {
+func (s *API) runMOSS(ctx context.Context, args kilonova.PlagiarismOptions) error {
 	if util.ContestContext(ctx).Type != kilonova.ContestTypeOfficial {
 		return kilonova.Statusf(400, "MOSS can't run on virtual contests, for now")
 	}
-	return s.base.RunMOSS(context.WithoutCancel(ctx), util.ContestContext(ctx))
+	return s.base.RunPlagiarismCheck(context.WithoutCancel(ctx), util.ContestContext(ctx), args)
 }
 
 ///
@@ -652,6 +722,13 @@ type apiContest struct {
 	// that someone is allowed to send to a problem during a contest.
 	// Any number < 0 means no limit
 	MaxSubs int `json:"max_subs"`
+
+	// MaxTeamSize is the maximum number of members of a registered team.
+	// Individual contests have it set to 0
+	MaxTeamSize int `json:"max_team_size"`
+
+	Upsolving            bool `json:"upsolving"`
+	VirtualParticipation bool `json:"virtual_participation"`
 }
 
 func contestFromDomain(c *kilonova.Contest) *apiContest {
@@ -677,6 +754,9 @@ func contestFromDomain(c *kilonova.Contest) *apiContest {
 		PerUserTime:               c.PerUserTime,
 		Type:                      c.Type,
 		MaxSubs:                   c.MaxSubs,
+		MaxTeamSize:               c.MaxTeamSize,
+		Upsolving:                 c.Upsolving,
+		VirtualParticipation:      c.VirtualParticipation,
 	}
 }
 
@@ -719,3 +799,175 @@ type ContestSingleGetOutput struct {
 func (s *API) contestSingleGet(ctx context.Context, _ *struct{}) (*ContestSingleGetOutput, error) {
 	return &ContestSingleGetOutput{contestFromDomain(util.ContestContext(ctx))}, nil
 }
+
+type ContestLeaderboardInput struct {
+	Frozen       bool   `query:"frozen" doc:"Editors only: show the frozen leaderboard, as contestants see it"`
+	Accounts     string `query:"accounts" enum:"all,generated,regular" default:"all" doc:"Only include generated or regular accounts"`
+	Category     int    `query:"category" doc:"Only include contestants from this registration category"`
+	OfficialOnly bool   `query:"official_only" doc:"Exclude contestants from unofficial registration categories"`
+}
+
+type ContestLeaderboardOutput struct {
+	Body *kilonova.ContestLeaderboard
+}
+
+func (s *API) contestLeaderboardV2(ctx context.Context, input *ContestLeaderboardInput) (*ContestLeaderboardOutput, error) {
+	args := &contestLeaderboardParams{Frozen: input.Frozen, OfficialOnly: input.OfficialOnly}
+	if input.Category > 0 {
+		args.CategoryID = &input.Category
+	}
+	switch input.Accounts {
+	case "generated":
+		args.Generated = new(true)
+	case "regular":
+		args.Generated = new(false)
+	}
+	ld, err := s.leaderboard(ctx, util.ContestContext(ctx), user.UserBriefContext(ctx), args)
+	if err != nil {
+		return nil, err
+	}
+	return &ContestLeaderboardOutput{ld}, nil
+}
+
+type ContestAnnouncementsOutput struct {
+	Body []*kilonova.ContestAnnouncement
+}
+
+func (s *API) contestAnnouncementsV2(ctx context.Context, _ *struct{}) (*ContestAnnouncementsOutput, error) {
+	announcements, err := s.contestAnnouncements(ctx, struct{}{})
+	return &ContestAnnouncementsOutput{announcements}, err
+}
+
+type ContestQuestionsOutput struct {
+	Body []*kilonova.ContestQuestion
+}
+
+func (s *API) contestUserQuestionsV2(ctx context.Context, _ *struct{}) (*ContestQuestionsOutput, error) {
+	questions, err := s.contestUserQuestions(ctx, struct{}{})
+	return &ContestQuestionsOutput{questions}, err
+}
+
+func (s *API) contestAllQuestionsV2(ctx context.Context, _ *struct{}) (*ContestQuestionsOutput, error) {
+	questions, err := s.contestAllQuestions(ctx, struct{}{})
+	return &ContestQuestionsOutput{questions}, err
+}
+
+type ContestAskQuestionInput struct {
+	Body struct {
+		Text string `json:"text" minLength:"1"`
+	}
+}
+
+type ContestAskQuestionOutput struct {
+	Body int `doc:"ID of the new question"`
+}
+
+func (s *API) askContestQuestionV2(ctx context.Context, input *ContestAskQuestionInput) (*ContestAskQuestionOutput, error) {
+	id, err := s.base.CreateContestQuestion(ctx, util.ContestContext(ctx), user.UserBriefContext(ctx).ID, input.Body.Text)
+	if err != nil {
+		return nil, err
+	}
+	return &ContestAskQuestionOutput{id}, nil
+}
+
+type ContestRegistrationsInput struct {
+	FuzzyName    string `query:"name_fuzzy"`
+	InvitationID string `query:"invitation_id"`
+
+	Limit  uint64 `query:"limit" maximum:"50" default:"50"`
+	Offset uint64 `query:"offset"`
+}
+
+type ContestRegistrationsOutput struct {
+	Body registrationsResult
+}
+
+func (s *API) contestRegistrationsV2(ctx context.Context, input *ContestRegistrationsInput) (*ContestRegistrationsOutput, error) {
+	var fuzzyName, invitationID *string
+	if input.FuzzyName != "" {
+		fuzzyName = &input.FuzzyName
+	}
+	if input.InvitationID != "" {
+		invitationID = &input.InvitationID
+	}
+	rez, cnt, err := s.registrationsWithUsers(ctx, util.ContestContext(ctx).ID, fuzzyName, invitationID, cmp.Or(input.Limit, 50), input.Offset)
+	if err != nil {
+		return nil, err
+	}
+	return &ContestRegistrationsOutput{registrationsResult{Registrations: rez, Count: cnt}}, nil
+}
+
+type ContestRegistrationOutput struct {
+	Body *kilonova.ContestRegistration
+}
+
+func (s *API) contestRegistrationV2(ctx context.Context, _ *struct{}) (*ContestRegistrationOutput, error) {
+	reg, err := s.checkRegistration(ctx, struct{}{})
+	if err != nil {
+		return nil, err
+	}
+	return &ContestRegistrationOutput{reg}, nil
+}
+
+type ContestRegisterInput struct {
+	Body *struct {
+		CategoryID *int `json:"category_id,omitempty" required:"false" doc:"Self-assignable registration category to register in"`
+	}
+}
+
+func (s *API) registerForContestV2(ctx context.Context, input *ContestRegisterInput) (*struct{}, error) {
+	var categoryID *int
+	if input.Body != nil {
+		categoryID = input.Body.CategoryID
+	}
+	if err := s.base.RegisterContestUser(ctx, util.ContestContext(ctx), user.UserBriefContext(ctx).ID, nil, categoryID, false); err != nil {
+		return nil, err
+	}
+	return nil, nil
+}
+
+// Registration categories
+
+func (s *API) contestCategories(ctx context.Context, _ struct{}) ([]*kilonova.ContestCategory, error) {
+	return s.base.ContestCategories(ctx, util.ContestContext(ctx).ID)
+}
+
+type contestCategoryArgs struct {
+	Name           string `json:"name"`
+	Official       bool   `json:"official"`
+	SelfAssignable bool   `json:"self_assignable"`
+}
+
+func (s *API) createContestCategory(ctx context.Context, args contestCategoryArgs) (int, error) {
+	return s.base.CreateContestCategory(ctx, util.ContestContext(ctx).ID, args.Name, args.Official, args.SelfAssignable)
+}
+
+func (s *API) updateContestCategory(ctx context.Context, args struct {
+	ID             int    `json:"id"`
+	Name           string `json:"name"`
+	Official       bool   `json:"official"`
+	SelfAssignable bool   `json:"self_assignable"`
+}) error {
+	category, err := s.base.ContestCategory(ctx, util.ContestContext(ctx).ID, args.ID)
+	if err != nil {
+		return err
+	}
+	return s.base.UpdateContestCategory(ctx, category, args.Name, args.Official, args.SelfAssignable)
+}
+
+func (s *API) deleteContestCategory(ctx context.Context, args struct {
+	ID int `json:"id"`
+}) error {
+	category, err := s.base.ContestCategory(ctx, util.ContestContext(ctx).ID, args.ID)
+	if err != nil {
+		return err
+	}
+	return s.base.DeleteContestCategory(ctx, category)
+}
+
+func (s *API) setRegistrationCategory(ctx context.Context, args struct {
+	UserID     int  `json:"user_id"`
+	CategoryID *int `json:"category_id"`
+}) error {
+	return s.base.SetContestRegistrationCategory(ctx, util.ContestContext(ctx).ID, args.UserID, args.CategoryID)
+}
@@ -0,0 +1,86 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/KiloProjects/kilonova/domain/user"
+	"github.com/KiloProjects/kilonova/internal/pubsub"
+	"github.com/KiloProjects/kilonova/internal/util"
+)
+
+const eventsKeepalive = 25 * time.Second
+
+func (s *API) submissionEvents(w http.ResponseWriter, r *http.Request) {
+	var args struct {
+		SubID int `json:"id"`
+	}
+	if err := parseRequest(r, &args); err != nil {
+		errorData(w, err, http.StatusBadRequest)
+		return
+	}
+
+	events, cancel, err := s.base.SubscribeSubmission(r.Context(), args.SubID, user.UserBrief(r))
+	if err != nil {
+		statusError(w, err)
+		return
+	}
+	defer cancel()
+
+	serveEvents(w, r, events, nil)
+}
+
+func (s *API) contestEvents(w http.ResponseWriter, r *http.Request) {
+	contest := util.Contest(r)
+	events, cancel, err := s.base.SubscribeContest(r.Context(), contest)
+	if err != nil {
+		statusError(w, err)
+		return
+	}
+	defer cancel()
+
+	lookingUser := user.UserBrief(r)
+	serveEvents(w, r, events, func(ev pubsub.Event) bool {
+		return s.base.LiveContestEventVisible(ev, lookingUser, contest)
+	})
+}
+
+// serveEvents streams events as server-sent events until the client disconnects or the subscription ends.
+// Events for which filter returns false are skipped.
+func serveEvents(w http.ResponseWriter, r *http.Request, events <-chan pubsub.Event, filter func(pubsub.Event) bool) {
+	rc := http.NewResponseController(w)
+	// The stream is long-lived, so it must not be cut off by the server's write timeout
+	_ = rc.SetWriteDeadline(time.Time{})
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprint(w, "retry: 5000\n\n")
+	if err := rc.Flush(); err != nil {
+		return
+	}
+
+	keepalive := time.NewTicker(eventsKeepalive)
+	defer keepalive.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-keepalive.C:
+			fmt.Fprint(w, ": keepalive\n\n")
+		case ev, ok := <-events:
+			if !ok {
+				return
+			}
+			if filter != nil && !filter(ev) {
+				continue
+			}
+			fmt.Fprintf(w, "event: %s\ndata: %s\n\n", ev.Type, ev.Data)
+		}
+		if err := rc.Flush(); err != nil {
+			return
+		}
+	}
+}
@@ -0,0 +1,166 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/KiloProjects/kilonova"
+	"github.com/KiloProjects/kilonova/domain/user"
+	"github.com/KiloProjects/kilonova/internal/util"
+	"github.com/KiloProjects/kilonova/net/llm"
+	"github.com/KiloProjects/kilonova/sudoapi"
+	"github.com/KiloProjects/kilonova/sudoapi/sudoapitest"
+)
+
+// newTestProblem returns a BaseAPI backed by a fresh database, along with a problem and its (admin) author.
+// The test is skipped if no test database server is configured.
+func newTestProblem(t *testing.T) (*sudoapi.BaseAPI, *kilonova.Problem, *kilonova.UserFull) {
+	t.Helper()
+	ctx := t.Context()
+	pgx, mgr := sudoapitest.Deps(t)
+	base, err := sudoapi.GetBaseAPI(ctx, pgx, mgr, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The first user is an admin
+	author, err := base.GenerateUser(ctx, "author", "password123", "en", kilonova.PreferredThemeNone, nil, nil, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	pb, err := base.CreateProblem(ctx, "Problem", author.Brief(), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return base, pb, author
+}
+
+// problemRequest builds a request made by author in the context of the problem, as set by the API middleware
+func problemRequest(pb *kilonova.Problem, author *kilonova.UserFull, target, body string) *http.Request {
+	r := httptest.NewRequest(http.MethodPost, target, strings.NewReader(body))
+	r.Header.Set("Content-Type", "application/json")
+	ctx := context.WithValue(r.Context(), util.ProblemKey, pb)
+	ctx = context.WithValue(ctx, user.AuthedUserKey, author)
+	return r.WithContext(ctx)
+}
+
+func TestTranscribeStatementValidation(t *testing.T) {
+	transcribe := func(s *API, body string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodPost, "/problem/1/update/transcribeStatement", strings.NewReader(body))
+		r.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		s.transcribeProblemPDF()(w, r)
+		return w
+	}
+
+	// No provider is configured by default
+	if w := transcribe(&API{}, `{"filename": "statement-ro.pdf"}`); w.Code != 400 || !strings.Contains(w.Body.String(), "unauthenticated") {
+		t.Fatalf("expected the missing provider to be reported, got %d: %s", w.Code, w.Body.String())
+	}
+
+	fake := &llm.Fake{Response: "transcribed"}
+	s := &API{llmProvider: fake}
+	for _, body := range []string{`{}`, `{"filename": "statement-ro.md"}`, `{"filename": "statement-ro.pdf", "extra": 1}`} {
+		if w := transcribe(s, body); w.Code != 400 {
+			t.Errorf("expected %s to be rejected, got %d: %s", body, w.Code, w.Body.String())
+		}
+	}
+	if len(fake.Requests()) != 0 {
+		t.Fatal("invalid requests reached the LLM provider")
+	}
+}
+
+func TestTranslateStatement(t *testing.T) {
+	base, pb, author := newTestProblem(t)
+	ctx := t.Context()
+	const statement = "# Enunț\n\nSe dă un număr $n$."
+	if err := base.CreateProblemAttachment(ctx, &kilonova.Attachment{Name: "statement-ro.md"}, pb.ID, strings.NewReader(statement), &author.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	fake := &llm.Fake{Response: "# Statement\n\nYou are given a number $n$."}
+	s := &API{base: base, llmProvider: fake}
+	translate := s.translateProblemStatement()
+
+	w := httptest.NewRecorder()
+	translate(w, problemRequest(pb, author, "/problem/1/update/translateStatement", `{}`))
+	if w.Code != 200 || !strings.Contains(w.Body.String(), "Created translation") {
+		t.Fatalf("expected the translation to be created, got %d: %s", w.Code, w.Body.String())
+	}
+
+	reqs := fake.Requests()
+	if len(reqs) != 1 {
+		t.Fatalf("expected one LLM request, got %d", len(reqs))
+	}
+	if reqs[0].Prompt != statement || reqs[0].Model != fake.DefaultModel(false) || reqs[0].System == "" || reqs[0].PDF != nil {
+		t.Errorf("unexpected LLM request %+v", reqs[0])
+	}
+
+	att, err := base.ProblemAttByName(ctx, pb.ID, "statement-en-llm.md")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !att.Private || att.Provenance != kilonova.StatementMachineTranslated || att.TranslatedFrom == nil || *att.TranslatedFrom != "statement-ro.md" {
+		t.Errorf("expected a private machine translation of statement-ro.md, got %+v", att)
+	}
+	data, err := base.AttachmentData(ctx, att.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != fake.Response {
+		t.Errorf("expected the attachment to hold the LLM output, got %q", data)
+	}
+
+	// Translating again replaces the previous translation
+	fake.Response = "# Statement\n\nGiven $n$."
+	w = httptest.NewRecorder()
+	translate(w, problemRequest(pb, author, "/problem/1/update/translateStatement", `{"model": "other"}`))
+	if w.Code != 200 || !strings.Contains(w.Body.String(), "Updated translation") {
+		t.Fatalf("expected the translation to be updated, got %d: %s", w.Code, w.Body.String())
+	}
+	if reqs := fake.Requests(); len(reqs) != 2 || reqs[1].Model != "other" {
+		t.Errorf("expected the second request to use the given model, got %+v", reqs)
+	}
+	data, err = base.ProblemAttDataByName(ctx, pb.ID, "statement-en-llm.md")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != fake.Response {
+		t.Errorf("expected the translation to be replaced, got %q", data)
+	}
+}
+
+func TestTranscribeStatement(t *testing.T) {
+	base, pb, author := newTestProblem(t)
+	ctx := t.Context()
+	pdf := []byte("%PDF-1.4 statement")
+	if err := base.CreateProblemAttachment(ctx, &kilonova.Attachment{Name: "statement-ro.pdf"}, pb.ID, strings.NewReader(string(pdf)), &author.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	fake := &llm.Fake{Response: "# Enunț"}
+	s := &API{base: base, llmProvider: fake}
+	w := httptest.NewRecorder()
+	s.transcribeProblemPDF()(w, problemRequest(pb, author, "/problem/1/update/transcribeStatement", `{"filename": "statement-ro.pdf"}`))
+	if w.Code != 200 || !strings.Contains(w.Body.String(), "Created transcription") {
+		t.Fatalf("expected the transcription to be created, got %d: %s", w.Code, w.Body.String())
+	}
+
+	reqs := fake.Requests()
+	if len(reqs) != 1 {
+		t.Fatalf("expected one LLM request, got %d", len(reqs))
+	}
+	if string(reqs[0].PDF) != string(pdf) || reqs[0].Model != fake.DefaultModel(true) {
+		t.Errorf("unexpected LLM request %+v", reqs[0])
+	}
+
+	data, err := base.ProblemAttDataByName(ctx, pb.ID, "statement-ro.md")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != fake.Response {
+		t.Errorf("expected the transcription to hold the LLM output, got %q", data)
+	}
+}
@@ -1,14 +1,18 @@
 package api
 
 import (
+	"cmp"
 	"context"
 	"log/slog"
 	"net/http"
+	"slices"
 	"strconv"
 	"strings"
 
 	"github.com/KiloProjects/kilonova/domain/datastore"
 	"github.com/KiloProjects/kilonova/domain/user"
+	"github.com/KiloProjects/kilonova/internal/auth"
+	"github.com/KiloProjects/kilonova/sudoapi"
 	"github.com/KiloProjects/kilonova/sudoapi/flags"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/trace"
@@ -77,6 +81,32 @@ func (s *API) MustBeProposer(next http.Handler) http.Handler {
 // SetupSession adds the user with the specified user ID to context
 func (s *API) SetupSession(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if token, ok := strings.CutPrefix(getAuthHeader(r), "Bearer "); ok {
+			tokenUser, scopes, err := s.base.AccessTokenUser(r.Context(), token)
+			if err != nil {
+				errorData(w, "Invalid access token", http.StatusUnauthorized)
+				return
+			}
+			trace.SpanFromContext(r.Context()).SetAttributes(attribute.Int("user.id", tokenUser.ID), attribute.String("user.name", tokenUser.Name))
+			ctx := context.WithValue(r.Context(), user.AuthedUserKey, tokenUser)
+			ctx = context.WithValue(ctx, util.ScopesKey, scopes)
+			next.ServeHTTP(w, r.WithContext(context.WithValue(ctx, util.AuthMethodKey, "oauth")))
+			return
+		}
+
+		if apiKey := cmp.Or(r.Header.Get("X-Api-Key"), getAuthHeader(r)); sudoapi.IsAPIToken(apiKey) {
+			tokenUser, scopes, err := s.base.APITokenUser(r.Context(), apiKey)
+			if err != nil {
+				statusError(w, err)
+				return
+			}
+			trace.SpanFromContext(r.Context()).SetAttributes(attribute.Int("user.id", tokenUser.ID), attribute.String("user.name", tokenUser.Name))
+			ctx := context.WithValue(r.Context(), user.AuthedUserKey, tokenUser)
+			ctx = context.WithValue(ctx, util.ScopesKey, scopes)
+			next.ServeHTTP(w, r.WithContext(context.WithValue(ctx, util.AuthMethodKey, "api_token")))
+			return
+		}
+
 		sessionUser, err := s.base.SessionUser(r.Context(), getAuthHeader(r), r)
 		if err != nil || sessionUser == nil {
 			if err != nil {
@@ -416,6 +446,54 @@ func (s *API) validateContestID(next http.Handler) http.Handler {
 	})
 }
 
+// requireScopes makes sure requests authenticated with an OAuth or API token were granted all the given scopes,
+// besides the api scope. Requests authenticated with a session have full access.
+func (s *API) requireScopes(scopes ...string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if err := checkScopes(r.Context(), scopes...); err != nil {
+				errorData(w, err.Error(), http.StatusForbidden)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// MustUseSession is middleware rejecting OAuth and personal API tokens, for account security operations (such as changing the password)
+func (s *API) MustUseSession(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if tokenAuthed(r.Context()) {
+			errorData(w, "This can't be done with an access token", http.StatusForbidden)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// tokenAuthed reports whether the request was authenticated with an OAuth access token or a personal API token.
+// Such requests are limited to the scopes granted to the token.
+func tokenAuthed(ctx context.Context) bool {
+	authMethod, _ := ctx.Value(util.AuthMethodKey).(string)
+	return authMethod == "oauth" || authMethod == "api_token"
+}
+
+func checkScopes(ctx context.Context, scopes ...string) error {
+	if !tokenAuthed(ctx) {
+		return nil
+	}
+	granted, _ := ctx.Value(util.ScopesKey).([]string)
+	if !slices.Contains(granted, auth.ScopeAPI) {
+		return kilonova.Statusf(http.StatusForbidden, "Missing `%s` scope", auth.ScopeAPI)
+	}
+	for _, scope := range scopes {
+		if !slices.Contains(granted, scope) {
+			return kilonova.Statusf(http.StatusForbidden, "Missing `%s` scope", scope)
+		}
+	}
+	return nil
+}
+
 func getAuthHeader(r *http.Request) string {
 	header := r.Header.Get("Authorization")
 	if header == "guest" {
@@ -2,19 +2,15 @@ package api
 
 import (
 	"cmp"
-	"log/slog"
 	"net/http"
-	"slices"
 	"strconv"
 	"strings"
 
 	"github.com/KiloProjects/kilonova/domain/user"
 	"github.com/KiloProjects/kilonova/internal/util"
+	"github.com/KiloProjects/kilonova/sudoapi"
 	"github.com/danielgtaylor/huma/v2"
 	"github.com/danielgtaylor/huma/v2/adapters/humachi"
-	"github.com/google/uuid"
-	"github.com/zitadel/oidc/v3/pkg/oidc"
-	"github.com/zitadel/oidc/v3/pkg/op"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/trace"
 )
@@ -28,52 +24,36 @@ func (s *API) SetupSessionV2(api huma.API) func(ctx huma.Context, next func(huma
 		h = cmp.Or(h, ctx.Header("X-Api-Key"))
 
 		// Handle OAuth token
-		if strings.HasPrefix(h, "Bearer ") {
-			h = h[7:]
-			// TODO: It's not good, it does not handle revoked tokens
-			claims, err := op.VerifyAccessToken[*oidc.AccessTokenClaims](ctx.Context(), h, s.base.OIDCProvider().AccessTokenVerifier(ctx.Context()))
+		if token, ok := strings.CutPrefix(h, "Bearer "); ok {
+			userFull, scopes, err := s.base.AccessTokenUser(ctx.Context(), token)
 			if err != nil {
-				next(ctx)
-				return
-			}
-			userID, err := strconv.Atoi(claims.GetSubject())
-			if err != nil {
-				slog.ErrorContext(ctx.Context(), "Received access token with invalid subject", slog.Any("err", err))
-				next(ctx)
+				next(huma.WithValue(ctx, util.AuthMethodKey, "none"))
 				return
 			}
 
-			tokenID, err := uuid.Parse(claims.JWTID)
-			if err != nil {
-				slog.ErrorContext(ctx.Context(), "Received access token with invalid jwt id", slog.Any("err", err))
-				next(ctx)
-				return
-			}
+			trace.SpanFromContext(ctx.Context()).SetAttributes(attribute.Int("user.id", userFull.ID), attribute.String("user.name", userFull.Name))
 
-			token, err := s.base.GetAccessToken(ctx.Context(), tokenID)
-			if err != nil {
-				// If err is nil, the token is probably revoked
-				next(ctx)
-				return
-			}
-			if token.UserID == nil || userID != *token.UserID {
-				slog.ErrorContext(ctx.Context(), "Received access token with invalid user id", slog.Int("user_id", userID), slog.Int("token_user_id", *token.UserID))
-				next(ctx)
-				return
-			}
+			next(huma.WithValue(
+				huma.WithValue(huma.WithValue(ctx, util.ScopesKey, scopes),
+					user.AuthedUserKey, userFull),
+				util.AuthMethodKey, "oauth",
+			))
+			return
+		}
 
-			userFull, err := s.base.UserFull(ctx.Context(), userID)
+		if sudoapi.IsAPIToken(h) {
+			userFull, scopes, err := s.base.APITokenUser(ctx.Context(), h)
 			if err != nil {
-				next(ctx)
+				next(huma.WithValue(ctx, util.AuthMethodKey, "none"))
 				return
 			}
 
 			trace.SpanFromContext(ctx.Context()).SetAttributes(attribute.Int("user.id", userFull.ID), attribute.String("user.name", userFull.Name))
 
 			next(huma.WithValue(
-				huma.WithValue(huma.WithValue(ctx, util.ScopesKey, token.Scopes),
+				huma.WithValue(huma.WithValue(ctx, util.ScopesKey, scopes),
 					user.AuthedUserKey, userFull),
-				util.AuthMethodKey, "oauth",
+				util.AuthMethodKey, "api_token",
 			))
 			return
 		}
@@ -85,7 +65,6 @@ func (s *API) SetupSessionV2(api huma.API) func(ctx huma.Context, next func(huma
 			return
 		}
 		trace.SpanFromContext(ctx.Context()).SetAttributes(attribute.Int("user.id", sessionUser.ID), attribute.String("user.name", sessionUser.Name))
-		// TODO: Scopes
 		next(huma.WithValue(
 			huma.WithValue(ctx, user.AuthedUserKey, sessionUser),
 			util.AuthMethodKey, "session",
@@ -93,6 +72,8 @@ func (s *API) SetupSessionV2(api huma.API) func(ctx huma.Context, next func(huma
 	}
 }
 
+// CheckScopes makes sure OAuth and API tokens were granted the scopes listed in the operation's oauth security requirement.
+// Sessions have full access, just like in the web UI.
 func (s *API) CheckScopes(api huma.API) func(ctx huma.Context, next func(huma.Context)) {
 	return func(ctx huma.Context, next func(huma.Context)) {
 		authMethod, ok := ctx.Context().Value(util.AuthMethodKey).(string)
@@ -101,43 +82,34 @@ func (s *API) CheckScopes(api huma.API) func(ctx huma.Context, next func(huma.Co
 			return
 		}
 
-		if authMethod == "oauth" {
-			var anyOfNeededScopes []string
-			authorizationRequired := false
-			for _, opScheme := range ctx.Operation().Security {
-				var ok bool
-				if anyOfNeededScopes, ok = opScheme[authMethod]; ok {
-					authorizationRequired = true
-					break
-				}
-			}
-
-			if !authorizationRequired {
-				next(ctx)
-				return
-			}
-
-			scopes, ok := ctx.Context().Value(util.ScopesKey).([]string)
-			if !ok || len(scopes) == 0 {
-				huma.WriteErr(api, ctx, http.StatusForbidden, "Missing scopes")
-				return
-			}
-
-			if !slices.Contains(scopes, "api") {
-				huma.WriteErr(api, ctx, http.StatusForbidden, "Missing `api` scope")
-				return
-			}
+		if !tokenAuthed(ctx.Context()) {
+			next(ctx)
+			return
+		}
 
-			for _, scope := range anyOfNeededScopes {
-				if slices.Contains(scopes, scope) {
-					next(ctx)
-					return
-				}
+		// API tokens share the scopes of OAuth tokens, since OpenAPI doesn't allow scopes on apiKey security schemes
+		var neededScopes []string
+		for _, opScheme := range ctx.Operation().Security {
+			if scopes, ok := opScheme["oauth"]; ok {
+				neededScopes = scopes
+				break
 			}
-			huma.WriteErr(api, ctx, http.StatusForbidden, "Missing required scope")
-		} else {
-			next(ctx)
 		}
+		if err := checkScopes(ctx.Context(), neededScopes...); err != nil {
+			huma.WriteErr(api, ctx, http.StatusForbidden, err.Error())
+			return
+		}
+		next(ctx)
+	}
+}
+
+// scoped is the security requirement of operations that OAuth tokens may only access with all the given scopes
+// (besides the api scope, which is always required).
+func scoped(scopes ...string) []map[string][]string {
+	return []map[string][]string{
+		{"oauth": append([]string{}, scopes...)},
+		{"apiKey": {}},
+		{},
 	}
 }
 
@@ -197,3 +169,25 @@ func (s *API) MustBeAuthedV2(api huma.API) func(ctx huma.Context, next func(huma
 		next(ctx)
 	}
 }
+
+// validateContestEditorV2 must be used after validateContestIDv2
+func (s *API) validateContestEditorV2(api huma.API) func(ctx huma.Context, next func(huma.Context)) {
+	return func(ctx huma.Context, next func(huma.Context)) {
+		if !util.ContestContext(ctx.Context()).IsEditor(user.UserBriefContext(ctx.Context())) {
+			huma.WriteErr(api, ctx, http.StatusUnauthorized, "You must be authorized to access this contest data")
+			return
+		}
+		next(ctx)
+	}
+}
+
+// validateContestParticipantV2 must be used after validateContestIDv2
+func (s *API) validateContestParticipantV2(api huma.API) func(ctx huma.Context, next func(huma.Context)) {
+	return func(ctx huma.Context, next func(huma.Context)) {
+		if !s.base.CanSubmitInContest(user.UserBriefContext(ctx.Context()), util.ContestContext(ctx.Context())) {
+			huma.WriteErr(api, ctx, http.StatusUnauthorized, "You must be registered and during a contest to do this")
+			return
+		}
+		next(ctx)
+	}
+}
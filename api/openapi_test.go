@@ -0,0 +1,51 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/KiloProjects/kilonova"
+)
+
+// TestOpenAPIOperations makes sure the v2 operations are registered and show up in the generated document,
+// which is what clients are generated from.
+func TestOpenAPIOperations(t *testing.T) {
+	kilonova.SetHostPrefix("http://localhost:8070")
+
+	rec := httptest.NewRecorder()
+	New(nil).HandlerV2().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/openapi.json", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("got status %d", rec.Code)
+	}
+
+	var doc struct {
+		Paths map[string]map[string]struct {
+			OperationID string `json:"operationId"`
+		} `json:"paths"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &doc); err != nil {
+		t.Fatal(err)
+	}
+	ops := make(map[string]bool)
+	for _, methods := range doc.Paths {
+		for _, op := range methods {
+			ops[op.OperationID] = true
+		}
+	}
+
+	for _, id := range []string{
+		"get-problems", "get-problem-by-id", "get-problem-languages", "get-problem-statement-variants", "create-submission",
+		"get-submissions", "get-submission", "get-submission-subtests", "get-submission-subtasks",
+		"get-contests", "get-contest-by-id", "get-contest-leaderboard", "get-contest-announcements",
+		"get-contest-questions", "get-contest-all-questions", "create-contest-question",
+		"get-contest-registrations", "get-contest-registration", "register-for-contest",
+		"get-problem-lists", "get-problem-list-by-id", "get-tags", "get-tag-by-id",
+		"get-user-by-id", "get-user-by-name", "get-user-solved-problems",
+	} {
+		if !ops[id] {
+			t.Errorf("operation %q is missing from the OpenAPI document", id)
+		}
+	}
+}
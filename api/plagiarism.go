@@ -0,0 +1,30 @@
+package api
+
+import (
+	"context"
+
+	"github.com/KiloProjects/kilonova"
+	"github.com/KiloProjects/kilonova/domain/user"
+	"github.com/KiloProjects/kilonova/internal/util"
+)
+
+// checkSubmissionPlagiarism compares a submission to the problem against its accepted submissions.
+// It returns the ID of the report.
+func (s *API) checkSubmissionPlagiarism(ctx context.Context, args struct {
+	SubmissionID int `json:"submission_id"`
+}) (int, error) {
+	sub, err := s.base.RawSubmission(ctx, args.SubmissionID)
+	if err != nil {
+		return -1, err
+	}
+	if sub.ProblemID != util.ProblemContext(ctx).ID {
+		return -1, kilonova.Statusf(400, "Submission is not for this problem")
+	}
+	return s.base.CheckSubmissionPlagiarism(context.WithoutCancel(ctx), sub, user.UserBriefContext(ctx))
+}
+
+// checkUserPlagiarism compares the submissions of a user against everyone else's.
+// It returns the ID of the report.
+func (s *API) checkUserPlagiarism(ctx context.Context, _ struct{}) (int, error) {
+	return s.base.CheckUserPlagiarism(context.WithoutCancel(ctx), user.ContentUserBriefContext(ctx), user.UserBriefContext(ctx))
+}
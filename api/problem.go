@@ -419,6 +419,14 @@ func (s *API) updateProblem(ctx context.Context, args kilonova.ProblemUpdate) er
 	return s.base.UpdateProblem(ctx, util.ProblemContext(ctx).ID, args, user.UserBriefContext(ctx))
 }
 
+// statementLLM returns the provider used for the statement tools
+func (s *API) statementLLM() (llm.Provider, error) {
+	if s.llmProvider != nil {
+		return s.llmProvider, nil
+	}
+	return llm.DefaultProvider()
+}
+
 func (s *API) translateProblemStatement() http.HandlerFunc {
 	var translateMu sync.Mutex
 	return func(w http.ResponseWriter, r *http.Request) {
@@ -429,6 +437,11 @@ func (s *API) translateProblemStatement() http.HandlerFunc {
 			statusError(w, err)
 			return
 		}
+		provider, err := s.statementLLM()
+		if err != nil {
+			errorData(w, err.Error(), 400)
+			return
+		}
 		if !translateMu.TryLock() {
 			errorData(w, "Will not process more than one pending translation at once. Please try again later.", 400)
 			return
@@ -445,19 +458,26 @@ func (s *API) translateProblemStatement() http.HandlerFunc {
 			return
 		}
 		t := time.Now()
-		output, err := llm.TranslateStatement(r.Context(), string(data), args.Model)
+		output, err := llm.TranslateStatement(r.Context(), provider, string(data), args.Model)
 		if err != nil {
 			errorData(w, err, 400)
 			return
 		}
-		s.base.LogUserAction(r.Context(), "Triggered LLM translation", slog.String("model", args.Model), slog.Any("problem", util.Problem(r)), slog.Duration("duration", time.Since(t)))
+		s.base.LogUserAction(r.Context(), "Triggered LLM translation", slog.String("provider", provider.Name()), slog.String("model", args.Model), slog.Any("problem", util.Problem(r)), slog.Duration("duration", time.Since(t)))
+		// Machine translations stay private until someone reviews them
 		att2, err := s.base.ProblemAttByName(r.Context(), util.Problem(r).ID, "statement-en-llm.md")
 		if err != nil {
 			if errors.Is(err, kilonova.ErrNotFound) {
-				att2 = &kilonova.Attachment{Name: "statement-en-llm.md"}
+				att2 = &kilonova.Attachment{
+					Name:           "statement-en-llm.md",
+					Private:        true,
+					Provenance:     kilonova.StatementMachineTranslated,
+					TranslatedFrom: &att.Name,
+				}
 				err = s.base.CreateProblemAttachment(r.Context(), att2, util.Problem(r).ID, strings.NewReader(output), &user.UserBrief(r).ID)
 				if err != nil {
 					statusError(w, err)
+					return
 				}
 				returnData(w, "Created translation")
 				return
@@ -465,6 +485,14 @@ func (s *API) translateProblemStatement() http.HandlerFunc {
 			statusError(w, err)
 			return
 		}
+		if err := s.base.UpdateAttachment(r.Context(), att2.ID, &kilonova.AttachmentUpdate{
+			Private:        new(true),
+			Provenance:     new(kilonova.StatementMachineTranslated),
+			TranslatedFrom: &att.Name,
+		}); err != nil {
+			statusError(w, err)
+			return
+		}
 		if err := s.base.UpdateAttachmentData(r.Context(), att2.ID, []byte(output), user.UserBrief(r)); err != nil {
 			statusError(w, err)
 			return
@@ -474,6 +502,12 @@ func (s *API) translateProblemStatement() http.HandlerFunc {
 	}
 }
 
+func (s *API) reviewStatement(ctx context.Context, args struct {
+	Name string `json:"name"`
+}) error {
+	return s.base.ReviewStatement(ctx, util.ProblemContext(ctx).ID, args.Name, user.UserBriefContext(ctx))
+}
+
 func (s *API) transcribeProblemPDF() http.HandlerFunc {
 	var transcribeMu sync.Mutex
 	return func(w http.ResponseWriter, r *http.Request) {
@@ -485,6 +519,11 @@ func (s *API) transcribeProblemPDF() http.HandlerFunc {
 			statusError(w, err)
 			return
 		}
+		provider, err := s.statementLLM()
+		if err != nil {
+			errorData(w, err.Error(), 400)
+			return
+		}
 		if !transcribeMu.TryLock() {
 			errorData(w, "Will not process more than one pending transcription at once. Please try again later.", 400)
 			return
@@ -510,14 +549,14 @@ func (s *API) transcribeProblemPDF() http.HandlerFunc {
 			return
 		}
 		t := time.Now()
-		output, err := llm.TranscribeStatement(r.Context(), bytes.NewReader(data), args.Model)
+		output, err := llm.TranscribeStatement(r.Context(), provider, data, args.Model)
 		if err != nil {
 			errorData(w, err, 400)
 			return
 		}
 		targetFilename := strings.ReplaceAll(args.Filename, ".pdf", ".md")
 
-		s.base.LogUserAction(r.Context(), "Triggered LLM transcription", slog.String("model", args.Model), slog.Any("problem", util.Problem(r)), slog.Duration("duration", time.Since(t)))
+		s.base.LogUserAction(r.Context(), "Triggered LLM transcription", slog.String("provider", provider.Name()), slog.String("model", args.Model), slog.Any("problem", util.Problem(r)), slog.Duration("duration", time.Since(t)))
 		att2, err := s.base.ProblemAttByName(r.Context(), util.Problem(r).ID, targetFilename)
 		if err == nil {
 			// Save old statement
@@ -865,6 +904,8 @@ type StatementVariant struct {
 	Format   string `json:"format" enum:"md,pdf" doc:"Statement format. Markdown is the recommended one to interpret, as PDFs are usually served only for historical purposes."`
 	Type     string `json:"type" doc:"Usually empty, can be used to distinguish between multiple loose types of variants."`
 
+	Provenance kilonova.StatementProvenance `json:"provenance" enum:"original,machine_translated,human_reviewed" doc:"Whether the statement is an original, an unreviewed machine translation or a translation reviewed by a human."`
+
 	Permalink string `json:"permalink" doc:"Link to the statement's contents, raw. It is not guaranteed that this URL will remain the same."`
 	RenderURL string `json:"renderURL" doc:"For markdown statements, this URL represents the HTML rendered version of this statement. See additional documentation for how to correctly render the HTML. It is not guaranteed that this URL will remain the same."`
 
@@ -884,9 +925,10 @@ func (s *API) getStatementVariants(ctx context.Context, problem *kilonova.Proble
 	for _, v := range variant {
 
 		out := StatementVariant{
-			Language: v.Language,
-			Format:   v.Format,
-			Type:     v.Type,
+			Language:   v.Language,
+			Format:     v.Format,
+			Type:       v.Type,
+			Provenance: v.Provenance,
 
 			Permalink:     kilonova.HostURL().JoinPath("assets/problem", strconv.Itoa(problem.ID), "attachment", v.AttachmentName).String(),
 			LastUpdatedAt: v.LastUpdatedAt,
@@ -229,3 +229,35 @@ func (s *API) filterProblems(ctx context.Context, problemIDs []int, user *kilono
 	}
 	return actualIDs, nil
 }
+
+type ProblemListsGetInput struct {
+	Root bool `query:"root" doc:"Only return lists that aren't part of other lists"`
+}
+
+type ProblemListsGetOutput struct {
+	Body []*kilonova.ProblemList
+}
+
+func (s *API) problemListsGet(ctx context.Context, input *ProblemListsGetInput) (*ProblemListsGetOutput, error) {
+	lists, err := s.base.ProblemLists(ctx, kilonova.ProblemListFilter{Root: input.Root})
+	if err != nil {
+		return nil, err
+	}
+	return &ProblemListsGetOutput{lists}, nil
+}
+
+type ProblemListGetInput struct {
+	ListID int `path:"listID"`
+}
+
+type ProblemListGetOutput struct {
+	Body *kilonova.ProblemList
+}
+
+func (s *API) problemListSingleGet(ctx context.Context, input *ProblemListGetInput) (*ProblemListGetOutput, error) {
+	list, err := s.base.ProblemList(ctx, input.ListID)
+	if err != nil {
+		return nil, err
+	}
+	return &ProblemListGetOutput{list}, nil
+}
@@ -0,0 +1,58 @@
+package api
+
+import (
+	"context"
+	"testing"
+
+	"github.com/KiloProjects/kilonova"
+	"github.com/KiloProjects/kilonova/internal/auth"
+	"github.com/KiloProjects/kilonova/internal/util"
+)
+
+func TestCheckScopes(t *testing.T) {
+	oauthCtx := func(scopes ...string) context.Context {
+		ctx := context.WithValue(context.Background(), util.AuthMethodKey, "oauth")
+		return context.WithValue(ctx, util.ScopesKey, scopes)
+	}
+
+	tests := []struct {
+		name   string
+		ctx    context.Context
+		needed []string
+		ok     bool
+	}{
+		{"session", context.WithValue(context.Background(), util.AuthMethodKey, "session"), []string{auth.ScopeAdmin}, true},
+		{"anonymous", context.Background(), []string{auth.ScopeSubmissionsRead}, true},
+		{"openid only", oauthCtx("openid", "profile"), nil, false},
+		{"api", oauthCtx(auth.ScopeAPI), nil, true},
+		{"missing scope", oauthCtx(auth.ScopeAPI, auth.ScopeSubmissionsRead), []string{auth.ScopeSubmissionsWrite}, false},
+		{"granted scope", oauthCtx(auth.ScopeAPI, auth.ScopeSubmissionsWrite), []string{auth.ScopeSubmissionsWrite}, true},
+		{"api token", context.WithValue(context.WithValue(context.Background(), util.AuthMethodKey, "api_token"), util.ScopesKey, []string{auth.ScopeAPI}), []string{auth.ScopeContestsManage}, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := checkScopes(test.ctx, test.needed...)
+			if test.ok && err != nil {
+				t.Fatalf("Expected access, got %v", err)
+			}
+			if !test.ok && err == nil {
+				t.Fatal("Expected access to be denied")
+			}
+		})
+	}
+}
+
+func TestGrantableScopes(t *testing.T) {
+	requested := []string{"openid", auth.ScopeAPI, auth.ScopeAdmin, auth.ScopeProposer}
+
+	got := auth.GrantableScopes(requested, &kilonova.UserBrief{ID: 1, Proposer: true})
+	if len(got) != 3 || got[2] != auth.ScopeProposer {
+		t.Fatalf("Proposer should only be granted the proposer role scope, got %v", got)
+	}
+	if got := auth.GrantableScopes(requested, &kilonova.UserBrief{ID: 1, Admin: true}); len(got) != 4 {
+		t.Fatalf("Admins should be granted every role scope, got %v", got)
+	}
+	if len(requested) != 4 {
+		t.Fatal("GrantableScopes modified the requested slice")
+	}
+}
@@ -12,6 +12,7 @@ import (
 	"github.com/KiloProjects/kilonova/internal/util"
 	"github.com/KiloProjects/kilonova/sudoapi"
 	"github.com/danielgtaylor/huma/v2"
+	"github.com/shopspring/decimal"
 )
 
 func (s *API) fullSubmission(ctx context.Context, id int, lookingUser *kilonova.UserBrief, looking bool) (sub *sudoapi.FullSubmission, err error) {
@@ -216,3 +217,89 @@ func (s *API) submissionGet(ctx context.Context, args *SubmissionGetInput) (*Sub
 
 	return &SubmissionGetOutput{sub}, nil
 }
+
+type SubmissionsGetInput struct {
+	Body *struct {
+		IDs           []int `json:"ids,omitempty" nullable:"false"`
+		UserID        *int  `json:"user_id,omitempty"`
+		ProblemID     *int  `json:"problem_id,omitempty"`
+		ProblemListID *int  `json:"problem_list_id,omitempty"`
+		ContestID     *int  `json:"contest_id,omitempty"`
+
+		Status kilonova.Status `json:"status,omitempty" enum:"creating,waiting,working,finished,reevaling"`
+
+		Lang         *string `json:"lang,omitempty"`
+		CompileError *bool   `json:"compile_error,omitempty"`
+
+		Score *decimal.Decimal `json:"score,omitempty"`
+
+		FromAuthors bool `json:"from_authors,omitempty" doc:"Only return submissions sent by the problem's authors"`
+
+		Limit  int `json:"limit,omitempty" maximum:"50"`
+		Offset int `json:"offset,omitempty"`
+
+		Ordering  string `json:"ordering,omitempty" enum:"id,max_time,max_mem,score,code_size"`
+		Ascending bool   `json:"ascending,omitempty"`
+	}
+}
+
+type SubmissionsGetOutput struct {
+	Body *sudoapi.Submissions
+}
+
+// submissionsGet filters the submissions visible to the user
+func (s *API) submissionsGet(ctx context.Context, input *SubmissionsGetInput) (*SubmissionsGetOutput, error) {
+	var args kilonova.SubmissionFilter
+	if input.Body != nil {
+		args = kilonova.SubmissionFilter{
+			IDs:           input.Body.IDs,
+			UserID:        input.Body.UserID,
+			ProblemID:     input.Body.ProblemID,
+			ProblemListID: input.Body.ProblemListID,
+			ContestID:     input.Body.ContestID,
+
+			Status: input.Body.Status,
+
+			Lang:         input.Body.Lang,
+			CompileError: input.Body.CompileError,
+			Score:        input.Body.Score,
+			FromAuthors:  input.Body.FromAuthors,
+
+			Limit:  input.Body.Limit,
+			Offset: input.Body.Offset,
+
+			Ordering:  input.Body.Ordering,
+			Ascending: input.Body.Ascending,
+		}
+	}
+
+	subs, err := s.base.Submissions(ctx, args, true, user.UserBriefContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	return &SubmissionsGetOutput{subs}, nil
+}
+
+type SubmissionSubTestsOutput struct {
+	Body []*kilonova.SubTest
+}
+
+func (s *API) submissionSubTestsGet(ctx context.Context, args *SubmissionGetInput) (*SubmissionSubTestsOutput, error) {
+	sub, err := s.base.Submission(ctx, args.SubmissionID, user.UserBriefContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	return &SubmissionSubTestsOutput{sub.SubTests}, nil
+}
+
+type SubmissionSubTasksOutput struct {
+	Body []*kilonova.SubmissionSubTask
+}
+
+func (s *API) submissionSubTasksGet(ctx context.Context, args *SubmissionGetInput) (*SubmissionSubTasksOutput, error) {
+	sub, err := s.base.Submission(ctx, args.SubmissionID, user.UserBriefContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	return &SubmissionSubTasksOutput{sub.SubTasks}, nil
+}
@@ -14,6 +14,8 @@ func (s *API) createSubTask(w http.ResponseWriter, r *http.Request) {
 		VisibleID int   `json:"visible_id"`
 		Score     int   `json:"score"`
 		Tests     []int `json:"tests"`
+		// Public defaults to true
+		Public *bool `json:"public"`
 	}
 	if err := parseJSONBody(r, &args); err != nil {
 		statusError(w, err)
@@ -47,6 +49,7 @@ func (s *API) createSubTask(w http.ResponseWriter, r *http.Request) {
 		VisibleID: args.VisibleID,
 		Score:     decimal.NewFromInt(int64(args.Score)),
 		Tests:     realIDs,
+		Public:    args.Public == nil || *args.Public,
 	}
 
 	if err := s.base.CreateSubTask(r.Context(), &stk); err != nil {
@@ -61,6 +64,7 @@ func (s *API) updateSubTask(w http.ResponseWriter, r *http.Request) {
 		SubTaskID *int     `json:"subtask_id"`
 		NewID     *int     `json:"new_id"`
 		Score     *float64 `json:"score"`
+		Public    *bool    `json:"public"`
 		Tests     []int    `json:"tests"`
 	}
 	if err := parseJSONBody(r, &args); err != nil {
@@ -91,6 +95,7 @@ func (s *API) updateSubTask(w http.ResponseWriter, r *http.Request) {
 	if err := s.base.UpdateSubTask(r.Context(), stk.ID, kilonova.SubTaskUpdate{
 		VisibleID: args.NewID,
 		Score:     score,
+		Public:    args.Public,
 	}); err != nil {
 		statusError(w, err)
 		return
@@ -114,3 +114,41 @@ func (s *API) updateProblemTags(ctx context.Context, args struct {
 func (s *API) problemTags(ctx context.Context, _ struct{}) ([]*kilonova.Tag, error) {
 	return s.base.ProblemTags(ctx, util.ProblemContext(ctx).ID)
 }
+
+type TagsGetInput struct {
+	Type kilonova.TagType `query:"type" enum:"author,contest,method,other" doc:"If set, only return tags of this type"`
+}
+
+type TagsGetOutput struct {
+	Body []*kilonova.Tag
+}
+
+func (s *API) tagsGet(ctx context.Context, input *TagsGetInput) (*TagsGetOutput, error) {
+	var tags []*kilonova.Tag
+	var err error
+	if input.Type == kilonova.TagTypeNone {
+		tags, err = s.base.Tags(ctx)
+	} else {
+		tags, err = s.base.TagsByType(ctx, input.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &TagsGetOutput{tags}, nil
+}
+
+type TagGetInput struct {
+	TagID int `path:"tagID"`
+}
+
+type TagGetOutput struct {
+	Body *kilonova.Tag
+}
+
+func (s *API) tagSingleGet(ctx context.Context, input *TagGetInput) (*TagGetOutput, error) {
+	tag, err := s.base.TagByID(ctx, input.TagID)
+	if err != nil {
+		return nil, err
+	}
+	return &TagGetOutput{tag}, nil
+}
@@ -0,0 +1,138 @@
+package api
+
+import (
+	"context"
+
+	"github.com/KiloProjects/kilonova"
+	"github.com/KiloProjects/kilonova/domain/user"
+	"github.com/KiloProjects/kilonova/internal/util"
+)
+
+type teamInfo struct {
+	*kilonova.Team
+	// JoinCode is only shown to the members
+	JoinCode string                `json:"join_code,omitempty"`
+	Members  []*kilonova.UserBrief `json:"members"`
+}
+
+// team returns the team with the given ID, making sure the authed user is a member (or can manage it)
+func (s *API) team(ctx context.Context, id int) (*kilonova.Team, error) {
+	team, err := s.base.Team(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	lookingUser := user.UserBriefContext(ctx)
+	if !s.base.CanManageTeam(ctx, team, lookingUser) && !s.base.IsTeamMember(ctx, team, lookingUser) {
+		return nil, kilonova.Statusf(404, "Team not found")
+	}
+	return team, nil
+}
+
+func (s *API) userTeams(ctx context.Context, _ struct{}) ([]*teamInfo, error) {
+	teams, err := s.base.UserTeams(ctx, user.UserBriefContext(ctx).ID)
+	if err != nil {
+		return nil, err
+	}
+	rez := make([]*teamInfo, 0, len(teams))
+	for _, team := range teams {
+		members, err := s.base.TeamMembers(ctx, team)
+		if err != nil {
+			return nil, err
+		}
+		rez = append(rez, &teamInfo{Team: team, JoinCode: team.JoinCode, Members: members})
+	}
+	return rez, nil
+}
+
+func (s *API) createTeam(ctx context.Context, args struct {
+	Name string `json:"name"`
+}) (int, error) {
+	return s.base.CreateTeam(ctx, args.Name, user.UserBriefContext(ctx))
+}
+
+func (s *API) joinTeam(ctx context.Context, args struct {
+	Code string `json:"code"`
+}) (*kilonova.Team, error) {
+	return s.base.JoinTeam(ctx, args.Code, user.UserBriefContext(ctx))
+}
+
+func (s *API) renameTeam(ctx context.Context, args struct {
+	TeamID int    `json:"team_id"`
+	Name   string `json:"name"`
+}) error {
+	team, err := s.team(ctx, args.TeamID)
+	if err != nil {
+		return err
+	}
+	if !s.base.CanManageTeam(ctx, team, user.UserBriefContext(ctx)) {
+		return kilonova.Statusf(403, "Only the team creator can rename the team")
+	}
+	return s.base.RenameTeam(ctx, team, args.Name)
+}
+
+func (s *API) leaveTeam(ctx context.Context, args struct {
+	TeamID int `json:"team_id"`
+}) error {
+	team, err := s.team(ctx, args.TeamID)
+	if err != nil {
+		return err
+	}
+	return s.base.RemoveTeamMember(ctx, team, user.UserBriefContext(ctx).ID)
+}
+
+func (s *API) removeTeamMember(ctx context.Context, args struct {
+	TeamID int `json:"team_id"`
+	UserID int `json:"user_id"`
+}) error {
+	team, err := s.team(ctx, args.TeamID)
+	if err != nil {
+		return err
+	}
+	if !s.base.CanManageTeam(ctx, team, user.UserBriefContext(ctx)) {
+		return kilonova.Statusf(403, "Only the team creator can remove members")
+	}
+	return s.base.RemoveTeamMember(ctx, team, args.UserID)
+}
+
+func (s *API) deleteTeam(ctx context.Context, args struct {
+	TeamID int `json:"team_id"`
+}) error {
+	team, err := s.team(ctx, args.TeamID)
+	if err != nil {
+		return err
+	}
+	if !s.base.CanManageTeam(ctx, team, user.UserBriefContext(ctx)) {
+		return kilonova.Statusf(403, "Only the team creator can delete the team")
+	}
+	return s.base.DeleteTeam(ctx, team)
+}
+
+// Contest teams
+
+func (s *API) contestTeams(ctx context.Context, _ struct{}) ([]*teamInfo, error) {
+	contest := util.ContestContext(ctx)
+	teams, err := s.base.ContestTeams(ctx, contest.ID)
+	if err != nil {
+		return nil, err
+	}
+	rez := make([]*teamInfo, 0, len(teams))
+	for _, team := range teams {
+		members, err := s.base.ContestTeamMembers(ctx, contest.ID, team.ID)
+		if err != nil {
+			return nil, err
+		}
+		rez = append(rez, &teamInfo{Team: team, Members: members})
+	}
+	return rez, nil
+}
+
+func (s *API) registerTeamForContest(ctx context.Context, args struct {
+	TeamID     int  `json:"team_id"`
+	CategoryID *int `json:"category_id"`
+}) error {
+	team, err := s.team(ctx, args.TeamID)
+	if err != nil {
+		return err
+	}
+	return s.base.RegisterContestTeam(ctx, util.ContestContext(ctx), team, nil, args.CategoryID, false)
+}
@@ -15,9 +15,12 @@ import (
 
 	"github.com/KiloProjects/kilonova"
 	"github.com/KiloProjects/kilonova/sudoapi"
+	"github.com/KiloProjects/kilonova/util/slicealg"
 	validation "github.com/go-ozzo/ozzo-validation/v4"
 	"github.com/go-ozzo/ozzo-validation/v4/is"
+	"github.com/google/uuid"
 	"github.com/microcosm-cc/bluemonday"
+	"github.com/shopspring/decimal"
 )
 
 var (
@@ -32,6 +35,26 @@ func (s *API) deauthAllSessions(w http.ResponseWriter, r *http.Request) {
 	returnData(w, "Force logged out")
 }
 
+func (s *API) revokeOAuthClient(ctx context.Context, args struct {
+	ClientID uuid.UUID `json:"client_id"`
+}) error {
+	return s.base.RevokeAuthorizedClient(ctx, user.ContentUserBriefContext(ctx).ID, args.ClientID)
+}
+
+func (s *API) createAPIToken(ctx context.Context, args struct {
+	Name      string     `json:"name"`
+	Scopes    []string   `json:"scopes"`
+	ExpiresAt *time.Time `json:"expires_at"`
+}) (string, error) {
+	return s.base.CreateAPIToken(ctx, user.UserBriefContext(ctx), args.Name, args.Scopes, args.ExpiresAt)
+}
+
+func (s *API) revokeAPIToken(ctx context.Context, args struct {
+	ID int `json:"id"`
+}) error {
+	return s.base.RemoveAPIToken(ctx, user.ContentUserBriefContext(ctx).ID, args.ID)
+}
+
 func (s *API) setPreferredLanguage() func(w http.ResponseWriter, r *http.Request) {
 	return func(w http.ResponseWriter, r *http.Request) {
 		var args struct{ Language string }
@@ -459,3 +482,83 @@ func (s *API) generateUser(w http.ResponseWriter, r *http.Request) {
 		User:     userFull,
 	})
 }
+
+// v2
+
+type apiUser struct {
+	ID          int    `json:"id"`
+	Name        string `json:"name"`
+	DisplayName string `json:"display_name"`
+
+	Admin     bool `json:"admin"`
+	Proposer  bool `json:"proposer"`
+	Generated bool `json:"generated"`
+
+	Bio       string    `json:"bio"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func userFromDomain(u *kilonova.UserFull) *apiUser {
+	return &apiUser{
+		ID:          u.ID,
+		Name:        u.Name,
+		DisplayName: u.DisplayName,
+		Admin:       u.Admin,
+		Proposer:    u.Proposer,
+		Generated:   u.Generated,
+		Bio:         u.Bio,
+		CreatedAt:   u.CreatedAt,
+	}
+}
+
+type UserGetInput struct {
+	UserID int `path:"userID"`
+}
+
+type UserByNameGetInput struct {
+	Username string `path:"username"`
+}
+
+type UserGetOutput struct {
+	Body *apiUser
+}
+
+func (s *API) userGet(ctx context.Context, input *UserGetInput) (*UserGetOutput, error) {
+	userFull, err := s.base.UserFull(ctx, input.UserID)
+	if err != nil {
+		return nil, err
+	}
+	return &UserGetOutput{userFromDomain(userFull)}, nil
+}
+
+func (s *API) userByNameGet(ctx context.Context, input *UserByNameGetInput) (*UserGetOutput, error) {
+	userFull, err := s.base.UserFullByName(ctx, strings.TrimSpace(input.Username))
+	if err != nil {
+		return nil, err
+	}
+	return &UserGetOutput{userFromDomain(userFull)}, nil
+}
+
+type apiSolvedProblem struct {
+	ID       int              `json:"id"`
+	Name     string           `json:"name"`
+	MaxScore *decimal.Decimal `json:"max_score"`
+}
+
+type UserSolvedProblemsOutput struct {
+	Body []*apiSolvedProblem
+}
+
+func (s *API) userSolvedProblemsGet(ctx context.Context, input *UserGetInput) (*UserSolvedProblemsOutput, error) {
+	userFull, err := s.base.UserFull(ctx, input.UserID)
+	if err != nil {
+		return nil, err
+	}
+	pbs, err := s.base.SolvedProblems(ctx, userFull.Brief(), user.UserBriefContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	return &UserSolvedProblemsOutput{slicealg.Map(pbs, func(pb *kilonova.ScoredProblem) *apiSolvedProblem {
+		return &apiSolvedProblem{ID: pb.ID, Name: pb.Name, MaxScore: pb.MaxScore}
+	})}, nil
+}
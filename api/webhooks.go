@@ -2,6 +2,7 @@ package api
 
 import (
 	"bytes"
+	"context"
 	"crypto/hmac"
 	"crypto/sha256"
 	"encoding/hex"
@@ -11,6 +12,10 @@ import (
 	"log/slog"
 	"net/http"
 
+	"github.com/KiloProjects/kilonova"
+	"github.com/KiloProjects/kilonova/domain/user"
+	"github.com/KiloProjects/kilonova/internal/util"
+	"github.com/KiloProjects/kilonova/sudoapi"
 	"github.com/KiloProjects/kilonova/sudoapi/flags"
 )
 
@@ -108,3 +113,64 @@ func (s *API) bmacEvent(w http.ResponseWriter, r *http.Request) {
 
 	returnData(w, "Logged event")
 }
+
+type webhookArgs struct {
+	URL    string                 `json:"url"`
+	Events []sudoapi.WebhookEvent `json:"events"`
+}
+
+// createdWebhook is returned only once, since the secret is needed to verify webhook signatures
+type createdWebhook struct {
+	ID     int    `json:"id"`
+	Secret string `json:"secret"`
+}
+
+func (s *API) userWebhooks(ctx context.Context, _ struct{}) ([]*sudoapi.Webhook, error) {
+	return s.base.UserWebhooks(ctx, user.ContentUserBriefContext(ctx).ID)
+}
+
+func (s *API) createUserWebhook(ctx context.Context, args webhookArgs) (*createdWebhook, error) {
+	id, secret, err := s.base.CreateWebhook(ctx, user.ContentUserBriefContext(ctx).ID, nil, args.URL, args.Events)
+	if err != nil {
+		return nil, err
+	}
+	return &createdWebhook{ID: id, Secret: secret}, nil
+}
+
+func (s *API) deleteUserWebhook(ctx context.Context, args struct {
+	ID int `json:"id"`
+}) error {
+	hook, err := s.base.Webhook(ctx, args.ID)
+	if err != nil {
+		return err
+	}
+	if hook.ContestID != nil || hook.OwnerID != user.ContentUserBriefContext(ctx).ID {
+		return kilonova.Statusf(404, "Webhook not found")
+	}
+	return s.base.DeleteWebhook(ctx, hook.ID)
+}
+
+func (s *API) contestWebhooks(ctx context.Context, _ struct{}) ([]*sudoapi.Webhook, error) {
+	return s.base.ContestWebhooks(ctx, util.ContestContext(ctx).ID)
+}
+
+func (s *API) createContestWebhook(ctx context.Context, args webhookArgs) (*createdWebhook, error) {
+	id, secret, err := s.base.CreateWebhook(ctx, user.UserBriefContext(ctx).ID, &util.ContestContext(ctx).ID, args.URL, args.Events)
+	if err != nil {
+		return nil, err
+	}
+	return &createdWebhook{ID: id, Secret: secret}, nil
+}
+
+func (s *API) deleteContestWebhook(ctx context.Context, args struct {
+	ID int `json:"id"`
+}) error {
+	hook, err := s.base.Webhook(ctx, args.ID)
+	if err != nil {
+		return err
+	}
+	if hook.ContestID == nil || *hook.ContestID != util.ContestContext(ctx).ID {
+		return kilonova.Statusf(404, "Webhook not found")
+	}
+	return s.base.DeleteWebhook(ctx, hook.ID)
+}
@@ -12,6 +12,7 @@ import (
 	"github.com/KiloProjects/kilonova/eval/box"
 	"github.com/KiloProjects/kilonova/eval/scheduler"
 	"github.com/KiloProjects/kilonova/eval/scratch"
+	"github.com/KiloProjects/kilonova/infra/prometheus"
 	"github.com/spf13/afero"
 	"github.com/urfave/cli/v3"
 )
@@ -36,6 +37,9 @@ var graderServe = &cli.Command{
 		}
 		g := gc.Grader
 
+		// Per-client queue depth is exported through the usual metrics endpoint
+		prometheus.InitMetrics(ctx)
+
 		boxFunc := box.New
 		if !scheduler.CheckCanRun(ctx, boxFunc) {
 			return fmt.Errorf("secure sandbox (isolate) is unavailable; refusing to start remote grader")
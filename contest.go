@@ -87,6 +87,24 @@ type Contest struct {
 	// that someone is allowed to send to a problem during a contest.
 	// Any number < 0 means no limit
 	MaxSubs int `json:"max_subs"`
+
+	// MaxTeamSize is the maximum number of members of a registered team.
+	// Setting it to 0 makes the contest individual
+	MaxTeamSize int `json:"max_team_size"`
+
+	// Upsolving allows submissions to be sent to the contest after it ended.
+	// They are shown separately on the leaderboard and don't affect the ranking
+	Upsolving bool `json:"upsolving"`
+	// VirtualParticipation allows users to replay the contest after it ended, in their own time window
+	VirtualParticipation bool `json:"virtual_participation"`
+}
+
+// TeamContest returns whether the contestants register as teams
+func (c *Contest) TeamContest() bool {
+	if c == nil {
+		return false
+	}
+	return c.MaxTeamSize > 0
 }
 
 func (c *Contest) Started() bool {
@@ -240,6 +258,11 @@ type ContestUpdate struct {
 	WhitelistEnabled    *bool `json:"whitelist_enabled"`
 
 	PerUserTime *int `json:"per_user_time"` // Seconds
+
+	MaxTeamSize *int `json:"max_team_size"`
+
+	Upsolving            *bool `json:"upsolving"`
+	VirtualParticipation *bool `json:"virtual_participation"`
 }
 
 type ContestQuestion struct {
@@ -260,17 +283,84 @@ type ContestAnnouncement struct {
 	Text      string    `json:"text"`
 }
 
+// MOSSSubmission is a plagiarism check, also called a plagiarism report.
+// Contest checks compare the submissions of a contest problem, submission checks compare one submission
+// against the archive of its problem and user checks compare the submissions of a user against everyone else's.
 type MOSSSubmission struct {
 	ID        int       `json:"id" db:"id"`
 	CreatedAt time.Time `json:"created_at" db:"created_at"`
 
-	ContestID int `json:"contest_id" db:"contest_id"`
-	ProblemID int `json:"problem_id" db:"problem_id"`
+	ContestID *int `json:"contest_id" db:"contest_id"`
+	// ProblemID is nil for user checks, which span multiple problems
+	ProblemID *int `json:"problem_id" db:"problem_id"`
 
 	Language string `json:"language" db:"language"`
 
 	URL      string `json:"url" db:"url"`
 	SubCount int    `json:"subcount" db:"subcount"`
+
+	Engine PlagiarismEngine `json:"engine" db:"engine"`
+
+	SubmissionID *int `json:"submission_id" db:"submission_id"`
+	UserID       *int `json:"user_id" db:"user_id"`
+	AuthorID     *int `json:"author_id" db:"author_id"`
+}
+
+type PlagiarismEngine string
+
+const (
+	PlagiarismEngineMOSS PlagiarismEngine = "moss"
+	// PlagiarismEngineLocal runs the check in-process, without sending code off-site
+	PlagiarismEngineLocal PlagiarismEngine = "local"
+)
+
+type PlagiarismOptions struct {
+	Engine PlagiarismEngine `json:"engine"`
+	// ProblemIDs restricts the check to some of the contest problems. If empty, all problems are checked
+	ProblemIDs []int `json:"problem_ids"`
+	// AllSubmissions checks every submission, instead of only the best one of each contestant
+	AllSubmissions bool `json:"all_submissions"`
+	// SubmissionIDs restricts the check to the given submissions
+	SubmissionIDs []int `json:"submission_ids"`
+}
+
+type PlagiarismReportFilter struct {
+	// SubmissionID matches the reports of the submission, as well as all reports where it was found to be similar to another one
+	SubmissionID *int `json:"submission_id"`
+	// UserID matches the reports of checks targeting the user
+	UserID *int `json:"user_id"`
+}
+
+// PlagiarismMatch is a pair of similar submissions found by a local plagiarism check
+type PlagiarismMatch struct {
+	ID      int `json:"id" db:"id"`
+	CheckID int `json:"check_id" db:"check_id"`
+
+	Submission1ID int `json:"submission1_id" db:"submission1_id"`
+	Submission2ID int `json:"submission2_id" db:"submission2_id"`
+	User1ID       int `json:"user1_id" db:"user1_id"`
+	User2ID       int `json:"user2_id" db:"user2_id"`
+
+	// Similarity1 is the fraction of the first submission that is found in the second one, and vice versa
+	Similarity1 float64 `json:"similarity1" db:"similarity1"`
+	Similarity2 float64 `json:"similarity2" db:"similarity2"`
+	Shared      int     `json:"shared" db:"shared"`
+	// Cluster groups submissions that are all similar to each other. It is nil for pairs that are only slightly similar
+	Cluster *int `json:"cluster" db:"cluster"`
+
+	Fragments []PlagiarismFragment `json:"fragments" db:"fragments"`
+}
+
+// PlagiarismFragment is a pair of matching line ranges (1-indexed, inclusive) of the two submissions of a match
+type PlagiarismFragment struct {
+	Start1 int `json:"start1"`
+	End1   int `json:"end1"`
+	Start2 int `json:"start2"`
+	End2   int `json:"end2"`
+}
+
+func (m *PlagiarismMatch) Similarity() float64 {
+	return max(m.Similarity1, m.Similarity2)
 }
 
 type ContestRegistration struct {
@@ -282,6 +372,60 @@ type ContestRegistration struct {
 	IndividualEndTime   *time.Time `json:"individual_end" db:"individual_end_at"`
 
 	InvitationID *string `json:"invitation_id" db:"invitation_id"`
+
+	CategoryID *int `json:"category_id" db:"category_id"`
+
+	// TeamID is set for every member of a team registered in a team contest
+	TeamID *int `json:"team_id" db:"team_id"`
+}
+
+// VirtualParticipation is a replay of an ended contest by a single user, in their own time window.
+type VirtualParticipation struct {
+	ID        int       `json:"id"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	ContestID int       `json:"contest_id" db:"contest_id"`
+	UserID    int       `json:"user_id" db:"user_id"`
+
+	StartTime time.Time `json:"start_time" db:"start_time"`
+	EndTime   time.Time `json:"end_time" db:"end_time"`
+}
+
+func (vp *VirtualParticipation) Running() bool {
+	if vp == nil {
+		return false
+	}
+	return vp.StartTime.Before(time.Now()) && vp.EndTime.After(time.Now())
+}
+
+// Elapsed returns how much of the participation went by, capped at its duration
+func (vp *VirtualParticipation) Elapsed() time.Duration {
+	return min(time.Since(vp.StartTime), vp.EndTime.Sub(vp.StartTime))
+}
+
+// VirtualStanding is the result of a virtual participation, compared to the original leaderboard
+// as it stood at the same time since the start of the contest.
+type VirtualStanding struct {
+	Participation *VirtualParticipation `json:"participation"`
+	Entry         *LeaderboardEntry     `json:"entry"`
+	// Rank is the place the virtual participant would have had between the official contestants
+	Rank int `json:"rank"`
+	// NumRanked is the number of official contestants
+	NumRanked int `json:"num_ranked"`
+}
+
+// ContestCategory is a registration category, such as "official", "official at home" or "unofficial".
+// Contestants without a category are considered official.
+type ContestCategory struct {
+	ID        int       `json:"id"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	ContestID int       `json:"contest_id" db:"contest_id"`
+
+	Name string `json:"name"`
+	// Official categories are ranked on the leaderboard. Unofficial contestants are still listed, but without a rank
+	Official bool `json:"official"`
+	// SelfAssignable categories can be picked by contestants when registering.
+	// Others can only be given by editors or through invitations
+	SelfAssignable bool `json:"self_assignable" db:"self_assignable"`
 }
 
 type ContestInvitation struct {
@@ -295,6 +439,9 @@ type ContestInvitation struct {
 	MaxCount    *int `json:"max_invitation_count" db:"max_invitation_cnt"`
 
 	Expired bool `json:"expired"`
+
+	// CategoryID is assigned to everyone registering through the invitation
+	CategoryID *int `json:"category_id" db:"category_id"`
 }
 
 func (ci *ContestInvitation) Invalid() bool {
@@ -305,6 +452,10 @@ func (ci *ContestInvitation) Invalid() bool {
 type LeaderboardEntry struct {
 	User *UserBrief `json:"user"`
 
+	// For team contests, User is just one of the members
+	Team    *Team        `json:"team,omitempty"`
+	Members []*UserBrief `json:"members,omitempty"`
+
 	// For classic mode
 	ProblemScores map[int]decimal.Decimal `json:"scores"`
 	TotalScore    decimal.Decimal         `json:"total"`
@@ -318,6 +469,14 @@ type LeaderboardEntry struct {
 
 	LastTime   *time.Time `json:"last_time"`
 	FreezeTime *time.Time `json:"freeze_time"`
+
+	CategoryID *int `json:"category_id"`
+	// Rank is nil for unofficial contestants
+	Rank *int `json:"rank"`
+
+	// UpsolveTotal is the result including the submissions sent while upsolving:
+	// the total score in classic mode and the number of solved problems in ICPC mode
+	UpsolveTotal *decimal.Decimal `json:"upsolve_total,omitempty"`
 }
 
 type ContestLeaderboard struct {
@@ -325,12 +484,98 @@ type ContestLeaderboard struct {
 	ProblemNames map[int]string      `json:"problem_names"`
 	Entries      []*LeaderboardEntry `json:"entries"`
 
+	Categories []*ContestCategory `json:"categories"`
+
 	AdvancedFilter bool `json:"advanced_filter"`
+	// Upsolving is set if the entries have their upsolving results filled in
+	Upsolving bool `json:"upsolving"`
 
 	FreezeTime *time.Time      `json:"freeze_time"`
 	Type       LeaderboardType `json:"type"`
 }
 
+// RankEntries assigns ranks to the leaderboard entries, which must already be sorted.
+// Unofficial contestants don't get a rank and don't push others down, unless the leaderboard was
+// filtered to a single category, in which case everyone left gets that category's own ranking.
+// Contestants get the same rank only if they are tied on every ordering criterion.
+func (ld *ContestLeaderboard) RankEntries(categoryFiltered bool) {
+	unofficial := make(map[int]bool)
+	if !categoryFiltered {
+		for _, category := range ld.Categories {
+			if !category.Official {
+				unofficial[category.ID] = true
+			}
+		}
+	}
+
+	var rank, count int
+	var last *LeaderboardEntry
+	for _, entry := range ld.Entries {
+		entry.Rank = nil
+		if entry.CategoryID != nil && unofficial[*entry.CategoryID] {
+			continue
+		}
+		count++
+		if last == nil || !ld.tied(last, entry) {
+			rank = count
+		}
+		entry.Rank = new(rank)
+		last = entry
+	}
+}
+
+func (ld *ContestLeaderboard) tied(a, b *LeaderboardEntry) bool {
+	sameTime := (a.LastTime == nil && b.LastTime == nil) || (a.LastTime != nil && b.LastTime != nil && a.LastTime.Equal(*b.LastTime))
+	if ld.Type == LeaderboardTypeICPC {
+		return a.NumSolved == b.NumSolved && a.Penalty == b.Penalty && sameTime
+	}
+	return a.TotalScore.Equal(b.TotalScore) && sameTime
+}
+
+// VirtualRank returns the rank entry would get between the ranked contestants of the leaderboard, and their number.
+// The times of entry must already be shifted to the contest's timeline.
+func (ld *ContestLeaderboard) VirtualRank(entry *LeaderboardEntry) (rank int, numRanked int) {
+	rank = 1
+	for _, other := range ld.Entries {
+		if other.Rank == nil {
+			continue
+		}
+		numRanked++
+		if ld.better(other, entry) {
+			rank++
+		}
+	}
+	return rank, numRanked
+}
+
+// better reports whether a is strictly ahead of b, using the same ordering as the leaderboard queries
+func (ld *ContestLeaderboard) better(a, b *LeaderboardEntry) bool {
+	if ld.Type == LeaderboardTypeICPC {
+		if a.NumSolved != b.NumSolved {
+			return a.NumSolved > b.NumSolved
+		}
+		if a.Penalty != b.Penalty {
+			return a.Penalty < b.Penalty
+		}
+	} else if !a.TotalScore.Equal(b.TotalScore) {
+		return a.TotalScore.GreaterThan(b.TotalScore)
+	}
+	if a.LastTime == nil || b.LastTime == nil {
+		// Entries without a last time come last
+		return a.LastTime != nil && b.LastTime == nil
+	}
+	return a.LastTime.Before(*b.LastTime)
+}
+
+// LeaderboardFilter narrows down the contestants shown on a leaderboard
+type LeaderboardFilter struct {
+	Generated *bool
+	// CategoryID only keeps contestants registered in the given category
+	CategoryID *int
+	// OfficialOnly drops contestants registered in unofficial categories
+	OfficialOnly bool
+}
+
 type ContestUserIPs struct {
 	User *UserBrief
 	IPs  []*netip.Addr
@@ -0,0 +1,104 @@
+package kilonova
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestRankEntries(t *testing.T) {
+	early := time.Now()
+	late := early.Add(time.Minute)
+	unofficial := 2
+
+	ld := &ContestLeaderboard{
+		Type: LeaderboardTypeClassic,
+		Categories: []*ContestCategory{
+			{ID: 1, Name: "Official", Official: true},
+			{ID: unofficial, Name: "Unofficial"},
+		},
+		Entries: []*LeaderboardEntry{
+			{TotalScore: decimal.NewFromInt(300), LastTime: &early},
+			{TotalScore: decimal.NewFromInt(250), LastTime: &early, CategoryID: &unofficial},
+			{TotalScore: decimal.NewFromInt(200), LastTime: &early, CategoryID: new(1)},
+			{TotalScore: decimal.NewFromInt(200), LastTime: &early},
+			{TotalScore: decimal.NewFromInt(200), LastTime: &late},
+			{TotalScore: decimal.Zero},
+		},
+	}
+	ld.RankEntries(false)
+
+	expected := []int{1, -1, 2, 2, 4, 5}
+	for i, entry := range ld.Entries {
+		got := -1
+		if entry.Rank != nil {
+			got = *entry.Rank
+		}
+		if got != expected[i] {
+			t.Errorf("Entry %d: expected rank %d, got %d", i, expected[i], got)
+		}
+	}
+}
+
+func TestRankEntriesCategoryFilter(t *testing.T) {
+	early := time.Now()
+	unofficial := 2
+
+	// The leaderboard filtered to the unofficial category
+	ld := &ContestLeaderboard{
+		Type: LeaderboardTypeClassic,
+		Categories: []*ContestCategory{
+			{ID: 1, Name: "Official", Official: true},
+			{ID: unofficial, Name: "Unofficial"},
+		},
+		Entries: []*LeaderboardEntry{
+			{TotalScore: decimal.NewFromInt(250), LastTime: &early, CategoryID: &unofficial},
+			{TotalScore: decimal.NewFromInt(100), LastTime: &early, CategoryID: &unofficial},
+			{TotalScore: decimal.NewFromInt(100), LastTime: &early, CategoryID: &unofficial},
+			{TotalScore: decimal.Zero, CategoryID: &unofficial},
+		},
+	}
+	ld.RankEntries(true)
+
+	expected := []int{1, 2, 2, 4}
+	for i, entry := range ld.Entries {
+		if entry.Rank == nil {
+			t.Errorf("Entry %d: expected rank %d, got none", i, expected[i])
+		} else if *entry.Rank != expected[i] {
+			t.Errorf("Entry %d: expected rank %d, got %d", i, expected[i], *entry.Rank)
+		}
+	}
+}
+
+func TestVirtualRank(t *testing.T) {
+	early := time.Now()
+	late := early.Add(time.Minute)
+
+	ld := &ContestLeaderboard{
+		Type: LeaderboardTypeICPC,
+		Entries: []*LeaderboardEntry{
+			{NumSolved: 3, Penalty: 100, LastTime: &late, Rank: new(1)},
+			{NumSolved: 2, Penalty: 50, LastTime: &early, Rank: new(2)},
+			{NumSolved: 2, Penalty: 80, LastTime: &late}, // unofficial
+			{NumSolved: 2, Penalty: 80, LastTime: &late, Rank: new(3)},
+			{NumSolved: 0, Rank: new(4)},
+		},
+	}
+
+	for _, tc := range []struct {
+		entry *LeaderboardEntry
+		rank  int
+	}{
+		{&LeaderboardEntry{NumSolved: 4, Penalty: 500, LastTime: &late}, 1},
+		{&LeaderboardEntry{NumSolved: 2, Penalty: 80, LastTime: &late}, 3},
+		{&LeaderboardEntry{NumSolved: 2, Penalty: 80, LastTime: &early}, 3},
+		{&LeaderboardEntry{NumSolved: 2, Penalty: 60, LastTime: &late}, 3},
+		{&LeaderboardEntry{}, 4},
+	} {
+		rank, numRanked := ld.VirtualRank(tc.entry)
+		if rank != tc.rank || numRanked != 4 {
+			t.Errorf("Entry %+v: expected rank %d/4, got %d/%d", tc.entry, tc.rank, rank, numRanked)
+		}
+	}
+}
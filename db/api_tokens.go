@@ -0,0 +1,58 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// APIToken is a personal access token, used for scripting against the API without a session
+type APIToken struct {
+	ID        int       `db:"id" json:"id"`
+	UserID    int       `db:"user_id" json:"user_id"`
+	Name      string    `db:"name" json:"name"`
+	TokenHash string    `db:"token_hash" json:"-"`
+	Scopes    []string  `db:"scopes" json:"scopes"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+
+	ExpiresAt  *time.Time `db:"expires_at" json:"expires_at"`
+	LastUsedAt *time.Time `db:"last_used_at" json:"last_used_at"`
+}
+
+func (t *APIToken) Expired() bool {
+	return t.ExpiresAt != nil && t.ExpiresAt.Before(time.Now())
+}
+
+func (s *DB) CreateAPIToken(ctx context.Context, userID int, name string, tokenHash string, scopes []string, expiresAt *time.Time) (int, error) {
+	var id int
+	err := s.conn.QueryRow(ctx, `INSERT INTO api_tokens (user_id, name, token_hash, scopes, expires_at) VALUES ($1, $2, $3, $4, $5) RETURNING id`, userID, name, tokenHash, scopes, expiresAt).Scan(&id)
+	return id, err
+}
+
+// APITokenByHash returns the unexpired token with the given hash, or nil if there is none
+func (s *DB) APITokenByHash(ctx context.Context, tokenHash string) (*APIToken, error) {
+	rows, _ := s.conn.Query(ctx, `SELECT * FROM api_tokens WHERE token_hash = $1 AND (expires_at IS NULL OR expires_at > NOW())`, tokenHash)
+	token, err := pgx.CollectOneRow(rows, pgx.RowToAddrOfStructByName[APIToken])
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	return token, err
+}
+
+func (s *DB) UserAPITokens(ctx context.Context, userID int) ([]*APIToken, error) {
+	rows, _ := s.conn.Query(ctx, `SELECT * FROM api_tokens WHERE user_id = $1 ORDER BY created_at DESC`, userID)
+	return pgx.CollectRows(rows, pgx.RowToAddrOfStructByName[APIToken])
+}
+
+// MarkAPITokenUsed updates the last usage timestamp. To avoid a write on every request, it is only updated once a minute.
+func (s *DB) MarkAPITokenUsed(ctx context.Context, id int) error {
+	_, err := s.conn.Exec(ctx, `UPDATE api_tokens SET last_used_at = NOW() WHERE id = $1 AND (last_used_at IS NULL OR last_used_at < NOW() - INTERVAL '1 minute')`, id)
+	return err
+}
+
+func (s *DB) RemoveAPIToken(ctx context.Context, userID int, id int) error {
+	_, err := s.conn.Exec(ctx, `DELETE FROM api_tokens WHERE user_id = $1 AND id = $2`, userID, id)
+	return err
+}
@@ -1,6 +1,7 @@
 package db
 
 import (
+	"cmp"
 	"context"
 	"errors"
 	"log/slog"
@@ -12,7 +13,7 @@ import (
 	"github.com/jackc/pgx/v5"
 )
 
-const createAttachmentQuery = "INSERT INTO attachments (visible, private, execable, name, data, last_updated_by) VALUES ($1, $2, $3, $4, $5, $6) RETURNING id;"
+const createAttachmentQuery = "INSERT INTO attachments (visible, private, execable, name, data, last_updated_by, provenance, translated_from) VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING id;"
 
 func (s *DB) createAttachment(ctx context.Context, att *kilonova.Attachment, data []byte, authorID *int) (int, error) {
 	if data == nil {
@@ -20,7 +21,8 @@ func (s *DB) createAttachment(ctx context.Context, att *kilonova.Attachment, dat
 	}
 
 	var id int
-	err := s.conn.QueryRow(ctx, createAttachmentQuery, att.Visible, att.Private, att.Exec, att.Name, data, authorID).Scan(&id)
+	provenance := cmp.Or(att.Provenance, kilonova.StatementOriginal)
+	err := s.conn.QueryRow(ctx, createAttachmentQuery, att.Visible, att.Private, att.Exec, att.Name, data, authorID, provenance, att.TranslatedFrom).Scan(&id)
 	if err != nil {
 		return -1, err
 	}
@@ -81,7 +83,7 @@ func (s *DB) Attachment(ctx context.Context, filter *kilonova.AttachmentFilter)
 
 // TODO: Remove problem_attachments and blog_post_attachments views from DB
 func (s *DB) Attachments(ctx context.Context, filter *kilonova.AttachmentFilter) ([]*kilonova.Attachment, error) {
-	qb := sq.Select("id", "created_at", "last_updated_at", "last_updated_by", "visible", "private", "execable", "name", "data_size").From("attachments").Where(attachmentFilterQuery(filter)).OrderBy("name ASC")
+	qb := sq.Select("id", "created_at", "last_updated_at", "last_updated_by", "visible", "private", "execable", "name", "data_size", "provenance", "translated_from", "reviewed_by", "reviewed_at").From("attachments").Where(attachmentFilterQuery(filter)).OrderBy("name ASC")
 	qb = LimitOffset(qb, filter.Limit, filter.Offset)
 	query, args, err := qb.ToSql()
 	if err != nil {
@@ -123,6 +125,14 @@ func (s *DB) UpdateAttachment(ctx context.Context, id int, upd *kilonova.Attachm
 	if v := upd.Exec; v != nil {
 		qb = qb.Set("execable", v)
 	}
+	if v := upd.Provenance; v != nil {
+		qb = qb.Set("provenance", v)
+		// Any change of provenance invalidates the previous review
+		qb = qb.Set("reviewed_by", nil).Set("reviewed_at", nil)
+	}
+	if v := upd.TranslatedFrom; v != nil {
+		qb = qb.Set("translated_from", v)
+	}
 	query, args, err := qb.ToSql()
 	if err != nil {
 		if err.Error() == "update statements must have at least one Set clause" {
@@ -139,6 +149,12 @@ func (s *DB) UpdateAttachmentData(ctx context.Context, id int, data []byte, upda
 	return err
 }
 
+// ReviewAttachment marks a machine-translated statement as reviewed by the user and publishes it
+func (s *DB) ReviewAttachment(ctx context.Context, id int, reviewerID int) error {
+	_, err := s.conn.Exec(ctx, "UPDATE attachments SET provenance = $2, reviewed_by = $3, reviewed_at = NOW(), private = false WHERE id = $1", id, kilonova.StatementHumanReviewed, reviewerID)
+	return err
+}
+
 func (s *DB) DeleteAttachments(ctx context.Context, filter *kilonova.AttachmentFilter) (int, error) {
 	qb := sq.Delete("attachments").Where(attachmentFilterQuery(filter))
 	query, args, err := qb.ToSql()
@@ -211,6 +227,11 @@ type dbAttachment struct {
 	Name string `db:"name"`
 	Size int    `db:"data_size"`
 	//Data []byte `db:"data"`
+
+	Provenance     kilonova.StatementProvenance `db:"provenance"`
+	TranslatedFrom *string                      `db:"translated_from"`
+	ReviewedBy     *int                         `db:"reviewed_by"`
+	ReviewedAt     *time.Time                   `db:"reviewed_at"`
 }
 
 func internalToAttachment(att *dbAttachment) *kilonova.Attachment {
@@ -229,5 +250,10 @@ func internalToAttachment(att *dbAttachment) *kilonova.Attachment {
 
 		Name: att.Name,
 		Size: att.Size,
+
+		Provenance:     att.Provenance,
+		TranslatedFrom: att.TranslatedFrom,
+		ReviewedBy:     att.ReviewedBy,
+		ReviewedAt:     att.ReviewedAt,
 	}
 }
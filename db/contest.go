@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"log/slog"
+	"slices"
 	"time"
 
 	"github.com/KiloProjects/kilonova"
@@ -39,6 +40,11 @@ type dbContest struct {
 	PerUserTime           int  `db:"per_user_time"`
 	RegisterDuringContest bool `db:"register_during_contest"`
 
+	MaxTeamSize int `db:"max_team_size"`
+
+	Upsolving            bool `db:"upsolving"`
+	VirtualParticipation bool `db:"virtual_participation"`
+
 	SubmissionCooldown int `db:"submission_cooldown_ms"`
 	QuestionCooldown   int `db:"question_cooldown_ms"`
 
@@ -228,6 +234,9 @@ type databaseClassicEntry struct {
 	Total     decimal.Decimal `db:"total_score"`
 	LastTime  *time.Time      `db:"last_time"`
 
+	CategoryID *int `db:"category_id"`
+	TeamID     *int `db:"team_id"`
+
 	FreezeTime *time.Time `db:"freeze_time"`
 }
 
@@ -237,6 +246,11 @@ func (s *DB) classicToLeaderboardEntry(ctx context.Context, entry *databaseClass
 		return nil, err
 	}
 
+	team, members, err := s.leaderboardTeam(ctx, entry.ContestID, entry.TeamID)
+	if err != nil {
+		return nil, err
+	}
+
 	rows, _ := s.conn.Query(ctx, "SELECT problem_id, score FROM contest_max_scores($2, $3) WHERE user_id = $1", entry.UserID, entry.ContestID, entry.FreezeTime)
 	pbs, err := pgx.CollectRows(rows, pgx.RowToStructByName[struct {
 		ProblemID int             `db:"problem_id"`
@@ -257,6 +271,8 @@ func (s *DB) classicToLeaderboardEntry(ctx context.Context, entry *databaseClass
 
 	return &kilonova.LeaderboardEntry{
 		User:          user.Brief(),
+		Team:          team,
+		Members:       members,
 		TotalScore:    entry.Total,
 		ProblemScores: scores,
 
@@ -267,37 +283,43 @@ func (s *DB) classicToLeaderboardEntry(ctx context.Context, entry *databaseClass
 
 		LastTime:   entry.LastTime,
 		FreezeTime: entry.FreezeTime,
+
+		CategoryID: entry.CategoryID,
 	}, nil
 }
 
+// contestMaxScores mirrors the contest_max_scores SQL function.
+// In team contests, the submissions are credited to the team, so every member gets the best score of the team
 func (s *DB) contestMaxScores(contestID int, freezeTime *time.Time, userID *int, problemID *int) sq.SelectBuilder {
 	maxSubmissionStrat := sq.Select(
-		"user_id",
+		"team_id",
+		"(CASE WHEN team_id IS NULL THEN user_id END) AS user_id",
 		"problem_id",
-		"FIRST_VALUE(score * (leaderboard_score_scale / 100)) OVER w AS max_score",
+		"FIRST_VALUE(shown_score * (leaderboard_score_scale / 100)) OVER w AS max_score",
 		"FIRST_VALUE(created_at) OVER w AS mintime").Distinct().
-		From("submissions").Where("contest_id = ?", contestID).
-		Where("created_at <= COALESCE(?, NOW())", freezeTime).
-		Where("(status = 'finished' OR status = 'reevaling')").
-		Suffix("WINDOW w AS (PARTITION BY user_id, problem_id ORDER BY score DESC, created_at ASC)")
+		FromSelect(sq.Select("*", "shown_submission_score(id, contest_id, score) AS shown_score").
+			From("submissions").Where("contest_id = ?", contestID).
+			Where("created_at <= COALESCE(?, NOW())", freezeTime).
+			Where("(status = 'finished' OR status = 'reevaling')"), "subs").
+		Suffix("WINDOW w AS (PARTITION BY team_id, (CASE WHEN team_id IS NULL THEN user_id END), problem_id ORDER BY shown_score DESC, created_at ASC)")
 
 	subtaskMaxScores := sq.Select(
-		"user_id",
-		"subtask_id",
-		"problem_id",
-		"FIRST_VALUE(computed_score * (leaderboard_score_scale / 100)) OVER w AS max_score",
-		"FIRST_VALUE(created_at) OVER w AS mintime").Distinct().
-		From("submission_subtasks").Where("contest_id = ?", contestID).Where("subtask_id IS NOT NULL").
-		Where("created_at <= COALESCE(?, NOW())", freezeTime).
-		Suffix("WINDOW w AS (PARTITION BY user_id, subtask_id, problem_id ORDER BY computed_score DESC, created_at ASC)")
-
-	sumSubtasksStrat := sq.Select("user_id", "problem_id", "coalesce(SUM(max_score), -1) AS max_score", "MAX(mintime) AS mintime").
+		"subs.team_id",
+		"(CASE WHEN subs.team_id IS NULL THEN stks.user_id END) AS user_id",
+		"stks.subtask_id",
+		"stks.problem_id",
+		"FIRST_VALUE(stks.computed_score * (stks.leaderboard_score_scale / 100)) OVER w AS max_score",
+		"FIRST_VALUE(stks.created_at) OVER w AS mintime").Distinct().
+		From("submission_subtasks stks").InnerJoin("submissions subs ON subs.id = stks.submission_id").
+		Where("stks.contest_id = ?", contestID).Where("stks.subtask_id IS NOT NULL").
+		Where("stks.created_at <= COALESCE(?, NOW())", freezeTime).
+		Where("NOT contest_hides_subtask(stks.contest_id, stks.subtask_id)").
+		Suffix("WINDOW w AS (PARTITION BY subs.team_id, (CASE WHEN subs.team_id IS NULL THEN stks.user_id END), stks.subtask_id, stks.problem_id ORDER BY stks.computed_score DESC, stks.created_at ASC)")
+
+	sumSubtasksStrat := sq.Select("team_id", "user_id", "problem_id", "coalesce(SUM(max_score), -1) AS max_score", "MAX(mintime) AS mintime").
 		FromSelect(subtaskMaxScores, "max_scores").
-		GroupBy("user_id", "problem_id")
+		GroupBy("team_id", "user_id", "problem_id")
 
-	if userID != nil {
-		maxSubmissionStrat = maxSubmissionStrat.Where("user_id = ?", userID)
-	}
 	if problemID != nil {
 		maxSubmissionStrat = maxSubmissionStrat.Where("problem_id = ?", problemID)
 	}
@@ -318,11 +340,11 @@ func (s *DB) contestMaxScores(contestID int, freezeTime *time.Time, userID *int,
 		sq.Expr("problems.scoring_strategy = 'sum_subtasks'"),
 		"COALESCE(ms_subtask.mintime, NULL)",
 	).Else("NULL"), "mintime",
-	)).From("contest_problems pbs").
+	)).Column("contest_users.team_id AS team_id").From("contest_problems pbs").
 		InnerJoin("contest_registrations contest_users ON contest_users.contest_id = pbs.contest_id").
 		InnerJoin("problems ON pbs.problem_id = problems.id").
-		JoinClause(maxSubmissionStrat.Prefix("LEFT JOIN (").Suffix(") AS ms_sub ON (ms_sub.user_id = contest_users.user_id AND ms_sub.problem_id = pbs.problem_id)")).
-		JoinClause(sumSubtasksStrat.Prefix("LEFT JOIN (").Suffix(") AS ms_subtask ON (ms_subtask.user_id = contest_users.user_id AND ms_subtask.problem_id = pbs.problem_id)")).
+		JoinClause(maxSubmissionStrat.Prefix("LEFT JOIN (").Suffix(") AS ms_sub ON (ms_sub.problem_id = pbs.problem_id AND (ms_sub.team_id = contest_users.team_id OR (contest_users.team_id IS NULL AND ms_sub.team_id IS NULL AND ms_sub.user_id = contest_users.user_id)))")).
+		JoinClause(sumSubtasksStrat.Prefix("LEFT JOIN (").Suffix(") AS ms_subtask ON (ms_subtask.problem_id = pbs.problem_id AND (ms_subtask.team_id = contest_users.team_id OR (contest_users.team_id IS NULL AND ms_subtask.team_id IS NULL AND ms_subtask.user_id = contest_users.user_id)))")).
 		Where(sq.Eq{"pbs.contest_id": contestID})
 
 	if userID != nil {
@@ -352,6 +374,8 @@ func (s *DB) contestTopView(contestID int, freezeTime *time.Time, includeEditors
 		Column("?::integer AS contest_id", contestID).
 		Column("COALESCE(scores.total_score, 0) AS total_score").
 		Column("last_time").
+		Column("contest_users.category_id").
+		Column("contest_users.team_id").
 		FromSelect(legitContestants, "contest_users").
 		JoinClause(contestScores.Prefix("LEFT JOIN (").Suffix(") AS scores ON contest_users.user_id = scores.user_id")).
 		OrderBy("total_score DESC", "last_time ASC NULLS LAST")
@@ -372,7 +396,53 @@ func (s *DB) contestTopView(contestID int, freezeTime *time.Time, includeEditors
 // 		FromSelect(solvedPbs, "solved_pbs").GroupBy("user_id")
 // }
 
-func (s *DB) ContestClassicLeaderboard(ctx context.Context, contest *kilonova.Contest, freezeTime *time.Time, generated *bool) (*kilonova.ContestLeaderboard, error) {
+// uniqueTeamEntries keeps only the first entry of every team, since all members of a team share their scores.
+// Entries without a team are kept as they are
+func uniqueTeamEntries[T any](entries []T, teamID func(T) *int) []T {
+	seen := make(map[int]bool)
+	return slices.DeleteFunc(entries, func(entry T) bool {
+		id := teamID(entry)
+		if id == nil {
+			return false
+		}
+		if seen[*id] {
+			return true
+		}
+		seen[*id] = true
+		return false
+	})
+}
+
+// leaderboardTeam returns the team of a leaderboard entry, along with its members registered in the contest
+func (s *DB) leaderboardTeam(ctx context.Context, contestID int, teamID *int) (*kilonova.Team, []*kilonova.UserBrief, error) {
+	if teamID == nil {
+		return nil, nil, nil
+	}
+	team, err := s.Team(ctx, *teamID)
+	if err != nil {
+		return nil, nil, err
+	}
+	members, err := s.ContestTeamMembers(ctx, contestID, *teamID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return team, slicealg.Map(members, (*kilonova.UserFull).Brief), nil
+}
+
+func leaderboardFilterQuery(filter kilonova.LeaderboardFilter, sb sq.SelectBuilder) sq.SelectBuilder {
+	if filter.Generated != nil {
+		sb = sb.Where("EXISTS (SELECT 1 FROM users WHERE user_id = users.id AND generated = ?)", *filter.Generated)
+	}
+	if filter.CategoryID != nil {
+		sb = sb.Where(sq.Eq{"category_id": *filter.CategoryID})
+	}
+	if filter.OfficialOnly {
+		sb = sb.Where("NOT EXISTS (SELECT 1 FROM contest_categories cats WHERE cats.id = category_id AND NOT cats.official)")
+	}
+	return sb
+}
+
+func (s *DB) ContestClassicLeaderboard(ctx context.Context, contest *kilonova.Contest, freezeTime *time.Time, filter kilonova.LeaderboardFilter) (*kilonova.ContestLeaderboard, error) {
 	pbs, err := s.ContestProblems(ctx, contest.ID)
 	if err != nil {
 		return nil, err
@@ -394,10 +464,7 @@ func (s *DB) ContestClassicLeaderboard(ctx context.Context, contest *kilonova.Co
 	sb := sq.Select("*").Column("?::timestamptz AS freeze_time", freezeTime).
 		FromSelect(s.contestTopView(contest.ID, freezeTime, contest.Type == kilonova.ContestTypeVirtual), "contest_top_view").
 		OrderBy("total_score DESC", "last_time ASC NULLS LAST", "user_id")
-
-	if generated != nil {
-		sb = sb.Where("EXISTS (SELECT 1 FROM users WHERE user_id = users.id AND generated = ?)", *generated)
-	}
+	sb = leaderboardFilterQuery(filter, sb)
 
 	query, args, err := sb.ToSql()
 	if err != nil {
@@ -410,6 +477,10 @@ func (s *DB) ContestClassicLeaderboard(ctx context.Context, contest *kilonova.Co
 		return nil, err
 	}
 
+	if contest.TeamContest() {
+		topList = uniqueTeamEntries(topList, func(entry *databaseClassicEntry) *int { return entry.TeamID })
+	}
+
 	leaderboard.Entries = slicealg.MapCtx(ctx, topList, s.classicToLeaderboardEntry)
 
 	return leaderboard, nil
@@ -425,6 +496,9 @@ type databaseICPCEntry struct {
 
 	NumAttempts int `db:"num_attempts"`
 
+	CategoryID *int `db:"category_id"`
+	TeamID     *int `db:"team_id"`
+
 	FreezeTime *time.Time `db:"freeze_time"`
 }
 
@@ -434,11 +508,16 @@ func (s *DB) icpcToLeaderboardEntry(ctx context.Context, entry *databaseICPCEntr
 		return nil, err
 	}
 
+	team, members, err := s.leaderboardTeam(ctx, entry.ContestID, entry.TeamID)
+	if err != nil {
+		return nil, err
+	}
+
 	rows, _ := s.conn.Query(ctx, `
 		SELECT problem_id, score, mintime, COALESCE(natts.num_atts, 0) AS num_attempts
 			FROM contest_max_scores($2, $3) cms, 
 			LATERAL (SELECT COUNT(*) AS num_atts FROM submissions 
-				WHERE contest_id = $2 AND user_id = $1 AND compile_error = false AND (status = 'finished' OR status = 'reevaling') AND problem_id = cms.problem_id AND created_at <= COALESCE($3, NOW()) AND (cms.score < 100 OR created_at < cms.mintime)) natts 
+				WHERE contest_id = $2 AND (CASE WHEN cms.team_id IS NULL THEN user_id = $1 ELSE team_id = cms.team_id END) AND compile_error = false AND (status = 'finished' OR status = 'reevaling') AND problem_id = cms.problem_id AND created_at <= COALESCE($3, NOW()) AND (cms.score < 100 OR created_at < cms.mintime)) natts 
 		WHERE user_id = $1
 `, entry.UserID, entry.ContestID, entry.FreezeTime)
 	pbs, err := pgx.CollectRows(rows, pgx.RowToStructByName[struct {
@@ -464,6 +543,8 @@ func (s *DB) icpcToLeaderboardEntry(ctx context.Context, entry *databaseICPCEntr
 
 	return &kilonova.LeaderboardEntry{
 		User:          user.Brief(),
+		Team:          team,
+		Members:       members,
 		TotalScore:    decimal.Zero,
 		ProblemScores: scores,
 
@@ -474,10 +555,12 @@ func (s *DB) icpcToLeaderboardEntry(ctx context.Context, entry *databaseICPCEntr
 
 		LastTime:   entry.LastTime,
 		FreezeTime: entry.FreezeTime,
+
+		CategoryID: entry.CategoryID,
 	}, nil
 }
 
-func (s *DB) ContestICPCLeaderboard(ctx context.Context, contest *kilonova.Contest, freezeTime *time.Time, generated *bool) (*kilonova.ContestLeaderboard, error) {
+func (s *DB) ContestICPCLeaderboard(ctx context.Context, contest *kilonova.Contest, freezeTime *time.Time, filter kilonova.LeaderboardFilter) (*kilonova.ContestLeaderboard, error) {
 	pbs, err := s.ContestProblems(ctx, contest.ID)
 	if err != nil {
 		return nil, err
@@ -498,18 +581,30 @@ func (s *DB) ContestICPCLeaderboard(ctx context.Context, contest *kilonova.Conte
 
 	var topList []*databaseICPCEntry
 
-	where, args := "1 = 1", []any{contest.ID, freezeTime, contest.Type == kilonova.ContestTypeVirtual}
-	if generated != nil {
-		where = "EXISTS (SELECT 1 FROM users WHERE user_id = users.id AND generated = ?)"
-		args = append(args, *generated)
+	icpcView := sq.Select("icpc.*", "regs.category_id", "regs.team_id").Column("?::timestamptz AS freeze_time", freezeTime).
+		From("contest_registrations regs").
+		JoinClause("INNER JOIN contest_icpc_view(?, ?, ?) icpc ON regs.user_id = icpc.user_id", contest.ID, freezeTime, contest.Type == kilonova.ContestTypeVirtual).
+		Where(sq.Eq{"regs.contest_id": contest.ID})
+
+	sb := sq.Select("*").FromSelect(icpcView, "contest_icpc_view").
+		OrderBy("num_solved DESC", "penalty ASC NULLS LAST", "last_time ASC NULLS LAST", "user_id")
+	sb = leaderboardFilterQuery(filter, sb)
+
+	query, args, err := sb.ToSql()
+	if err != nil {
+		return nil, err
 	}
 
-	err = Select(s.conn, ctx, &topList, "SELECT *, $2 AS freeze_time FROM contest_icpc_view($1, $2, $3) WHERE "+where+" ORDER BY num_solved DESC, penalty ASC NULLS LAST, last_time ASC NULLS LAST, user_id", args...)
+	err = Select(s.conn, ctx, &topList, query, args...)
 	if err != nil {
 		slog.WarnContext(ctx, "Couldn't get ICPC leaderboard", slog.Any("err", err))
 		return nil, err
 	}
 
+	if contest.TeamContest() {
+		topList = uniqueTeamEntries(topList, func(entry *databaseICPCEntry) *int { return entry.TeamID })
+	}
+
 	leaderboard.Entries = slicealg.MapCtx(context.WithValue(ctx, util.ContestKey, contest), topList, s.icpcToLeaderboardEntry)
 
 	return leaderboard, nil
@@ -517,9 +612,9 @@ func (s *DB) ContestICPCLeaderboard(ctx context.Context, contest *kilonova.Conte
 
 // MOSS setup
 
-func (s *DB) InsertMossSubmission(ctx context.Context, contestID int, problemID int, lang string, subcount int) (int, error) {
+func (s *DB) InsertMossSubmission(ctx context.Context, contestID int, problemID int, lang string, subcount int, engine kilonova.PlagiarismEngine) (int, error) {
 	var id int
-	err := s.conn.QueryRow(ctx, "INSERT INTO moss_submissions (contest_id, problem_id, language, subcount, url) VALUES ($1, $2, $3, $4, '') RETURNING id", contestID, problemID, lang, subcount).Scan(&id)
+	err := s.conn.QueryRow(ctx, "INSERT INTO moss_submissions (contest_id, problem_id, language, subcount, url, engine) VALUES ($1, $2, $3, $4, '', $5) RETURNING id", contestID, problemID, lang, subcount, engine).Scan(&id)
 	return id, err
 }
 
@@ -528,6 +623,18 @@ func (s *DB) SetMossURL(ctx context.Context, subID int, url string) error {
 	return err
 }
 
+func (s *DB) MossSubmission(ctx context.Context, id int) (*kilonova.MOSSSubmission, error) {
+	rows, _ := s.conn.Query(ctx, "SELECT * FROM moss_submissions WHERE id = $1", id)
+	sub, err := pgx.CollectOneRow(rows, pgx.RowToAddrOfStructByName[kilonova.MOSSSubmission])
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return sub, nil
+}
+
 func (s *DB) MossSubmissions(ctx context.Context, contestID int) ([]*kilonova.MOSSSubmission, error) {
 	rows, _ := s.conn.Query(ctx, "SELECT * FROM moss_submissions WHERE contest_id = $1 ORDER BY created_at DESC", contestID)
 	subs, err := pgx.CollectRows(rows, pgx.RowToAddrOfStructByName[kilonova.MOSSSubmission])
@@ -626,6 +733,15 @@ func contestUpdateQuery(upd *kilonova.ContestUpdate, ub *updateBuilder) {
 	if v := upd.WhitelistEnabled; v != nil {
 		ub.AddUpdate("whitelist_enabled = %s", v)
 	}
+	if v := upd.MaxTeamSize; v != nil {
+		ub.AddUpdate("max_team_size = %s", v)
+	}
+	if v := upd.Upsolving; v != nil {
+		ub.AddUpdate("upsolving = %s", v)
+	}
+	if v := upd.VirtualParticipation; v != nil {
+		ub.AddUpdate("virtual_participation = %s", v)
+	}
 }
 
 func getContestOrdering(ordering string, ascending bool) string {
@@ -671,6 +787,10 @@ func (s *DB) internalToContest(ctx context.Context, contest *dbContest) (*kilono
 		Description: contest.Desc,
 
 		PerUserTime: contest.PerUserTime,
+		MaxTeamSize: contest.MaxTeamSize,
+
+		Upsolving:            contest.Upsolving,
+		VirtualParticipation: contest.VirtualParticipation,
 
 		PublicLeaderboard: contest.PublicLeaderboard,
 		LeaderboardStyle:  contest.LeaderboardStyle,
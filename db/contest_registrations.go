@@ -59,8 +59,45 @@ func (s *DB) ContestRegistration(ctx context.Context, contestID, userID int) (*k
 	return &reg, nil
 }
 
-func (s *DB) InsertContestRegistration(ctx context.Context, contestID, userID int, invitationID *string) error {
-	_, err := s.conn.Exec(ctx, "INSERT INTO contest_registrations (user_id, contest_id, invitation_id) VALUES ($1, $2, $3)", userID, contestID, invitationID)
+// ErrInvitationLimit is returned when registering through an invitation that was already used the maximum number of times
+var ErrInvitationLimit = kilonova.Statusf(400, "Invite limit reached")
+
+// invitationRedeemCount counts the registrations made through the invitation inv, a registered team counting only once
+const invitationRedeemCount = "(SELECT COUNT(DISTINCT team_id) + COUNT(*) FILTER (WHERE team_id IS NULL) FROM contest_registrations WHERE invitation_id = inv.id) AS redeem_cnt"
+
+// reserveInvitation locks the invitation until the end of the transaction and checks that it can be redeemed once more
+func reserveInvitation(ctx context.Context, tx pgx.Tx, invitationID *string) error {
+	if invitationID == nil {
+		return nil
+	}
+	var maxCount *int
+	var redeemCount int
+	if err := tx.QueryRow(ctx, "SELECT max_invitation_cnt, "+invitationRedeemCount+" FROM contest_invitations inv WHERE id = $1 FOR UPDATE", *invitationID).Scan(&maxCount, &redeemCount); err != nil {
+		return err
+	}
+	if maxCount != nil && redeemCount >= *maxCount {
+		return ErrInvitationLimit
+	}
+	return nil
+}
+
+func (s *DB) InsertContestRegistration(ctx context.Context, contestID, userID int, invitationID *string, categoryID *int) error {
+	return pgx.BeginFunc(ctx, s.conn, func(tx pgx.Tx) error {
+		if err := reserveInvitation(ctx, tx, invitationID); err != nil {
+			return err
+		}
+		_, err := tx.Exec(ctx, "INSERT INTO contest_registrations (user_id, contest_id, invitation_id, category_id) VALUES ($1, $2, $3, $4)", userID, contestID, invitationID, categoryID)
+		return err
+	})
+}
+
+func (s *DB) UpdateContestRegistrationCategory(ctx context.Context, contestID, userID int, categoryID *int) error {
+	_, err := s.conn.Exec(ctx, "UPDATE contest_registrations SET category_id = $3 WHERE contest_id = $1 AND user_id = $2", contestID, userID, categoryID)
+	return err
+}
+
+func (s *DB) UpdateTeamRegistrationCategory(ctx context.Context, contestID, teamID int, categoryID *int) error {
+	_, err := s.conn.Exec(ctx, "UPDATE contest_registrations SET category_id = $3 WHERE contest_id = $1 AND team_id = $2", contestID, teamID, categoryID)
 	return err
 }
 
@@ -75,7 +112,7 @@ func (s *DB) DeleteContestRegistration(ctx context.Context, contestID, userID in
 }
 
 func (s *DB) ContestInvitations(ctx context.Context, contestID int) ([]*kilonova.ContestInvitation, error) {
-	rows, _ := s.conn.Query(ctx, "SELECT *, (SELECT COUNT(*) FROM contest_registrations WHERE invitation_id = inv.id) AS redeem_cnt FROM contest_invitations inv WHERE contest_id = $1 ORDER BY expired ASC, created_at DESC", contestID)
+	rows, _ := s.conn.Query(ctx, "SELECT *, "+invitationRedeemCount+" FROM contest_invitations inv WHERE contest_id = $1 ORDER BY expired ASC, created_at DESC", contestID)
 	invitations, err := pgx.CollectRows(rows, pgx.RowToAddrOfStructByName[kilonova.ContestInvitation])
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
@@ -87,7 +124,7 @@ func (s *DB) ContestInvitations(ctx context.Context, contestID int) ([]*kilonova
 }
 
 func (s *DB) ContestInvitation(ctx context.Context, id string) (*kilonova.ContestInvitation, error) {
-	rows, _ := s.conn.Query(ctx, "SELECT *, (SELECT COUNT(*) FROM contest_registrations WHERE invitation_id = inv.id) AS redeem_cnt FROM contest_invitations inv WHERE id = $1 LIMIT 1", id)
+	rows, _ := s.conn.Query(ctx, "SELECT *, "+invitationRedeemCount+" FROM contest_invitations inv WHERE id = $1 LIMIT 1", id)
 	invitation, err := pgx.CollectOneRow(rows, pgx.RowToAddrOfStructByName[kilonova.ContestInvitation])
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
@@ -103,8 +140,50 @@ func (s *DB) UpdateContestInvitation(ctx context.Context, id string, expired boo
 	return err
 }
 
-func (s *DB) CreateContestInvitation(ctx context.Context, contestID int, creatorID *int, maxUses *int) (string, error) {
+func (s *DB) CreateContestInvitation(ctx context.Context, contestID int, creatorID *int, maxUses *int, categoryID *int) (string, error) {
 	id := kilonova.RandomString(12)
-	_, err := s.conn.Exec(ctx, "INSERT INTO contest_invitations (id, contest_id, creator_id, max_invitation_cnt) VALUES ($1, $2, $3, $4)", id, contestID, creatorID, maxUses)
+	_, err := s.conn.Exec(ctx, "INSERT INTO contest_invitations (id, contest_id, creator_id, max_invitation_cnt, category_id) VALUES ($1, $2, $3, $4, $5)", id, contestID, creatorID, maxUses, categoryID)
+	return id, err
+}
+
+// Registration categories
+
+func (s *DB) ContestCategories(ctx context.Context, contestID int) ([]*kilonova.ContestCategory, error) {
+	rows, _ := s.conn.Query(ctx, "SELECT * FROM contest_categories WHERE contest_id = $1 ORDER BY id ASC", contestID)
+	categories, err := pgx.CollectRows(rows, pgx.RowToAddrOfStructByName[kilonova.ContestCategory])
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return []*kilonova.ContestCategory{}, nil
+		}
+		return nil, err
+	}
+	return categories, nil
+}
+
+func (s *DB) ContestCategory(ctx context.Context, id int) (*kilonova.ContestCategory, error) {
+	rows, _ := s.conn.Query(ctx, "SELECT * FROM contest_categories WHERE id = $1 LIMIT 1", id)
+	category, err := pgx.CollectOneRow(rows, pgx.RowToAddrOfStructByName[kilonova.ContestCategory])
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return category, nil
+}
+
+func (s *DB) CreateContestCategory(ctx context.Context, contestID int, name string, official, selfAssignable bool) (int, error) {
+	var id int
+	err := s.conn.QueryRow(ctx, "INSERT INTO contest_categories (contest_id, name, official, self_assignable) VALUES ($1, $2, $3, $4) RETURNING id", contestID, name, official, selfAssignable).Scan(&id)
 	return id, err
 }
+
+func (s *DB) UpdateContestCategory(ctx context.Context, id int, name string, official, selfAssignable bool) error {
+	_, err := s.conn.Exec(ctx, "UPDATE contest_categories SET name = $2, official = $3, self_assignable = $4 WHERE id = $1", id, name, official, selfAssignable)
+	return err
+}
+
+func (s *DB) DeleteContestCategory(ctx context.Context, id int) error {
+	_, err := s.conn.Exec(ctx, "DELETE FROM contest_categories WHERE id = $1", id)
+	return err
+}
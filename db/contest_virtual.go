@@ -0,0 +1,46 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/KiloProjects/kilonova"
+	"github.com/jackc/pgx/v5"
+	"github.com/shopspring/decimal"
+)
+
+func (s *DB) CreateVirtualParticipation(ctx context.Context, contestID, userID int, startTime, endTime time.Time) (int, error) {
+	var id int
+	err := s.conn.QueryRow(ctx, "INSERT INTO contest_virtual_participations (contest_id, user_id, start_time, end_time) VALUES ($1, $2, $3, $4) RETURNING id", contestID, userID, startTime, endTime).Scan(&id)
+	return id, err
+}
+
+func (s *DB) VirtualParticipation(ctx context.Context, contestID, userID int) (*kilonova.VirtualParticipation, error) {
+	rows, _ := s.conn.Query(ctx, "SELECT * FROM contest_virtual_participations WHERE contest_id = $1 AND user_id = $2", contestID, userID)
+	vp, err := pgx.CollectOneRow(rows, pgx.RowToAddrOfStructByName[kilonova.VirtualParticipation])
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	return vp, err
+}
+
+// PostContestScore is the best score of a user on a contest problem, after the contest ended
+type PostContestScore struct {
+	UserID    int             `db:"user_id"`
+	ProblemID int             `db:"problem_id"`
+	Score     decimal.Decimal `db:"score"`
+	MinTime   *time.Time      `db:"mintime"`
+}
+
+// ContestUpsolveScores returns the best scores of all submissions sent after the contest ended.
+func (s *DB) ContestUpsolveScores(ctx context.Context, contestID int) ([]*PostContestScore, error) {
+	rows, _ := s.conn.Query(ctx, "SELECT * FROM post_contest_max_scores($1, NULL, NULL)", contestID)
+	return pgx.CollectRows(rows, pgx.RowToAddrOfStructByName[PostContestScore])
+}
+
+// VirtualParticipationScores returns the best scores of the submissions sent during the virtual participation.
+func (s *DB) VirtualParticipationScores(ctx context.Context, vp *kilonova.VirtualParticipation) ([]*PostContestScore, error) {
+	rows, _ := s.conn.Query(ctx, "SELECT * FROM post_contest_max_scores($1, $2, $3)", vp.ContestID, vp.ID, vp.EndTime)
+	return pgx.CollectRows(rows, pgx.RowToAddrOfStructByName[PostContestScore])
+}
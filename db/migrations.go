@@ -86,6 +86,71 @@ var Migrations = postgres.MigrationConfig{
 			Name:    "Add column for problem review",
 			Handler: runFile("014.review_ready.sql"),
 		},
+		{
+			ID:      16,
+			Name:    "Add output only as a valid problem type",
+			Handler: runFile("015.output_only_type.sql"),
+		},
+		{
+			ID:      17,
+			Name:    "Add two-step as a valid problem type",
+			Handler: runFile("016.two_step_type.sql"),
+		},
+		{
+			ID:      18,
+			Name:    "Add built-in checker modes",
+			Handler: runFile("017.checker_mode.sql"),
+		},
+		{
+			ID:      19,
+			Name:    "Add subtest resource usage",
+			Handler: runFile("018.subtest_resources.sql"),
+		},
+		{
+			ID:      20,
+			Name:    "Add personal API tokens",
+			Handler: runFile("019.api_tokens.sql"),
+		},
+		{
+			ID:      21,
+			Name:    "Add outgoing webhooks",
+			Handler: runFile("020.webhooks.sql"),
+		},
+		{
+			ID:      22,
+			Name:    "Add contest registration categories",
+			Handler: runFile("021.contest_categories.sql"),
+		},
+		{
+			ID:      23,
+			Name:    "Add teams",
+			Handler: runFile("022.teams.sql"),
+		},
+		{
+			ID:      24,
+			Name:    "Add upsolving and virtual participation",
+			Handler: runFile("023.upsolving.sql"),
+		},
+		{
+			ID:      25,
+			Name:    "Add local plagiarism checks",
+			Handler: runFile("024.plagiarism.sql"),
+		},
+		{
+			ID:      26,
+			Name:    "Add submission and user plagiarism reports",
+			Handler: runFile("025.plagiarism_reports.sql"),
+		},
+		{
+			ID:      27,
+			Name:    "Add statement provenance and review state",
+			Handler: runFile("026.statement_review.sql"),
+		},
+		{
+			ID:      28,
+			Name:    "Add public subtasks and expected submission verdicts",
+			Handler: runFile("027.polygon_import.sql"),
+		},
 	},
 	// Run every time a migrate up happens
 	SpecialMigrations: []postgres.Migration{
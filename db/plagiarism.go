@@ -0,0 +1,80 @@
+package db
+
+import (
+	"context"
+	"errors"
+
+	"github.com/KiloProjects/kilonova"
+	"github.com/jackc/pgx/v5"
+)
+
+const plagiarismMatchQuery = `SELECT matches.*, sub1.user_id AS user1_id, sub2.user_id AS user2_id
+	FROM plagiarism_matches matches
+	INNER JOIN submissions sub1 ON sub1.id = matches.submission1_id
+	INNER JOIN submissions sub2 ON sub2.id = matches.submission2_id`
+
+func (s *DB) InsertPlagiarismMatches(ctx context.Context, checkID int, matches []*kilonova.PlagiarismMatch) error {
+	_, err := s.conn.CopyFrom(ctx,
+		pgx.Identifier{"plagiarism_matches"},
+		[]string{"check_id", "submission1_id", "submission2_id", "similarity1", "similarity2", "shared", "cluster", "fragments"},
+		pgx.CopyFromSlice(len(matches), func(i int) ([]any, error) {
+			m := matches[i]
+			return []any{checkID, m.Submission1ID, m.Submission2ID, m.Similarity1, m.Similarity2, m.Shared, m.Cluster, m.Fragments}, nil
+		}),
+	)
+	return err
+}
+
+func (s *DB) PlagiarismMatches(ctx context.Context, checkID int) ([]*kilonova.PlagiarismMatch, error) {
+	rows, _ := s.conn.Query(ctx, plagiarismMatchQuery+" WHERE matches.check_id = $1 ORDER BY GREATEST(matches.similarity1, matches.similarity2) DESC, matches.id", checkID)
+	matches, err := pgx.CollectRows(rows, pgx.RowToAddrOfStructByName[kilonova.PlagiarismMatch])
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return []*kilonova.PlagiarismMatch{}, nil
+		}
+		return nil, err
+	}
+	return matches, nil
+}
+
+func (s *DB) PlagiarismMatch(ctx context.Context, id int) (*kilonova.PlagiarismMatch, error) {
+	rows, _ := s.conn.Query(ctx, plagiarismMatchQuery+" WHERE matches.id = $1", id)
+	match, err := pgx.CollectOneRow(rows, pgx.RowToAddrOfStructByName[kilonova.PlagiarismMatch])
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return match, nil
+}
+
+// InsertPlagiarismReport creates a non-contest plagiarism check
+func (s *DB) InsertPlagiarismReport(ctx context.Context, report *kilonova.MOSSSubmission) (int, error) {
+	var id int
+	err := s.conn.QueryRow(ctx, `INSERT INTO moss_submissions (problem_id, submission_id, user_id, author_id, language, subcount, url, engine)
+		VALUES ($1, $2, $3, $4, $5, $6, '', $7) RETURNING id`,
+		report.ProblemID, report.SubmissionID, report.UserID, report.AuthorID, report.Language, report.SubCount, report.Engine,
+	).Scan(&id)
+	return id, err
+}
+
+func (s *DB) PlagiarismReports(ctx context.Context, filter kilonova.PlagiarismReportFilter) ([]*kilonova.MOSSSubmission, error) {
+	fb := newFilterBuilder()
+	if v := filter.SubmissionID; v != nil {
+		fb.AddConstraint("(submission_id = %s OR id IN (SELECT check_id FROM plagiarism_matches WHERE submission1_id = %s OR submission2_id = %s))", v, v, v)
+	}
+	if v := filter.UserID; v != nil {
+		fb.AddConstraint("user_id = %s", v)
+	}
+
+	rows, _ := s.conn.Query(ctx, "SELECT * FROM moss_submissions WHERE "+fb.Where()+" ORDER BY created_at DESC", fb.Args()...)
+	reports, err := pgx.CollectRows(rows, pgx.RowToAddrOfStructByName[kilonova.MOSSSubmission])
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return []*kilonova.MOSSSubmission{}, nil
+		}
+		return nil, err
+	}
+	return reports, nil
+}
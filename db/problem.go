@@ -48,6 +48,9 @@ type dbProblem struct {
 
 	CommunicationProcesses int `db:"communication_num_processes"`
 
+	CheckerMode    kilonova.CheckerMode `db:"checker_mode"`
+	CheckerEpsilon float64              `db:"checker_epsilon"`
+
 	ReviewRequestedAt *time.Time `db:"review_requested_at"`
 	ReviewRequestedBy *int       `db:"review_requested_by"`
 }
@@ -394,6 +397,12 @@ func problemUpdateQuery(ctx context.Context, upd *kilonova.ProblemUpdate, ub sq.
 	if v := upd.CommunicationProcesses; v != nil {
 		ub = ub.Set("communication_num_processes", v)
 	}
+	if v := upd.CheckerMode; v != kilonova.CheckerModeNone {
+		ub = ub.Set("checker_mode", v)
+	}
+	if v := upd.CheckerEpsilon; v != nil {
+		ub = ub.Set("checker_epsilon", v)
+	}
 	return ub
 }
 
@@ -453,6 +462,9 @@ func (s *DB) internalToProblem(pb *dbProblem) *kilonova.Problem {
 
 		CommunicationProcesses: pb.CommunicationProcesses,
 
+		CheckerMode:    pb.CheckerMode,
+		CheckerEpsilon: pb.CheckerEpsilon,
+
 		ReviewRequestedAt: pb.ReviewRequestedAt,
 		ReviewRequestedBy: pb.ReviewRequestedBy,
 	}
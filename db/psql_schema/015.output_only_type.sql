@@ -0,0 +1 @@
+ALTER TYPE problem_task_type ADD VALUE 'output_only';
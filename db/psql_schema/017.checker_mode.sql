@@ -0,0 +1,2 @@
+ALTER TABLE problems ADD COLUMN checker_mode text NOT NULL DEFAULT 'diff';
+ALTER TABLE problems ADD COLUMN checker_epsilon double precision NOT NULL DEFAULT 1e-6;
@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS api_tokens (
+    id              bigint          GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    user_id         integer         NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name            text            NOT NULL,
+    -- only the SHA-256 hash of the token is stored, the token itself is shown once on creation
+    token_hash      text            NOT NULL UNIQUE,
+    scopes          text[]          NOT NULL DEFAULT '{}',
+    created_at      timestamptz     NOT NULL DEFAULT NOW(),
+    expires_at      timestamptz,
+    last_used_at    timestamptz
+);
+
+CREATE INDEX IF NOT EXISTS api_tokens_user_id ON api_tokens (user_id);
@@ -0,0 +1,19 @@
+CREATE TABLE IF NOT EXISTS webhooks (
+    id                      bigint          GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    created_at              timestamptz     NOT NULL DEFAULT NOW(),
+    owner_id                integer         NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    -- contest webhooks receive the events of the contest, the other ones receive the events of their owner
+    contest_id              integer         REFERENCES contests(id) ON DELETE CASCADE,
+    url                     text            NOT NULL,
+    secret                  text            NOT NULL,
+    events                  text[]          NOT NULL,
+    active                  boolean         NOT NULL DEFAULT true,
+
+    last_delivery_at        timestamptz,
+    last_status             integer,
+    last_error              text,
+    consecutive_failures    integer         NOT NULL DEFAULT 0
+);
+
+CREATE INDEX IF NOT EXISTS webhooks_owner_id ON webhooks (owner_id) WHERE contest_id IS NULL;
+CREATE INDEX IF NOT EXISTS webhooks_contest_id ON webhooks (contest_id) WHERE contest_id IS NOT NULL;
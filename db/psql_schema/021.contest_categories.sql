@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS contest_categories (
+    id              bigint      GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    created_at      timestamptz NOT NULL DEFAULT NOW(),
+    contest_id      bigint      NOT NULL REFERENCES contests(id) ON DELETE CASCADE,
+
+    name            text        NOT NULL,
+    official        boolean     NOT NULL DEFAULT TRUE,
+    self_assignable boolean     NOT NULL DEFAULT TRUE,
+
+    UNIQUE (contest_id, name)
+);
+
+CREATE INDEX IF NOT EXISTS contest_categories_index ON contest_categories (contest_id);
+
+ALTER TABLE contest_registrations ADD COLUMN IF NOT EXISTS category_id bigint REFERENCES contest_categories(id) ON DELETE SET NULL;
+ALTER TABLE contest_invitations ADD COLUMN IF NOT EXISTS category_id bigint REFERENCES contest_categories(id) ON DELETE SET NULL;
@@ -0,0 +1,28 @@
+CREATE TABLE IF NOT EXISTS teams (
+    id          bigint      GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    created_at  timestamptz NOT NULL DEFAULT NOW(),
+    name        text        NOT NULL,
+    creator_id  bigint      REFERENCES users(id) ON DELETE SET NULL,
+    -- shared by the members in order to invite others
+    join_code   text        NOT NULL UNIQUE
+);
+
+CREATE TABLE IF NOT EXISTS team_members (
+    created_at  timestamptz NOT NULL DEFAULT NOW(),
+    team_id     bigint      NOT NULL REFERENCES teams(id) ON DELETE CASCADE,
+    user_id     bigint      NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+
+    UNIQUE (team_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS team_members_user_id ON team_members (user_id);
+
+-- contests with a positive max team size only accept registrations of teams
+ALTER TABLE contests ADD COLUMN IF NOT EXISTS max_team_size integer NOT NULL DEFAULT 0;
+
+-- every member of a registered team gets a registration, this way the membership at the time of the contest is kept
+ALTER TABLE contest_registrations ADD COLUMN IF NOT EXISTS team_id bigint REFERENCES teams(id);
+ALTER TABLE submissions ADD COLUMN IF NOT EXISTS team_id bigint REFERENCES teams(id);
+
+CREATE INDEX IF NOT EXISTS contest_registrations_team_id ON contest_registrations (contest_id, team_id) WHERE team_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS submissions_team_id ON submissions (team_id) WHERE team_id IS NOT NULL;
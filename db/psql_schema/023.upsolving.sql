@@ -0,0 +1,22 @@
+-- upsolving lets participants keep submitting after the contest ends, in a separate leaderboard column
+ALTER TABLE contests ADD COLUMN IF NOT EXISTS upsolving boolean NOT NULL DEFAULT false;
+-- virtual participation lets users replay an ended contest in their own time window
+ALTER TABLE contests ADD COLUMN IF NOT EXISTS virtual_participation boolean NOT NULL DEFAULT false;
+
+CREATE TABLE IF NOT EXISTS contest_virtual_participations (
+    id          bigint      GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    created_at  timestamptz NOT NULL DEFAULT NOW(),
+    contest_id  bigint      NOT NULL REFERENCES contests(id) ON DELETE CASCADE,
+    user_id     bigint      NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    start_time  timestamptz NOT NULL,
+    end_time    timestamptz NOT NULL,
+
+    UNIQUE (contest_id, user_id)
+);
+
+-- post-contest submissions keep contest_id empty, so that they never count towards the contest itself
+ALTER TABLE submissions ADD COLUMN IF NOT EXISTS upsolve_contest_id bigint REFERENCES contests(id) ON DELETE SET NULL;
+ALTER TABLE submissions ADD COLUMN IF NOT EXISTS virtual_participation_id bigint REFERENCES contest_virtual_participations(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS submissions_upsolve_contest_id ON submissions (upsolve_contest_id) WHERE upsolve_contest_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS submissions_virtual_participation_id ON submissions (virtual_participation_id) WHERE virtual_participation_id IS NOT NULL;
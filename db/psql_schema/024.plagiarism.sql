@@ -0,0 +1,21 @@
+-- plagiarism checks can now also run locally, instead of being sent to MOSS
+ALTER TABLE moss_submissions ADD COLUMN IF NOT EXISTS engine text NOT NULL DEFAULT 'moss';
+
+-- results of local plagiarism checks. MOSS results are only available on its website
+CREATE TABLE IF NOT EXISTS plagiarism_matches (
+    id              bigint              GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    check_id        bigint              NOT NULL REFERENCES moss_submissions(id) ON DELETE CASCADE,
+
+    submission1_id  bigint              NOT NULL REFERENCES submissions(id) ON DELETE CASCADE,
+    submission2_id  bigint              NOT NULL REFERENCES submissions(id) ON DELETE CASCADE,
+
+    similarity1     double precision    NOT NULL,
+    similarity2     double precision    NOT NULL,
+    shared          integer             NOT NULL,
+    cluster         integer,
+
+    -- matching line ranges of the two submissions
+    fragments       jsonb               NOT NULL DEFAULT '[]'
+);
+
+CREATE INDEX IF NOT EXISTS plagiarism_matches_check_id ON plagiarism_matches (check_id);
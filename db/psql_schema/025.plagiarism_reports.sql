@@ -0,0 +1,13 @@
+-- plagiarism checks can also target a single submission (against the problem archive) or a user (against everyone else),
+-- so they are no longer tied to a contest or to a single problem
+ALTER TABLE moss_submissions ALTER COLUMN contest_id DROP NOT NULL;
+ALTER TABLE moss_submissions ALTER COLUMN problem_id DROP NOT NULL;
+
+ALTER TABLE moss_submissions ADD COLUMN IF NOT EXISTS submission_id bigint REFERENCES submissions(id) ON DELETE CASCADE;
+ALTER TABLE moss_submissions ADD COLUMN IF NOT EXISTS user_id bigint REFERENCES users(id) ON DELETE CASCADE;
+ALTER TABLE moss_submissions ADD COLUMN IF NOT EXISTS author_id bigint REFERENCES users(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS moss_submissions_submission_id ON moss_submissions (submission_id) WHERE submission_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS moss_submissions_user_id ON moss_submissions (user_id) WHERE user_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS plagiarism_matches_submission1_id ON plagiarism_matches (submission1_id);
+CREATE INDEX IF NOT EXISTS plagiarism_matches_submission2_id ON plagiarism_matches (submission2_id);
@@ -0,0 +1,8 @@
+-- Statements keep track of where their text came from, so machine translations can be reviewed before being published
+ALTER TABLE attachments ADD COLUMN IF NOT EXISTS provenance text NOT NULL DEFAULT 'original';
+ALTER TABLE attachments ADD COLUMN IF NOT EXISTS translated_from text;
+ALTER TABLE attachments ADD COLUMN IF NOT EXISTS reviewed_by bigint REFERENCES users(id) ON DELETE SET NULL;
+ALTER TABLE attachments ADD COLUMN IF NOT EXISTS reviewed_at timestamptz;
+
+-- LLM translations were the only machine-translated statements so far
+UPDATE attachments SET provenance = 'machine_translated', translated_from = 'statement-ro.md' WHERE name = 'statement-en-llm.md';
@@ -0,0 +1,5 @@
+-- Hidden subtasks only show their results to contestants after the contest ends, like Polygon's `tests` testset
+ALTER TABLE subtasks ADD COLUMN IF NOT EXISTS public boolean NOT NULL DEFAULT true;
+
+-- Editor submissions can be tagged with the verdict they should get, like Polygon's tagged solutions
+ALTER TABLE submissions ADD COLUMN IF NOT EXISTS expected_verdict text;
@@ -139,21 +139,71 @@ CREATE OR REPLACE FUNCTION visible_contests(user_id bigint) RETURNS TABLE (conte
         WHERE contests.id = users.contest_id AND contests.visible = false AND users.user_id = $1) -- not visible but registered
 $$ LANGUAGE SQL STABLE;
 
+-- Whether the result of the subtask stays hidden in the contest, since the subtask isn't public and the contest is still running
+CREATE OR REPLACE FUNCTION contest_hides_subtask(contest_id bigint, subtask_id bigint) RETURNS boolean AS $$
+    SELECT EXISTS (SELECT 1 FROM contests, subtasks WHERE contests.id = $1 AND contests.end_time > NOW() AND subtasks.id = $2 AND NOT subtasks.public)
+$$ LANGUAGE SQL STABLE;
+
+-- Score of the submission counting only the subtasks whose results are shown in its contest
+CREATE OR REPLACE FUNCTION shown_submission_score(submission_id bigint, contest_id bigint, score decimal) RETURNS decimal AS $$
+    SELECT CASE WHEN bool_or(contest_hides_subtask($2, stks.subtask_id))
+            THEN COALESCE(SUM(stks.computed_score) FILTER (WHERE NOT contest_hides_subtask($2, stks.subtask_id)), 0)
+            ELSE $3
+        END
+    FROM submission_subtasks stks WHERE stks.submission_id = $1
+$$ LANGUAGE SQL STABLE;
+
 DROP FUNCTION IF EXISTS contest_max_scores(bigint);
 DROP FUNCTION IF EXISTS contest_max_scores(bigint, timestamptz);
-CREATE OR REPLACE FUNCTION contest_max_scores(contest_id bigint, freeze_time timestamptz) RETURNS TABLE(user_id bigint, problem_id bigint, score decimal, mintime timestamptz) AS $$
+-- In team contests, the submissions are credited to the team, so every member gets the best score of the team
+CREATE OR REPLACE FUNCTION contest_max_scores(contest_id bigint, freeze_time timestamptz) RETURNS TABLE(user_id bigint, problem_id bigint, score decimal, mintime timestamptz, team_id bigint) AS $$
     WITH max_submission_strat AS (
+        SELECT DISTINCT team_id, (CASE WHEN team_id IS NULL THEN user_id END) AS user_id, problem_id, FIRST_VALUE(shown_score * (leaderboard_score_scale / 100)) OVER w AS max_score, FIRST_VALUE(created_at) OVER w AS mintime
+            FROM (SELECT *, shown_submission_score(id, contest_id, score) AS shown_score FROM submissions WHERE contest_id = $1 AND created_at <= COALESCE(freeze_time, NOW()) AND (status = 'finished' OR status = 'reevaling')) subs
+            WINDOW w AS (PARTITION BY team_id, (CASE WHEN team_id IS NULL THEN user_id END), problem_id ORDER BY shown_score DESC, created_at ASC)
+    ), subtask_max_scores AS (
+        SELECT DISTINCT subs.team_id, (CASE WHEN subs.team_id IS NULL THEN stks.user_id END) AS user_id, stks.subtask_id, stks.problem_id, FIRST_VALUE(stks.computed_score * (stks.leaderboard_score_scale / 100)) OVER w AS max_score, FIRST_VALUE(stks.created_at) OVER w AS mintime
+        FROM submission_subtasks stks INNER JOIN submissions subs ON subs.id = stks.submission_id
+        WHERE stks.subtask_id IS NOT NULL AND stks.contest_id = $1
+            AND stks.created_at <= COALESCE(freeze_time, NOW()) AND NOT contest_hides_subtask($1, stks.subtask_id)
+            WINDOW w AS (PARTITION BY subs.team_id, (CASE WHEN subs.team_id IS NULL THEN stks.user_id END), stks.subtask_id, stks.problem_id ORDER BY stks.computed_score DESC, stks.created_at ASC)
+    ), sum_subtasks_strat AS (
+        SELECT DISTINCT team_id, user_id, problem_id, coalesce(SUM(max_score), -1) AS max_score, MAX(mintime) AS mintime FROM subtask_max_scores GROUP BY team_id, user_id, problem_id
+    ) SELECT
+        users.user_id AS user_id,
+        pbs.problem_id AS problem_id,
+        CASE WHEN problems.scoring_strategy = 'max_submission' OR problems.scoring_strategy = 'acm-icpc' THEN COALESCE(ms_sub.max_score, -1)
+            WHEN problems.scoring_strategy = 'sum_subtasks'   THEN COALESCE(ms_subtask.max_score, -1)
+            ELSE -1
+        END score,
+        CASE WHEN problems.scoring_strategy = 'max_submission' OR problems.scoring_strategy = 'acm-icpc' THEN COALESCE(ms_sub.mintime, NULL)
+            WHEN problems.scoring_strategy = 'sum_subtasks'   THEN COALESCE(ms_subtask.mintime, NULL)
+            ELSE NULL
+        END AS mintime,
+        users.team_id AS team_id
+    FROM ((contest_problems pbs INNER JOIN contest_registrations users ON users.contest_id = pbs.contest_id AND pbs.contest_id = $1) INNER JOIN problems ON pbs.problem_id = problems.id)
+        LEFT JOIN max_submission_strat ms_sub ON (ms_sub.problem_id = pbs.problem_id AND (ms_sub.team_id = users.team_id OR (users.team_id IS NULL AND ms_sub.team_id IS NULL AND ms_sub.user_id = users.user_id)))
+        LEFT JOIN sum_subtasks_strat ms_subtask ON (ms_subtask.problem_id = pbs.problem_id AND (ms_subtask.team_id = users.team_id OR (users.team_id IS NULL AND ms_subtask.team_id IS NULL AND ms_subtask.user_id = users.user_id)))
+$$ LANGUAGE SQL STABLE;
+
+DROP FUNCTION IF EXISTS post_contest_max_scores;
+-- Best scores of the submissions sent after the contest ended (upsolving and virtual participations).
+-- Setting the virtual participation only keeps its submissions
+CREATE OR REPLACE FUNCTION post_contest_max_scores(contest_id bigint, virtual_participation_id bigint, until timestamptz) RETURNS TABLE(user_id bigint, problem_id bigint, score decimal, mintime timestamptz) AS $$
+    WITH post_subs AS (
+        SELECT * FROM submissions subs WHERE subs.upsolve_contest_id = $1 AND ($2 IS NULL OR subs.virtual_participation_id = $2)
+            AND subs.created_at <= COALESCE($3, NOW()) AND (subs.status = 'finished' OR subs.status = 'reevaling')
+    ), max_submission_strat AS (
         SELECT DISTINCT user_id, problem_id, FIRST_VALUE(score * (leaderboard_score_scale / 100)) OVER w AS max_score, FIRST_VALUE(created_at) OVER w AS mintime
-            FROM submissions WHERE contest_id = $1 AND created_at <= COALESCE(freeze_time, NOW()) AND (status = 'finished' OR status = 'reevaling')
+            FROM post_subs
             WINDOW w AS (PARTITION BY user_id, problem_id ORDER BY score DESC, created_at ASC)
     ), subtask_max_scores AS (
-        SELECT DISTINCT user_id, subtask_id, problem_id, FIRST_VALUE(computed_score * (leaderboard_score_scale / 100)) OVER w AS max_score, FIRST_VALUE(created_at) OVER w AS mintime
-        FROM submission_subtasks stks
-        WHERE subtask_id IS NOT NULL AND contest_id = $1
-            AND created_at <= COALESCE(freeze_time, NOW())
-            WINDOW w AS (PARTITION BY user_id, subtask_id, problem_id ORDER BY computed_score DESC, created_at ASC)
+        SELECT DISTINCT stks.user_id, stks.subtask_id, stks.problem_id, FIRST_VALUE(stks.computed_score * (stks.leaderboard_score_scale / 100)) OVER w AS max_score, FIRST_VALUE(stks.created_at) OVER w AS mintime
+        FROM submission_subtasks stks INNER JOIN post_subs subs ON subs.id = stks.submission_id
+        WHERE stks.subtask_id IS NOT NULL
+            WINDOW w AS (PARTITION BY stks.user_id, stks.subtask_id, stks.problem_id ORDER BY stks.computed_score DESC, stks.created_at ASC)
     ), sum_subtasks_strat AS (
-        SELECT DISTINCT user_id, problem_id, coalesce(SUM(max_score), -1) AS max_score, MAX(mintime) AS mintime FROM subtask_max_scores GROUP BY user_id, problem_id
+        SELECT user_id, problem_id, coalesce(SUM(max_score), -1) AS max_score, MAX(mintime) AS mintime FROM subtask_max_scores GROUP BY user_id, problem_id
     ) SELECT
         users.user_id AS user_id,
         pbs.problem_id AS problem_id,
@@ -165,9 +215,9 @@ CREATE OR REPLACE FUNCTION contest_max_scores(contest_id bigint, freeze_time tim
             WHEN problems.scoring_strategy = 'sum_subtasks'   THEN COALESCE(ms_subtask.mintime, NULL)
             ELSE NULL
         END AS mintime
-    FROM ((contest_problems pbs INNER JOIN contest_registrations users ON users.contest_id = pbs.contest_id AND pbs.contest_id = $1) INNER JOIN problems ON pbs.problem_id = problems.id)
-        LEFT JOIN max_submission_strat ms_sub ON (ms_sub.user_id = users.user_id AND ms_sub.problem_id = pbs.problem_id)
-        LEFT JOIN sum_subtasks_strat ms_subtask ON (ms_subtask.user_id = users.user_id AND ms_subtask.problem_id = pbs.problem_id)
+    FROM ((contest_problems pbs INNER JOIN (SELECT DISTINCT user_id FROM post_subs) users ON pbs.contest_id = $1) INNER JOIN problems ON pbs.problem_id = problems.id)
+        LEFT JOIN max_submission_strat ms_sub ON (ms_sub.problem_id = pbs.problem_id AND ms_sub.user_id = users.user_id)
+        LEFT JOIN sum_subtasks_strat ms_subtask ON (ms_subtask.problem_id = pbs.problem_id AND ms_subtask.user_id = users.user_id)
 $$ LANGUAGE SQL STABLE;
 
 DROP VIEW IF EXISTS contest_top_view CASCADE;
@@ -180,7 +230,7 @@ RETURNS TABLE (user_id bigint, contest_id bigint, last_time timestamptz, num_sol
     WITH legit_contestants AS (
         SELECT regs.* FROM contest_registrations regs WHERE regs.contest_id = $1 AND (NOT EXISTS (SELECT 1 FROM contest_user_access acc WHERE acc.user_id = regs.user_id AND acc.contest_id = regs.contest_id) OR $3 = true)
     ), solved_pbs AS (
-        SELECT user_id, problem_id, mintime AS last_time FROM contest_max_scores($1, $2) WHERE score = 100
+        SELECT user_id, team_id, problem_id, mintime AS last_time FROM contest_max_scores($1, $2) WHERE score = 100
     ), last_times AS (
         SELECT user_id, MAX(last_time) AS last_time FROM solved_pbs GROUP BY user_id
     ), num_solved AS (
@@ -190,7 +240,7 @@ RETURNS TABLE (user_id bigint, contest_id bigint, last_time timestamptz, num_sol
         SELECT solved_pbs.user_id, COUNT(*) AS num_attempts 
             FROM solved_pbs 
             INNER JOIN submissions subs ON subs.contest_id = $1 
-                AND subs.user_id = solved_pbs.user_id 
+                AND (CASE WHEN solved_pbs.team_id IS NULL THEN subs.user_id = solved_pbs.user_id ELSE subs.team_id = solved_pbs.team_id END)
                 AND subs.problem_id = solved_pbs.problem_id 
                 AND subs.created_at < solved_pbs.last_time
 				AND compile_error = false
@@ -224,6 +274,11 @@ CREATE OR REPLACE FUNCTION visible_submissions(user_id bigint) RETURNS TABLE (su
         FROM submissions, v_pbs
         WHERE submissions.user_id = $1 AND submissions.problem_id = v_pbs.problem_id) -- base case, users should see their own submissions if problem is still visible (also, coincidentally, works for contest problems)
     UNION ALL
+    (SELECT subs.id as sub_id
+        FROM submissions subs, contest_registrations regs, v_pbs
+        WHERE regs.user_id = $1 AND regs.team_id IS NOT NULL AND subs.contest_id = regs.contest_id AND subs.team_id = regs.team_id
+        AND subs.problem_id = v_pbs.problem_id AND subs.user_id <> $1) -- team members share their contest submissions
+    UNION ALL
     (SELECT subs.id as sub_id
         FROM submissions subs, v_pbs pb_viewers
         WHERE pb_viewers.problem_id = subs.problem_id AND subs.contest_id IS NULL) -- contest is null, so judge if problem is visible
@@ -246,6 +301,11 @@ CREATE OR REPLACE FUNCTION visible_submissions_ex(user_id bigint, problem_id big
         FROM submissions subs, v_pbs
         WHERE subs.user_id = $1 AND subs.problem_id = v_pbs.problem_id AND ($3 IS NULL OR subs.user_id = $3) ) -- base case, users should see their own submissions if problem is still visible (also, coincidentally, works for contest problems)
     UNION ALL
+    (SELECT subs.id as sub_id
+        FROM submissions subs, contest_registrations regs, v_pbs
+        WHERE regs.user_id = $1 AND regs.team_id IS NOT NULL AND subs.contest_id = regs.contest_id AND subs.team_id = regs.team_id
+        AND subs.problem_id = v_pbs.problem_id AND subs.user_id <> $1 AND ($3 IS NULL OR subs.user_id = $3)) -- team members share their contest submissions
+    UNION ALL
     (SELECT subs.id as sub_id
         FROM submissions subs, v_pbs pb_viewers
         WHERE pb_viewers.problem_id = subs.problem_id AND subs.contest_id IS NULL AND ($3 IS NULL OR subs.user_id = $3)) -- contest is null, so judge if problem is visible
@@ -315,9 +375,10 @@ CREATE OR REPLACE VIEW pblist_user_solved (user_id, list_id, count) AS
 DROP VIEW IF EXISTS problem_checklist;
 -- note that, because max_scores only counts *users* that completed the problem, the num_sols is actually the number of users that completed the problem
 -- NOT the number of 100-point solutions (but this metric would be irrelevant in the max-subtasks scoring strategy)
-CREATE OR REPLACE VIEW problem_checklist (problem_id, num_pdf, num_md, num_tests, num_subtasks, has_source, num_authors, num_other_tags, num_sols) AS 
+CREATE OR REPLACE VIEW problem_checklist (problem_id, num_pdf, num_md, num_tests, num_subtasks, has_source, num_authors, num_other_tags, num_sols, num_unreviewed_translations) AS 
     WITH problem_attachments AS (
         SELECT  atts.name AS name,
+                atts.provenance AS provenance,
                 pam.problem_id AS problem_id 
         FROM attachments atts, problem_attachments_m2m pam 
         WHERE atts.id = pam.attachment_id
@@ -331,7 +392,8 @@ CREATE OR REPLACE VIEW problem_checklist (problem_id, num_pdf, num_md, num_tests
         (length(btrim(pbs.source_credits)) > 0) has_source,
         (SELECT COUNT(*) FROM problem_tags ptags WHERE EXISTS (SELECT 1 FROM tags WHERE tags.id = ptags.tag_id AND tags.type = 'author') AND problem_id = pbs.id) AS num_authors,
         (SELECT COUNT(*) FROM problem_tags ptags WHERE EXISTS (SELECT 1 FROM tags WHERE tags.id = ptags.tag_id AND tags.type != 'author') AND problem_id = pbs.id) AS num_other_tags,
-        (SELECT COUNT(*) FROM max_scores WHERE problem_id = pbs.id AND score = 100) AS num_sols
+        (SELECT COUNT(*) FROM max_scores WHERE problem_id = pbs.id AND score = 100) AS num_sols,
+        (SELECT COUNT(*) FROM problem_attachments WHERE name LIKE 'statement-%' AND provenance = 'machine_translated' AND problem_id = pbs.id) AS num_unreviewed_translations
     FROM problems pbs;
 
 COMMIT;
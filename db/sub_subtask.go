@@ -52,7 +52,7 @@ func getSubmissionSubtaskQuery(inContest bool) string {
 	if inContest {
 		return `
 WITH org AS (
-	SELECT DISTINCT ON (subtask_id) * FROM submission_subtasks WHERE subtask_id IS NOT NULL AND problem_id = $1 AND user_id = $2 AND contest_id = $3 ORDER BY subtask_id ASC, final_percentage DESC NULLS LAST, submission_id ASC
+	SELECT DISTINCT ON (subtask_id) * FROM submission_subtasks WHERE subtask_id IS NOT NULL AND problem_id = $1 AND user_id = $2 AND contest_id = $3 AND NOT contest_hides_subtask(contest_id, subtask_id) ORDER BY subtask_id ASC, final_percentage DESC NULLS LAST, submission_id ASC
 ) SELECT * FROM org ORDER BY visible_id ASC
 `
 	}
@@ -31,6 +31,10 @@ type dbSubmission struct {
 	MaxMemory int     `db:"max_memory"`
 
 	ContestID *int `db:"contest_id"`
+	TeamID    *int `db:"team_id"`
+
+	UpsolveContestID       *int `db:"upsolve_contest_id"`
+	VirtualParticipationID *int `db:"virtual_participation_id"`
 
 	Score          decimal.Decimal `db:"score"`
 	ScorePrecision int32           `db:"digit_precision"`
@@ -43,6 +47,8 @@ type dbSubmission struct {
 
 	SubmissionType kilonova.EvalType `db:"submission_type"`
 	ICPCVerdict    *string           `db:"icpc_verdict"`
+
+	ExpectedVerdict *string `db:"expected_verdict"`
 }
 
 type dbSubmissionFile struct {
@@ -152,7 +158,17 @@ type SubmissionUploadFile struct {
 	Data     []byte
 }
 
-func (s *DB) CreateSubmission(ctx context.Context, authorID int, problem *kilonova.Problem, langName string, files []SubmissionUploadFile, contestID *int) (int, error) {
+// SubmissionContest records the contest a submission was sent to
+type SubmissionContest struct {
+	ContestID *int
+	TeamID    *int
+
+	// UpsolveContestID is set instead of ContestID for submissions sent after the contest ended
+	UpsolveContestID       *int
+	VirtualParticipationID *int
+}
+
+func (s *DB) CreateSubmission(ctx context.Context, authorID int, problem *kilonova.Problem, langName string, files []SubmissionUploadFile, subContest SubmissionContest) (int, error) {
 
 	if authorID <= 0 || problem == nil || langName == "" || len(files) == 0 || len(files[0].Data) == 0 {
 		return -1, kilonova.ErrMissingRequired
@@ -162,13 +178,16 @@ func (s *DB) CreateSubmission(ctx context.Context, authorID int, problem *kilono
 
 		if err := tx.QueryRow(
 			ctx,
-			"INSERT INTO submissions (user_id, problem_id, contest_id, language, code_size, sent_ip) VALUES ($1, $2, $3, $4, $5, $6) RETURNING id;",
+			"INSERT INTO submissions (user_id, problem_id, contest_id, language, code_size, sent_ip, team_id, upsolve_contest_id, virtual_participation_id) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9) RETURNING id;",
 			authorID,
 			problem.ID,
-			contestID,
+			subContest.ContestID,
 			langName,
 			len(files[0].Data),
 			user.IPContext(ctx),
+			subContest.TeamID,
+			subContest.UpsolveContestID,
+			subContest.VirtualParticipationID,
 		).Scan(&id); err != nil {
 			return err
 		}
@@ -259,6 +278,25 @@ func (s *DB) ContestMaxScore(ctx context.Context, userid, problemid, contestid i
 	return score
 }
 
+// ShownSubmissionScores returns the scores of the submissions with results hidden by their running contest,
+// counting only the subtasks whose results are shown. The other submissions are left out of the map
+func (s *DB) ShownSubmissionScores(ctx context.Context, ids []int) (map[int]decimal.Decimal, error) {
+	rows, _ := s.conn.Query(ctx, `SELECT subs.id, shown_submission_score(subs.id, subs.contest_id, subs.score) AS score FROM submissions subs
+	WHERE subs.id = ANY($1) AND EXISTS (SELECT 1 FROM submission_subtasks stks WHERE stks.submission_id = subs.id AND contest_hides_subtask(subs.contest_id, stks.subtask_id))`, ids)
+	scores, err := pgx.CollectRows(rows, pgx.RowToStructByName[struct {
+		ID    int             `db:"id"`
+		Score decimal.Decimal `db:"score"`
+	}])
+	if err != nil {
+		return nil, err
+	}
+	rez := make(map[int]decimal.Decimal, len(scores))
+	for _, score := range scores {
+		rez[score.ID] = score.Score
+	}
+	return rez, nil
+}
+
 func subFilterQuery(filter *kilonova.SubmissionFilter, fb *filterBuilder) {
 	if v := filter.ID; v != nil {
 		fb.AddConstraint("id = %s", v)
@@ -272,6 +310,15 @@ func subFilterQuery(filter *kilonova.SubmissionFilter, fb *filterBuilder) {
 	if v := filter.UserID; v != nil {
 		fb.AddConstraint("user_id = %s", v)
 	}
+	if v := filter.TeamID; v != nil {
+		fb.AddConstraint("team_id = %s", v)
+	}
+	if v := filter.UpsolveContestID; v != nil {
+		fb.AddConstraint("upsolve_contest_id = %s", v)
+	}
+	if v := filter.VirtualParticipationID; v != nil {
+		fb.AddConstraint("virtual_participation_id = %s", v)
+	}
 	if v := filter.ProblemID; v != nil {
 		fb.AddConstraint("problem_id = %s", v)
 	}
@@ -347,6 +394,14 @@ func subUpdateQuery(upd *kilonova.SubmissionUpdate, b *updateBuilder) {
 		b.AddUpdate("icpc_verdict = %s", v)
 	}
 
+	if v := upd.ExpectedVerdict; v != nil {
+		if *v == kilonova.ExpectedVerdictNone {
+			b.AddUpdate("expected_verdict = NULL")
+		} else {
+			b.AddUpdate("expected_verdict = %s", string(*v))
+		}
+	}
+
 	if v := upd.CompileError; v != nil {
 		b.AddUpdate("compile_error = %s", v)
 	}
@@ -389,7 +444,7 @@ func (s *DB) internalToSubmission(sub *dbSubmission) *kilonova.Submission {
 		return nil
 	}
 
-	return &kilonova.Submission{
+	rez := &kilonova.Submission{
 		ID:             sub.ID,
 		CreatedAt:      sub.CreatedAt,
 		UserID:         sub.UserID,
@@ -402,10 +457,14 @@ func (s *DB) internalToSubmission(sub *dbSubmission) *kilonova.Submission {
 		MaxTime:        sub.MaxTime,
 		MaxMemory:      sub.MaxMemory,
 		ContestID:      sub.ContestID,
+		TeamID:         sub.TeamID,
 		Score:          sub.Score,
 		ScorePrecision: sub.ScorePrecision,
 		ScoreScale:     sub.ScoreScale,
 
+		UpsolveContestID:       sub.UpsolveContestID,
+		VirtualParticipationID: sub.VirtualParticipationID,
+
 		CompileTime: sub.CompileDuration,
 
 		SubmissionType: sub.SubmissionType,
@@ -413,6 +472,10 @@ func (s *DB) internalToSubmission(sub *dbSubmission) *kilonova.Submission {
 
 		IP: sub.IP,
 	}
+	if sub.ExpectedVerdict != nil {
+		rez.ExpectedVerdict = kilonova.ExpectedVerdict(*sub.ExpectedVerdict)
+	}
+	return rez
 }
 
 func (s *DB) internalToSubmissionFile(subFile *dbSubmissionFile) *kilonova.SubmissionFile {
@@ -17,7 +17,7 @@ func (s *DB) CreateSubTask(ctx context.Context, subtask *kilonova.SubTask) error
 	}
 	var id int
 	// Do insertion
-	err := s.conn.QueryRow(ctx, "INSERT INTO subtasks (problem_id, visible_id, score) VALUES ($1, $2, $3) RETURNING id", subtask.ProblemID, subtask.VisibleID, subtask.Score).Scan(&id)
+	err := s.conn.QueryRow(ctx, "INSERT INTO subtasks (problem_id, visible_id, score, public) VALUES ($1, $2, $3, $4) RETURNING id", subtask.ProblemID, subtask.VisibleID, subtask.Score, subtask.Public).Scan(&id)
 	if err != nil {
 		return err
 	}
@@ -96,6 +96,9 @@ func (s *DB) UpdateSubTask(ctx context.Context, id int, upd kilonova.SubTaskUpda
 	if v := upd.Score; v != nil {
 		ub.AddUpdate("score = %s", v)
 	}
+	if v := upd.Public; v != nil {
+		ub.AddUpdate("public = %s", v)
+	}
 
 	if ub.CheckUpdates() != nil {
 		return kilonova.ErrNoUpdates
@@ -145,7 +148,8 @@ type subtask struct {
 	ProblemID int       `db:"problem_id"`
 	VisibleID int       `db:"visible_id"`
 
-	Score decimal.Decimal
+	Score  decimal.Decimal
+	Public bool
 }
 
 func (s *DB) internalToSubTask(ctx context.Context, st *subtask) (*kilonova.SubTask, error) {
@@ -179,5 +183,6 @@ ORDER BY tests.visible_id ASC
 		VisibleID: st.VisibleID,
 		Score:     st.Score,
 		Tests:     ids,
+		Public:    st.Public,
 	}, nil
 }
@@ -78,6 +78,18 @@ func subtestUpdateQuery(upd *kilonova.SubTestUpdate, ub *updateBuilder) {
 	if v := upd.Time; v != nil {
 		ub.AddUpdate("time = %s", v)
 	}
+	if v := upd.WallTime; v != nil {
+		ub.AddUpdate("wall_time = %s", v)
+	}
+	if v := upd.MaxRSS; v != nil {
+		ub.AddUpdate("max_rss = %s", v)
+	}
+	if v := upd.VoluntaryCSW; v != nil {
+		ub.AddUpdate("csw_voluntary = %s", v)
+	}
+	if v := upd.ForcedCSW; v != nil {
+		ub.AddUpdate("csw_forced = %s", v)
+	}
 	if v := upd.Verdict; v != nil {
 		ub.AddUpdate("verdict = %s", v)
 	}
@@ -88,3 +100,12 @@ func subtestUpdateQuery(upd *kilonova.SubTestUpdate, ub *updateBuilder) {
 		ub.AddUpdate("skipped = %s", v)
 	}
 }
+
+// SubTestHidden reports whether the subtest only belongs to subtasks whose results are hidden by the running contest
+func (s *DB) SubTestHidden(ctx context.Context, id int) (bool, error) {
+	var hidden bool
+	err := s.conn.QueryRow(ctx, `SELECT COALESCE(bool_and(contest_hides_subtask(stks.contest_id, stks.subtask_id)), false)
+	FROM submission_subtask_subtests ssst INNER JOIN submission_subtasks stks ON stks.id = ssst.submission_subtask_id
+	WHERE ssst.submission_test_id = $1`, id).Scan(&hidden)
+	return hidden, err
+}
@@ -0,0 +1,126 @@
+package db
+
+import (
+	"context"
+	"errors"
+
+	"github.com/KiloProjects/kilonova"
+	"github.com/jackc/pgx/v5"
+)
+
+func (s *DB) CreateTeam(ctx context.Context, name string, creatorID int) (int, error) {
+	var id int
+	err := pgx.BeginFunc(ctx, s.conn, func(tx pgx.Tx) error {
+		if err := tx.QueryRow(ctx, "INSERT INTO teams (name, creator_id, join_code) VALUES ($1, $2, $3) RETURNING id", name, creatorID, kilonova.RandomString(16)).Scan(&id); err != nil {
+			return err
+		}
+		_, err := tx.Exec(ctx, "INSERT INTO team_members (team_id, user_id) VALUES ($1, $2)", id, creatorID)
+		return err
+	})
+	return id, err
+}
+
+func (s *DB) Team(ctx context.Context, id int) (*kilonova.Team, error) {
+	rows, _ := s.conn.Query(ctx, "SELECT * FROM teams WHERE id = $1", id)
+	team, err := pgx.CollectOneRow(rows, pgx.RowToAddrOfStructByName[kilonova.Team])
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	return team, err
+}
+
+func (s *DB) TeamByJoinCode(ctx context.Context, code string) (*kilonova.Team, error) {
+	rows, _ := s.conn.Query(ctx, "SELECT * FROM teams WHERE join_code = $1", code)
+	team, err := pgx.CollectOneRow(rows, pgx.RowToAddrOfStructByName[kilonova.Team])
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	return team, err
+}
+
+// UserTeams returns the teams the user is currently a member of
+func (s *DB) UserTeams(ctx context.Context, userID int) ([]*kilonova.Team, error) {
+	rows, _ := s.conn.Query(ctx, "SELECT teams.* FROM teams WHERE EXISTS (SELECT 1 FROM team_members WHERE team_id = teams.id AND user_id = $1) ORDER BY teams.id", userID)
+	return pgx.CollectRows(rows, pgx.RowToAddrOfStructByName[kilonova.Team])
+}
+
+func (s *DB) UpdateTeamName(ctx context.Context, id int, name string) error {
+	_, err := s.conn.Exec(ctx, "UPDATE teams SET name = $2 WHERE id = $1", id, name)
+	return err
+}
+
+func (s *DB) DeleteTeam(ctx context.Context, id int) error {
+	_, err := s.conn.Exec(ctx, "DELETE FROM teams WHERE id = $1", id)
+	return err
+}
+
+// TeamMembers returns the current members of the team
+func (s *DB) TeamMembers(ctx context.Context, teamID int) ([]*kilonova.UserFull, error) {
+	rows, _ := s.conn.Query(ctx, "SELECT user_id FROM team_members WHERE team_id = $1", teamID)
+	ids, err := pgx.CollectRows(rows, pgx.RowTo[int])
+	if err != nil {
+		return nil, err
+	}
+	if len(ids) == 0 {
+		return []*kilonova.UserFull{}, nil
+	}
+	return s.userRepo.Users(ctx, kilonova.UserFilter{IDs: ids})
+}
+
+func (s *DB) AddTeamMember(ctx context.Context, teamID, userID int) error {
+	_, err := s.conn.Exec(ctx, "INSERT INTO team_members (team_id, user_id) VALUES ($1, $2) ON CONFLICT DO NOTHING", teamID, userID)
+	return err
+}
+
+func (s *DB) RemoveTeamMember(ctx context.Context, teamID, userID int) error {
+	_, err := s.conn.Exec(ctx, "DELETE FROM team_members WHERE team_id = $1 AND user_id = $2", teamID, userID)
+	return err
+}
+
+// TeamHasContestHistory returns whether the team was ever registered in a contest or sent submissions.
+// Such teams can't be deleted, since the leaderboards reference them
+func (s *DB) TeamHasContestHistory(ctx context.Context, teamID int) (bool, error) {
+	var exists bool
+	err := s.conn.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM contest_registrations WHERE team_id = $1) OR EXISTS (SELECT 1 FROM submissions WHERE team_id = $1)`, teamID).Scan(&exists)
+	return exists, err
+}
+
+// InsertTeamRegistration registers every given member of the team in the contest.
+// The registration uses a single redeem of the invitation, no matter the number of members.
+func (s *DB) InsertTeamRegistration(ctx context.Context, contestID, teamID int, userIDs []int, invitationID *string, categoryID *int) error {
+	return pgx.BeginFunc(ctx, s.conn, func(tx pgx.Tx) error {
+		if err := reserveInvitation(ctx, tx, invitationID); err != nil {
+			return err
+		}
+		for _, userID := range userIDs {
+			if _, err := tx.Exec(ctx, "INSERT INTO contest_registrations (user_id, contest_id, invitation_id, category_id, team_id) VALUES ($1, $2, $3, $4, $5)", userID, contestID, invitationID, categoryID, teamID); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (s *DB) DeleteTeamRegistration(ctx context.Context, contestID, teamID int) error {
+	_, err := s.conn.Exec(ctx, "DELETE FROM contest_registrations WHERE contest_id = $1 AND team_id = $2", contestID, teamID)
+	return err
+}
+
+// ContestTeamMembers returns the members of the team as registered in the contest
+func (s *DB) ContestTeamMembers(ctx context.Context, contestID, teamID int) ([]*kilonova.UserFull, error) {
+	rows, _ := s.conn.Query(ctx, "SELECT user_id FROM contest_registrations WHERE contest_id = $1 AND team_id = $2", contestID, teamID)
+	ids, err := pgx.CollectRows(rows, pgx.RowTo[int])
+	if err != nil {
+		return nil, err
+	}
+	if len(ids) == 0 {
+		return []*kilonova.UserFull{}, nil
+	}
+	return s.userRepo.Users(ctx, kilonova.UserFilter{IDs: ids})
+}
+
+// ContestTeams returns the teams registered in the contest
+func (s *DB) ContestTeams(ctx context.Context, contestID int) ([]*kilonova.Team, error) {
+	rows, _ := s.conn.Query(ctx, "SELECT teams.* FROM teams WHERE EXISTS (SELECT 1 FROM contest_registrations WHERE contest_id = $1 AND team_id = teams.id) ORDER BY teams.name", contestID)
+	return pgx.CollectRows(rows, pgx.RowToAddrOfStructByName[kilonova.Team])
+}
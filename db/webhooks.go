@@ -0,0 +1,89 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// Webhook is an outgoing webhook, receiving platform events of either a user or a contest
+type Webhook struct {
+	ID        int       `db:"id" json:"id"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+	OwnerID   int       `db:"owner_id" json:"owner_id"`
+	ContestID *int      `db:"contest_id" json:"contest_id"`
+	URL       string    `db:"url" json:"url"`
+	Secret    string    `db:"secret" json:"-"`
+	Events    []string  `db:"events" json:"events"`
+	Active    bool      `db:"active" json:"active"`
+
+	LastDeliveryAt      *time.Time `db:"last_delivery_at" json:"last_delivery_at"`
+	LastStatus          *int       `db:"last_status" json:"last_status"`
+	LastError           *string    `db:"last_error" json:"last_error"`
+	ConsecutiveFailures int        `db:"consecutive_failures" json:"consecutive_failures"`
+}
+
+func (s *DB) CreateWebhook(ctx context.Context, ownerID int, contestID *int, url string, secret string, events []string) (int, error) {
+	var id int
+	err := s.conn.QueryRow(ctx, `INSERT INTO webhooks (owner_id, contest_id, url, secret, events) VALUES ($1, $2, $3, $4, $5) RETURNING id`, ownerID, contestID, url, secret, events).Scan(&id)
+	return id, err
+}
+
+func (s *DB) Webhook(ctx context.Context, id int) (*Webhook, error) {
+	rows, _ := s.conn.Query(ctx, `SELECT * FROM webhooks WHERE id = $1`, id)
+	hook, err := pgx.CollectOneRow(rows, pgx.RowToAddrOfStructByName[Webhook])
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	return hook, err
+}
+
+// UserWebhooks returns the webhooks receiving the events of the given user (not the contest webhooks they created)
+func (s *DB) UserWebhooks(ctx context.Context, userID int) ([]*Webhook, error) {
+	rows, _ := s.conn.Query(ctx, `SELECT * FROM webhooks WHERE owner_id = $1 AND contest_id IS NULL ORDER BY id`, userID)
+	return pgx.CollectRows(rows, pgx.RowToAddrOfStructByName[Webhook])
+}
+
+func (s *DB) ContestWebhooks(ctx context.Context, contestID int) ([]*Webhook, error) {
+	rows, _ := s.conn.Query(ctx, `SELECT * FROM webhooks WHERE contest_id = $1 ORDER BY id`, contestID)
+	return pgx.CollectRows(rows, pgx.RowToAddrOfStructByName[Webhook])
+}
+
+// EventWebhooks returns the active webhooks subscribed to the event, either belonging to one of the users or to the contest
+func (s *DB) EventWebhooks(ctx context.Context, event string, userIDs []int, contestID *int) ([]*Webhook, error) {
+	rows, _ := s.conn.Query(ctx, `SELECT * FROM webhooks 
+		WHERE active AND $1 = ANY(events) AND (
+			(contest_id IS NULL AND owner_id = ANY($2)) OR 
+			(contest_id IS NOT NULL AND contest_id = $3)
+		)`, event, userIDs, contestID)
+	return pgx.CollectRows(rows, pgx.RowToAddrOfStructByName[Webhook])
+}
+
+// WebhookContestsBetween returns the contests having webhooks that started and ended in the (from, to] interval
+func (s *DB) WebhookContestsBetween(ctx context.Context, from, to time.Time) (started []int, ended []int, err error) {
+	rows, _ := s.conn.Query(ctx, `SELECT id FROM contests WHERE start_time > $1 AND start_time <= $2 AND EXISTS (SELECT 1 FROM webhooks WHERE contest_id = contests.id)`, from, to)
+	started, err = pgx.CollectRows(rows, pgx.RowTo[int])
+	if err != nil {
+		return nil, nil, err
+	}
+	rows, _ = s.conn.Query(ctx, `SELECT id FROM contests WHERE end_time > $1 AND end_time <= $2 AND EXISTS (SELECT 1 FROM webhooks WHERE contest_id = contests.id)`, from, to)
+	ended, err = pgx.CollectRows(rows, pgx.RowTo[int])
+	return started, ended, err
+}
+
+// MarkWebhookDelivery saves the outcome of a delivery. Webhooks are disabled after maxFailures consecutive failed deliveries.
+func (s *DB) MarkWebhookDelivery(ctx context.Context, id int, status *int, deliveryErr *string, maxFailures int) error {
+	_, err := s.conn.Exec(ctx, `UPDATE webhooks SET 
+		last_delivery_at = NOW(), last_status = $2, last_error = $3,
+		consecutive_failures = CASE WHEN $3::text IS NULL THEN 0 ELSE consecutive_failures + 1 END,
+		active = active AND ($3::text IS NULL OR consecutive_failures + 1 < $4)
+	WHERE id = $1`, id, status, deliveryErr, maxFailures)
+	return err
+}
+
+func (s *DB) DeleteWebhook(ctx context.Context, id int) error {
+	_, err := s.conn.Exec(ctx, `DELETE FROM webhooks WHERE id = $1`, id)
+	return err
+}
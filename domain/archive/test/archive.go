@@ -29,6 +29,9 @@ type ArchiveCtx struct {
 
 	submissions []*submissionStub
 
+	// polygon is set when problem.xml was processed
+	polygon *polygonPackage
+
 	params *TestProcessParams
 
 	scoreParameters []ScoreParamEntry
@@ -63,6 +66,9 @@ type properties struct {
 	TaskType kilonova.TaskType
 
 	CommunicationProcesses *int
+
+	CheckerMode    kilonova.CheckerMode
+	CheckerEpsilon *float64
 }
 
 func NewArchiveCtx(ctx context.Context, params *TestProcessParams, filesystem fs.FS) *ArchiveCtx {
@@ -150,12 +156,9 @@ func ProcessArchiveFile(ctx *ArchiveCtx, fpath string, base *sudoapi.BaseAPI) er
 			return ProcessSubmissionFile(ctx, fpath, r, base)
 		}
 
-		if strings.HasPrefix(fpath, "tests") {
-			if slices.Contains(testOutputSuffixes, ext) {
-				return ProcessTestOutputFile(ctx, fpath)
-			}
-
-			return ProcessTestInputFile(ctx, fpath)
+		// Tests are loaded from the testsets in problem.xml, after all files are processed
+		if strings.HasPrefix(fpath, "tests/") || strings.HasPrefix(fpath, "pretests/") {
+			return nil
 		}
 
 		if fpath == "check.cpp" {
@@ -235,6 +238,12 @@ func ProcessTestArchive(ctx context.Context, pb *kilonova.Problem, ar fs.FS, bas
 		return err
 	}
 
+	if aCtx.polygon != nil {
+		if err := loadPolygonTests(aCtx, base); err != nil {
+			return err
+		}
+	}
+
 	if aCtx.props != nil && aCtx.props.Subtasks != nil && len(aCtx.props.SubtaskedTests) != len(aCtx.tests) {
 		slog.InfoContext(ctx,
 			"Mismatched tests and subtasked tests",
@@ -245,7 +254,7 @@ func ProcessTestArchive(ctx context.Context, pb *kilonova.Problem, ar fs.FS, bas
 	}
 
 	for k, v := range aCtx.tests {
-		if v.InFilePath == "" || v.OutFilePath == "" {
+		if !v.hasInput() || !v.hasOutput() {
 			return kilonova.Statusf(400, "Missing input or output file for test %q", k)
 		}
 	}
@@ -381,7 +390,7 @@ func ProcessTestArchive(ctx context.Context, pb *kilonova.Problem, ar fs.FS, bas
 
 			createdTests[v.VisibleID] = test
 
-			f, err := aCtx.fs.Open(v.InFilePath)
+			f, err := openTestFile(aCtx.fs, v.InFilePath, v.InData)
 			if err != nil {
 				return fmt.Errorf("couldn't open() input file: %w", err)
 			}
@@ -391,7 +400,7 @@ func ProcessTestArchive(ctx context.Context, pb *kilonova.Problem, ar fs.FS, bas
 				return fmt.Errorf("couldn't create test input: %w", err)
 			}
 			f.Close()
-			f, err = aCtx.fs.Open(v.OutFilePath)
+			f, err = openTestFile(aCtx.fs, v.OutFilePath, v.OutData)
 			if err != nil {
 				return fmt.Errorf("couldn't open() output file: %w", err)
 			}
@@ -445,6 +454,7 @@ func ProcessTestArchive(ctx context.Context, pb *kilonova.Problem, ar fs.FS, bas
 						VisibleID: i + 1,
 						Score:     entry.Score,
 						Tests:     testIDs,
+						Public:    true,
 					}); err != nil {
 						slog.WarnContext(ctx, "Couldn't create subtask", slog.Any("err", err))
 						return fmt.Errorf("couldn't create subtask: %w", err)
@@ -468,6 +478,7 @@ func ProcessTestArchive(ctx context.Context, pb *kilonova.Problem, ar fs.FS, bas
 					VisibleID: stkID,
 					Score:     stk.Score,
 					Tests:     tests,
+					Public:    !stk.Hidden,
 				}); err != nil {
 					slog.WarnContext(ctx, "Couldn't create subtask", slog.Any("err", err))
 					return fmt.Errorf("couldn't create subtask: %w", err)
@@ -509,6 +520,12 @@ func ProcessTestArchive(ctx context.Context, pb *kilonova.Problem, ar fs.FS, bas
 		if aCtx.props.CommunicationProcesses != nil {
 			upd.CommunicationProcesses, shouldUpd = aCtx.props.CommunicationProcesses, true
 		}
+		if aCtx.props.CheckerMode != kilonova.CheckerModeNone {
+			upd.CheckerMode, shouldUpd = aCtx.props.CheckerMode, true
+		}
+		if aCtx.props.CheckerEpsilon != nil {
+			upd.CheckerEpsilon, shouldUpd = aCtx.props.CheckerEpsilon, true
+		}
 		if aCtx.props.ScorePrecision != nil {
 			upd.ScorePrecision, shouldUpd = aCtx.props.ScorePrecision, true
 		}
@@ -609,8 +626,17 @@ func ProcessTestArchive(ctx context.Context, pb *kilonova.Problem, ar fs.FS, bas
 				slog.InfoContext(ctx, "Skipping submission, unknown language")
 				continue
 			}
-			if _, err := base.CreateSubmission(ctx, params.Requestor, pb, sub.code, sub.filename, lang, nil, true, nil, ""); err != nil {
+			id, err := base.CreateSubmission(ctx, params.Requestor, pb, sub.code, sub.filename, lang, nil, true, nil, "")
+			if err != nil {
 				slog.WarnContext(ctx, "Couldn't create submission", slog.Any("err", err))
+				continue
+			}
+			if aCtx.polygon != nil {
+				if verdict := aCtx.polygon.Solutions[sub.path]; verdict != kilonova.ExpectedVerdictNone {
+					if err := base.UpdateSubmission(ctx, id, kilonova.SubmissionUpdate{ExpectedVerdict: &verdict}); err != nil {
+						slog.WarnContext(ctx, "Couldn't set expected verdict", slog.Any("err", err))
+					}
+				}
 			}
 		}
 	}
@@ -75,10 +75,12 @@ func ProcessAttachmentFile(ctx *ArchiveCtx, fpath string) error {
 	return nil
 }
 
+// ProcessPolygonCheckFile imports a Polygon checker. They are written against upstream testlib,
+// so the attachment name selects the testlib checker protocol.
 func ProcessPolygonCheckFile(ctx *ArchiveCtx, fpath string) error {
-	ctx.attachments["checker.cpp17"] = archiveAttachment{
+	ctx.attachments["checker_testlib.cpp17"] = archiveAttachment{
 		FilePath: fpath,
-		Name:     "checker.cpp17",
+		Name:     "checker_testlib.cpp17",
 		Visible:  false,
 		Private:  true,
 		Exec:     true,
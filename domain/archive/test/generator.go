@@ -175,8 +175,9 @@ func (ag *archiveGenerator) addGraderProperties(ctx context.Context) error {
 
 			groups := []string{}
 			weights := []string{}
+			hidden := []string{}
 
-			for _, st := range subtasks {
+			for idx, st := range subtasks {
 				var group strings.Builder
 				for i, t := range st.Tests {
 					if i > 0 {
@@ -191,9 +192,15 @@ func (ag *archiveGenerator) addGraderProperties(ctx context.Context) error {
 				}
 				groups = append(groups, group.String())
 				weights = append(weights, st.Score.String())
+				if !st.Public {
+					hidden = append(hidden, strconv.Itoa(idx+1))
+				}
 			}
 			fmt.Fprintf(&buf, "groups=%s\n", strings.Join(groups, ","))
 			fmt.Fprintf(&buf, "weights=%s\n", strings.Join(weights, ","))
+			if len(hidden) > 0 {
+				fmt.Fprintf(&buf, "hidden_groups=%s\n", strings.Join(hidden, ";"))
+			}
 		}
 	}
 	if ag.opts.ProblemDetails {
@@ -218,6 +225,12 @@ func (ag *archiveGenerator) addGraderProperties(ctx context.Context) error {
 		if ag.pb.TaskType == kilonova.TaskTypeCommunication {
 			fmt.Fprintf(&buf, "communication_processes=%d\n", ag.pb.CommunicationProcesses)
 		}
+		if ag.pb.CheckerMode != kilonova.CheckerModeNone && ag.pb.CheckerMode != kilonova.CheckerModeDiff {
+			fmt.Fprintf(&buf, "checker_mode=%s\n", ag.pb.CheckerMode)
+			if ag.pb.CheckerMode == kilonova.CheckerModeFloat {
+				fmt.Fprintf(&buf, "checker_epsilon=%g\n", ag.pb.CheckerEpsilon)
+			}
+		}
 	}
 
 	if ag.opts.Tags {
@@ -0,0 +1,410 @@
+package test
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/KiloProjects/kilonova"
+	"github.com/KiloProjects/kilonova/domain/datastore"
+	"github.com/KiloProjects/kilonova/eval"
+	"github.com/KiloProjects/kilonova/eval/language"
+	"github.com/KiloProjects/kilonova/eval/tasks"
+	"github.com/KiloProjects/kilonova/sudoapi"
+	"github.com/antchfx/xmlquery"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+const (
+	// Limits for the generators and the main solution, when materializing tests.
+	// TimeLimit is in seconds, MemoryLimit is in kilobytes
+	polygonTimeLimit   = 10
+	polygonMemoryLimit = 512 * 1024
+)
+
+type polygonTest struct {
+	// Manual tests have their input in the package
+	Manual bool
+	// Cmd is the generator command line of a generated test
+	Cmd string
+
+	Points *decimal.Decimal
+	Group  string
+}
+
+type polygonTestset struct {
+	Name string
+
+	// Patterns of the test files in the package, formatted with the 1-based test index
+	InputPattern  string
+	AnswerPattern string
+
+	Tests []polygonTest
+}
+
+// polygonPackage holds what the importer needs from problem.xml, besides the problem properties
+type polygonPackage struct {
+	Pretests *polygonTestset
+	Tests    *polygonTestset
+
+	// InputFile and OutputFile are empty when the solutions use standard input/output
+	InputFile  string
+	OutputFile string
+
+	// Executables maps the names used in generator commands to their source files
+	Executables map[string]string
+	// Resources are the package files available when compiling, such as testlib.h
+	Resources []string
+
+	MainSolution string
+	// Solutions maps the solution paths to their expected verdicts
+	Solutions map[string]kilonova.ExpectedVerdict
+}
+
+func (pkg *polygonPackage) testsets() []*polygonTestset {
+	if pkg.Pretests == nil {
+		return []*polygonTestset{pkg.Tests}
+	}
+	return []*polygonTestset{pkg.Pretests, pkg.Tests}
+}
+
+// polygonExpectedVerdict maps the Polygon solution tags to verdicts. Other tags are imported without an expected verdict
+func polygonExpectedVerdict(tag string) kilonova.ExpectedVerdict {
+	switch tag {
+	case "main", "accepted":
+		return kilonova.ExpectedVerdictAccepted
+	case "wrong-answer":
+		return kilonova.ExpectedVerdictWrongAnswer
+	case "time-limit-exceeded":
+		return kilonova.ExpectedVerdictTimeLimit
+	default:
+		return kilonova.ExpectedVerdictNone
+	}
+}
+
+func parsePolygonTestset(node *xmlquery.Node) (*polygonTestset, error) {
+	ts := &polygonTestset{
+		Name:          node.SelectAttr("name"),
+		InputPattern:  node.SelectAttr("name") + "/%02d",
+		AnswerPattern: node.SelectAttr("name") + "/%02d.a",
+	}
+	if pattern := xmlquery.FindOne(node, "input-path-pattern"); pattern != nil {
+		ts.InputPattern = strings.TrimSpace(pattern.InnerText())
+	}
+	if pattern := xmlquery.FindOne(node, "answer-path-pattern"); pattern != nil {
+		ts.AnswerPattern = strings.TrimSpace(pattern.InnerText())
+	}
+
+	for _, node := range xmlquery.Find(node, "tests/test") {
+		test := polygonTest{
+			Manual: node.SelectAttr("method") != "generated",
+			Group:  node.SelectAttr("group"),
+		}
+		// Tests generated together with others (from-file) can only be imported if they are in the package
+		if !test.Manual && node.SelectAttr("from-file") == "" {
+			test.Cmd = strings.TrimSpace(node.SelectAttr("cmd"))
+		}
+		if points := node.SelectAttr("points"); points != "" {
+			val, err := decimal.NewFromString(points)
+			if err == nil {
+				test.Points = &val
+			}
+		}
+		ts.Tests = append(ts.Tests, test)
+	}
+
+	if script := xmlquery.FindOne(node, "script"); script != nil {
+		if err := ts.applyScript(script.InnerText()); err != nil {
+			return nil, err
+		}
+	}
+	return ts, nil
+}
+
+// applyScript assigns the commands of a generator script to their tests.
+// Each line is either `gen args > N`, for the N-th test, or `gen args > $`, for the first test without an input.
+func (ts *polygonTestset) applyScript(script string) error {
+	for line := range strings.Lines(script) {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		cmd, target, ok := strings.Cut(line, ">")
+		if !ok {
+			return kilonova.Statusf(400, "Invalid generator script line %q", line)
+		}
+		cmd, target = strings.TrimSpace(cmd), strings.TrimSpace(target)
+
+		idx := len(ts.Tests)
+		if target == "$" {
+			for i, test := range ts.Tests {
+				if !test.Manual && test.Cmd == "" {
+					idx = i
+					break
+				}
+			}
+		} else {
+			n, err := strconv.Atoi(target)
+			if err != nil || n <= 0 {
+				// Also rejects generators with multiple outputs (`gen > {1-3}`)
+				return kilonova.Statusf(400, "Unsupported generator script target %q", target)
+			}
+			idx = n - 1
+		}
+
+		for len(ts.Tests) <= idx {
+			ts.Tests = append(ts.Tests, polygonTest{})
+		}
+		ts.Tests[idx].Manual = false
+		ts.Tests[idx].Cmd = cmd
+	}
+	return nil
+}
+
+// loadPolygonTests adds the tests of every testset to the archive, generating the inputs and answers
+// that are missing from the package in the grader's sandbox.
+func loadPolygonTests(actx *ArchiveCtx, base *sudoapi.BaseAPI) error {
+	r := &polygonRunner{
+		actx:     actx,
+		base:     base,
+		id:       uuid.NewString(),
+		compiled: make(map[string]*polygonExecutable),
+		inputs:   make(map[string][]byte),
+	}
+	defer r.cleanup()
+
+	visibleID := 1
+	for _, ts := range actx.polygon.testsets() {
+		for i, test := range ts.Tests {
+			at := archiveTest{Key: fmt.Sprintf("%04d", visibleID)}
+			visibleID++
+
+			if fpath := fmt.Sprintf(ts.InputPattern, i+1); fileExists(actx.fs, fpath) {
+				at.InFilePath = fpath
+			} else if test.Cmd != "" {
+				data, err := r.generate(actx.ctx, test.Cmd)
+				if err != nil {
+					return err
+				}
+				at.InData = data
+			} else {
+				return kilonova.Statusf(400, "Input file %q of test %d from testset %q is missing and cannot be generated", fpath, i+1, ts.Name)
+			}
+
+			if fpath := fmt.Sprintf(ts.AnswerPattern, i+1); fileExists(actx.fs, fpath) {
+				at.OutFilePath = fpath
+			} else {
+				input := at.InData
+				if input == nil {
+					var err error
+					input, err = fs.ReadFile(actx.fs, at.InFilePath)
+					if err != nil {
+						return fmt.Errorf("couldn't read test input: %w", err)
+					}
+				}
+				data, err := r.answer(actx.ctx, input)
+				if err != nil {
+					return err
+				}
+				at.OutData = data
+			}
+
+			actx.tests[at.Key] = at
+		}
+	}
+	return nil
+}
+
+func fileExists(fsys fs.FS, fpath string) bool {
+	stat, err := fs.Stat(fsys, fpath)
+	return err == nil && !stat.IsDir()
+}
+
+type polygonExecutable struct {
+	lang     language.GraderLang
+	filename string
+	bin      *eval.BucketFile
+}
+
+// polygonRunner compiles and runs the generators and the main solution of a Polygon package
+type polygonRunner struct {
+	actx *ArchiveCtx
+	base *sudoapi.BaseAPI
+	mgr  eval.BoxScheduler
+
+	// id makes the names of the files in the data store unique
+	id      string
+	numRuns int
+
+	headers  map[string][]byte
+	compiled map[string]*polygonExecutable
+	// inputs caches the generated inputs by command, since pretests are usually also part of the tests
+	inputs map[string][]byte
+}
+
+func (r *polygonRunner) generate(ctx context.Context, cmd string) ([]byte, error) {
+	if data, ok := r.inputs[cmd]; ok {
+		return data, nil
+	}
+	args := strings.Fields(cmd)
+	src, ok := r.actx.polygon.Executables[args[0]]
+	if !ok {
+		return nil, kilonova.Statusf(400, "Unknown generator %q", args[0])
+	}
+	exe, err := r.compile(ctx, src)
+	if err != nil {
+		return nil, err
+	}
+	data, err := r.run(ctx, exe, args[1:], nil, "stdin", "stdout")
+	if err != nil {
+		return nil, fmt.Errorf("generator command %q failed: %w", cmd, err)
+	}
+	r.inputs[cmd] = data
+	return data, nil
+}
+
+func (r *polygonRunner) answer(ctx context.Context, input []byte) ([]byte, error) {
+	pkg := r.actx.polygon
+	if pkg.MainSolution == "" {
+		return nil, kilonova.Statusf(400, "The package has no main solution to generate the missing answers")
+	}
+	exe, err := r.compile(ctx, pkg.MainSolution)
+	if err != nil {
+		return nil, err
+	}
+	data, err := r.run(ctx, exe, nil, input, cmp.Or(pkg.InputFile, "stdin"), cmp.Or(pkg.OutputFile, "stdout"))
+	if err != nil {
+		return nil, fmt.Errorf("main solution failed: %w", err)
+	}
+	return data, nil
+}
+
+func (r *polygonRunner) compile(ctx context.Context, src string) (*polygonExecutable, error) {
+	if exe, ok := r.compiled[src]; ok {
+		return exe, nil
+	}
+	if r.mgr == nil {
+		r.mgr = r.base.BoxScheduler()
+		if r.mgr == nil {
+			return nil, kilonova.Statusf(500, "The grader must be running to generate the missing tests")
+		}
+	}
+	if r.headers == nil {
+		r.headers = make(map[string][]byte)
+		for _, res := range r.actx.polygon.Resources {
+			if path.Ext(res) != ".h" {
+				continue
+			}
+			data, err := fs.ReadFile(r.actx.fs, res)
+			if err != nil {
+				slog.WarnContext(ctx, "Couldn't read Polygon resource", slog.String("path", res), slog.Any("err", err))
+				continue
+			}
+			r.headers[path.Join("/box", path.Base(res))] = data
+		}
+	}
+
+	filename := path.Base(src)
+	lang := r.base.GraderLanguage(r.base.LanguageFromFilename(filename))
+	if lang == nil {
+		return nil, kilonova.Statusf(400, "Unsupported language for %q", src)
+	}
+	code, err := fs.ReadFile(r.actx.fs, src)
+	if err != nil {
+		return nil, kilonova.Statusf(400, "Couldn't read %q from the package", src)
+	}
+
+	exe := &polygonExecutable{
+		lang:     lang,
+		filename: filename,
+		bin: &eval.BucketFile{
+			Bucket:   datastore.BucketTypeCompiles,
+			Filename: fmt.Sprintf("polygon-%s-%d.bin", r.id, len(r.compiled)),
+			Mode:     0777,
+		},
+	}
+	resp, err := tasks.CompileTask(ctx, r.mgr, &tasks.CompileRequest{
+		File:             exe.bin,
+		CodeFiles:        map[string][]byte{lang.SourceName(filename): code},
+		HeaderFiles:      r.headers,
+		Lang:             lang,
+		Store:            r.base.DataStore(),
+		OriginalFilename: filename,
+	}, slog.Default())
+	if err != nil {
+		return nil, fmt.Errorf("couldn't compile %q: %w", src, err)
+	}
+	if !resp.Success {
+		r.remove(exe.bin)
+		return nil, kilonova.Statusf(400, "Couldn't compile %q:\n%s", src, resp.Output)
+	}
+	// Only successful compilations are cached, so a failed one isn't reused later as if it worked
+	r.compiled[src] = exe
+	return exe, nil
+}
+
+// run executes the program in the sandbox and returns its output
+func (r *polygonRunner) run(ctx context.Context, exe *polygonExecutable, args []string, input []byte, inputName, outputName string) ([]byte, error) {
+	store := r.base.DataStore()
+	r.numRuns++
+	inFile := &eval.BucketFile{Bucket: datastore.BucketTypeSubtests, Filename: fmt.Sprintf("polygon-%s-%d.in", r.id, r.numRuns), Mode: 0666}
+	outFile := &eval.BucketFile{Bucket: datastore.BucketTypeSubtests, Filename: fmt.Sprintf("polygon-%s-%d.out", r.id, r.numRuns), Mode: 0644}
+	defer r.remove(inFile)
+	defer r.remove(outFile)
+
+	if err := store.WriteFile(inFile.Bucket, inFile.Filename, bytes.NewReader(input), inFile.Mode); err != nil {
+		return nil, fmt.Errorf("couldn't save input: %w", err)
+	}
+
+	req := &tasks.BatchRequest{
+		InputName:    inputName,
+		OutputName:   outputName,
+		MemoryLimit:  polygonMemoryLimit,
+		TimeLimit:    polygonTimeLimit,
+		CodeFilename: exe.filename,
+		Args:         args,
+		Lang:         exe.lang,
+		ExecFile:     exe.bin,
+		InputFile:    inFile,
+		OutputFile:   outFile,
+	}
+	resp, err := tasks.ExecuteBatch(ctx, r.mgr, int64(req.MemoryLimit), req, slog.Default())
+	if err != nil {
+		return nil, err
+	}
+	if resp.Comments != "" {
+		return nil, kilonova.Statusf(400, "%s", strings.TrimPrefix(resp.Comments, "translate:"))
+	}
+
+	out, err := store.Reader(outFile.Bucket, outFile.Filename)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't open output: %w", err)
+	}
+	defer out.Close()
+	return io.ReadAll(out)
+}
+
+func (r *polygonRunner) remove(file *eval.BucketFile) {
+	bucket, err := r.base.DataStore().Get(file.Bucket)
+	if err != nil {
+		return
+	}
+	if err := bucket.RemoveFile(file.Filename); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		slog.WarnContext(r.actx.ctx, "Couldn't remove Polygon import file", slog.String("file", file.Filename), slog.Any("err", err))
+	}
+}
+
+func (r *polygonRunner) cleanup() {
+	for _, exe := range r.compiled {
+		r.remove(exe.bin)
+	}
+}
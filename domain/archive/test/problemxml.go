@@ -3,7 +3,11 @@ package test
 import (
 	"fmt"
 	"io"
+	"maps"
+	"path"
+	"slices"
 	"strconv"
+	"strings"
 
 	"github.com/KiloProjects/kilonova"
 	"github.com/antchfx/xmlquery"
@@ -27,13 +31,24 @@ func ProcessProblemXMLFile(actx *ArchiveCtx, file io.Reader) error {
 		}
 	}
 
-	// Get main testset
-	// Kilonova doesn't support more than one testset
+	pkg := &polygonPackage{
+		Executables: make(map[string]string),
+		Solutions:   make(map[string]kilonova.ExpectedVerdict),
+	}
+	actx.polygon = pkg
+
+	// Get the testsets. Pretests, if they exist, are imported before the main tests
 	var testsetNode *xmlquery.Node
 	for _, node := range xmlquery.Find(node, "//judging/testset") {
-		if node.SelectAttr("name") == "tests" {
+		switch node.SelectAttr("name") {
+		case "tests":
 			testsetNode = node
-			break
+			pkg.Tests, err = parsePolygonTestset(node)
+		case "pretests":
+			pkg.Pretests, err = parsePolygonTestset(node)
+		}
+		if err != nil {
+			return err
 		}
 	}
 
@@ -41,25 +56,57 @@ func ProcessProblemXMLFile(actx *ArchiveCtx, file io.Reader) error {
 		return kilonova.Statusf(400, "There must be a `tests` testset")
 	}
 
+	if judging := xmlquery.FindOne(node, "//judging"); judging != nil {
+		pkg.InputFile = judging.SelectAttr("input-file")
+		pkg.OutputFile = judging.SelectAttr("output-file")
+	}
+
+	for _, source := range xmlquery.Find(node, "//files/executables/executable/source") {
+		if fpath := source.SelectAttr("path"); fpath != "" {
+			pkg.Executables[strings.TrimSuffix(path.Base(fpath), path.Ext(fpath))] = fpath
+		}
+	}
+	for _, file := range xmlquery.Find(node, "//files/resources/file") {
+		if fpath := file.SelectAttr("path"); fpath != "" {
+			pkg.Resources = append(pkg.Resources, fpath)
+		}
+	}
+
+	for _, solution := range xmlquery.Find(node, "//assets/solutions/solution") {
+		source := xmlquery.FindOne(solution, "source")
+		if source == nil || source.SelectAttr("path") == "" {
+			continue
+		}
+		tag := solution.SelectAttr("tag")
+		pkg.Solutions[source.SelectAttr("path")] = polygonExpectedVerdict(tag)
+		if tag == "main" {
+			pkg.MainSolution = source.SelectAttr("path")
+		}
+	}
+
+	// Tests of the main testset come after the pretests
+	offset := 0
+	if pkg.Pretests != nil {
+		offset = len(pkg.Pretests.Tests)
+		// Pretests only give feedback, the points come from the main tests
+		for id := range offset {
+			actx.testScores[id+1] = decimal.Zero
+		}
+	}
+
 	// Get task points and subtask
 	var isICPC = true
 	var subtasks = make(map[string]parsedSubtask)
-	for id, test := range xmlquery.Find(testsetNode, "//tests/test") {
-		if points := test.SelectAttr("points"); points != "" {
+	for id, test := range pkg.Tests.Tests {
+		if test.Points != nil {
 			isICPC = false
-			val, err := decimal.NewFromString(points)
-			if err == nil {
-				actx.testScores[id+1] = val
-			}
+			actx.testScores[offset+id+1] = *test.Points
 		}
-		if group := test.SelectAttr("group"); group != "" {
+		if test.Group != "" {
 			isICPC = false
-			stk, ok := subtasks[group]
-			if !ok {
-				stk = parsedSubtask{}
-			}
-			stk.Tests = append(stk.Tests, id+1)
-			subtasks[group] = stk
+			stk := subtasks[test.Group]
+			stk.Tests = append(stk.Tests, offset+id+1)
+			subtasks[test.Group] = stk
 		}
 	}
 	if isICPC && actx.params.FirstImport && len(actx.params.ScoreParamsStr) == 0 {
@@ -68,7 +115,7 @@ func ProcessProblemXMLFile(actx *ArchiveCtx, file io.Reader) error {
 
 	if len(subtasks) > 0 {
 		// Parse group points and dependencies
-		for i, group := range xmlquery.Find(testsetNode, "//groups/group") {
+		for i, group := range xmlquery.Find(testsetNode, "groups/group") {
 			name := group.SelectAttr("name")
 			stk, ok := subtasks[name]
 			if !ok {
@@ -88,7 +135,7 @@ func ProcessProblemXMLFile(actx *ArchiveCtx, file io.Reader) error {
 			}
 
 			var dependencies []string
-			for _, dep := range xmlquery.Find(group, "//dependencies/dependency") {
+			for _, dep := range xmlquery.Find(group, "dependencies/dependency") {
 				if val := dep.SelectAttr("name"); len(val) > 0 {
 					dependencies = append(dependencies, val)
 				}
@@ -106,15 +153,19 @@ func ProcessProblemXMLFile(actx *ArchiveCtx, file io.Reader) error {
 		actx.props.Subtasks, actx.props.SubtaskedTests = solveSubtaskDependencies(actx.ctx, subtasks)
 	}
 
+	if pkg.Pretests != nil {
+		addPretestSubtask(actx, offset, len(pkg.Tests.Tests))
+	}
+
 	// Parse time/memory limit
-	if node := xmlquery.FindOne(testsetNode, "//time-limit"); node != nil {
+	if node := xmlquery.FindOne(testsetNode, "time-limit"); node != nil {
 		timeLimit, err := strconv.Atoi(node.InnerText())
 		if err == nil {
 			actx.props.TimeLimit = new(float64(timeLimit) / 1000.0)
 		}
 	}
 
-	if node := xmlquery.FindOne(testsetNode, "//memory-limit"); node != nil {
+	if node := xmlquery.FindOne(testsetNode, "memory-limit"); node != nil {
 		memoryLimit, err := strconv.Atoi(node.InnerText())
 		if err == nil {
 			memoryLimit /= 1024
@@ -124,3 +175,35 @@ func ProcessProblemXMLFile(actx *ArchiveCtx, file io.Reader) error {
 
 	return nil
 }
+
+// addPretestSubtask groups the pretests in a public subtask worth no points, placed before the subtasks of the main tests, which become hidden.
+// The main tests are grouped in a single subtask if they weren't already split in groups.
+func addPretestSubtask(actx *ArchiveCtx, numPretests, numTests int) {
+	testSubtasks := actx.props.Subtasks
+	if len(testSubtasks) == 0 {
+		score := decimal.Zero
+		tests := make([]int, 0, numTests)
+		for id := range numTests {
+			score = score.Add(actx.testScores[numPretests+id+1])
+			tests = append(tests, numPretests+id+1)
+		}
+		if score.IsZero() {
+			score = decimal.NewFromInt(100)
+		}
+		testSubtasks = map[int]Subtask{1: {Score: score, Tests: tests}}
+		actx.props.SubtaskedTests = slices.Clone(tests)
+	}
+
+	pretests := make([]int, 0, numPretests)
+	for id := range numPretests {
+		pretests = append(pretests, id+1)
+	}
+
+	actx.props.Subtasks = map[int]Subtask{1: {Score: decimal.Zero, Tests: pretests}}
+	for i, id := range slices.Sorted(maps.Keys(testSubtasks)) {
+		stk := testSubtasks[id]
+		stk.Hidden = true
+		actx.props.Subtasks[i+2] = stk
+	}
+	actx.props.SubtaskedTests = append(pretests, actx.props.SubtaskedTests...)
+}
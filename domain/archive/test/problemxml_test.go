@@ -0,0 +1,163 @@
+package test
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/KiloProjects/kilonova"
+)
+
+const polygonProblemXML = `<?xml version="1.0" encoding="utf-8" standalone="no"?>
+<problem revision="3" short-name="sum">
+    <names>
+        <name language="english" value="Sum"/>
+    </names>
+    <judging input-file="" output-file="">
+        <testset name="pretests">
+            <time-limit>1000</time-limit>
+            <memory-limit>268435456</memory-limit>
+            <test-count>2</test-count>
+            <input-path-pattern>pretests/%02d</input-path-pattern>
+            <answer-path-pattern>pretests/%02d.a</answer-path-pattern>
+            <tests>
+                <test method="manual" sample="true"/>
+                <test cmd="gen 1 10" method="generated"/>
+            </tests>
+        </testset>
+        <testset name="tests">
+            <time-limit>2000</time-limit>
+            <memory-limit>268435456</memory-limit>
+            <test-count>3</test-count>
+            <input-path-pattern>tests/%02d</input-path-pattern>
+            <answer-path-pattern>tests/%02d.a</answer-path-pattern>
+            <tests>
+                <test method="manual" sample="true"/>
+                <test method="generated"/>
+            </tests>
+            <script>
+gen 1 100 > $
+gen 1 1000000 > 3
+            </script>
+        </testset>
+    </judging>
+    <files>
+        <resources>
+            <file path="files/testlib.h" type="h.g++"/>
+        </resources>
+        <executables>
+            <executable>
+                <source path="files/gen.cpp" type="cpp.g++17"/>
+            </executable>
+        </executables>
+    </files>
+    <assets>
+        <solutions>
+            <solution tag="main"><source path="solutions/sol.cpp" type="cpp.g++17"/></solution>
+            <solution tag="wrong-answer"><source path="solutions/wa.cpp" type="cpp.g++17"/></solution>
+            <solution tag="time-limit-exceeded"><source path="solutions/tle.py" type="python.3"/></solution>
+            <solution tag="rejected"><source path="solutions/bad.cpp" type="cpp.g++17"/></solution>
+        </solutions>
+    </assets>
+</problem>`
+
+func TestProcessPolygonTestsets(t *testing.T) {
+	actx := NewArchiveCtx(t.Context(), &TestProcessParams{}, fstest.MapFS{})
+	if err := ProcessProblemXMLFile(actx, strings.NewReader(polygonProblemXML)); err != nil {
+		t.Fatal(err)
+	}
+
+	pkg := actx.polygon
+	if pkg.Pretests == nil || len(pkg.Pretests.Tests) != 2 || len(pkg.Tests.Tests) != 3 {
+		t.Fatalf("unexpected testsets %+v %+v", pkg.Pretests, pkg.Tests)
+	}
+	cmds := []string{}
+	for _, test := range pkg.Tests.Tests {
+		cmds = append(cmds, test.Cmd)
+	}
+	if !slices.Equal(cmds, []string{"", "gen 1 100", "gen 1 1000000"}) {
+		t.Fatalf("generator script was not applied: %q", cmds)
+	}
+	if pkg.Executables["gen"] != "files/gen.cpp" || pkg.MainSolution != "solutions/sol.cpp" {
+		t.Fatalf("unexpected executables %v / main solution %q", pkg.Executables, pkg.MainSolution)
+	}
+	if pkg.Solutions["solutions/wa.cpp"] != kilonova.ExpectedVerdictWrongAnswer ||
+		pkg.Solutions["solutions/tle.py"] != kilonova.ExpectedVerdictTimeLimit ||
+		pkg.Solutions["solutions/bad.cpp"] != kilonova.ExpectedVerdictNone {
+		t.Fatalf("unexpected solution verdicts %v", pkg.Solutions)
+	}
+
+	// The limits come from the main testset
+	if actx.props.TimeLimit == nil || *actx.props.TimeLimit != 2 {
+		t.Fatalf("unexpected time limit %v", actx.props.TimeLimit)
+	}
+
+	stks := actx.props.Subtasks
+	if len(stks) != 2 {
+		t.Fatalf("expected 2 subtasks, got %d", len(stks))
+	}
+	if stk := stks[1]; stk.Hidden || !stk.Score.IsZero() || !slices.Equal(stk.Tests, []int{1, 2}) {
+		t.Fatalf("pretests should be a public subtask worth no points: %+v", stk)
+	}
+	if stk := stks[2]; !stk.Hidden || stk.Score.IntPart() != 100 || !slices.Equal(stk.Tests, []int{3, 4, 5}) {
+		t.Fatalf("tests should be a hidden subtask: %+v", stk)
+	}
+	if len(actx.props.SubtaskedTests) != 5 {
+		t.Fatalf("expected every test to be in a subtask, got %v", actx.props.SubtaskedTests)
+	}
+}
+
+func TestLoadPolygonTests(t *testing.T) {
+	fsys := fstest.MapFS{
+		"pretests/01":   {Data: []byte("1 2\n")},
+		"pretests/01.a": {Data: []byte("3\n")},
+		"pretests/02":   {Data: []byte("2 2\n")},
+		"pretests/02.a": {Data: []byte("4\n")},
+		"tests/01":      {Data: []byte("1 2\n")},
+		"tests/01.a":    {Data: []byte("3\n")},
+		"tests/02":      {Data: []byte("5 5\n")},
+		"tests/02.a":    {Data: []byte("10\n")},
+		"tests/03":      {Data: []byte("7 1\n")},
+		"tests/03.a":    {Data: []byte("8\n")},
+	}
+	actx := NewArchiveCtx(t.Context(), &TestProcessParams{}, fsys)
+	if err := ProcessProblemXMLFile(actx, strings.NewReader(polygonProblemXML)); err != nil {
+		t.Fatal(err)
+	}
+	// Nothing has to be generated, so the grader isn't needed
+	if err := loadPolygonTests(actx, nil); err != nil {
+		t.Fatal(err)
+	}
+	if len(actx.tests) != 5 {
+		t.Fatalf("expected 5 tests, got %d", len(actx.tests))
+	}
+	if test := actx.tests["0002"]; test.InFilePath != "pretests/02" || test.OutFilePath != "pretests/02.a" {
+		t.Fatalf("unexpected pretest %+v", test)
+	}
+	if test := actx.tests["0005"]; test.InFilePath != "tests/03" || test.OutFilePath != "tests/03.a" {
+		t.Fatalf("unexpected test %+v", test)
+	}
+
+	// Generated tests can't be materialized without their generator
+	delete(fsys, "tests/03")
+	pkg := actx.polygon
+	pkg.Executables = map[string]string{}
+	if err := loadPolygonTests(actx, nil); err == nil {
+		t.Fatal("expected unknown generator error")
+	}
+}
+
+func TestPolygonScriptTargets(t *testing.T) {
+	ts := &polygonTestset{Tests: []polygonTest{{Manual: true}}}
+	if err := ts.applyScript("gen 1 > $\ngen 2 > 4\n"); err != nil {
+		t.Fatal(err)
+	}
+	if len(ts.Tests) != 4 || ts.Tests[1].Cmd != "gen 1" || ts.Tests[2].Cmd != "" || ts.Tests[3].Cmd != "gen 2" {
+		t.Fatalf("unexpected tests %+v", ts.Tests)
+	}
+
+	if err := ts.applyScript("gen > {1-3}"); err == nil {
+		t.Fatal("multiple output generators should be rejected")
+	}
+}
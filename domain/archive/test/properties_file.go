@@ -20,6 +20,8 @@ import (
 type Subtask struct {
 	Score decimal.Decimal
 	Tests []int
+	// Hidden subtasks only show their results after the contest ends
+	Hidden bool
 
 	index *int
 }
@@ -29,10 +31,20 @@ type mockTag struct {
 	Type kilonova.TagType
 }
 
+// archiveTaskTypes are the task types that may be set through grader.properties
+var archiveTaskTypes = []kilonova.TaskType{
+	kilonova.TaskTypeBatch,
+	kilonova.TaskTypeCommunication,
+	kilonova.TaskTypeAI,
+	kilonova.TaskTypeOutputOnly,
+	kilonova.TaskTypeTwoStep,
+}
+
 type PropertiesRaw struct {
 	Groups       string   `props:"groups"`
 	Weights      string   `props:"weights"`
 	Dependencies string   `props:"dependencies"`
+	HiddenGroups string   `props:"hidden_groups"`
 	Time         *float64 `props:"time"`
 	Memory       *float64 `props:"memory"`
 	Tags         *string  `props:"tags"`
@@ -52,6 +64,9 @@ type PropertiesRaw struct {
 	TaskType        *string `props:"task_type"`
 
 	CommunicationProcesses *int `props:"communication_processes"`
+
+	CheckerMode    *string  `props:"checker_mode"`
+	CheckerEpsilon *float64 `props:"checker_epsilon"`
 }
 
 func ParsePropertiesFile(r io.Reader) (*PropertiesRaw, bool, error) {
@@ -197,12 +212,18 @@ func ProcessPropertiesFile(ctx *ArchiveCtx, r io.Reader) error {
 	if rawProps.MergeAttachments != nil && (*rawProps.MergeAttachments == "true" || *rawProps.MergeAttachments == "false") {
 		ctx.params.MergeAttachments = *rawProps.MergeAttachments == "true"
 	}
-	if rawProps.TaskType != nil && (*rawProps.TaskType == string(kilonova.TaskTypeBatch) || *rawProps.TaskType == string(kilonova.TaskTypeCommunication) || *rawProps.TaskType == string(kilonova.TaskTypeAI)) {
+	if rawProps.TaskType != nil && slices.Contains(archiveTaskTypes, kilonova.TaskType(*rawProps.TaskType)) {
 		props.TaskType = kilonova.TaskType(*rawProps.TaskType)
 	}
 	if rawProps.CommunicationProcesses != nil {
 		props.CommunicationProcesses = rawProps.CommunicationProcesses
 	}
+	if rawProps.CheckerMode != nil && kilonova.ValidCheckerMode(kilonova.CheckerMode(*rawProps.CheckerMode)) {
+		props.CheckerMode = kilonova.CheckerMode(*rawProps.CheckerMode)
+	}
+	if rawProps.CheckerEpsilon != nil {
+		props.CheckerEpsilon = rawProps.CheckerEpsilon
+	}
 
 	// handle subtasks
 	if rawProps.Groups != "" {
@@ -262,6 +283,21 @@ func ProcessPropertiesFile(ctx *ArchiveCtx, r io.Reader) error {
 			}
 		}
 
+		if rawProps.HiddenGroups != "" {
+			glist, err := parsePropListItem(rawProps.HiddenGroups, "hidden_groups")
+			if err != nil {
+				return err
+			}
+			for _, vid := range glist {
+				if vid <= 0 || vid > len(groupStrings) {
+					return kilonova.Statusf(400, "Hidden group number out of range")
+				}
+				stk := stks[strconv.Itoa(vid)]
+				stk.Hidden = true
+				stks[strconv.Itoa(vid)] = stk
+			}
+		}
+
 		props.Subtasks, props.SubtaskedTests = solveSubtaskDependencies(ctx.ctx, stks)
 	}
 
@@ -275,6 +311,8 @@ type parsedSubtask struct {
 
 	Index *int
 
+	Hidden bool
+
 	// The current subtask is automatically considered a dependency
 	Dependencies []string
 }
@@ -286,7 +324,7 @@ func solveSubtaskDependencies(ctx context.Context, subtasks map[string]parsedSub
 	finalSubtasks := make(map[string]Subtask)
 
 	for id, group := range subtasks {
-		stk := Subtask{Score: group.Score, index: group.Index}
+		stk := Subtask{Score: group.Score, Hidden: group.Hidden, index: group.Index}
 		stk.Tests = slices.Clone(group.Tests)
 		for _, dependency := range group.Dependencies {
 			dep, ok := subtasks[dependency]
@@ -0,0 +1,28 @@
+package test
+
+import (
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestPropertiesHiddenGroups(t *testing.T) {
+	actx := NewArchiveCtx(t.Context(), &TestProcessParams{}, fstest.MapFS{})
+	props := "groups=1-2,3,4;5\nweights=20,30,50\nhidden_groups=2;3\n"
+	if err := ProcessPropertiesFile(actx, strings.NewReader(props)); err != nil {
+		t.Fatal(err)
+	}
+	stks := actx.props.Subtasks
+	if len(stks) != 3 {
+		t.Fatalf("expected 3 subtasks, got %d", len(stks))
+	}
+	if stks[1].Hidden || !stks[2].Hidden || !stks[3].Hidden {
+		t.Fatalf("unexpected hidden subtasks: %+v", stks)
+	}
+
+	actx = NewArchiveCtx(t.Context(), &TestProcessParams{}, fstest.MapFS{})
+	props = "groups=1,2\nweights=50,50\nhidden_groups=3\n"
+	if err := ProcessPropertiesFile(actx, strings.NewReader(props)); err == nil {
+		t.Fatal("out of range hidden groups should be rejected")
+	}
+}
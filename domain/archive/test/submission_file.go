@@ -15,6 +15,7 @@ type submissionStub struct {
 	lang string
 
 	filename string
+	path     string
 }
 
 func ProcessSubmissionFile(ctx *ArchiveCtx, fpath string, r io.Reader, base *sudoapi.BaseAPI) error {
@@ -36,6 +37,7 @@ func ProcessSubmissionFile(ctx *ArchiveCtx, fpath string, r io.Reader, base *sud
 		lang: lang,
 
 		filename: path.Base(fpath),
+		path:     fpath,
 	})
 	return nil
 }
@@ -1,7 +1,10 @@
 package test
 
 import (
+	"bytes"
 	"fmt"
+	"io"
+	"io/fs"
 	"log/slog"
 	"path"
 	"regexp"
@@ -28,6 +31,9 @@ var (
 type archiveTest struct {
 	InFilePath  string
 	OutFilePath string
+	// Generated tests are kept in memory instead
+	InData  []byte
+	OutData []byte
 
 	VisibleID int
 	Key       string
@@ -42,6 +48,16 @@ func (t archiveTest) Matches(re *regexp.Regexp) bool {
 	return places[0] == 0
 }
 
+func (t archiveTest) hasInput() bool  { return t.InFilePath != "" || t.InData != nil }
+func (t archiveTest) hasOutput() bool { return t.OutFilePath != "" || t.OutData != nil }
+
+func openTestFile(fsys fs.FS, fpath string, data []byte) (io.ReadCloser, error) {
+	if fpath == "" {
+		return io.NopCloser(bytes.NewReader(data)), nil
+	}
+	return fsys.Open(fpath)
+}
+
 func ProcessTestInputFile(ctx *ArchiveCtx, fpath string) error {
 	testName := path.Base(fpath)
 	if slices.Contains(testInputSuffixes, path.Ext(testName)) {
@@ -63,6 +63,18 @@ func (e EvalConf) IsRemote() bool { return e.Mode == "remote" }
 type RemoteEvalConf struct {
 	Endpoint string `toml:"endpoint"` // grader base URL, e.g. https://grader:9000
 	Token    string `toml:"token"`    // grader-minted bearer token for this platform instance
+
+	// Graders, if set, replaces Endpoint/Token with a pool of graders that
+	// requests are load balanced across, failing over when one is unreachable.
+	Graders []RemoteGraderConf `toml:"grader"`
+}
+
+// RemoteGraderConf is one grader of a remote grader pool.
+type RemoteGraderConf struct {
+	Name     string `toml:"name"`     // unique name, used in logs and scratch identifiers
+	Endpoint string `toml:"endpoint"` // grader base URL
+	Token    string `toml:"token"`    // grader-minted bearer token for this platform instance
+	Capacity int    `toml:"capacity"` // usually the grader's num_concurrent; defaults to 1
 }
 
 // CommonConf is the data required for all services
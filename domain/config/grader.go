@@ -31,7 +31,8 @@ type GraderSection struct {
 }
 
 // GraderClientConf is one entry in the token registry: a named platform client
-// with its bearer token. Priority is reserved (documented, unconsumed).
+// with its bearer token. Priority is "low", "normal" (default), "high" or an
+// integer; queued box requests from higher priority clients are served first.
 type GraderClientConf struct {
 	Name     string `toml:"name"`
 	Token    string `toml:"token"`
@@ -365,11 +365,16 @@ func parseMetaFile(ctx context.Context, r io.Reader, out bytes.Buffer) *eval.Run
 		case "time":
 			file.Time, _ = strconv.ParseFloat(val, 64)
 		case "time-wall":
-			// file.WallTime, _ = strconv.ParseFloat(val, 32)
-			continue
+			file.WallTime, _ = strconv.ParseFloat(val, 64)
+		case "max-rss":
+			file.MaxRSS, _ = strconv.Atoi(val)
+		case "csw-voluntary":
+			file.VoluntaryCSW, _ = strconv.Atoi(val)
+		case "csw-forced":
+			file.ForcedCSW, _ = strconv.Atoi(val)
 		case "cg-oom-killed":
 			file.MemoryLimitExceeded = true
-		case "max-rss", "csw-voluntary", "csw-forced", "cg-enabled":
+		case "cg-enabled":
 			continue
 		default:
 			slog.InfoContext(ctx, "Unknown isolate stat", slog.String("key", key), slog.String("value", val))
@@ -43,6 +43,11 @@ type Multibox2Request struct {
 	// UseStdin is true if the user sandboxes read from stdin and write to stdout.
 	// Otherwise, user processes read from and write to fifos whose paths are given as extra arguments.
 	UseStdin bool
+
+	// Transcript, if set, is where the communication between the manager and the user sandboxes is saved,
+	// capped at TranscriptLimit bytes. It is listed in the response's BucketFiles as eval.TranscriptPath.
+	Transcript      *BucketFile
+	TranscriptLimit int64
 }
 
 type Box2Response struct {
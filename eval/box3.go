@@ -1,6 +1,7 @@
 package eval
 
 import (
+	"context"
 	"io"
 	"io/fs"
 )
@@ -35,11 +36,62 @@ type Multibox3Request struct {
 	// UseStdin is true if the user sandboxes read from stdin and write to stdout.
 	// Otherwise, user processes read from and write to fifos whose paths are given as extra arguments.
 	UseStdin bool
+
+	// TranscriptLimit enables recording what the manager and the user sandboxes send each other, if positive.
+	// The transcript is cut off after TranscriptLimit bytes and returned in the response's Files, under TranscriptPath.
+	TranscriptLimit int64
 }
 
+// TranscriptPath is the key of a Multibox3Request's transcript in Box3Response.Files.
+// It is not a sandbox path, so it can't clash with the manager's output files.
+const TranscriptPath = "<transcript>"
+
 type Box3Response struct {
 	Stats *RunStats
 
 	// Files maps the output file path to a scratch identifier
 	Files map[string]string
 }
+
+type Box3EventKind int
+
+const (
+	// Box3Queued is sent while the request waits for a free box.
+	Box3Queued Box3EventKind = iota + 1
+	// Box3Started is sent once the request's box starts running.
+	Box3Started
+	// Box3Progress carries interim stats while the box runs.
+	Box3Progress
+)
+
+// Box3Event reports the state of a Box3 request before it finishes.
+type Box3Event struct {
+	Kind Box3EventKind
+
+	// Stats is only set for Box3Progress events. Since the sandbox only
+	// reports real stats once it exits, Time is the elapsed wall time.
+	Stats *RunStats
+}
+
+// ProgressFunc receives the events of a running Box3 request, in order.
+type ProgressFunc func(*Box3Event)
+
+// Box3StreamScheduler is implemented by Box3 schedulers that can report
+// progress while a request runs.
+type Box3StreamScheduler interface {
+	RunBox3Stream(ctx context.Context, req *Box3Request, memQuota int64, progress ProgressFunc) (*Box3Response, error)
+}
+
+type progressKey struct{}
+
+// WithProgress attaches a ProgressFunc to ctx. Box2 requests run with this
+// context report their progress to it, if the underlying scheduler supports it.
+func WithProgress(ctx context.Context, progress ProgressFunc) context.Context {
+	return context.WithValue(ctx, progressKey{}, progress)
+}
+
+// ProgressFromContext returns the ProgressFunc attached to ctx, or nil.
+func ProgressFromContext(ctx context.Context) ProgressFunc {
+	progress, _ := ctx.Value(progressKey{}).(ProgressFunc)
+	return progress
+}
@@ -0,0 +1,148 @@
+package checkers
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/KiloProjects/kilonova"
+	"github.com/KiloProjects/kilonova/domain/datastore"
+	"github.com/KiloProjects/kilonova/eval/language"
+	"github.com/shopspring/decimal"
+)
+
+// maxTokenSize bounds the length of a single token or line read by the built-in checker
+const maxTokenSize = 64 * 1024 * 1024
+
+var _ Checker = &BuiltinChecker{}
+
+// BuiltinChecker compares outputs in-process according to the problem's checker mode.
+// The default diff mode is handled by DiffChecker instead.
+type BuiltinChecker struct {
+	Store *datastore.Manager
+
+	Mode    kilonova.CheckerMode
+	Epsilon float64
+}
+
+func (b *BuiltinChecker) Language() language.GraderLang {
+	return nil
+}
+
+func (b *BuiltinChecker) Prepare(_ context.Context) (string, error) { return "", nil }
+
+func (b *BuiltinChecker) Cleanup(_ context.Context) error { return nil }
+
+func (b *BuiltinChecker) CodeFilename() string { return "" }
+
+func (b *BuiltinChecker) RunChecker(ctx context.Context, subtestID int, testID int) (string, decimal.Decimal) {
+	output, err := b.Store.Subtests().Reader(strconv.Itoa(subtestID))
+	if err != nil {
+		return ErrOut, decimal.Zero
+	}
+	defer output.Close()
+
+	answer, err := b.Store.Tests().Reader(strconv.Itoa(testID) + ".out")
+	if err != nil {
+		return ErrOut, decimal.Zero
+	}
+	defer answer.Close()
+
+	ok, err := compareOutputs(b.Mode, b.Epsilon, output, answer)
+	if err != nil {
+		slog.WarnContext(ctx, "Built-in checker failed", slog.Any("mode", b.Mode), slog.Any("err", err))
+		return ErrOut, decimal.Zero
+	}
+	if !ok {
+		return WrongOut, decimal.Zero
+	}
+	return CorrectOut, decimal.NewFromInt(100)
+}
+
+// compareOutputs reports whether the contestant's output matches the answer under the given mode.
+// An error is returned only if the answer couldn't be read.
+func compareOutputs(mode kilonova.CheckerMode, epsilon float64, output, answer io.Reader) (bool, error) {
+	switch mode {
+	case kilonova.CheckerModeTokens:
+		return compareTokens(output, answer, func(out, ans string) bool { return out == ans })
+	case kilonova.CheckerModeCaseInsensitive:
+		return compareTokens(output, answer, strings.EqualFold)
+	case kilonova.CheckerModeFloat:
+		return compareTokens(output, answer, func(out, ans string) bool { return floatTokensEqual(out, ans, epsilon) })
+	case kilonova.CheckerModeUnorderedLines:
+		return compareUnorderedLines(output, answer)
+	default:
+		return false, errors.New("unknown checker mode")
+	}
+}
+
+func newScanner(r io.Reader, split bufio.SplitFunc) *bufio.Scanner {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 0, 64*1024), maxTokenSize)
+	sc.Split(split)
+	return sc
+}
+
+func compareTokens(output, answer io.Reader, equal func(out, ans string) bool) (bool, error) {
+	out, ans := newScanner(output, bufio.ScanWords), newScanner(answer, bufio.ScanWords)
+	for ans.Scan() {
+		if !out.Scan() || !equal(out.Text(), ans.Text()) {
+			return false, nil
+		}
+	}
+	if err := ans.Err(); err != nil {
+		return false, err
+	}
+	// The contestant's output must not have extra tokens. A read error (ie. a huge token) is also a mismatch.
+	return !out.Scan() && out.Err() == nil, nil
+}
+
+// floatTokensEqual compares the tokens as numbers if the answer is a number, allowing for
+// an absolute or a relative error of at most epsilon. Other tokens must match exactly.
+func floatTokensEqual(out, ans string, epsilon float64) bool {
+	expected, err := strconv.ParseFloat(ans, 64)
+	if err != nil {
+		return out == ans
+	}
+	got, err := strconv.ParseFloat(out, 64)
+	if err != nil || math.IsNaN(got) {
+		return false
+	}
+	if math.IsInf(expected, 0) || math.IsInf(got, 0) {
+		return expected == got
+	}
+	diff := math.Abs(got - expected)
+	return diff <= epsilon || diff <= epsilon*math.Abs(expected)
+}
+
+func compareUnorderedLines(output, answer io.Reader) (bool, error) {
+	ansLines, err := normalizedLines(answer)
+	if err != nil {
+		return false, err
+	}
+	outLines, err := normalizedLines(output)
+	if err != nil {
+		return false, nil
+	}
+	slices.Sort(ansLines)
+	slices.Sort(outLines)
+	return slices.Equal(outLines, ansLines), nil
+}
+
+// normalizedLines returns the non-empty lines of r, with their whitespace collapsed
+func normalizedLines(r io.Reader) ([]string, error) {
+	var lines []string
+	sc := newScanner(r, bufio.ScanLines)
+	for sc.Scan() {
+		if fields := strings.Fields(sc.Text()); len(fields) > 0 {
+			lines = append(lines, strings.Join(fields, " "))
+		}
+	}
+	return lines, sc.Err()
+}
@@ -0,0 +1,43 @@
+package checkers
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/KiloProjects/kilonova"
+)
+
+func TestCompareOutputs(t *testing.T) {
+	tests := []struct {
+		name    string
+		mode    kilonova.CheckerMode
+		epsilon float64
+		output  string
+		answer  string
+		want    bool
+	}{
+		{"tokens ignore whitespace", kilonova.CheckerModeTokens, 0, "1  2\n\n3", "1 2 3\n", true},
+		{"tokens extra output", kilonova.CheckerModeTokens, 0, "1 2 3 4", "1 2 3", false},
+		{"tokens missing output", kilonova.CheckerModeTokens, 0, "1 2", "1 2 3", false},
+		{"tokens case sensitive", kilonova.CheckerModeTokens, 0, "yes", "YES", false},
+		{"case insensitive", kilonova.CheckerModeCaseInsensitive, 0, "yes\nNo", "YES no", true},
+		{"float absolute error", kilonova.CheckerModeFloat, 1e-6, "0.0000005", "0", true},
+		{"float relative error", kilonova.CheckerModeFloat, 1e-6, "1000000.5", "1000000", true},
+		{"float too far", kilonova.CheckerModeFloat, 1e-6, "1.001", "1", false},
+		{"float non-number token", kilonova.CheckerModeFloat, 1e-6, "YES 3.14159", "YES 3.141592", true},
+		{"float invalid number", kilonova.CheckerModeFloat, 1e-6, "nan", "1", false},
+		{"unordered lines", kilonova.CheckerModeUnorderedLines, 0, "3 4\n1  2\n\n", "1 2\n3 4\n", true},
+		{"unordered lines multiset", kilonova.CheckerModeUnorderedLines, 0, "1 2\n1 2\n", "1 2\n3 4\n", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := compareOutputs(tt.mode, tt.epsilon, strings.NewReader(tt.output), strings.NewReader(tt.answer))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("got %t, want %t", got, tt.want)
+			}
+		})
+	}
+}
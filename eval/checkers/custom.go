@@ -54,7 +54,7 @@ type customChecker struct {
 
 	store *datastore.Manager
 
-	legacy bool
+	protocol kilonova.CheckerProtocol
 }
 
 // TODO: Remove
@@ -66,10 +66,29 @@ func (c *customChecker) CodeFilename() string {
 	return c.filename
 }
 
+// binaryName is the checker's compiled file in the checkers bucket.
+// testlib checkers are compiled with a different header, so they must not reuse the binary of another protocol.
+func (c *customChecker) binaryName() string {
+	if c.protocol == kilonova.CheckerProtocolTestlib {
+		return fmt.Sprintf("%d.testlib.bin", c.pb.ID)
+	}
+	return fmt.Sprintf("%d.bin", c.pb.ID)
+}
+
+// testlibHeader returns the testlib.h version matching the checker's protocol.
+// Kilonova's testlib reports in the standard format when KNOVA is defined (which all compile commands do),
+// so it must be undefined to get upstream testlib's exit codes and argument order.
+func (c *customChecker) testlibHeader() []byte {
+	if c.protocol == kilonova.CheckerProtocolTestlib {
+		return append([]byte("#undef KNOVA\n"), testlibFile...)
+	}
+	return testlibFile
+}
+
 // Prepare compiles the checker for the submission
 func (c *customChecker) Prepare(ctx context.Context) (string, error) {
 	var shouldCompile bool
-	modtime, err := c.store.Checkers().Modtime(fmt.Sprintf("%d.bin", c.pb.ID))
+	modtime, err := c.store.Checkers().Modtime(c.binaryName())
 	if err != nil {
 		if !errors.Is(err, fs.ErrNotExist) {
 			slog.WarnContext(ctx, "Checker stat error", slog.Any("err", err))
@@ -98,13 +117,13 @@ func (c *customChecker) Prepare(ctx context.Context) (string, error) {
 	resp, err := tasks.CompileTask(ctx, c.mgr, &tasks.CompileRequest{
 		File: &eval.BucketFile{
 			Bucket:   datastore.BucketTypeCheckers,
-			Filename: fmt.Sprintf("%d.bin", c.pb.ID),
+			Filename: c.binaryName(),
 			Mode:     0777,
 		},
 		CodeFiles: map[string][]byte{
 			lang.SourceName(c.filename): c.code,
 		}, HeaderFiles: map[string][]byte{
-			"/box/testlib.h": testlibFile,
+			"/box/testlib.h": c.testlibHeader(),
 		},
 		Lang: lang,
 
@@ -130,8 +149,13 @@ func (c *customChecker) RunChecker(ctx context.Context, subtestID int, testID in
 	defer checkerPrepareMu.RUnlock()
 
 	var task = standardCheckerTask
-	if c.legacy {
+	switch c.protocol {
+	case kilonova.CheckerProtocolLegacy:
 		task = legacyCheckerTask
+	case kilonova.CheckerProtocolTestlib:
+		task = testlibCheckerTask
+	case kilonova.CheckerProtocolCMS:
+		task = cmsCheckerTask
 	}
 
 	return task(ctx, c.mgr, c.langMgr, &customCheckerInput{
@@ -147,12 +171,8 @@ func (c *customChecker) Cleanup(_ context.Context) error {
 	return nil // eval.CleanCompilation(-c.sub.ID)
 }
 
-func NewLegacyCustomChecker(mgr eval.BoxScheduler, langMgr eval.LanguageManager, store *datastore.Manager, logger *slog.Logger, pb *kilonova.Problem, filename string, code []byte, subCode []byte, lastUpdatedAt time.Time) Checker {
-	return &customChecker{mgr, langMgr, pb, filename, code, subCode, lastUpdatedAt, logger, store, true}
-}
-
-func NewStandardCustomChecker(mgr eval.BoxScheduler, langMgr eval.LanguageManager, store *datastore.Manager, logger *slog.Logger, pb *kilonova.Problem, filename string, code []byte, subCode []byte, lastUpdatedAt time.Time) Checker {
-	return &customChecker{mgr, langMgr, pb, filename, code, subCode, lastUpdatedAt, logger, store, false}
+func NewCustomChecker(mgr eval.BoxScheduler, langMgr eval.LanguageManager, store *datastore.Manager, logger *slog.Logger, pb *kilonova.Problem, protocol kilonova.CheckerProtocol, filename string, code []byte, subCode []byte, lastUpdatedAt time.Time) Checker {
+	return &customChecker{mgr, langMgr, pb, filename, code, subCode, lastUpdatedAt, logger, store, protocol}
 }
 
 func initRequest(lang language.GraderLang, job *customCheckerInput) *eval.Box2Request {
@@ -175,7 +195,7 @@ func initRequest(lang language.GraderLang, job *customCheckerInput) *eval.Box2Re
 			},
 			lang.CompiledName(job.c.filename): {
 				Bucket:   datastore.BucketTypeCheckers,
-				Filename: fmt.Sprintf("%d.bin", job.c.pb.ID),
+				Filename: job.c.binaryName(),
 				Mode:     0777,
 			},
 		},
@@ -0,0 +1,63 @@
+package checkers
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"log/slog"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/KiloProjects/kilonova/eval"
+	"github.com/shopspring/decimal"
+)
+
+func cmsCheckerTask(ctx context.Context, mgr eval.BoxScheduler, langMgr eval.LanguageManager, job *customCheckerInput, logger *slog.Logger) (string, decimal.Decimal) {
+	lang := langMgr.LanguageFromFilename(job.c.filename)
+	if lang == nil {
+		return ErrOut, decimal.Zero
+	}
+
+	req := initRequest(lang, job)
+
+	req.Command = append(
+		lang.RunCommand([]string{lang.ExecuteName(job.c.filename)}, checkerMemoryLimit),
+		"/box/correct.in",
+		"/box/correct.out",
+		"/box/program.out",
+	)
+	req.RunConfig.OutputPath = "/box/verdict.out"
+	req.RunConfig.StderrPath = "/box/verdict.err"
+	req.OutputByteFiles = []string{"/box/verdict.out", "/box/verdict.err"}
+
+	resp, err := mgr.RunBox2(ctx, req, checkerMemoryLimit)
+	if resp == nil || err != nil {
+		return ErrOut, decimal.Zero
+	}
+
+	output, percentage := parseCMSVerdict(resp.Stats, resp.ByteFiles["/box/verdict.out"], resp.ByteFiles["/box/verdict.err"])
+	if output == ErrOut {
+		logger.WarnContext(ctx, "CMS checker failed", slog.Any("metadata", resp.Stats))
+	}
+	return output, percentage
+}
+
+// parseCMSVerdict parses the CMS standard manager output.
+// Unlike the standard protocol, CMS considers a checker that didn't exit successfully to have failed,
+// and only reads the first line of each stream.
+func parseCMSVerdict(stats *eval.RunStats, stdout, stderr []byte) (string, decimal.Decimal) {
+	if stats == nil || stats.Status != "" || stats.ExitCode != 0 {
+		return ErrOut, decimal.Zero
+	}
+
+	scoreLine, _, _ := bytes.Cut(stdout, []byte{'\n'})
+	score, err := strconv.ParseFloat(strings.TrimSpace(string(scoreLine)), 64)
+	if err != nil || math.IsInf(score, 0) || math.IsNaN(score) {
+		return "Invalid score", decimal.Zero
+	}
+	score = min(max(score, 0), 1)
+
+	message, _, _ := bytes.Cut(stderr, []byte{'\n'})
+	return cmp.Or(strings.TrimSpace(string(message)), "No message"), decimal.NewFromFloat(score).Shift(2)
+}
@@ -0,0 +1,46 @@
+package checkers
+
+import (
+	"testing"
+
+	"github.com/KiloProjects/kilonova/eval"
+)
+
+func TestParseTestlibVerdict(t *testing.T) {
+	tests := []struct {
+		name     string
+		stats    *eval.RunStats
+		stderr   string
+		wantMsg  string
+		wantPerc string
+	}{
+		{"ok", &eval.RunStats{}, "ok 3 numbers\n", "ok 3 numbers", "100"},
+		{"ok without message", &eval.RunStats{}, "", CorrectOut, "100"},
+		{"wrong answer", &eval.RunStats{Status: "RE", ExitCode: 1}, "wrong answer expected 3, found 4", "wrong answer expected 3, found 4", "0"},
+		{"presentation error", &eval.RunStats{Status: "RE", ExitCode: 2}, "", WrongOut, "0"},
+		{"fail", &eval.RunStats{Status: "RE", ExitCode: 3}, "FAIL answer is wrong", ErrOut, "0"},
+		{"points", &eval.RunStats{Status: "RE", ExitCode: 7}, "points 0.25 half of the queries", "half of the queries", "25"},
+		{"points out of range", &eval.RunStats{Status: "RE", ExitCode: 7}, "points 3", "translate:partial", "100"},
+		{"partially", &eval.RunStats{Status: "RE", ExitCode: 150}, "partially correct (100)", "partially correct (100)", "50"},
+		{"timeout", &eval.RunStats{Status: "TO"}, "", ErrOut, "0"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg, perc := parseTestlibVerdict(tt.stats, []byte(tt.stderr))
+			if msg != tt.wantMsg || perc.String() != tt.wantPerc {
+				t.Errorf("got (%q, %s), want (%q, %s)", msg, perc, tt.wantMsg, tt.wantPerc)
+			}
+		})
+	}
+}
+
+func TestParseCMSVerdict(t *testing.T) {
+	msg, perc := parseCMSVerdict(&eval.RunStats{}, []byte("0.5\ndebug output\n"), []byte("translate:partial\nmore\n"))
+	if msg != "translate:partial" || perc.String() != "50" {
+		t.Errorf("got (%q, %s), want only the first lines to be read", msg, perc)
+	}
+	msg, _ = parseCMSVerdict(&eval.RunStats{Status: "RE", ExitCode: 1}, []byte("1.0\n"), nil)
+	if msg != ErrOut {
+		t.Errorf("got %q, want a failing checker to be an internal error", msg)
+	}
+}
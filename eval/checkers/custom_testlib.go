@@ -0,0 +1,91 @@
+package checkers
+
+import (
+	"cmp"
+	"context"
+	"log/slog"
+	"strings"
+
+	"github.com/KiloProjects/kilonova/eval"
+	"github.com/shopspring/decimal"
+)
+
+// Exit codes of upstream testlib's verdicts
+const (
+	testlibOK            = 0
+	testlibWrongAnswer   = 1
+	testlibPresentation  = 2
+	testlibFail          = 3
+	testlibDirt          = 4
+	testlibPoints        = 7
+	testlibUnexpectedEOF = 8
+	// testlibPartiallyBase is the exit code of a checker quitting with _pc(0), when compiled with TESTSYS.
+	// _pc(200) means the full score.
+	testlibPartiallyBase = 50
+)
+
+func testlibCheckerTask(ctx context.Context, mgr eval.BoxScheduler, langMgr eval.LanguageManager, job *customCheckerInput, logger *slog.Logger) (string, decimal.Decimal) {
+	lang := langMgr.LanguageFromFilename(job.c.filename)
+	if lang == nil {
+		return ErrOut, decimal.Zero
+	}
+
+	req := initRequest(lang, job)
+
+	// testlib's argument order is <input-file> <output-file> <answer-file>
+	req.Command = append(
+		lang.RunCommand([]string{lang.ExecuteName(job.c.filename)}, checkerMemoryLimit),
+		"/box/correct.in",
+		"/box/program.out",
+		"/box/correct.out",
+	)
+	req.RunConfig.StderrPath = "/box/verdict.err"
+	req.OutputByteFiles = []string{"/box/verdict.err"}
+
+	resp, err := mgr.RunBox2(ctx, req, checkerMemoryLimit)
+	if resp == nil || err != nil {
+		return ErrOut, decimal.Zero
+	}
+
+	output, percentage := parseTestlibVerdict(resp.Stats, resp.ByteFiles["/box/verdict.err"])
+	if output == ErrOut {
+		logger.WarnContext(ctx, "testlib checker failed", slog.Any("metadata", resp.Stats), slog.String("stderr", string(resp.ByteFiles["/box/verdict.err"])))
+	}
+	return output, percentage
+}
+
+// parseTestlibVerdict maps the exit code and stderr of an upstream testlib checker to a message and a percentage
+func parseTestlibVerdict(stats *eval.RunStats, stderr []byte) (string, decimal.Decimal) {
+	if stats == nil {
+		return ErrOut, decimal.Zero
+	}
+	switch stats.Status {
+	case "TO", "SG", "XX":
+		return ErrOut, decimal.Zero
+	}
+
+	message := strings.TrimSpace(string(stderr))
+	switch code := stats.ExitCode; {
+	case code == testlibOK:
+		return cmp.Or(message, CorrectOut), decimal.NewFromInt(100)
+	case code == testlibWrongAnswer || code == testlibPresentation || code == testlibDirt || code == testlibUnexpectedEOF:
+		return cmp.Or(message, WrongOut), decimal.Zero
+	case code == testlibPoints:
+		// quitp writes "points <score> <message>", where score is in [0, 1]
+		rest, _ := strings.CutPrefix(message, "points ")
+		points, message, _ := strings.Cut(rest, " ")
+		score, err := decimal.NewFromString(points)
+		if err != nil {
+			return "Invalid checker points", decimal.Zero
+		}
+		score = decimal.Min(decimal.Max(score, decimal.Zero), decimal.NewFromInt(1))
+		return cmp.Or(strings.TrimSpace(message), "translate:partial"), score.Shift(2)
+	case code >= testlibPartiallyBase && code <= testlibPartiallyBase+200:
+		return cmp.Or(message, "translate:partial"), decimal.NewFromInt(int64(code - testlibPartiallyBase)).Div(decimal.NewFromInt(2))
+	case code == testlibFail:
+		// The checker itself found an error, such as an invalid correct output
+		return ErrOut, decimal.Zero
+	default:
+		return ErrOut, decimal.Zero
+	}
+}
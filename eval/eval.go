@@ -67,6 +67,12 @@ type Box3Scheduler interface {
 	Close(ctx context.Context) error
 }
 
+// CapacityScheduler is implemented by schedulers that know how many requests they run at once.
+type CapacityScheduler interface {
+	// Capacity returns 0 if the capacity is not known
+	Capacity() int
+}
+
 type LanguageManager interface {
 	Language(name string) language.GraderLang
 	Languages() map[string]language.GraderLang
@@ -107,9 +113,16 @@ type RunStats struct {
 	Message string `json:"message"`
 	Status  string `json:"status"`
 
-	Time float64 `json:"time"`
+	Time     float64 `json:"time"`
+	WallTime float64 `json:"wall_time"`
 
 	InternalMessage string `json:"internal_msg"`
-	// WallTime float64 `json:"wall_time"`
+	// MaxRSS is the peak resident set size, in kilobytes
+	MaxRSS int `json:"max_rss"`
+	// VoluntaryCSW counts the context switches caused by the program waiting (for I/O, sleeping etc.),
+	// while ForcedCSW counts the ones caused by the scheduler preempting it
+	VoluntaryCSW int `json:"csw_voluntary"`
+	ForcedCSW    int `json:"csw_forced"`
+
 	MemoryLimitExceeded bool `json:"memory_limit_exceeded"`
 }
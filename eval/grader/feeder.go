@@ -3,6 +3,7 @@ package grader
 import (
 	"context"
 	"log/slog"
+	"os"
 	"path"
 	"sync"
 	"time"
@@ -35,6 +36,8 @@ type Handler struct {
 	wakeChan chan struct{}
 
 	runner eval.BoxScheduler
+	// stagingDir is the local scratch directory of the remote grader pool, if one is used
+	stagingDir string
 }
 
 func NewHandler(ctx context.Context, base *sudoapi.BaseAPI) (*Handler, error) {
@@ -57,7 +60,7 @@ func NewHandler(ctx context.Context, base *sudoapi.BaseAPI) (*Handler, error) {
 		}))
 	})
 
-	return &Handler{ctx, ch, base, wCh, nil}, nil
+	return &Handler{ctx, ch, base, wCh, nil, ""}, nil
 }
 
 func (h *Handler) Wake() {
@@ -67,6 +70,10 @@ func (h *Handler) Wake() {
 	}
 }
 
+func (h *Handler) Runner() eval.BoxScheduler {
+	return h.runner
+}
+
 func (h *Handler) runSubmission(runner eval.BoxScheduler, langMgr eval.LanguageManager, sub *kilonova.Submission) error {
 	// TODO: Do this in a smarter way
 	// var numConc int64
@@ -163,6 +170,9 @@ func (h *Handler) Start() error {
 	}
 
 	h.runner = runner
+	if h.stagingDir != "" {
+		defer os.RemoveAll(h.stagingDir)
+	}
 
 	h.base.RegisterGrader(h) // To allow waking from outside grader
 	h.base.RegisterLanguageManager(langMgr)
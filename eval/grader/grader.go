@@ -1,6 +1,7 @@
 package grader
 
 import (
+	"archive/zip"
 	"cmp"
 	"context"
 	"errors"
@@ -50,6 +51,9 @@ type submissionHandler struct {
 	files    []*kilonova.SubmissionFile
 
 	lang language.GraderLang
+
+	// outputs maps visible test IDs to their files in an output only submission
+	outputs map[int]*zip.File
 }
 
 func (sh *submissionHandler) getCode() []byte {
@@ -147,11 +151,6 @@ func executeSubmission(ctx context.Context, base *sudoapi.BaseAPI, runner eval.B
 		sh.lang = langMgr.Language("outputOnly")
 	}
 
-	if sh.lang == nil {
-		slog.WarnContext(ctx, "Could not find submission language when evaluating", slog.String("lang", sub.Language))
-		return fmt.Errorf("language not found for submission")
-	}
-
 	defer func() {
 		err := sh.markSubtestsDone(ctx)
 		if err != nil {
@@ -177,18 +176,39 @@ func executeSubmission(ctx context.Context, base *sudoapi.BaseAPI, runner eval.B
 	sh.pb = problem
 	sh.settings = problemSettings
 	sh.files = files
-	if err := sh.compileSubmission(ctx); err != nil {
-		if kilonova.ErrorCode(err) != 204 { // Skip
-			slog.WarnContext(ctx, "Non-skip error code", slog.Any("err", err))
-			return err
+
+	// Output only submissions aren't run, so they don't need a grader language
+	if sh.lang == nil && problem.TaskType != kilonova.TaskTypeOutputOnly {
+		slog.WarnContext(ctx, "Could not find submission language when evaluating", slog.String("lang", sub.Language))
+		return fmt.Errorf("language not found for submission")
+	}
+
+	if problem.TaskType == kilonova.TaskTypeOutputOnly {
+		// Nothing to compile, the outputs are checked straight from the submitted archive
+		sh.loadOutputArchive(ctx)
+		if err := base.UpdateSubmission(ctx, sub.ID, kilonova.SubmissionUpdate{CompileError: new(false)}); err != nil {
+			return fmt.Errorf("couldn't update submission: %w", err)
+		}
+	} else {
+		// Remote grader pools must route this submission to a grader that has its language
+		ctx = scheduler.WithLanguages(ctx, sh.lang.InternalName())
+
+		if err := sh.compileSubmission(ctx); err != nil {
+			if kilonova.ErrorCode(err) != 204 { // Skip
+				slog.WarnContext(ctx, "Non-skip error code", slog.Any("err", err))
+				return err
+			}
+			return nil
 		}
-		return nil
 	}
 
 	checker, err := sh.getAppropriateChecker(ctx)
 	if err != nil {
 		return fmt.Errorf("couldn't get checker: %w", err)
 	}
+	if lang := checker.Language(); lang != nil {
+		ctx = scheduler.WithLanguages(ctx, lang.InternalName())
+	}
 
 	if info, err := checker.Prepare(ctx); err != nil {
 		info = "Checker compile error:\n" + info
@@ -202,6 +222,23 @@ func executeSubmission(ctx context.Context, base *sudoapi.BaseAPI, runner eval.B
 		return fmt.Errorf("could not prepare checker: %w", err)
 	}
 
+	if problem.TaskType == kilonova.TaskTypeTwoStep {
+		if lang := sh.twoStepManagerLang(); lang != nil {
+			ctx = scheduler.WithLanguages(ctx, lang.InternalName())
+		}
+		if info, err := sh.prepareTwoStepManager(ctx); err != nil {
+			info = "Manager compile error:\n" + info
+			if err := base.UpdateSubmission(ctx, sub.ID, kilonova.SubmissionUpdate{
+				Status: kilonova.StatusFinished, Score: &problem.DefaultPoints,
+				CompileError: new(true), CompileMessage: &info,
+				ChangeVerdict: true, ICPCVerdict: new("test_verdict.internal_error"),
+			}); err != nil {
+				return fmt.Errorf("error during update of compile information: %w", err)
+			}
+			return fmt.Errorf("could not prepare two-step manager: %w", err)
+		}
+	}
+
 	subTests, err := base.SubTests(ctx, sub.ID)
 	if err != nil {
 		if err := base.UpdateSubmission(ctx, sub.ID, kilonova.SubmissionUpdate{
@@ -213,11 +250,6 @@ func executeSubmission(ctx context.Context, base *sudoapi.BaseAPI, runner eval.B
 		return fmt.Errorf("could not fetch subtests: %w", err)
 	}
 
-	// TODO: Go through tests in topological sort
-	// if _, err := sh.buildRunGraph(ctx, subTests); err != nil {
-	//	slog.WarnContext(ctx, "Error building experimental run graph", slog.Any("err", err))
-	// }
-
 	// TODO: This is shit.
 	// It is basically 2 implementations for ~ the same thing. It could be merged neater
 	switch sub.SubmissionType {
@@ -246,14 +278,36 @@ func executeSubmission(ctx context.Context, base *sudoapi.BaseAPI, runner eval.B
 }
 
 func (sh *submissionHandler) handleClassicSubmission(ctx context.Context, checker checkers.Checker, subTests []*kilonova.SubTest) error {
-	var wg sync.WaitGroup
-
-	for _, subTest := range subTests {
+	subTasks, err := sh.base.SubmissionSubTasks(ctx, sh.sub.ID)
+	if err != nil {
+		return fmt.Errorf("couldn't get submission subtasks: %w", err)
+	}
+	graph := buildRunGraph(subTests, subTasks)
 
+	var wg sync.WaitGroup
+	slots := make(chan struct{}, evalParallelism(sh.runner))
+	for _, subTest := range graph.order {
+		slots <- struct{}{}
 		wg.Go(func() {
-			_, _, err := sh.handleSubTest(ctx, checker, subTest)
+			defer func() { <-slots }()
+			if graph.skippable(subTest.ID) {
+				if err := sh.base.UpdateSubTest(ctx, sh.sub.ID, subTest.ID, kilonova.SubTestUpdate{
+					Done: new(true), Skipped: new(true),
+					Verdict: new(skippedVerdict),
+				}); err != nil {
+					slog.WarnContext(ctx, "Couldn't update skipped subtest", slog.Any("err", err))
+				}
+				return
+			}
+			score, _, err := sh.handleSubTest(ctx, checker, subTest)
 			if err != nil {
+				// The subtest will be scored as 0, just like a failed one
 				slog.WarnContext(ctx, "Error handling subtest", slog.Any("err", err))
+				graph.markFailed(subTest.ID)
+				return
+			}
+			if score.IsZero() {
+				graph.markFailed(subTest.ID)
 			}
 		})
 	}
@@ -275,7 +329,7 @@ func (sh *submissionHandler) handleICPCSubmission(ctx context.Context, checker c
 
 	for _, subTest := range subTests {
 		if failed {
-			if err := sh.base.UpdateSubTest(ctx, subTest.ID, kilonova.SubTestUpdate{
+			if err := sh.base.UpdateSubTest(ctx, sh.sub.ID, subTest.ID, kilonova.SubTestUpdate{
 				Done: new(true), Skipped: new(true),
 				Verdict: new(skippedVerdict),
 			}); err != nil {
@@ -386,6 +440,16 @@ func (sh *submissionHandler) handleSubTest(ctx context.Context, checker checkers
 		if err != nil {
 			return decimal.Zero, "", err
 		}
+	case kilonova.TaskTypeOutputOnly:
+		output, err = sh.handleOutputOnlySubTest(ctx, checker, subTest)
+		if err != nil {
+			return decimal.Zero, "", err
+		}
+	case kilonova.TaskTypeTwoStep:
+		output, err = sh.handleTwoStepSubTest(ctx, checker, subTest)
+		if err != nil {
+			return decimal.Zero, "", err
+		}
 	default:
 		return decimal.Zero, "", errors.New("invalid task type")
 	}
@@ -400,7 +464,10 @@ func (sh *submissionHandler) handleSubTest(ctx context.Context, checker checkers
 		}
 	}
 
-	if err := sh.base.UpdateSubTest(ctx, subTest.ID, kilonova.SubTestUpdate{Memory: &output.Memory, Percentage: &output.Score, Time: &output.Time, Verdict: &output.Comments, Done: new(true)}); err != nil {
+	if err := sh.base.UpdateSubTest(ctx, sh.sub.ID, subTest.ID, kilonova.SubTestUpdate{
+		Memory: &output.Memory, Percentage: &output.Score, Time: &output.Time, Verdict: &output.Comments, Done: new(true),
+		WallTime: &output.WallTime, MaxRSS: &output.MaxRSS, VoluntaryCSW: &output.VoluntaryCSW, ForcedCSW: &output.ForcedCSW,
+	}); err != nil {
 		return decimal.Zero, "", fmt.Errorf("error during evaltest updating: %w", err)
 	}
 
@@ -412,9 +479,14 @@ type subtestOutput struct {
 	Time     float64
 	Comments string
 	Score    decimal.Decimal
+
+	WallTime     float64
+	MaxRSS       int
+	VoluntaryCSW int
+	ForcedCSW    int
 }
 
-func (sh *submissionHandler) handleBatchSubTest(ctx context.Context, checker checkers.Checker, subTest *kilonova.SubTest) (*subtestOutput, error) {
+func (sh *submissionHandler) batchRequest(subTest *kilonova.SubTest) *tasks.BatchRequest {
 	memoryLimit := int(float64(sh.pb.MemoryLimit) * cmp.Or(sh.lang.MemoryLimitMultiplier(), 1.0))
 	if sh.lang.InternalName() == "python3" {
 		memoryLimit = max(memoryLimit, 8*1024)
@@ -448,12 +520,18 @@ func (sh *submissionHandler) handleBatchSubTest(ctx context.Context, checker che
 		execRequest.InputName = "stdin"
 		execRequest.OutputName = "stdout"
 	}
+	return execRequest
+}
+
+func (sh *submissionHandler) handleBatchSubTest(ctx context.Context, checker checkers.Checker, subTest *kilonova.SubTest) (*subtestOutput, error) {
+	execRequest := sh.batchRequest(subTest)
 
-	resp, err := tasks.ExecuteBatch(ctx, sh.runner, int64(memoryLimit), execRequest, graderLogger)
+	// Only the user's program reports progress, the checker runs with the original context
+	execCtx := eval.WithProgress(ctx, sh.subTestProgress(ctx, subTest))
+	resp, err := tasks.ExecuteBatch(execCtx, sh.runner, int64(execRequest.MemoryLimit), execRequest, graderLogger)
 	if err != nil {
 		return nil, fmt.Errorf("couldn't execute subtest: %w", err)
 	}
-	var testScore decimal.Decimal
 
 	// Make sure TLEs are fully handled
 	if resp.Time > sh.pb.TimeLimit {
@@ -461,18 +539,59 @@ func (sh *submissionHandler) handleBatchSubTest(ctx context.Context, checker che
 		resp.Comments = "translate:timeout"
 	}
 
+	return sh.checkBatchOutput(ctx, checker, subTest, resp), nil
+}
+
+// checkBatchOutput runs the checker on the subtest's output, unless the execution already failed
+func (sh *submissionHandler) checkBatchOutput(ctx context.Context, checker checkers.Checker, subTest *kilonova.SubTest, resp *tasks.BatchResponse) *subtestOutput {
+	var testScore decimal.Decimal
 	if resp.Comments == "" {
 		resp.Comments, testScore = checker.RunChecker(ctx, subTest.ID, *subTest.TestID)
 	}
 
-	output := &subtestOutput{
+	return &subtestOutput{
 		Memory:   resp.Memory,
 		Time:     resp.Time,
 		Comments: resp.Comments,
 		Score:    testScore,
+
+		WallTime:     resp.WallTime,
+		MaxRSS:       resp.MaxRSS,
+		VoluntaryCSW: resp.VoluntaryCSW,
+		ForcedCSW:    resp.ForcedCSW,
 	}
+}
 
-	return output, nil
+// subTestProgressInterval is the minimum time between two interim time updates of a subtest.
+// Boxes report their progress every second, but every update is a database write.
+const subTestProgressInterval = 5 * time.Second
+
+// subTestProgress mirrors the state of the subtest's box into the (not yet done) subtest,
+// so the submission page can tell a test waiting for a sandbox apart from a running one.
+// The final update in handleSubTest overwrites the interim verdict and time.
+func (sh *submissionHandler) subTestProgress(ctx context.Context, subTest *kilonova.SubTest) eval.ProgressFunc {
+	// Events are delivered in order, one at a time, so lastUpdate needs no locking
+	var lastUpdate time.Time
+	return func(ev *eval.Box3Event) {
+		var upd kilonova.SubTestUpdate
+		switch ev.Kind {
+		case eval.Box3Queued:
+			upd.Verdict = new("translate:waiting_box")
+		case eval.Box3Started:
+			upd.Verdict = new("translate:running")
+			lastUpdate = time.Now()
+		case eval.Box3Progress:
+			if ev.Stats == nil || time.Since(lastUpdate) < subTestProgressInterval {
+				return
+			}
+			upd.Time = new(min(ev.Stats.Time, sh.pb.TimeLimit))
+			lastUpdate = time.Now()
+		default:
+			return
+		}
+		// Progress is best-effort, UpdateSubTest already logs failures
+		_ = sh.base.UpdateSubTest(ctx, sh.sub.ID, subTest.ID, upd)
+	}
 }
 
 func (sh *submissionHandler) handleCommunicationSubTest(ctx context.Context, checker checkers.Checker, subTest *kilonova.SubTest) (*subtestOutput, error) {
@@ -505,6 +624,14 @@ func (sh *submissionHandler) handleCommunicationSubTest(ctx context.Context, che
 		TestID:           *subTest.TestID,
 		NumUserSandboxes: int64(sh.pb.CommunicationProcesses),
 	}
+	if limit := flags.CommunicationTranscriptSize.Value(); limit > 0 {
+		execRequest.TranscriptFile = &eval.BucketFile{
+			Bucket:   datastore.BucketTypeSubtests,
+			Filename: strconv.Itoa(subTest.ID) + ".transcript",
+			Mode:     0644,
+		}
+		execRequest.TranscriptLimit = int64(limit) * 1024
+	}
 
 	if execRequest.CheckerLang == nil {
 		return nil, fmt.Errorf("checker language not found")
@@ -526,6 +653,11 @@ func (sh *submissionHandler) handleCommunicationSubTest(ctx context.Context, che
 		Time:     resp.Time,
 		Comments: resp.Comments,
 		Score:    resp.Score,
+
+		WallTime:     resp.WallTime,
+		MaxRSS:       resp.MaxRSS,
+		VoluntaryCSW: resp.VoluntaryCSW,
+		ForcedCSW:    resp.ForcedCSW,
 	}
 
 	return output, nil
@@ -540,7 +672,7 @@ func (sh *submissionHandler) markSubtestsDone(ctx context.Context) error {
 		if st.Done {
 			continue
 		}
-		if err := sh.base.UpdateSubTest(ctx, st.ID, kilonova.SubTestUpdate{Done: new(true)}); err != nil {
+		if err := sh.base.UpdateSubTest(ctx, sh.sub.ID, st.ID, kilonova.SubTestUpdate{Done: new(true)}); err != nil {
 			slog.WarnContext(ctx, "Couldn't mark subtest done", slog.Any("subtest", st), slog.Any("err", err))
 		}
 	}
@@ -657,6 +789,9 @@ func (h *Handler) getRemoteRunner(ctx context.Context) (eval.BoxScheduler, eval.
 	// hanging (design D2). ponytail: 60s covers the largest test file; lift to a
 	// config field if real transfers approach it.
 	scratchClient := &http.Client{Timeout: 60 * time.Second}
+	if len(rc.Graders) > 0 {
+		return h.getRemotePoolRunner(ctx, scratchClient, rc.Graders)
+	}
 	remoteScratch, err := scratch.NewHTTP(scratchClient, rc.Endpoint+"/scratch", rc.Token)
 	if err != nil {
 		return nil, nil, fmt.Errorf("couldn't set up remote scratch: %w", err)
@@ -672,8 +807,57 @@ func (h *Handler) getRemoteRunner(ctx context.Context) (eval.BoxScheduler, eval.
 	return scheduler.NewBox2Wrapper(remoteScratch, h.base.DataStore(), client), langMgr, nil
 }
 
+// getRemotePoolRunner is getRemoteRunner for several graders: a GraderPool
+// routes each request to a grader and a local staging scratch holds inputs
+// until one is picked.
+func (h *Handler) getRemotePoolRunner(ctx context.Context, scratchClient *http.Client, graders []config.RemoteGraderConf) (eval.BoxScheduler, eval.LanguageManager, error) {
+	members := make([]scheduler.PoolMember, 0, len(graders))
+	for _, g := range graders {
+		remoteScratch, err := scratch.NewHTTP(scratchClient, g.Endpoint+"/scratch", g.Token)
+		if err != nil {
+			return nil, nil, fmt.Errorf("couldn't set up remote scratch for grader %q: %w", g.Name, err)
+		}
+		members = append(members, scheduler.PoolMember{
+			Name:     g.Name,
+			Client:   scheduler.NewGraderClient(http.DefaultClient, g.Endpoint, g.Token),
+			Scratch:  remoteScratch,
+			Capacity: g.Capacity,
+		})
+	}
+
+	baseDir := path.Join(os.TempDir(), uuid.New().String())
+	if err := os.MkdirAll(baseDir, 0777); err != nil {
+		return nil, nil, fmt.Errorf("couldn't create staging scratch dir: %w", err)
+	}
+	staging := scratch.New(afero.NewBasePathFs(afero.NewOsFs(), baseDir))
+
+	pool, err := scheduler.NewGraderPool(ctx, members, staging, graderLogger)
+	if err != nil {
+		os.RemoveAll(baseDir)
+		return nil, nil, err
+	}
+	langMgr, err := scheduler.NewRemoteLanguageManager(ctx, pool, graderLogger)
+	if err != nil {
+		pool.Close(ctx)
+		os.RemoveAll(baseDir)
+		return nil, nil, fmt.Errorf("couldn't fetch remote language inventory: %w", err)
+	}
+	// Removed by Start once the handler stops
+	h.stagingDir = baseDir
+
+	slog.InfoContext(ctx, "Running remote grader pool", slog.Int("graders", len(members)))
+	return scheduler.NewBox2Wrapper(pool.Scratch(), h.base.DataStore(), pool), langMgr, nil
+}
+
 func (sh *submissionHandler) getAppropriateChecker(ctx context.Context) (checkers.Checker, error) {
 	if sh.settings.CheckerName == "" {
+		if mode := sh.settings.CheckerMode; mode != kilonova.CheckerModeNone && mode != kilonova.CheckerModeDiff {
+			return &checkers.BuiltinChecker{
+				Store:   sh.base.DataStore(),
+				Mode:    mode,
+				Epsilon: sh.settings.CheckerEpsilon,
+			}, nil
+		}
 		return &checkers.DiffChecker{Store: sh.base.DataStore()}, nil
 	}
 	att, err := sh.base.ProblemAttByName(ctx, sh.pb.ID, sh.settings.CheckerName)
@@ -709,28 +893,13 @@ func (sh *submissionHandler) getAppropriateChecker(ctx context.Context) (checker
 		), nil
 	}
 
-	// Legacy checker
-	if sh.settings.LegacyChecker {
-		return checkers.NewLegacyCustomChecker(
-			sh.runner,
-			sh.langMgr,
-			sh.base.DataStore(),
-			graderLogger,
-			sh.pb,
-			sh.settings.CheckerName,
-			data,
-			subCode,
-			att.LastUpdatedAt,
-		), nil
-	}
-
-	// Standard checker
-	return checkers.NewStandardCustomChecker(
+	return checkers.NewCustomChecker(
 		sh.runner,
 		sh.langMgr,
 		sh.base.DataStore(),
 		graderLogger,
 		sh.pb,
+		sh.settings.CheckerProtocol,
 		sh.settings.CheckerName,
 		data,
 		subCode,
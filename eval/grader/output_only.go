@@ -0,0 +1,78 @@
+package grader
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/KiloProjects/kilonova"
+	"github.com/KiloProjects/kilonova/eval/checkers"
+	"github.com/shopspring/decimal"
+)
+
+const (
+	noOutputVerdict       = "translate:no_output"
+	outputTooLargeVerdict = "translate:output_too_large"
+
+	// maxOutputOnlyFileSize caps the uncompressed size of a single submitted output
+	maxOutputOnlyFileSize = 256 * 1024 * 1024
+)
+
+// loadOutputArchive indexes the submitted zip archive by test, matching every `<test>.out` file to
+// the test whose visible ID is <test>. Directories inside the archive are ignored.
+func (sh *submissionHandler) loadOutputArchive(ctx context.Context) {
+	code := sh.getCode()
+	sh.outputs = make(map[int]*zip.File)
+	archive, err := zip.NewReader(bytes.NewReader(code), int64(len(code)))
+	if err != nil {
+		// Submissions are validated on upload, so this should only happen for old submissions.
+		// Every test will simply have no output.
+		slog.WarnContext(ctx, "Invalid output only archive", slog.Int("subID", sh.sub.ID), slog.Any("err", err))
+		return
+	}
+	for _, file := range archive.File {
+		if file.FileInfo().IsDir() {
+			continue
+		}
+		name := path.Base(file.Name)
+		if path.Ext(name) != ".out" {
+			continue
+		}
+		visibleID, err := strconv.Atoi(strings.TrimSuffix(name, ".out"))
+		if err != nil {
+			continue
+		}
+		sh.outputs[visibleID] = file
+	}
+}
+
+func (sh *submissionHandler) handleOutputOnlySubTest(ctx context.Context, checker checkers.Checker, subTest *kilonova.SubTest) (*subtestOutput, error) {
+	file, ok := sh.outputs[subTest.VisibleID]
+	if !ok {
+		return &subtestOutput{Comments: noOutputVerdict, Score: decimal.Zero}, nil
+	}
+	if file.UncompressedSize64 > maxOutputOnlyFileSize {
+		return &subtestOutput{Comments: outputTooLargeVerdict, Score: decimal.Zero}, nil
+	}
+
+	rc, err := file.Open()
+	if err != nil {
+		slog.InfoContext(ctx, "Couldn't open output only file", slog.Int("subID", sh.sub.ID), slog.String("file", file.Name), slog.Any("err", err))
+		return &subtestOutput{Comments: noOutputVerdict, Score: decimal.Zero}, nil
+	}
+	defer rc.Close()
+
+	// The checker expects the output where a batch execution would have left it
+	if err := sh.base.DataStore().Subtests().WriteFile(strconv.Itoa(subTest.ID), io.LimitReader(rc, maxOutputOnlyFileSize), 0644); err != nil {
+		return nil, fmt.Errorf("couldn't save output: %w", err)
+	}
+
+	verdict, score := checker.RunChecker(ctx, subTest.ID, *subTest.TestID)
+	return &subtestOutput{Comments: verdict, Score: score}, nil
+}
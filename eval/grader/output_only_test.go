@@ -0,0 +1,47 @@
+package grader
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"maps"
+	"slices"
+	"testing"
+
+	"github.com/KiloProjects/kilonova"
+)
+
+func TestLoadOutputArchiveMatchesByVisibleID(t *testing.T) {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, name := range []string{"1.out", "outputs/02.out", "3.txt", "notes.out", "outputs/"} {
+		if _, err := zw.Create(name); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	sh := &submissionHandler{
+		sub:   &kilonova.Submission{ID: 1},
+		files: []*kilonova.SubmissionFile{{Filename: "outputs.zip", Data: buf.Bytes()}},
+	}
+	sh.loadOutputArchive(context.Background())
+
+	got := slices.Sorted(maps.Keys(sh.outputs))
+	if want := []int{1, 2}; !slices.Equal(got, want) {
+		t.Fatalf("matched tests %v, want %v", got, want)
+	}
+}
+
+func TestLoadOutputArchiveInvalidZip(t *testing.T) {
+	sh := &submissionHandler{
+		sub:   &kilonova.Submission{ID: 1},
+		files: []*kilonova.SubmissionFile{{Filename: "outputs.zip", Data: []byte("not a zip")}},
+	}
+	sh.loadOutputArchive(context.Background())
+	if len(sh.outputs) != 0 {
+		t.Fatalf("got %d outputs from an invalid archive", len(sh.outputs))
+	}
+}
@@ -0,0 +1,111 @@
+package grader
+
+import (
+	"cmp"
+	"slices"
+	"sync"
+
+	"github.com/KiloProjects/kilonova"
+	"github.com/KiloProjects/kilonova/domain/config"
+	"github.com/KiloProjects/kilonova/eval"
+)
+
+// runGraph decides the order in which a classic submission's subtests are run, and which of them can be skipped.
+//
+// Subtask dependencies are stored by including the tests of every dependency in the dependent subtask,
+// so a subtask depends on another exactly when its tests are a superset of the other's.
+// Since subtasks are min-scored, a single test with no points zeroes out every subtask it is part of,
+// including the ones depending on them. Tests whose subtasks have all been zeroed out can't change the score anymore.
+type runGraph struct {
+	// order is sorted such that dependencies are run before the subtasks depending on them
+	order []*kilonova.SubTest
+
+	mu sync.Mutex
+	// subtasks maps subtest IDs to the IDs of the submission subtasks they are part of
+	subtasks map[int][]int
+	// failed marks the submission subtasks which already have a test with no points
+	failed map[int]bool
+}
+
+func buildRunGraph(subTests []*kilonova.SubTest, subTasks []*kilonova.SubmissionSubTask) *runGraph {
+	g := &runGraph{
+		order:    make([]*kilonova.SubTest, 0, len(subTests)),
+		subtasks: make(map[int][]int),
+		failed:   make(map[int]bool),
+	}
+
+	// A strict subset has fewer tests, so sorting by size is a topological sort of the dependencies
+	subTasks = slices.Clone(subTasks)
+	slices.SortStableFunc(subTasks, func(a, b *kilonova.SubmissionSubTask) int {
+		return cmp.Or(cmp.Compare(len(a.Subtests), len(b.Subtests)), cmp.Compare(a.VisibleID, b.VisibleID))
+	})
+
+	subTestsByID := make(map[int]*kilonova.SubTest, len(subTests))
+	for _, st := range subTests {
+		subTestsByID[st.ID] = st
+	}
+	added := make(map[int]bool, len(subTests))
+	for _, stk := range subTasks {
+		var stage []*kilonova.SubTest
+		for _, id := range stk.Subtests {
+			g.subtasks[id] = append(g.subtasks[id], stk.ID)
+			if st, ok := subTestsByID[id]; ok && !added[id] {
+				added[id] = true
+				stage = append(stage, st)
+			}
+		}
+		slices.SortFunc(stage, func(a, b *kilonova.SubTest) int { return cmp.Compare(a.VisibleID, b.VisibleID) })
+		g.order = append(g.order, stage...)
+	}
+
+	// Tests outside of any subtask (or all of them, if there are no subtasks) run last, in order
+	var rest []*kilonova.SubTest
+	for _, st := range subTests {
+		if !added[st.ID] {
+			rest = append(rest, st)
+		}
+	}
+	slices.SortFunc(rest, func(a, b *kilonova.SubTest) int { return cmp.Compare(a.VisibleID, b.VisibleID) })
+	g.order = append(g.order, rest...)
+
+	return g
+}
+
+// skippable reports whether every subtask the subtest is part of has already failed.
+// Tests outside of subtasks are never skipped, since they are scored individually.
+func (g *runGraph) skippable(subTestID int) bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	stks := g.subtasks[subTestID]
+	if len(stks) == 0 {
+		return false
+	}
+	for _, id := range stks {
+		if !g.failed[id] {
+			return false
+		}
+	}
+	return true
+}
+
+// markFailed records that the subtest got no points, failing all of its subtasks
+func (g *runGraph) markFailed(subTestID int) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	for _, id := range g.subtasks[subTestID] {
+		g.failed[id] = true
+	}
+}
+
+// evalParallelism is the number of subtests of a single submission that may be evaluated at once.
+// Tests beyond that wait before being sent to the grader, so they can still be skipped.
+func evalParallelism(runner eval.BoxScheduler) int {
+	if sched, ok := runner.(eval.CapacityScheduler); ok && sched.Capacity() > 0 {
+		return sched.Capacity()
+	}
+	if config.Eval.IsRemote() {
+		// The grader didn't report its capacity
+		return 1
+	}
+	return max(config.Eval.NumConcurrent, 1)
+}
@@ -0,0 +1,62 @@
+package grader
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/KiloProjects/kilonova"
+)
+
+func runGraphFixture() *runGraph {
+	// Tests 1-2 form subtask 1, subtask 2 depends on it and adds tests 3-4,
+	// subtask 3 is independent with tests 5-6. Test 7 is outside of any subtask.
+	var subTests []*kilonova.SubTest
+	for i := 7; i >= 1; i-- {
+		subTests = append(subTests, &kilonova.SubTest{ID: 100 + i, VisibleID: i})
+	}
+	subTasks := []*kilonova.SubmissionSubTask{
+		{ID: 2, VisibleID: 2, Subtests: []int{101, 102, 103, 104}},
+		{ID: 3, VisibleID: 3, Subtests: []int{105, 106}},
+		{ID: 1, VisibleID: 1, Subtests: []int{101, 102}},
+	}
+	return buildRunGraph(subTests, subTasks)
+}
+
+func TestRunGraphOrdersDependenciesFirst(t *testing.T) {
+	g := runGraphFixture()
+	var order []int
+	for _, st := range g.order {
+		order = append(order, st.VisibleID)
+	}
+	if want := []int{1, 2, 5, 6, 3, 4, 7}; !slices.Equal(order, want) {
+		t.Fatalf("got order %v, want %v", order, want)
+	}
+}
+
+func TestRunGraphSkipsFailedSubtasksAndDependents(t *testing.T) {
+	g := runGraphFixture()
+	g.markFailed(101)
+
+	for id, want := range map[int]bool{
+		102: true,  // rest of the failed subtask
+		103: true,  // only in the dependent subtask
+		105: false, // independent subtask
+		107: false, // not in a subtask
+	} {
+		if got := g.skippable(id); got != want {
+			t.Errorf("skippable(%d) = %v, want %v", id, got, want)
+		}
+	}
+}
+
+func TestRunGraphKeepsTestsOfLiveSubtasks(t *testing.T) {
+	g := runGraphFixture()
+	// Failing only the dependent subtask doesn't affect its dependency
+	g.markFailed(103)
+	if g.skippable(101) {
+		t.Fatal("test of a subtask that can still score was skipped")
+	}
+	if !g.skippable(104) {
+		t.Fatal("test of a failed subtask wasn't skipped")
+	}
+}
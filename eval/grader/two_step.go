@@ -0,0 +1,132 @@
+package grader
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"strconv"
+	"sync"
+
+	"github.com/KiloProjects/kilonova"
+	"github.com/KiloProjects/kilonova/domain/datastore"
+	"github.com/KiloProjects/kilonova/eval"
+	"github.com/KiloProjects/kilonova/eval/checkers"
+	"github.com/KiloProjects/kilonova/eval/language"
+	"github.com/KiloProjects/kilonova/eval/tasks"
+)
+
+// twoStepManagerMu guards the compiled two-step managers, like the checker cache
+var twoStepManagerMu sync.RWMutex
+
+func twoStepManagerFile(problemID int) *eval.BucketFile {
+	return &eval.BucketFile{
+		Bucket:   datastore.BucketTypeCheckers,
+		Filename: fmt.Sprintf("%d.manager.bin", problemID),
+		Mode:     0777,
+	}
+}
+
+func (sh *submissionHandler) twoStepManagerLang() language.GraderLang {
+	return sh.langMgr.LanguageFromFilename(sh.settings.ManagerName)
+}
+
+// prepareTwoStepManager compiles the problem's two-step manager, if it wasn't already compiled since its last update.
+// Like checker.Prepare, it returns the compilation output on failure.
+func (sh *submissionHandler) prepareTwoStepManager(ctx context.Context) (string, error) {
+	if sh.settings.ManagerName == "" {
+		return "Two-step problem has no manager", errors.New("missing two-step manager")
+	}
+	lang := sh.twoStepManagerLang()
+	if lang == nil {
+		return "Couldn't compile manager", errors.New("unknown manager language")
+	}
+	att, err := sh.base.ProblemAttByName(ctx, sh.pb.ID, sh.settings.ManagerName)
+	if err != nil {
+		return "Couldn't get manager", fmt.Errorf("couldn't get manager metadata: %w", err)
+	}
+
+	twoStepManagerMu.Lock()
+	defer twoStepManagerMu.Unlock()
+
+	file := twoStepManagerFile(sh.pb.ID)
+	modtime, err := sh.base.DataStore().Checkers().Modtime(file.Filename)
+	if err == nil && !modtime.Before(att.LastUpdatedAt) {
+		graderLogger.InfoContext(ctx, "Using cached two-step manager")
+		return "", nil
+	}
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		slog.WarnContext(ctx, "Manager stat error", slog.Any("err", err))
+	}
+
+	code, err := sh.base.ProblemAttDataByName(ctx, sh.pb.ID, sh.settings.ManagerName)
+	if err != nil {
+		return "Couldn't get manager", fmt.Errorf("couldn't get manager code: %w", err)
+	}
+
+	graderLogger.InfoContext(ctx, "Compiling two-step manager", slog.Any("problem", sh.pb))
+	resp, err := tasks.CompileTask(ctx, sh.runner, &tasks.CompileRequest{
+		File: file,
+		CodeFiles: map[string][]byte{
+			lang.SourceName(sh.settings.ManagerName): code,
+		},
+		HeaderFiles: map[string][]byte{},
+		Lang:        lang,
+
+		OriginalFilename: sh.settings.ManagerName,
+
+		Store: sh.base.DataStore(),
+	}, graderLogger)
+	if err != nil {
+		return "Couldn't compile manager", err
+	}
+	if !resp.Success {
+		return fmt.Sprintf("Output:\n%s\nOther:\n%s", resp.Output, resp.Other), kilonova.Statusf(400, "Invalid manager code")
+	}
+	return "", nil
+}
+
+func (sh *submissionHandler) handleTwoStepSubTest(ctx context.Context, checker checkers.Checker, subTest *kilonova.SubTest) (*subtestOutput, error) {
+	batchRequest := sh.batchRequest(subTest)
+	execRequest := &tasks.TwoStepRequest{
+		BatchRequest: *batchRequest,
+		FirstOutputFile: &eval.BucketFile{
+			Bucket:   datastore.BucketTypeSubtests,
+			Filename: strconv.Itoa(subTest.ID) + ".first",
+			Mode:     0644,
+		},
+		SecondInputFile: &eval.BucketFile{
+			Bucket:   datastore.BucketTypeSubtests,
+			Filename: strconv.Itoa(subTest.ID) + ".second",
+			Mode:     0644,
+		},
+
+		ManagerLang:     sh.twoStepManagerLang(),
+		ManagerFile:     twoStepManagerFile(sh.pb.ID),
+		ManagerFilename: sh.settings.ManagerName,
+	}
+	if execRequest.ManagerLang == nil {
+		return nil, fmt.Errorf("manager language not found")
+	}
+
+	// The intermediate files are only needed while the subtest runs
+	defer sh.removeTwoStepFile(ctx, execRequest.FirstOutputFile.Filename)
+	defer sh.removeTwoStepFile(ctx, execRequest.SecondInputFile.Filename)
+
+	twoStepManagerMu.RLock()
+	execCtx := eval.WithProgress(ctx, sh.subTestProgress(ctx, subTest))
+	resp, err := tasks.ExecuteTwoStep(execCtx, sh.runner, int64(batchRequest.MemoryLimit), execRequest, graderLogger)
+	twoStepManagerMu.RUnlock()
+	if err != nil {
+		return nil, fmt.Errorf("couldn't execute subtest: %w", err)
+	}
+
+	return sh.checkBatchOutput(ctx, checker, subTest, resp), nil
+}
+
+func (sh *submissionHandler) removeTwoStepFile(ctx context.Context, name string) {
+	if err := sh.base.DataStore().Subtests().RemoveFile(name); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		slog.WarnContext(ctx, "Couldn't remove two-step intermediate file", slog.String("file", name), slog.Any("err", err))
+	}
+}
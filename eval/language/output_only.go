@@ -0,0 +1,29 @@
+package language
+
+// OutputOnly is the language of output only submissions, which are zip archives of test outputs.
+// It keeps the name of the old outputOnly language, so existing submissions are still recognized.
+func OutputOnly() Lang {
+	return outputOnly{}
+}
+
+type outputOnly struct{}
+
+func (outputOnly) InternalName() string {
+	return "outputOnly"
+}
+
+func (outputOnly) PrintableName() string {
+	return "Output Only"
+}
+
+func (outputOnly) Extensions() []string {
+	return []string{".zip"}
+}
+
+func (outputOnly) DefaultFilename() string {
+	return "outputs.zip"
+}
+
+func (outputOnly) MOSSName() string {
+	return "ascii"
+}
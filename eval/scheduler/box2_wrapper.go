@@ -40,7 +40,7 @@ func (b *Box2Wrapper) RunBox2(ctx context.Context, b2Req *eval.Box2Request, memQ
 		return nil, err
 	}
 
-	result, err := b.mgr.RunBox3(ctx, b3Req, memQuota)
+	result, err := b.runBox3(ctx, b3Req, memQuota)
 	if err != nil {
 		return nil, err
 	}
@@ -48,6 +48,17 @@ func (b *Box2Wrapper) RunBox2(ctx context.Context, b2Req *eval.Box2Request, memQ
 	return b.convertResponse(ctx, b2Req, result)
 }
 
+// runBox3 uses the streaming variant of RunBox3 if the caller attached a
+// ProgressFunc to ctx and the scheduler can report progress.
+func (b *Box2Wrapper) runBox3(ctx context.Context, req *eval.Box3Request, memQuota int64) (*eval.Box3Response, error) {
+	if progress := eval.ProgressFromContext(ctx); progress != nil {
+		if sched, ok := b.mgr.(eval.Box3StreamScheduler); ok {
+			return sched.RunBox3Stream(ctx, req, memQuota, progress)
+		}
+	}
+	return b.mgr.RunBox3(ctx, req, memQuota)
+}
+
 func (b *Box2Wrapper) RunMultibox2(ctx context.Context, b2Req *eval.Multibox2Request, managerMemQuota int64, individualMemQuota int64) (*eval.Box2Response, []*eval.RunStats, error) {
 	managerRequest, err := b.convertRequest(ctx, b2Req.ManagerSandbox)
 	if err != nil {
@@ -65,17 +76,44 @@ func (b *Box2Wrapper) RunMultibox2(ctx context.Context, b2Req *eval.Multibox2Req
 		userConfigs = append(userConfigs, userConfig)
 	}
 
+	var transcriptLimit int64
+	if b2Req.Transcript != nil {
+		transcriptLimit = b2Req.TranscriptLimit
+	}
+
 	result, stats, err := b.mgr.RunMultibox3(ctx, &eval.Multibox3Request{
 		ManagerSandbox:     managerRequest,
 		UserSandboxConfigs: userConfigs,
 		UseStdin:           b2Req.UseStdin,
+		TranscriptLimit:    transcriptLimit,
 	}, managerMemQuota, individualMemQuota)
 	if err != nil {
 		return nil, stats, err
 	}
 
+	// The transcript isn't one of the manager's output files, so it is saved separately
+	transcriptID, hasTranscript := "", false
+	if result != nil {
+		transcriptID, hasTranscript = result.Files[eval.TranscriptPath]
+		delete(result.Files, eval.TranscriptPath)
+	}
+
 	resp2, err := b.convertResponse(ctx, b2Req.ManagerSandbox, result)
-	return resp2, stats, err
+	if err != nil || !hasTranscript {
+		return resp2, stats, err
+	}
+	if b2Req.Transcript == nil {
+		b.scratch.DeleteFile(transcriptID)
+		return resp2, stats, nil
+	}
+	if err := b.copyDeleteScratch(ctx, transcriptID, b2Req.Transcript); err != nil {
+		slog.WarnContext(ctx, "Could not save transcript", slog.Any("err", err))
+		return resp2, stats, nil
+	}
+	if resp2 != nil {
+		resp2.BucketFiles = append(resp2.BucketFiles, eval.TranscriptPath)
+	}
+	return resp2, stats, nil
 }
 
 func (b *Box2Wrapper) convertRequest(ctx context.Context, b2Req *eval.Box2Request) (*eval.Box3Request, error) {
@@ -209,6 +247,14 @@ func (b *Box2Wrapper) clearInput(ctx context.Context, req *eval.Box3Request) {
 	}
 }
 
+// Capacity returns the capacity of the underlying scheduler, if it is known
+func (b *Box2Wrapper) Capacity() int {
+	if sched, ok := b.mgr.(eval.CapacityScheduler); ok {
+		return sched.Capacity()
+	}
+	return 0
+}
+
 func (b *Box2Wrapper) Close(ctx context.Context) error {
 	return b.mgr.Close(ctx)
 }
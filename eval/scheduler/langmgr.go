@@ -97,10 +97,17 @@ func (mgr *LanguageManager) Resync(ctx context.Context) error {
 	return nil
 }
 
+// inventorySource is a remote grader (or pool of graders) that reports its
+// supported language -> version map.
+type inventorySource interface {
+	languageVersions(ctx context.Context) (map[string]string, error)
+}
+
 // NewRemoteLanguageManager builds a platform-side LanguageManager whose inventory
-// is pulled from a remote grader. It rebuilds full language behavior from the
-// binary's compiled-in language.Langs, filtered to the grader's supported names.
-func NewRemoteLanguageManager(ctx context.Context, client *GraderClient, logger *slog.Logger) (eval.LanguageManager, error) {
+// is pulled from a remote grader (a GraderClient or a GraderPool). It rebuilds
+// full language behavior from the binary's compiled-in language.Langs, filtered
+// to the grader's supported names.
+func NewRemoteLanguageManager(ctx context.Context, client inventorySource, logger *slog.Logger) (eval.LanguageManager, error) {
 	mgr := &LanguageManager{
 		logger: logger,
 		refetch: func(ctx context.Context) (map[string]language.GraderLang, map[string]string, error) {
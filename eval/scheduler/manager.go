@@ -1,10 +1,12 @@
 package scheduler
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
 	"log/slog"
+	"math"
 	"os"
 	"os/exec"
 	"path"
@@ -13,6 +15,7 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/KiloProjects/kilonova/domain/config"
 	"github.com/KiloProjects/kilonova/eval"
@@ -37,13 +40,15 @@ var (
 type BoxFunc func(ctx context.Context, id int, mem int64, logger *slog.Logger) (eval.Sandbox, error)
 
 var _ eval.Box3Scheduler = &BoxManager{}
+var _ eval.Box3StreamScheduler = &BoxManager{}
 
 // BoxManager manages a box with eval-based submissions
 type BoxManager struct {
 	numConcurrent int64
-	// concSem measures the number of running Box3 requests.
+	// queue measures the number of running Box3 requests.
 	// Since a request will be able to have multiple boxes (communication type submissions), it does not reflect the number of concurrent boxes running.
-	concSem   *semaphore.Weighted
+	// Waiting requests are served by client priority, so one client's bulk reevaluations can't starve another's contest.
+	queue     *priorityQueue
 	memSem    *semaphore.Weighted
 	maxMemory int64
 
@@ -86,7 +91,10 @@ func (mgr *BoxManager) releaseBox(ctx context.Context, sb eval.Sandbox) {
 
 // Close waits for all boxes to finish running
 func (mgr *BoxManager) Close(ctx context.Context) error {
-	mgr.concSem.Acquire(ctx, mgr.numConcurrent)
+	// Lowest possible priority, so every queued request gets to run first
+	if err := mgr.queue.Acquire(ctx, ClientIdentity{Name: "close", priority: math.MinInt}, mgr.numConcurrent); err != nil {
+		return err
+	}
 	close(mgr.availableIDs)
 	return nil
 }
@@ -104,7 +112,7 @@ func New(startingNumber int, count int, maxMemory int64, logger *slog.Logger, sc
 	}
 
 	bm := &BoxManager{
-		concSem:       semaphore.NewWeighted(int64(count)),
+		queue:         newPriorityQueue(int64(count)),
 		memSem:        semaphore.NewWeighted(maxMemory),
 		maxMemory:     maxMemory,
 		availableIDs:  availableIDs,
@@ -132,17 +140,32 @@ func CheckCanRun(ctx context.Context, boxFunc BoxFunc) bool {
 	return true
 }
 
+// Capacity returns the number of boxes that may run at once
+func (mgr *BoxManager) Capacity() int {
+	return int(mgr.numConcurrent)
+}
+
 func (mgr *BoxManager) RunBox3(ctx context.Context, req *eval.Box3Request, memQuota int64) (*eval.Box3Response, error) {
+	return mgr.RunBox3Stream(ctx, req, memQuota, nil)
+}
+
+// RunBox3Stream is RunBox3, additionally reporting the request's progress to
+// progress (which may be nil).
+func (mgr *BoxManager) RunBox3Stream(ctx context.Context, req *eval.Box3Request, memQuota int64, progress eval.ProgressFunc) (*eval.Box3Response, error) {
+	if progress == nil {
+		progress = func(*eval.Box3Event) {}
+	}
 	goodCmd, err := makeGoodCommand(req)
 	if err != nil {
 		slog.ErrorContext(ctx, "Error running MakeGoodCommand", slog.Any("err", err))
 		return nil, err
 	}
 
-	if err := mgr.concSem.Acquire(ctx, 1); err != nil {
+	progress(&eval.Box3Event{Kind: eval.Box3Queued})
+	if err := mgr.queue.Acquire(ctx, clientFromContext(ctx), 1); err != nil {
 		return nil, err
 	}
-	defer mgr.concSem.Release(1)
+	defer mgr.queue.Release(1)
 	box, err := mgr.getBox(ctx, memQuota)
 	if err != nil {
 		slog.WarnContext(ctx, "Could not get box", slog.Any("err", err))
@@ -154,7 +177,10 @@ func (mgr *BoxManager) RunBox3(ctx context.Context, req *eval.Box3Request, memQu
 		return nil, err
 	}
 
+	progress(&eval.Box3Event{Kind: eval.Box3Started})
+	stopProgress := reportProgress(progress)
 	stats, err := box.RunCommand(ctx, goodCmd, req.RunConfig)
+	stopProgress()
 	if err != nil {
 		return nil, err
 	}
@@ -192,10 +218,10 @@ func (mgr *BoxManager) RunMultibox3(ctx context.Context, req *eval.Multibox3Requ
 	}
 
 	// Acquire the semaphores for the manager and the user sandboxes
-	if err := mgr.concSem.Acquire(ctx, int64(len(req.UserSandboxConfigs)+1)); err != nil {
+	if err := mgr.queue.Acquire(ctx, clientFromContext(ctx), int64(len(req.UserSandboxConfigs)+1)); err != nil {
 		return nil, nil, err
 	}
-	defer mgr.concSem.Release(int64(len(req.UserSandboxConfigs) + 1))
+	defer mgr.queue.Release(int64(len(req.UserSandboxConfigs) + 1))
 
 	// Initialize the communication FIFOs
 	fifoDirs := make([]string, len(req.UserSandboxConfigs))
@@ -227,6 +253,18 @@ func (mgr *BoxManager) RunMultibox3(ctx context.Context, req *eval.Multibox3Requ
 		if err := os.Chmod(fifoManagerToUser[i], 0666); err != nil {
 			return nil, nil, err
 		}
+
+		if req.TranscriptLimit > 0 {
+			// The user sandbox gets its own pair of fifos, which are relayed to the manager's
+			for _, name := range []string{fmt.Sprintf("u%d_to_r", i), fmt.Sprintf("r_to_u%d", i)} {
+				if err := unix.Mkfifo(path.Join(dir, name), 0666); err != nil {
+					return nil, nil, err
+				}
+				if err := os.Chmod(path.Join(dir, name), 0666); err != nil {
+					return nil, nil, err
+				}
+			}
+		}
 	}
 	sandboxFifoDirs := make([]string, len(req.UserSandboxConfigs))
 	sandboxFifoUserToManager := make([]string, len(req.UserSandboxConfigs))
@@ -243,6 +281,13 @@ func (mgr *BoxManager) RunMultibox3(ctx context.Context, req *eval.Multibox3Requ
 		})
 		req.ManagerSandbox.Command = append(req.ManagerSandbox.Command, sandboxFifoUserToManager[i], sandboxFifoManagerToUser[i])
 
+		// The user's ends of the fifos, which are the manager's unless the communication is relayed
+		userInput, userOutput := sandboxFifoManagerToUser[i], sandboxFifoUserToManager[i]
+		if req.TranscriptLimit > 0 {
+			userInput = path.Join(sandboxFifoDirs[i], fmt.Sprintf("r_to_u%d", i))
+			userOutput = path.Join(sandboxFifoDirs[i], fmt.Sprintf("u%d_to_r", i))
+		}
+
 		req.UserSandboxConfigs[i].RunConfig.Directories = append(req.UserSandboxConfigs[i].RunConfig.Directories, language.Directory{
 			In:   sandboxFifoDirs[i],
 			Out:  fifoDirs[i],
@@ -250,10 +295,10 @@ func (mgr *BoxManager) RunMultibox3(ctx context.Context, req *eval.Multibox3Requ
 		})
 
 		if req.UseStdin {
-			req.UserSandboxConfigs[i].RunConfig.InputPath = sandboxFifoManagerToUser[i]
-			req.UserSandboxConfigs[i].RunConfig.OutputPath = sandboxFifoUserToManager[i]
+			req.UserSandboxConfigs[i].RunConfig.InputPath = userInput
+			req.UserSandboxConfigs[i].RunConfig.OutputPath = userOutput
 		} else {
-			req.UserSandboxConfigs[i].Command = append(req.UserSandboxConfigs[i].Command, sandboxFifoManagerToUser[i], sandboxFifoUserToManager[i])
+			req.UserSandboxConfigs[i].Command = append(req.UserSandboxConfigs[i].Command, userInput, userOutput)
 		}
 
 		if len(req.UserSandboxConfigs) > 1 {
@@ -287,6 +332,31 @@ func (mgr *BoxManager) RunMultibox3(ctx context.Context, req *eval.Multibox3Requ
 		}
 	}
 
+	var tr *transcript
+	var relayWg sync.WaitGroup
+	var relayFifos []string
+	if req.TranscriptLimit > 0 {
+		tr = newTranscript(req.TranscriptLimit)
+		for i, dir := range fifoDirs {
+			userPrefix := "user"
+			if len(fifoDirs) > 1 {
+				userPrefix = fmt.Sprintf("user %d", i)
+			}
+			toUser, fromUser := path.Join(dir, fmt.Sprintf("r_to_u%d", i)), path.Join(dir, fmt.Sprintf("u%d_to_r", i))
+			relayFifos = append(relayFifos, fifoManagerToUser[i], toUser, fromUser, fifoUserToManager[i])
+			relayWg.Go(func() {
+				if err := relayFifo(fifoManagerToUser[i], toUser, tr.stream("manager -> "+userPrefix+": ")); err != nil {
+					mgr.logger.WarnContext(ctx, "Couldn't relay manager output", slog.Any("err", err))
+				}
+			})
+			relayWg.Go(func() {
+				if err := relayFifo(fromUser, fifoUserToManager[i], tr.stream(userPrefix+" -> manager: ")); err != nil {
+					mgr.logger.WarnContext(ctx, "Couldn't relay user output", slog.Any("err", err))
+				}
+			})
+		}
+	}
+
 	var wg, userWg sync.WaitGroup
 	userStats := make([]*eval.RunStats, len(req.UserSandboxConfigs))
 	wg.Add(len(req.UserSandboxConfigs) + 1)
@@ -347,6 +417,13 @@ func (mgr *BoxManager) RunMultibox3(ctx context.Context, req *eval.Multibox3Requ
 	close(errChan)
 	close(respChan)
 
+	if tr != nil {
+		relaysDone := make(chan struct{})
+		go unblockFifos(relayFifos, relaysDone)
+		relayWg.Wait()
+		close(relaysDone)
+	}
+
 	var errs []error
 	for err := range errChan {
 		if err != nil {
@@ -363,9 +440,44 @@ func (mgr *BoxManager) RunMultibox3(ctx context.Context, req *eval.Multibox3Requ
 		return nil, userStats, errors.New("no response from manager")
 	}
 
+	if tr != nil {
+		identifier, err := mgr.scratch.SaveFile(bytes.NewReader(tr.Bytes()))
+		if err != nil {
+			return nil, userStats, fmt.Errorf("couldn't save transcript: %w", err)
+		}
+		resp.Files[eval.TranscriptPath] = identifier
+	}
+
 	return resp, userStats, nil
 }
 
+// progressInterval is how often running boxes report interim stats.
+const progressInterval = time.Second
+
+// reportProgress sends a Box3Progress event every progressInterval until the
+// returned function is called. No events are sent after it returns.
+func reportProgress(progress eval.ProgressFunc) (stop func()) {
+	start := time.Now()
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Go(func() {
+		ticker := time.NewTicker(progressInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				progress(&eval.Box3Event{Kind: eval.Box3Progress, Stats: &eval.RunStats{Time: time.Since(start).Seconds()}})
+			}
+		}
+	})
+	return func() {
+		close(done)
+		wg.Wait()
+	}
+}
+
 func (mgr *BoxManager) copyScratchFile(box eval.Sandbox, sf eval.ScratchFile) error {
 	rc, err := mgr.scratch.ReadFile(sf.Identifier)
 	if err != nil {
@@ -0,0 +1,440 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"connectrpc.com/connect"
+	"github.com/KiloProjects/kilonova/eval"
+)
+
+var _ eval.Box3Scheduler = (*GraderPool)(nil)
+var _ eval.Box3StreamScheduler = (*GraderPool)(nil)
+
+var errNoGrader = errors.New("no healthy grader available")
+
+const (
+	poolHealthInterval = 15 * time.Second
+	poolHealthTimeout  = 5 * time.Second
+)
+
+// PoolMember describes one remote grader of a GraderPool. Scratch must be that
+// grader's own /scratch endpoint, since identifiers are only valid on the
+// grader that minted them.
+type PoolMember struct {
+	Name    string
+	Client  *GraderClient
+	Scratch eval.Scratch
+	// Capacity is the number of requests the grader runs at once (usually its
+	// num_concurrent). It is only used to spread load, not as a hard limit.
+	Capacity int
+}
+
+type poolMember struct {
+	PoolMember
+
+	inflight atomic.Int64
+	healthy  atomic.Bool
+	// versions is the last language inventory reported by the grader.
+	versions atomic.Pointer[map[string]string]
+}
+
+func (m *poolMember) supports(langs []string) bool {
+	versions := m.versions.Load()
+	if versions == nil {
+		return len(langs) == 0
+	}
+	for _, lang := range langs {
+		if _, ok := (*versions)[lang]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// GraderPool is the platform-side eval.Box3Scheduler spread over several
+// remote graders. Each request goes to the healthy grader with the most free
+// capacity whose language inventory covers the languages attached to the
+// context (see WithLanguages). If the call fails with a transport error, the
+// grader is marked unhealthy and the request is retried on another one.
+//
+// Since scratch identifiers are per grader, the pool exposes its own Scratch:
+// inputs are staged locally and only uploaded once a grader is picked, and
+// output identifiers are prefixed with the name of the grader holding them.
+type GraderPool struct {
+	members []*poolMember
+	staging eval.Scratch
+	logger  *slog.Logger
+
+	stopHealth context.CancelFunc
+}
+
+// NewGraderPool builds a pool over the given graders and runs a first health
+// check on all of them. staging is a platform-local scratch used to hold
+// inputs until a grader is chosen. Health checks keep running in the
+// background until Close is called.
+func NewGraderPool(ctx context.Context, members []PoolMember, staging eval.Scratch, logger *slog.Logger) (*GraderPool, error) {
+	if len(members) == 0 {
+		return nil, errors.New("grader pool: no graders configured")
+	}
+	pool := &GraderPool{
+		members: make([]*poolMember, 0, len(members)),
+		staging: staging,
+		logger:  logger,
+	}
+	for _, m := range members {
+		if m.Name == "" || strings.Contains(m.Name, ":") {
+			return nil, fmt.Errorf("grader pool: invalid grader name %q", m.Name)
+		}
+		if slices.ContainsFunc(pool.members, func(other *poolMember) bool { return other.Name == m.Name }) {
+			return nil, fmt.Errorf("grader pool: duplicate grader name %q", m.Name)
+		}
+		if m.Capacity <= 0 {
+			m.Capacity = 1
+		}
+		pool.members = append(pool.members, &poolMember{PoolMember: m})
+	}
+
+	pool.checkAll(ctx)
+
+	healthCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	pool.stopHealth = cancel
+	go pool.healthLoop(healthCtx)
+
+	return pool, nil
+}
+
+// Scratch returns the eval.Scratch that must be paired with the pool (for
+// example in a Box2Wrapper).
+func (p *GraderPool) Scratch() eval.Scratch {
+	return &poolScratch{pool: p}
+}
+
+func (p *GraderPool) RunBox3(ctx context.Context, req *eval.Box3Request, memQuota int64) (*eval.Box3Response, error) {
+	return p.RunBox3Stream(ctx, req, memQuota, nil)
+}
+
+// RunBox3Stream is RunBox3 over the grader's streaming RPC, passing its
+// progress events to progress. If progress is nil, the unary RPC is used.
+func (p *GraderPool) RunBox3Stream(ctx context.Context, req *eval.Box3Request, memQuota int64, progress eval.ProgressFunc) (*eval.Box3Response, error) {
+	var resp *eval.Box3Response
+	err := p.dispatch(ctx, func(m *poolMember) error {
+		remoteReq, err := p.upload(ctx, m, req)
+		defer p.clearUploaded(ctx, m, remoteReq)
+		if err != nil {
+			return err
+		}
+
+		var r *eval.Box3Response
+		if progress != nil {
+			r, err = m.Client.RunBox3Stream(ctx, remoteReq, memQuota, progress)
+		} else {
+			r, err = m.Client.RunBox3(ctx, remoteReq, memQuota)
+		}
+		if err != nil {
+			return err
+		}
+		resp = qualifyResponse(m, r)
+		return nil
+	})
+	return resp, err
+}
+
+func (p *GraderPool) RunMultibox3(ctx context.Context, req *eval.Multibox3Request, managerMemQuota int64, individualMemQuota int64) (*eval.Box3Response, []*eval.RunStats, error) {
+	var resp *eval.Box3Response
+	var stats []*eval.RunStats
+	err := p.dispatch(ctx, func(m *poolMember) error {
+		remoteReq := &eval.Multibox3Request{
+			UserSandboxConfigs: make([]*eval.Box3Request, 0, len(req.UserSandboxConfigs)),
+			UseStdin:           req.UseStdin,
+			TranscriptLimit:    req.TranscriptLimit,
+		}
+		var err error
+		remoteReq.ManagerSandbox, err = p.upload(ctx, m, req.ManagerSandbox)
+		defer p.clearUploaded(ctx, m, remoteReq.ManagerSandbox)
+		if err != nil {
+			return err
+		}
+		for _, userReq := range req.UserSandboxConfigs {
+			remoteUser, err := p.upload(ctx, m, userReq)
+			defer p.clearUploaded(ctx, m, remoteUser)
+			if err != nil {
+				return err
+			}
+			remoteReq.UserSandboxConfigs = append(remoteReq.UserSandboxConfigs, remoteUser)
+		}
+
+		r, s, err := m.Client.RunMultibox3(ctx, remoteReq, managerMemQuota, individualMemQuota)
+		stats = s
+		if err != nil {
+			return err
+		}
+		resp = qualifyResponse(m, r)
+		return nil
+	})
+	return resp, stats, err
+}
+
+// Close stops the background health checks. Like GraderClient.Close, it does
+// not touch the graders themselves.
+func (p *GraderPool) Close(ctx context.Context) error {
+	p.stopHealth()
+	return nil
+}
+
+// Capacity returns the summed capacity of the pool's graders.
+func (p *GraderPool) Capacity() int {
+	var capacity int
+	for _, m := range p.members {
+		capacity += m.Capacity
+	}
+	return capacity
+}
+
+// dispatch runs fn on the best grader for ctx, failing over to the next best
+// one as long as fn fails with a transport error.
+func (p *GraderPool) dispatch(ctx context.Context, fn func(m *poolMember) error) error {
+	langs := requiredLanguages(ctx)
+	tried := make(map[*poolMember]bool)
+	for {
+		m := p.pick(langs, tried)
+		if m == nil {
+			if len(tried) > 0 {
+				return fmt.Errorf("%w for languages %v (tried %d)", errNoGrader, langs, len(tried))
+			}
+			return fmt.Errorf("%w for languages %v", errNoGrader, langs)
+		}
+		tried[m] = true
+
+		m.inflight.Add(1)
+		err := fn(m)
+		m.inflight.Add(-1)
+		if err == nil {
+			return nil
+		}
+		if ctx.Err() != nil || !isTransportError(err) {
+			return err
+		}
+
+		m.healthy.Store(false)
+		p.logger.WarnContext(ctx, "Grader unreachable, retrying on another grader", slog.String("grader", m.Name), slog.Any("err", err))
+	}
+}
+
+// pick returns the healthy, untried grader covering langs with the most free
+// capacity, or nil if there is none.
+func (p *GraderPool) pick(langs []string, tried map[*poolMember]bool) *poolMember {
+	var best *poolMember
+	var bestFree int64
+	for _, m := range p.members {
+		if tried[m] || !m.healthy.Load() || !m.supports(langs) {
+			continue
+		}
+		free := int64(m.Capacity) - m.inflight.Load()
+		if best == nil || free > bestFree {
+			best, bestFree = m, free
+		}
+	}
+	return best
+}
+
+func (p *GraderPool) healthLoop(ctx context.Context) {
+	ticker := time.NewTicker(poolHealthInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			p.checkAll(ctx)
+		}
+	}
+}
+
+func (p *GraderPool) checkAll(ctx context.Context) {
+	var wg sync.WaitGroup
+	for _, m := range p.members {
+		wg.Go(func() {
+			p.check(ctx, m)
+		})
+	}
+	wg.Wait()
+}
+
+// check probes a grader through the Languages RPC, refreshing its inventory.
+func (p *GraderPool) check(ctx context.Context, m *poolMember) {
+	ctx, cancel := context.WithTimeout(ctx, poolHealthTimeout)
+	defer cancel()
+
+	versions, err := m.Client.languageVersions(ctx)
+	if err != nil {
+		if m.healthy.Swap(false) {
+			p.logger.WarnContext(ctx, "Grader failed health check", slog.String("grader", m.Name), slog.Any("err", err))
+		}
+		return
+	}
+	m.versions.Store(&versions)
+	if !m.healthy.Swap(true) {
+		p.logger.InfoContext(ctx, "Grader is healthy", slog.String("grader", m.Name), slog.Int("languages", len(versions)))
+	}
+}
+
+// languageVersions returns the union of the inventories of all healthy
+// graders, so the platform offers every language at least one grader has.
+func (p *GraderPool) languageVersions(ctx context.Context) (map[string]string, error) {
+	p.checkAll(ctx)
+	versions := make(map[string]string)
+	var healthy bool
+	for _, m := range p.members {
+		if !m.healthy.Load() {
+			continue
+		}
+		healthy = true
+		for lang, ver := range *m.versions.Load() {
+			if _, ok := versions[lang]; !ok {
+				versions[lang] = ver
+			}
+		}
+	}
+	if !healthy {
+		return nil, errNoGrader
+	}
+	return versions, nil
+}
+
+// upload copies the staged inputs of req to m's scratch, returning a copy of
+// req that references the remote identifiers. The returned request is non-nil
+// even on error, so the caller can clean up whatever was uploaded.
+func (p *GraderPool) upload(ctx context.Context, m *poolMember, req *eval.Box3Request) (*eval.Box3Request, error) {
+	if req == nil {
+		return nil, nil
+	}
+	remote := *req
+	remote.InputFiles = make([]eval.ScratchFile, 0, len(req.InputFiles))
+	for _, file := range req.InputFiles {
+		rc, err := p.staging.ReadFile(file.Identifier)
+		if err != nil {
+			return &remote, err
+		}
+		identifier, err := m.Scratch.SaveFile(rc)
+		if err := rc.Close(); err != nil {
+			p.logger.WarnContext(ctx, "Could not close staged scratch file", slog.Any("err", err))
+		}
+		if err != nil {
+			return &remote, err
+		}
+		file.Identifier = identifier
+		remote.InputFiles = append(remote.InputFiles, file)
+	}
+	return &remote, nil
+}
+
+func (p *GraderPool) clearUploaded(ctx context.Context, m *poolMember, req *eval.Box3Request) {
+	if req == nil {
+		return
+	}
+	for _, file := range req.InputFiles {
+		if err := m.Scratch.DeleteFile(file.Identifier); err != nil {
+			p.logger.WarnContext(ctx, "Could not clean up remote scratch file", slog.String("grader", m.Name), slog.Any("err", err))
+		}
+	}
+}
+
+// qualifyResponse prefixes output identifiers with the grader name, so that
+// poolScratch knows where to read them from.
+func qualifyResponse(m *poolMember, resp *eval.Box3Response) *eval.Box3Response {
+	if resp == nil {
+		return nil
+	}
+	files := make(map[string]string, len(resp.Files))
+	for path, identifier := range resp.Files {
+		files[path] = m.Name + ":" + identifier
+	}
+	return &eval.Box3Response{Stats: resp.Stats, Files: files}
+}
+
+// isTransportError reports whether err means the grader could not be reached,
+// as opposed to the grader rejecting or failing the request itself.
+func isTransportError(err error) bool {
+	if connect.CodeOf(err) == connect.CodeUnavailable {
+		return true
+	}
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+var _ eval.Scratch = (*poolScratch)(nil)
+
+// poolScratch saves new files to the pool's staging scratch and reads or
+// deletes grader-qualified identifiers from the grader that holds them.
+type poolScratch struct {
+	pool *GraderPool
+}
+
+func (s *poolScratch) resolve(identifier string) (eval.Scratch, string, error) {
+	name, id, ok := strings.Cut(identifier, ":")
+	if !ok {
+		return s.pool.staging, identifier, nil
+	}
+	for _, m := range s.pool.members {
+		if m.Name == name {
+			return m.Scratch, id, nil
+		}
+	}
+	return nil, "", fmt.Errorf("unknown grader %q in scratch identifier", name)
+}
+
+func (s *poolScratch) SaveFile(r io.Reader) (string, error) {
+	return s.pool.staging.SaveFile(r)
+}
+
+func (s *poolScratch) ReadFile(identifier string) (io.ReadCloser, error) {
+	sc, id, err := s.resolve(identifier)
+	if err != nil {
+		return nil, err
+	}
+	return sc.ReadFile(id)
+}
+
+func (s *poolScratch) DeleteFile(identifier string) error {
+	sc, id, err := s.resolve(identifier)
+	if err != nil {
+		return err
+	}
+	return sc.DeleteFile(id)
+}
+
+type languagesKey struct{}
+
+// WithLanguages attaches language names to ctx that any grader running
+// requests made with it must support. Calls accumulate, so a submission can
+// require both its own language and its checker's.
+func WithLanguages(ctx context.Context, langs ...string) context.Context {
+	prev := requiredLanguages(ctx)
+	merged := slices.Clone(prev)
+	for _, lang := range langs {
+		if lang != "" && !slices.Contains(merged, lang) {
+			merged = append(merged, lang)
+		}
+	}
+	return context.WithValue(ctx, languagesKey{}, merged)
+}
+
+func requiredLanguages(ctx context.Context) []string {
+	langs, _ := ctx.Value(languagesKey{}).([]string)
+	return langs
+}
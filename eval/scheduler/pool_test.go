@@ -0,0 +1,189 @@
+package scheduler
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/KiloProjects/kilonova/eval"
+	"github.com/KiloProjects/kilonova/eval/language"
+	"github.com/KiloProjects/kilonova/eval/scratch"
+	"github.com/spf13/afero"
+)
+
+// staticLangs is a stub LanguageManager that only answers LanguageVersions.
+type staticLangs map[string]string
+
+func (s staticLangs) Language(string) language.GraderLang             { return nil }
+func (s staticLangs) Languages() map[string]language.GraderLang       { return nil }
+func (s staticLangs) LanguageFromFilename(string) language.GraderLang { return nil }
+func (s staticLangs) LanguageVersions(context.Context) map[string]string {
+	return s
+}
+
+type testGrader struct {
+	srv   *httptest.Server
+	sched *echoSched
+	ran   int
+}
+
+// startPoolGrader starts a grader serving the given languages, backed by an
+// echoSched on its own in-memory scratch.
+func startPoolGrader(t *testing.T, langs staticLangs) (*testGrader, PoolMember) {
+	t.Helper()
+	reg := NewClientRegistry()
+	if err := reg.Add("token", "platform-test", ""); err != nil {
+		t.Fatal(err)
+	}
+	g := &testGrader{sched: &echoSched{scratch: scratch.New(afero.NewMemMapFs())}}
+	path, handler := NewGraderServer(&countingSched{echoSched: g.sched, count: &g.ran}, langs).Handler(reg)
+	mux := http.NewServeMux()
+	mux.Handle(path, handler)
+	g.srv = httptest.NewServer(mux)
+	t.Cleanup(g.srv.Close)
+	return g, PoolMember{
+		Client:  NewGraderClient(http.DefaultClient, g.srv.URL, "token"),
+		Scratch: g.sched.scratch,
+	}
+}
+
+type countingSched struct {
+	*echoSched
+	count *int
+}
+
+func (c *countingSched) RunBox3(ctx context.Context, req *eval.Box3Request, memQuota int64) (*eval.Box3Response, error) {
+	*c.count++
+	return c.echoSched.RunBox3(ctx, req, memQuota)
+}
+
+func newTestPool(t *testing.T, members ...PoolMember) *GraderPool {
+	t.Helper()
+	pool, err := NewGraderPool(context.Background(), members, scratch.New(afero.NewMemMapFs()), slog.Default())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { pool.Close(context.Background()) })
+	return pool
+}
+
+// runEcho stages payload through the pool scratch, runs it and reads the echoed output back.
+func runEcho(t *testing.T, ctx context.Context, pool *GraderPool, payload string) (string, error) {
+	t.Helper()
+	sc := pool.Scratch()
+	id, err := sc.SaveFile(strings.NewReader(payload))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := pool.RunBox3(ctx, &eval.Box3Request{
+		InputFiles: []eval.ScratchFile{{Identifier: id, BoxPath: "/box/in.txt"}},
+		Command:    []string{"/bin/cat"},
+	}, 0)
+	if err != nil {
+		return "", err
+	}
+	rc, err := sc.ReadFile(resp.Files["/box/in.txt"])
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rc.Close()
+	data, _ := io.ReadAll(rc)
+	return string(data), nil
+}
+
+func TestPoolFailsOverOnTransportError(t *testing.T) {
+	down, downMember := startPoolGrader(t, staticLangs{"cpp17": "g++ 14"})
+	up, upMember := startPoolGrader(t, staticLangs{"cpp17": "g++ 14"})
+	downMember.Name, downMember.Capacity = "down", 8
+	upMember.Name, upMember.Capacity = "up", 1
+
+	pool := newTestPool(t, downMember, upMember)
+	// Both graders passed the initial health check; "down" has more free
+	// capacity so it is picked first, then fails at the transport level.
+	down.srv.Close()
+
+	got, err := runEcho(t, context.Background(), pool, "failover")
+	if err != nil {
+		t.Fatalf("pool did not fail over: %v", err)
+	}
+	if got != "failover" {
+		t.Fatalf("output = %q, want %q", got, "failover")
+	}
+	if up.ran != 1 {
+		t.Fatalf("healthy grader ran %d boxes, want 1", up.ran)
+	}
+	if pool.members[0].healthy.Load() {
+		t.Fatal("unreachable grader is still marked healthy")
+	}
+}
+
+func TestPoolRoutesByLanguage(t *testing.T) {
+	cpp, cppMember := startPoolGrader(t, staticLangs{"cpp17": "g++ 14"})
+	py, pyMember := startPoolGrader(t, staticLangs{"python3": "3.13"})
+	cppMember.Name, cppMember.Capacity = "cpp", 8
+	pyMember.Name = "py"
+
+	pool := newTestPool(t, cppMember, pyMember)
+
+	ctx := WithLanguages(context.Background(), "python3")
+	if _, err := runEcho(t, ctx, pool, "x"); err != nil {
+		t.Fatal(err)
+	}
+	if py.ran != 1 || cpp.ran != 0 {
+		t.Fatalf("python3 request ran on cpp=%d py=%d, want only py", cpp.ran, py.ran)
+	}
+
+	ctx = WithLanguages(ctx, "cpp17")
+	if _, err := runEcho(t, ctx, pool, "x"); err == nil {
+		t.Fatal("request needing python3 and cpp17 was served by a grader that lacks one of them")
+	}
+
+	versions, err := pool.languageVersions(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(versions) != 2 {
+		t.Fatalf("pool inventory = %v, want the union of both graders", versions)
+	}
+}
+
+// cappedSched reports a fixed capacity, like a BoxManager
+type cappedSched struct {
+	*echoSched
+	capacity int
+}
+
+func (c *cappedSched) Capacity() int { return c.capacity }
+
+func TestClientCapacityFromGrader(t *testing.T) {
+	reg := NewClientRegistry()
+	if err := reg.Add("token", "platform-test", ""); err != nil {
+		t.Fatal(err)
+	}
+	sched := &cappedSched{echoSched: &echoSched{scratch: scratch.New(afero.NewMemMapFs())}, capacity: 6}
+	path, handler := NewGraderServer(sched, staticLangs{"cpp17": "g++ 13"}).Handler(reg)
+	mux := http.NewServeMux()
+	mux.Handle(path, handler)
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	client := NewGraderClient(http.DefaultClient, srv.URL, "token")
+	if client.Capacity() != 0 {
+		t.Fatalf("capacity should be unknown before the first language inventory, got %d", client.Capacity())
+	}
+	if _, err := client.languageVersions(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if client.Capacity() != 6 {
+		t.Fatalf("got capacity %d, want the grader's 6", client.Capacity())
+	}
+
+	wrapper := NewBox2Wrapper(sched.scratch, nil, client)
+	if c, ok := wrapper.(eval.CapacityScheduler); !ok || c.Capacity() != 6 {
+		t.Fatal("the box2 wrapper should report the remote grader's capacity")
+	}
+}
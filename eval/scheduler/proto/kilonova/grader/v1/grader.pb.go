@@ -26,6 +26,62 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+// Box3EventKind mirrors eval.Box3EventKind, plus the final FINISHED event.
+type Box3EventKind int32
+
+const (
+	Box3EventKind_BOX3_EVENT_KIND_UNSPECIFIED Box3EventKind = 0
+	Box3EventKind_BOX3_EVENT_KIND_QUEUED      Box3EventKind = 1
+	Box3EventKind_BOX3_EVENT_KIND_STARTED     Box3EventKind = 2
+	Box3EventKind_BOX3_EVENT_KIND_PROGRESS    Box3EventKind = 3
+	Box3EventKind_BOX3_EVENT_KIND_FINISHED    Box3EventKind = 4
+)
+
+// Enum value maps for Box3EventKind.
+var (
+	Box3EventKind_name = map[int32]string{
+		0: "BOX3_EVENT_KIND_UNSPECIFIED",
+		1: "BOX3_EVENT_KIND_QUEUED",
+		2: "BOX3_EVENT_KIND_STARTED",
+		3: "BOX3_EVENT_KIND_PROGRESS",
+		4: "BOX3_EVENT_KIND_FINISHED",
+	}
+	Box3EventKind_value = map[string]int32{
+		"BOX3_EVENT_KIND_UNSPECIFIED": 0,
+		"BOX3_EVENT_KIND_QUEUED":      1,
+		"BOX3_EVENT_KIND_STARTED":     2,
+		"BOX3_EVENT_KIND_PROGRESS":    3,
+		"BOX3_EVENT_KIND_FINISHED":    4,
+	}
+)
+
+func (x Box3EventKind) Enum() *Box3EventKind {
+	p := new(Box3EventKind)
+	*p = x
+	return p
+}
+
+func (x Box3EventKind) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (Box3EventKind) Descriptor() protoreflect.EnumDescriptor {
+	return file_kilonova_grader_v1_grader_proto_enumTypes[0].Descriptor()
+}
+
+func (Box3EventKind) Type() protoreflect.EnumType {
+	return &file_kilonova_grader_v1_grader_proto_enumTypes[0]
+}
+
+func (x Box3EventKind) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use Box3EventKind.Descriptor instead.
+func (Box3EventKind) EnumDescriptor() ([]byte, []int) {
+	return file_kilonova_grader_v1_grader_proto_rawDescGZIP(), []int{0}
+}
+
 // Directory mirrors eval/language.Directory.
 type Directory struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
@@ -309,6 +365,10 @@ type RunStats struct {
 	Time                float64                `protobuf:"fixed64,7,opt,name=time,proto3" json:"time,omitempty"`
 	InternalMessage     string                 `protobuf:"bytes,8,opt,name=internal_message,json=internalMessage,proto3" json:"internal_message,omitempty"`
 	MemoryLimitExceeded bool                   `protobuf:"varint,9,opt,name=memory_limit_exceeded,json=memoryLimitExceeded,proto3" json:"memory_limit_exceeded,omitempty"`
+	WallTime            float64                `protobuf:"fixed64,10,opt,name=wall_time,json=wallTime,proto3" json:"wall_time,omitempty"`
+	MaxRss              int32                  `protobuf:"varint,11,opt,name=max_rss,json=maxRss,proto3" json:"max_rss,omitempty"`
+	CswVoluntary        int32                  `protobuf:"varint,12,opt,name=csw_voluntary,json=cswVoluntary,proto3" json:"csw_voluntary,omitempty"`
+	CswForced           int32                  `protobuf:"varint,13,opt,name=csw_forced,json=cswForced,proto3" json:"csw_forced,omitempty"`
 	unknownFields       protoimpl.UnknownFields
 	sizeCache           protoimpl.SizeCache
 }
@@ -406,6 +466,34 @@ func (x *RunStats) GetMemoryLimitExceeded() bool {
 	return false
 }
 
+func (x *RunStats) GetWallTime() float64 {
+	if x != nil {
+		return x.WallTime
+	}
+	return 0
+}
+
+func (x *RunStats) GetMaxRss() int32 {
+	if x != nil {
+		return x.MaxRss
+	}
+	return 0
+}
+
+func (x *RunStats) GetCswVoluntary() int32 {
+	if x != nil {
+		return x.CswVoluntary
+	}
+	return 0
+}
+
+func (x *RunStats) GetCswForced() int32 {
+	if x != nil {
+		return x.CswForced
+	}
+	return 0
+}
+
 // Box3Request mirrors eval.Box3Request.
 type Box3Request struct {
 	state           protoimpl.MessageState `protogen:"open.v1"`
@@ -624,19 +712,85 @@ func (x *RunBox3Response) GetResponse() *Box3Response {
 	return nil
 }
 
+// RunBox3Event is one message of the RunBox3Stream response stream.
+type RunBox3Event struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Kind  Box3EventKind          `protobuf:"varint,1,opt,name=kind,proto3,enum=kilonova.grader.v1.Box3EventKind" json:"kind,omitempty"`
+	// stats holds interim stats for PROGRESS events.
+	Stats *RunStats `protobuf:"bytes,2,opt,name=stats,proto3" json:"stats,omitempty"`
+	// response is only set on the FINISHED event.
+	Response      *Box3Response `protobuf:"bytes,3,opt,name=response,proto3" json:"response,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RunBox3Event) Reset() {
+	*x = RunBox3Event{}
+	mi := &file_kilonova_grader_v1_grader_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RunBox3Event) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RunBox3Event) ProtoMessage() {}
+
+func (x *RunBox3Event) ProtoReflect() protoreflect.Message {
+	mi := &file_kilonova_grader_v1_grader_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RunBox3Event.ProtoReflect.Descriptor instead.
+func (*RunBox3Event) Descriptor() ([]byte, []int) {
+	return file_kilonova_grader_v1_grader_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *RunBox3Event) GetKind() Box3EventKind {
+	if x != nil {
+		return x.Kind
+	}
+	return Box3EventKind_BOX3_EVENT_KIND_UNSPECIFIED
+}
+
+func (x *RunBox3Event) GetStats() *RunStats {
+	if x != nil {
+		return x.Stats
+	}
+	return nil
+}
+
+func (x *RunBox3Event) GetResponse() *Box3Response {
+	if x != nil {
+		return x.Response
+	}
+	return nil
+}
+
 // Multibox3Request mirrors eval.Multibox3Request.
 type Multibox3Request struct {
 	state              protoimpl.MessageState `protogen:"open.v1"`
 	ManagerSandbox     *Box3Request           `protobuf:"bytes,1,opt,name=manager_sandbox,json=managerSandbox,proto3" json:"manager_sandbox,omitempty"`
 	UserSandboxConfigs []*Box3Request         `protobuf:"bytes,2,rep,name=user_sandbox_configs,json=userSandboxConfigs,proto3" json:"user_sandbox_configs,omitempty"`
 	UseStdin           bool                   `protobuf:"varint,3,opt,name=use_stdin,json=useStdin,proto3" json:"use_stdin,omitempty"`
-	unknownFields      protoimpl.UnknownFields
-	sizeCache          protoimpl.SizeCache
+	// transcript_limit enables the manager <-> user transcript if positive. It is
+	// returned in the manager response's files, under eval.TranscriptPath.
+	TranscriptLimit int64 `protobuf:"varint,4,opt,name=transcript_limit,json=transcriptLimit,proto3" json:"transcript_limit,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
 }
 
 func (x *Multibox3Request) Reset() {
 	*x = Multibox3Request{}
-	mi := &file_kilonova_grader_v1_grader_proto_msgTypes[8]
+	mi := &file_kilonova_grader_v1_grader_proto_msgTypes[9]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -648,7 +802,7 @@ func (x *Multibox3Request) String() string {
 func (*Multibox3Request) ProtoMessage() {}
 
 func (x *Multibox3Request) ProtoReflect() protoreflect.Message {
-	mi := &file_kilonova_grader_v1_grader_proto_msgTypes[8]
+	mi := &file_kilonova_grader_v1_grader_proto_msgTypes[9]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -661,7 +815,7 @@ func (x *Multibox3Request) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Multibox3Request.ProtoReflect.Descriptor instead.
 func (*Multibox3Request) Descriptor() ([]byte, []int) {
-	return file_kilonova_grader_v1_grader_proto_rawDescGZIP(), []int{8}
+	return file_kilonova_grader_v1_grader_proto_rawDescGZIP(), []int{9}
 }
 
 func (x *Multibox3Request) GetManagerSandbox() *Box3Request {
@@ -685,6 +839,13 @@ func (x *Multibox3Request) GetUseStdin() bool {
 	return false
 }
 
+func (x *Multibox3Request) GetTranscriptLimit() int64 {
+	if x != nil {
+		return x.TranscriptLimit
+	}
+	return 0
+}
+
 type RunMultibox3Request struct {
 	state              protoimpl.MessageState `protogen:"open.v1"`
 	Request            *Multibox3Request      `protobuf:"bytes,1,opt,name=request,proto3" json:"request,omitempty"`
@@ -696,7 +857,7 @@ type RunMultibox3Request struct {
 
 func (x *RunMultibox3Request) Reset() {
 	*x = RunMultibox3Request{}
-	mi := &file_kilonova_grader_v1_grader_proto_msgTypes[9]
+	mi := &file_kilonova_grader_v1_grader_proto_msgTypes[10]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -708,7 +869,7 @@ func (x *RunMultibox3Request) String() string {
 func (*RunMultibox3Request) ProtoMessage() {}
 
 func (x *RunMultibox3Request) ProtoReflect() protoreflect.Message {
-	mi := &file_kilonova_grader_v1_grader_proto_msgTypes[9]
+	mi := &file_kilonova_grader_v1_grader_proto_msgTypes[10]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -721,7 +882,7 @@ func (x *RunMultibox3Request) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use RunMultibox3Request.ProtoReflect.Descriptor instead.
 func (*RunMultibox3Request) Descriptor() ([]byte, []int) {
-	return file_kilonova_grader_v1_grader_proto_rawDescGZIP(), []int{9}
+	return file_kilonova_grader_v1_grader_proto_rawDescGZIP(), []int{10}
 }
 
 func (x *RunMultibox3Request) GetRequest() *Multibox3Request {
@@ -756,7 +917,7 @@ type RunMultibox3Response struct {
 
 func (x *RunMultibox3Response) Reset() {
 	*x = RunMultibox3Response{}
-	mi := &file_kilonova_grader_v1_grader_proto_msgTypes[10]
+	mi := &file_kilonova_grader_v1_grader_proto_msgTypes[11]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -768,7 +929,7 @@ func (x *RunMultibox3Response) String() string {
 func (*RunMultibox3Response) ProtoMessage() {}
 
 func (x *RunMultibox3Response) ProtoReflect() protoreflect.Message {
-	mi := &file_kilonova_grader_v1_grader_proto_msgTypes[10]
+	mi := &file_kilonova_grader_v1_grader_proto_msgTypes[11]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -781,7 +942,7 @@ func (x *RunMultibox3Response) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use RunMultibox3Response.ProtoReflect.Descriptor instead.
 func (*RunMultibox3Response) Descriptor() ([]byte, []int) {
-	return file_kilonova_grader_v1_grader_proto_rawDescGZIP(), []int{10}
+	return file_kilonova_grader_v1_grader_proto_rawDescGZIP(), []int{11}
 }
 
 func (x *RunMultibox3Response) GetManagerResponse() *Box3Response {
@@ -806,7 +967,7 @@ type LanguagesRequest struct {
 
 func (x *LanguagesRequest) Reset() {
 	*x = LanguagesRequest{}
-	mi := &file_kilonova_grader_v1_grader_proto_msgTypes[11]
+	mi := &file_kilonova_grader_v1_grader_proto_msgTypes[12]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -818,7 +979,7 @@ func (x *LanguagesRequest) String() string {
 func (*LanguagesRequest) ProtoMessage() {}
 
 func (x *LanguagesRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_kilonova_grader_v1_grader_proto_msgTypes[11]
+	mi := &file_kilonova_grader_v1_grader_proto_msgTypes[12]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -831,20 +992,22 @@ func (x *LanguagesRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use LanguagesRequest.ProtoReflect.Descriptor instead.
 func (*LanguagesRequest) Descriptor() ([]byte, []int) {
-	return file_kilonova_grader_v1_grader_proto_rawDescGZIP(), []int{11}
+	return file_kilonova_grader_v1_grader_proto_rawDescGZIP(), []int{12}
 }
 
 type LanguagesResponse struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
 	// versions maps supported language name -> installed version string.
-	Versions      map[string]string `protobuf:"bytes,1,rep,name=versions,proto3" json:"versions,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Versions map[string]string `protobuf:"bytes,1,rep,name=versions,proto3" json:"versions,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	// capacity is the number of requests the grader runs at once. 0 if unknown.
+	Capacity      int32 `protobuf:"varint,2,opt,name=capacity,proto3" json:"capacity,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *LanguagesResponse) Reset() {
 	*x = LanguagesResponse{}
-	mi := &file_kilonova_grader_v1_grader_proto_msgTypes[12]
+	mi := &file_kilonova_grader_v1_grader_proto_msgTypes[13]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -856,7 +1019,7 @@ func (x *LanguagesResponse) String() string {
 func (*LanguagesResponse) ProtoMessage() {}
 
 func (x *LanguagesResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_kilonova_grader_v1_grader_proto_msgTypes[12]
+	mi := &file_kilonova_grader_v1_grader_proto_msgTypes[13]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -869,7 +1032,7 @@ func (x *LanguagesResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use LanguagesResponse.ProtoReflect.Descriptor instead.
 func (*LanguagesResponse) Descriptor() ([]byte, []int) {
-	return file_kilonova_grader_v1_grader_proto_rawDescGZIP(), []int{12}
+	return file_kilonova_grader_v1_grader_proto_rawDescGZIP(), []int{13}
 }
 
 func (x *LanguagesResponse) GetVersions() map[string]string {
@@ -879,6 +1042,13 @@ func (x *LanguagesResponse) GetVersions() map[string]string {
 	return nil
 }
 
+func (x *LanguagesResponse) GetCapacity() int32 {
+	if x != nil {
+		return x.Capacity
+	}
+	return 0
+}
+
 var File_kilonova_grader_v1_grader_proto protoreflect.FileDescriptor
 
 const file_kilonova_grader_v1_grader_proto_rawDesc = "" +
@@ -918,7 +1088,7 @@ const file_kilonova_grader_v1_grader_proto_rawDesc = "" +
 	"\vdirectories\x18\f \x03(\v2\x1d.kilonova.grader.v1.DirectoryR\vdirectories\x1a;\n" +
 	"\rEnvToSetEntry\x12\x10\n" +
 	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
-	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\x9d\x02\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\x97\x03\n" +
 	"\bRunStats\x12\x16\n" +
 	"\x06memory\x18\x01 \x01(\x05R\x06memory\x12\x1b\n" +
 	"\texit_code\x18\x02 \x01(\x05R\bexitCode\x12\x1f\n" +
@@ -929,7 +1099,13 @@ const file_kilonova_grader_v1_grader_proto_rawDesc = "" +
 	"\x06status\x18\x06 \x01(\tR\x06status\x12\x12\n" +
 	"\x04time\x18\a \x01(\x01R\x04time\x12)\n" +
 	"\x10internal_message\x18\b \x01(\tR\x0finternalMessage\x122\n" +
-	"\x15memory_limit_exceeded\x18\t \x01(\bR\x13memoryLimitExceeded\"\xd3\x01\n" +
+	"\x15memory_limit_exceeded\x18\t \x01(\bR\x13memoryLimitExceeded\x12\x1b\n" +
+	"\twall_time\x18\n" +
+	" \x01(\x01R\bwallTime\x12\x17\n" +
+	"\amax_rss\x18\v \x01(\x05R\x06maxRss\x12#\n" +
+	"\rcsw_voluntary\x18\f \x01(\x05R\fcswVoluntary\x12\x1d\n" +
+	"\n" +
+	"csw_forced\x18\r \x01(\x05R\tcswForced\"\xd3\x01\n" +
 	"\vBox3Request\x12@\n" +
 	"\vinput_files\x18\x01 \x03(\v2\x1f.kilonova.grader.v1.ScratchFileR\n" +
 	"inputFiles\x12\x18\n" +
@@ -948,11 +1124,16 @@ const file_kilonova_grader_v1_grader_proto_rawDesc = "" +
 	"\arequest\x18\x01 \x01(\v2\x1f.kilonova.grader.v1.Box3RequestR\arequest\x12\x1b\n" +
 	"\tmem_quota\x18\x02 \x01(\x03R\bmemQuota\"O\n" +
 	"\x0fRunBox3Response\x12<\n" +
-	"\bresponse\x18\x01 \x01(\v2 .kilonova.grader.v1.Box3ResponseR\bresponse\"\xcc\x01\n" +
+	"\bresponse\x18\x01 \x01(\v2 .kilonova.grader.v1.Box3ResponseR\bresponse\"\xb7\x01\n" +
+	"\fRunBox3Event\x125\n" +
+	"\x04kind\x18\x01 \x01(\x0e2!.kilonova.grader.v1.Box3EventKindR\x04kind\x122\n" +
+	"\x05stats\x18\x02 \x01(\v2\x1c.kilonova.grader.v1.RunStatsR\x05stats\x12<\n" +
+	"\bresponse\x18\x03 \x01(\v2 .kilonova.grader.v1.Box3ResponseR\bresponse\"\xf7\x01\n" +
 	"\x10Multibox3Request\x12H\n" +
 	"\x0fmanager_sandbox\x18\x01 \x01(\v2\x1f.kilonova.grader.v1.Box3RequestR\x0emanagerSandbox\x12Q\n" +
 	"\x14user_sandbox_configs\x18\x02 \x03(\v2\x1f.kilonova.grader.v1.Box3RequestR\x12userSandboxConfigs\x12\x1b\n" +
-	"\tuse_stdin\x18\x03 \x01(\bR\buseStdin\"\xb3\x01\n" +
+	"\tuse_stdin\x18\x03 \x01(\bR\buseStdin\x12)\n" +
+	"\x10transcript_limit\x18\x04 \x01(\x03R\x0ftranscriptLimit\"\xb3\x01\n" +
 	"\x13RunMultibox3Request\x12>\n" +
 	"\arequest\x18\x01 \x01(\v2$.kilonova.grader.v1.Multibox3RequestR\arequest\x12*\n" +
 	"\x11manager_mem_quota\x18\x02 \x01(\x03R\x0fmanagerMemQuota\x120\n" +
@@ -961,14 +1142,22 @@ const file_kilonova_grader_v1_grader_proto_rawDesc = "" +
 	"\x10manager_response\x18\x01 \x01(\v2 .kilonova.grader.v1.Box3ResponseR\x0fmanagerResponse\x12;\n" +
 	"\n" +
 	"user_stats\x18\x02 \x03(\v2\x1c.kilonova.grader.v1.RunStatsR\tuserStats\"\x12\n" +
-	"\x10LanguagesRequest\"\xa1\x01\n" +
+	"\x10LanguagesRequest\"\xbd\x01\n" +
 	"\x11LanguagesResponse\x12O\n" +
-	"\bversions\x18\x01 \x03(\v23.kilonova.grader.v1.LanguagesResponse.VersionsEntryR\bversions\x1a;\n" +
+	"\bversions\x18\x01 \x03(\v23.kilonova.grader.v1.LanguagesResponse.VersionsEntryR\bversions\x12\x1a\n" +
+	"\bcapacity\x18\x02 \x01(\x05R\bcapacity\x1a;\n" +
 	"\rVersionsEntry\x12\x10\n" +
 	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
-	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x012\xa0\x02\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01*\xa5\x01\n" +
+	"\rBox3EventKind\x12\x1f\n" +
+	"\x1bBOX3_EVENT_KIND_UNSPECIFIED\x10\x00\x12\x1a\n" +
+	"\x16BOX3_EVENT_KIND_QUEUED\x10\x01\x12\x1b\n" +
+	"\x17BOX3_EVENT_KIND_STARTED\x10\x02\x12\x1c\n" +
+	"\x18BOX3_EVENT_KIND_PROGRESS\x10\x03\x12\x1c\n" +
+	"\x18BOX3_EVENT_KIND_FINISHED\x10\x042\xf9\x02\n" +
 	"\rGraderService\x12R\n" +
-	"\aRunBox3\x12\".kilonova.grader.v1.RunBox3Request\x1a#.kilonova.grader.v1.RunBox3Response\x12a\n" +
+	"\aRunBox3\x12\".kilonova.grader.v1.RunBox3Request\x1a#.kilonova.grader.v1.RunBox3Response\x12W\n" +
+	"\rRunBox3Stream\x12\".kilonova.grader.v1.RunBox3Request\x1a .kilonova.grader.v1.RunBox3Event0\x01\x12a\n" +
 	"\fRunMultibox3\x12'.kilonova.grader.v1.RunMultibox3Request\x1a(.kilonova.grader.v1.RunMultibox3Response\x12X\n" +
 	"\tLanguages\x12$.kilonova.grader.v1.LanguagesRequest\x1a%.kilonova.grader.v1.LanguagesResponseBSZQgithub.com/KiloProjects/kilonova/eval/scheduler/proto/kilonova/grader/v1;graderv1b\x06proto3"
 
@@ -984,51 +1173,59 @@ func file_kilonova_grader_v1_grader_proto_rawDescGZIP() []byte {
 	return file_kilonova_grader_v1_grader_proto_rawDescData
 }
 
-var file_kilonova_grader_v1_grader_proto_msgTypes = make([]protoimpl.MessageInfo, 16)
+var file_kilonova_grader_v1_grader_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_kilonova_grader_v1_grader_proto_msgTypes = make([]protoimpl.MessageInfo, 17)
 var file_kilonova_grader_v1_grader_proto_goTypes = []any{
-	(*Directory)(nil),            // 0: kilonova.grader.v1.Directory
-	(*ScratchFile)(nil),          // 1: kilonova.grader.v1.ScratchFile
-	(*RunConfig)(nil),            // 2: kilonova.grader.v1.RunConfig
-	(*RunStats)(nil),             // 3: kilonova.grader.v1.RunStats
-	(*Box3Request)(nil),          // 4: kilonova.grader.v1.Box3Request
-	(*Box3Response)(nil),         // 5: kilonova.grader.v1.Box3Response
-	(*RunBox3Request)(nil),       // 6: kilonova.grader.v1.RunBox3Request
-	(*RunBox3Response)(nil),      // 7: kilonova.grader.v1.RunBox3Response
-	(*Multibox3Request)(nil),     // 8: kilonova.grader.v1.Multibox3Request
-	(*RunMultibox3Request)(nil),  // 9: kilonova.grader.v1.RunMultibox3Request
-	(*RunMultibox3Response)(nil), // 10: kilonova.grader.v1.RunMultibox3Response
-	(*LanguagesRequest)(nil),     // 11: kilonova.grader.v1.LanguagesRequest
-	(*LanguagesResponse)(nil),    // 12: kilonova.grader.v1.LanguagesResponse
-	nil,                          // 13: kilonova.grader.v1.RunConfig.EnvToSetEntry
-	nil,                          // 14: kilonova.grader.v1.Box3Response.FilesEntry
-	nil,                          // 15: kilonova.grader.v1.LanguagesResponse.VersionsEntry
+	(Box3EventKind)(0),           // 0: kilonova.grader.v1.Box3EventKind
+	(*Directory)(nil),            // 1: kilonova.grader.v1.Directory
+	(*ScratchFile)(nil),          // 2: kilonova.grader.v1.ScratchFile
+	(*RunConfig)(nil),            // 3: kilonova.grader.v1.RunConfig
+	(*RunStats)(nil),             // 4: kilonova.grader.v1.RunStats
+	(*Box3Request)(nil),          // 5: kilonova.grader.v1.Box3Request
+	(*Box3Response)(nil),         // 6: kilonova.grader.v1.Box3Response
+	(*RunBox3Request)(nil),       // 7: kilonova.grader.v1.RunBox3Request
+	(*RunBox3Response)(nil),      // 8: kilonova.grader.v1.RunBox3Response
+	(*RunBox3Event)(nil),         // 9: kilonova.grader.v1.RunBox3Event
+	(*Multibox3Request)(nil),     // 10: kilonova.grader.v1.Multibox3Request
+	(*RunMultibox3Request)(nil),  // 11: kilonova.grader.v1.RunMultibox3Request
+	(*RunMultibox3Response)(nil), // 12: kilonova.grader.v1.RunMultibox3Response
+	(*LanguagesRequest)(nil),     // 13: kilonova.grader.v1.LanguagesRequest
+	(*LanguagesResponse)(nil),    // 14: kilonova.grader.v1.LanguagesResponse
+	nil,                          // 15: kilonova.grader.v1.RunConfig.EnvToSetEntry
+	nil,                          // 16: kilonova.grader.v1.Box3Response.FilesEntry
+	nil,                          // 17: kilonova.grader.v1.LanguagesResponse.VersionsEntry
 }
 var file_kilonova_grader_v1_grader_proto_depIdxs = []int32{
-	13, // 0: kilonova.grader.v1.RunConfig.env_to_set:type_name -> kilonova.grader.v1.RunConfig.EnvToSetEntry
-	0,  // 1: kilonova.grader.v1.RunConfig.directories:type_name -> kilonova.grader.v1.Directory
-	1,  // 2: kilonova.grader.v1.Box3Request.input_files:type_name -> kilonova.grader.v1.ScratchFile
-	2,  // 3: kilonova.grader.v1.Box3Request.run_config:type_name -> kilonova.grader.v1.RunConfig
-	3,  // 4: kilonova.grader.v1.Box3Response.stats:type_name -> kilonova.grader.v1.RunStats
-	14, // 5: kilonova.grader.v1.Box3Response.files:type_name -> kilonova.grader.v1.Box3Response.FilesEntry
-	4,  // 6: kilonova.grader.v1.RunBox3Request.request:type_name -> kilonova.grader.v1.Box3Request
-	5,  // 7: kilonova.grader.v1.RunBox3Response.response:type_name -> kilonova.grader.v1.Box3Response
-	4,  // 8: kilonova.grader.v1.Multibox3Request.manager_sandbox:type_name -> kilonova.grader.v1.Box3Request
-	4,  // 9: kilonova.grader.v1.Multibox3Request.user_sandbox_configs:type_name -> kilonova.grader.v1.Box3Request
-	8,  // 10: kilonova.grader.v1.RunMultibox3Request.request:type_name -> kilonova.grader.v1.Multibox3Request
-	5,  // 11: kilonova.grader.v1.RunMultibox3Response.manager_response:type_name -> kilonova.grader.v1.Box3Response
-	3,  // 12: kilonova.grader.v1.RunMultibox3Response.user_stats:type_name -> kilonova.grader.v1.RunStats
-	15, // 13: kilonova.grader.v1.LanguagesResponse.versions:type_name -> kilonova.grader.v1.LanguagesResponse.VersionsEntry
-	6,  // 14: kilonova.grader.v1.GraderService.RunBox3:input_type -> kilonova.grader.v1.RunBox3Request
-	9,  // 15: kilonova.grader.v1.GraderService.RunMultibox3:input_type -> kilonova.grader.v1.RunMultibox3Request
-	11, // 16: kilonova.grader.v1.GraderService.Languages:input_type -> kilonova.grader.v1.LanguagesRequest
-	7,  // 17: kilonova.grader.v1.GraderService.RunBox3:output_type -> kilonova.grader.v1.RunBox3Response
-	10, // 18: kilonova.grader.v1.GraderService.RunMultibox3:output_type -> kilonova.grader.v1.RunMultibox3Response
-	12, // 19: kilonova.grader.v1.GraderService.Languages:output_type -> kilonova.grader.v1.LanguagesResponse
-	17, // [17:20] is the sub-list for method output_type
-	14, // [14:17] is the sub-list for method input_type
-	14, // [14:14] is the sub-list for extension type_name
-	14, // [14:14] is the sub-list for extension extendee
-	0,  // [0:14] is the sub-list for field type_name
+	15, // 0: kilonova.grader.v1.RunConfig.env_to_set:type_name -> kilonova.grader.v1.RunConfig.EnvToSetEntry
+	1,  // 1: kilonova.grader.v1.RunConfig.directories:type_name -> kilonova.grader.v1.Directory
+	2,  // 2: kilonova.grader.v1.Box3Request.input_files:type_name -> kilonova.grader.v1.ScratchFile
+	3,  // 3: kilonova.grader.v1.Box3Request.run_config:type_name -> kilonova.grader.v1.RunConfig
+	4,  // 4: kilonova.grader.v1.Box3Response.stats:type_name -> kilonova.grader.v1.RunStats
+	16, // 5: kilonova.grader.v1.Box3Response.files:type_name -> kilonova.grader.v1.Box3Response.FilesEntry
+	5,  // 6: kilonova.grader.v1.RunBox3Request.request:type_name -> kilonova.grader.v1.Box3Request
+	6,  // 7: kilonova.grader.v1.RunBox3Response.response:type_name -> kilonova.grader.v1.Box3Response
+	0,  // 8: kilonova.grader.v1.RunBox3Event.kind:type_name -> kilonova.grader.v1.Box3EventKind
+	4,  // 9: kilonova.grader.v1.RunBox3Event.stats:type_name -> kilonova.grader.v1.RunStats
+	6,  // 10: kilonova.grader.v1.RunBox3Event.response:type_name -> kilonova.grader.v1.Box3Response
+	5,  // 11: kilonova.grader.v1.Multibox3Request.manager_sandbox:type_name -> kilonova.grader.v1.Box3Request
+	5,  // 12: kilonova.grader.v1.Multibox3Request.user_sandbox_configs:type_name -> kilonova.grader.v1.Box3Request
+	10, // 13: kilonova.grader.v1.RunMultibox3Request.request:type_name -> kilonova.grader.v1.Multibox3Request
+	6,  // 14: kilonova.grader.v1.RunMultibox3Response.manager_response:type_name -> kilonova.grader.v1.Box3Response
+	4,  // 15: kilonova.grader.v1.RunMultibox3Response.user_stats:type_name -> kilonova.grader.v1.RunStats
+	17, // 16: kilonova.grader.v1.LanguagesResponse.versions:type_name -> kilonova.grader.v1.LanguagesResponse.VersionsEntry
+	7,  // 17: kilonova.grader.v1.GraderService.RunBox3:input_type -> kilonova.grader.v1.RunBox3Request
+	7,  // 18: kilonova.grader.v1.GraderService.RunBox3Stream:input_type -> kilonova.grader.v1.RunBox3Request
+	11, // 19: kilonova.grader.v1.GraderService.RunMultibox3:input_type -> kilonova.grader.v1.RunMultibox3Request
+	13, // 20: kilonova.grader.v1.GraderService.Languages:input_type -> kilonova.grader.v1.LanguagesRequest
+	8,  // 21: kilonova.grader.v1.GraderService.RunBox3:output_type -> kilonova.grader.v1.RunBox3Response
+	9,  // 22: kilonova.grader.v1.GraderService.RunBox3Stream:output_type -> kilonova.grader.v1.RunBox3Event
+	12, // 23: kilonova.grader.v1.GraderService.RunMultibox3:output_type -> kilonova.grader.v1.RunMultibox3Response
+	14, // 24: kilonova.grader.v1.GraderService.Languages:output_type -> kilonova.grader.v1.LanguagesResponse
+	21, // [21:25] is the sub-list for method output_type
+	17, // [17:21] is the sub-list for method input_type
+	17, // [17:17] is the sub-list for extension type_name
+	17, // [17:17] is the sub-list for extension extendee
+	0,  // [0:17] is the sub-list for field type_name
 }
 
 func init() { file_kilonova_grader_v1_grader_proto_init() }
@@ -1041,13 +1238,14 @@ func file_kilonova_grader_v1_grader_proto_init() {
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_kilonova_grader_v1_grader_proto_rawDesc), len(file_kilonova_grader_v1_grader_proto_rawDesc)),
-			NumEnums:      0,
-			NumMessages:   16,
+			NumEnums:      1,
+			NumMessages:   17,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
 		GoTypes:           file_kilonova_grader_v1_grader_proto_goTypes,
 		DependencyIndexes: file_kilonova_grader_v1_grader_proto_depIdxs,
+		EnumInfos:         file_kilonova_grader_v1_grader_proto_enumTypes,
 		MessageInfos:      file_kilonova_grader_v1_grader_proto_msgTypes,
 	}.Build()
 	File_kilonova_grader_v1_grader_proto = out.File
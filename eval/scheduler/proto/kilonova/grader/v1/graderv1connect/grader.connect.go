@@ -39,6 +39,9 @@ const (
 const (
 	// GraderServiceRunBox3Procedure is the fully-qualified name of the GraderService's RunBox3 RPC.
 	GraderServiceRunBox3Procedure = "/kilonova.grader.v1.GraderService/RunBox3"
+	// GraderServiceRunBox3StreamProcedure is the fully-qualified name of the GraderService's
+	// RunBox3Stream RPC.
+	GraderServiceRunBox3StreamProcedure = "/kilonova.grader.v1.GraderService/RunBox3Stream"
 	// GraderServiceRunMultibox3Procedure is the fully-qualified name of the GraderService's
 	// RunMultibox3 RPC.
 	GraderServiceRunMultibox3Procedure = "/kilonova.grader.v1.GraderService/RunMultibox3"
@@ -49,6 +52,11 @@ const (
 // GraderServiceClient is a client for the kilonova.grader.v1.GraderService service.
 type GraderServiceClient interface {
 	RunBox3(context.Context, *connect.Request[v1.RunBox3Request]) (*connect.Response[v1.RunBox3Response], error)
+	// RunBox3Stream is RunBox3 with progress reporting: the grader sends QUEUED
+	// while the request waits for a free box, STARTED once it runs, periodic
+	// PROGRESS events with interim stats, and a final FINISHED event carrying
+	// the response.
+	RunBox3Stream(context.Context, *connect.Request[v1.RunBox3Request]) (*connect.ServerStreamForClient[v1.RunBox3Event], error)
 	RunMultibox3(context.Context, *connect.Request[v1.RunMultibox3Request]) (*connect.Response[v1.RunMultibox3Response], error)
 	// Languages returns the grader-supported language names mapped to their
 	// installed version string. The platform rebuilds full language behavior from
@@ -73,6 +81,12 @@ func NewGraderServiceClient(httpClient connect.HTTPClient, baseURL string, opts
 			connect.WithSchema(graderServiceMethods.ByName("RunBox3")),
 			connect.WithClientOptions(opts...),
 		),
+		runBox3Stream: connect.NewClient[v1.RunBox3Request, v1.RunBox3Event](
+			httpClient,
+			baseURL+GraderServiceRunBox3StreamProcedure,
+			connect.WithSchema(graderServiceMethods.ByName("RunBox3Stream")),
+			connect.WithClientOptions(opts...),
+		),
 		runMultibox3: connect.NewClient[v1.RunMultibox3Request, v1.RunMultibox3Response](
 			httpClient,
 			baseURL+GraderServiceRunMultibox3Procedure,
@@ -90,9 +104,10 @@ func NewGraderServiceClient(httpClient connect.HTTPClient, baseURL string, opts
 
 // graderServiceClient implements GraderServiceClient.
 type graderServiceClient struct {
-	runBox3      *connect.Client[v1.RunBox3Request, v1.RunBox3Response]
-	runMultibox3 *connect.Client[v1.RunMultibox3Request, v1.RunMultibox3Response]
-	languages    *connect.Client[v1.LanguagesRequest, v1.LanguagesResponse]
+	runBox3       *connect.Client[v1.RunBox3Request, v1.RunBox3Response]
+	runBox3Stream *connect.Client[v1.RunBox3Request, v1.RunBox3Event]
+	runMultibox3  *connect.Client[v1.RunMultibox3Request, v1.RunMultibox3Response]
+	languages     *connect.Client[v1.LanguagesRequest, v1.LanguagesResponse]
 }
 
 // RunBox3 calls kilonova.grader.v1.GraderService.RunBox3.
@@ -100,6 +115,11 @@ func (c *graderServiceClient) RunBox3(ctx context.Context, req *connect.Request[
 	return c.runBox3.CallUnary(ctx, req)
 }
 
+// RunBox3Stream calls kilonova.grader.v1.GraderService.RunBox3Stream.
+func (c *graderServiceClient) RunBox3Stream(ctx context.Context, req *connect.Request[v1.RunBox3Request]) (*connect.ServerStreamForClient[v1.RunBox3Event], error) {
+	return c.runBox3Stream.CallServerStream(ctx, req)
+}
+
 // RunMultibox3 calls kilonova.grader.v1.GraderService.RunMultibox3.
 func (c *graderServiceClient) RunMultibox3(ctx context.Context, req *connect.Request[v1.RunMultibox3Request]) (*connect.Response[v1.RunMultibox3Response], error) {
 	return c.runMultibox3.CallUnary(ctx, req)
@@ -113,6 +133,11 @@ func (c *graderServiceClient) Languages(ctx context.Context, req *connect.Reques
 // GraderServiceHandler is an implementation of the kilonova.grader.v1.GraderService service.
 type GraderServiceHandler interface {
 	RunBox3(context.Context, *connect.Request[v1.RunBox3Request]) (*connect.Response[v1.RunBox3Response], error)
+	// RunBox3Stream is RunBox3 with progress reporting: the grader sends QUEUED
+	// while the request waits for a free box, STARTED once it runs, periodic
+	// PROGRESS events with interim stats, and a final FINISHED event carrying
+	// the response.
+	RunBox3Stream(context.Context, *connect.Request[v1.RunBox3Request], *connect.ServerStream[v1.RunBox3Event]) error
 	RunMultibox3(context.Context, *connect.Request[v1.RunMultibox3Request]) (*connect.Response[v1.RunMultibox3Response], error)
 	// Languages returns the grader-supported language names mapped to their
 	// installed version string. The platform rebuilds full language behavior from
@@ -133,6 +158,12 @@ func NewGraderServiceHandler(svc GraderServiceHandler, opts ...connect.HandlerOp
 		connect.WithSchema(graderServiceMethods.ByName("RunBox3")),
 		connect.WithHandlerOptions(opts...),
 	)
+	graderServiceRunBox3StreamHandler := connect.NewServerStreamHandler(
+		GraderServiceRunBox3StreamProcedure,
+		svc.RunBox3Stream,
+		connect.WithSchema(graderServiceMethods.ByName("RunBox3Stream")),
+		connect.WithHandlerOptions(opts...),
+	)
 	graderServiceRunMultibox3Handler := connect.NewUnaryHandler(
 		GraderServiceRunMultibox3Procedure,
 		svc.RunMultibox3,
@@ -149,6 +180,8 @@ func NewGraderServiceHandler(svc GraderServiceHandler, opts ...connect.HandlerOp
 		switch r.URL.Path {
 		case GraderServiceRunBox3Procedure:
 			graderServiceRunBox3Handler.ServeHTTP(w, r)
+		case GraderServiceRunBox3StreamProcedure:
+			graderServiceRunBox3StreamHandler.ServeHTTP(w, r)
 		case GraderServiceRunMultibox3Procedure:
 			graderServiceRunMultibox3Handler.ServeHTTP(w, r)
 		case GraderServiceLanguagesProcedure:
@@ -166,6 +199,10 @@ func (UnimplementedGraderServiceHandler) RunBox3(context.Context, *connect.Reque
 	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("kilonova.grader.v1.GraderService.RunBox3 is not implemented"))
 }
 
+func (UnimplementedGraderServiceHandler) RunBox3Stream(context.Context, *connect.Request[v1.RunBox3Request], *connect.ServerStream[v1.RunBox3Event]) error {
+	return connect.NewError(connect.CodeUnimplemented, errors.New("kilonova.grader.v1.GraderService.RunBox3Stream is not implemented"))
+}
+
 func (UnimplementedGraderServiceHandler) RunMultibox3(context.Context, *connect.Request[v1.RunMultibox3Request]) (*connect.Response[v1.RunMultibox3Response], error) {
 	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("kilonova.grader.v1.GraderService.RunMultibox3 is not implemented"))
 }
@@ -0,0 +1,88 @@
+package scheduler
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestPriorityQueueServesHigherPriorityFirst queues bulk requests from a low
+// priority client before a high priority one, and checks the high priority
+// request still runs as soon as the box frees up.
+func TestPriorityQueueServesHigherPriorityFirst(t *testing.T) {
+	q := newPriorityQueue(1)
+	bulk := ClientIdentity{Name: "staging", priority: priorityClasses["low"]}
+	contest := ClientIdentity{Name: "contest", priority: priorityClasses["high"]}
+
+	if err := q.Acquire(context.Background(), bulk, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	var mu sync.Mutex
+	var order []string
+	var wg sync.WaitGroup
+	enqueue := func(client ClientIdentity, queued int) {
+		wg.Go(func() {
+			if err := q.Acquire(context.Background(), client, 1); err != nil {
+				t.Error(err)
+				return
+			}
+			mu.Lock()
+			order = append(order, client.Name)
+			mu.Unlock()
+			q.Release(1)
+		})
+		// Make sure arrival order is deterministic
+		waitForWaiters(t, q, queued)
+	}
+	enqueue(bulk, 1)
+	enqueue(bulk, 2)
+	enqueue(contest, 3)
+
+	q.Release(1)
+	wg.Wait()
+
+	want := []string{"contest", "staging", "staging"}
+	if !slices.Equal(order, want) {
+		t.Fatalf("served in order %v, want %v", order, want)
+	}
+}
+
+func TestPriorityQueueCancelledWaiterLeavesQueue(t *testing.T) {
+	q := newPriorityQueue(1)
+	if err := q.Acquire(context.Background(), localClient, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errCh := make(chan error, 1)
+	go func() { errCh <- q.Acquire(ctx, localClient, 1) }()
+	waitForWaiters(t, q, 1)
+	cancel()
+	if err := <-errCh; err == nil {
+		t.Fatal("cancelled Acquire succeeded")
+	}
+
+	q.Release(1)
+	if err := q.Acquire(context.Background(), localClient, 1); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// waitForWaiters blocks until exactly n requests are queued.
+func waitForWaiters(t *testing.T, q *priorityQueue, n int) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		q.mu.Lock()
+		queued := len(q.waiters)
+		q.mu.Unlock()
+		if queued == n {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatal("request was never queued")
+}
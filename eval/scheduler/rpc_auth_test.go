@@ -84,3 +84,16 @@ func TestAuthMissingTokenRejectedBeforeExecution(t *testing.T) {
 		t.Fatal("SECURITY: box executed despite a missing token")
 	}
 }
+
+func TestAuthInvalidTokenRejectedOnStream(t *testing.T) {
+	sched := &recordingSched{}
+	url := startTestGrader(t, sched, "good-token")
+	client := NewGraderClient(http.DefaultClient, url, "wrong-token")
+	_, err := client.RunBox3Stream(context.Background(), &eval.Box3Request{Command: []string{"/bin/true"}}, 0, func(*eval.Box3Event) {})
+	if connect.CodeOf(err) != connect.CodeUnauthenticated {
+		t.Fatalf("want Unauthenticated for wrong token, got %v", err)
+	}
+	if sched.ran {
+		t.Fatal("SECURITY: streamed box executed despite an unregistered token")
+	}
+}
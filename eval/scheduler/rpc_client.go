@@ -2,6 +2,8 @@ package scheduler
 
 import (
 	"context"
+	"errors"
+	"sync/atomic"
 
 	"connectrpc.com/connect"
 	"github.com/KiloProjects/kilonova/eval"
@@ -10,15 +12,19 @@ import (
 )
 
 var _ eval.Box3Scheduler = (*GraderClient)(nil)
+var _ eval.Box3StreamScheduler = (*GraderClient)(nil)
 
 // GraderClient is the platform-side eval.Box3Scheduler backed by a remote grader
 // over ConnectRPC. It carries the grader-minted bearer token on every request.
 type GraderClient struct {
 	client graderv1connect.GraderServiceClient
+
+	// capacity is the last capacity reported by the grader
+	capacity atomic.Int64
 }
 
 func NewGraderClient(httpClient connect.HTTPClient, baseURL, token string, opts ...connect.ClientOption) *GraderClient {
-	opts = append(opts, connect.WithInterceptors(bearerInterceptor(token)))
+	opts = append(opts, connect.WithInterceptors(bearerInterceptor{token: token}))
 	return &GraderClient{client: graderv1connect.NewGraderServiceClient(httpClient, baseURL, opts...)}
 }
 
@@ -33,6 +39,33 @@ func (c *GraderClient) RunBox3(ctx context.Context, req *eval.Box3Request, memQu
 	return box3ResponseFromProto(resp.Msg.GetResponse()), nil
 }
 
+// RunBox3Stream runs the request over the streaming RPC, passing the grader's
+// progress events to progress (which may be nil).
+func (c *GraderClient) RunBox3Stream(ctx context.Context, req *eval.Box3Request, memQuota int64, progress eval.ProgressFunc) (*eval.Box3Response, error) {
+	stream, err := c.client.RunBox3Stream(ctx, connect.NewRequest(&graderv1.RunBox3Request{
+		Request:  box3RequestToProto(req),
+		MemQuota: memQuota,
+	}))
+	if err != nil {
+		return nil, err
+	}
+	defer stream.Close()
+
+	for stream.Receive() {
+		ev := stream.Msg()
+		if ev.GetKind() == graderv1.Box3EventKind_BOX3_EVENT_KIND_FINISHED {
+			return box3ResponseFromProto(ev.GetResponse()), nil
+		}
+		if progress != nil {
+			progress(box3EventFromProto(ev))
+		}
+	}
+	if err := stream.Err(); err != nil {
+		return nil, err
+	}
+	return nil, errors.New("grader closed the stream without a response")
+}
+
 func (c *GraderClient) RunMultibox3(ctx context.Context, req *eval.Multibox3Request, managerMemQuota, individualMemQuota int64) (*eval.Box3Response, []*eval.RunStats, error) {
 	resp, err := c.client.RunMultibox3(ctx, connect.NewRequest(&graderv1.RunMultibox3Request{
 		Request:            multibox3RequestToProto(req),
@@ -55,16 +88,38 @@ func (c *GraderClient) languageVersions(ctx context.Context) (map[string]string,
 	if err != nil {
 		return nil, err
 	}
+	c.capacity.Store(int64(resp.Msg.GetCapacity()))
 	return resp.Msg.GetVersions(), nil
 }
 
-func bearerInterceptor(token string) connect.UnaryInterceptorFunc {
-	return func(next connect.UnaryFunc) connect.UnaryFunc {
-		return func(ctx context.Context, req connect.AnyRequest) (connect.AnyResponse, error) {
-			if req.Spec().IsClient {
-				req.Header().Set("Authorization", "Bearer "+token)
-			}
-			return next(ctx, req)
+// Capacity returns the number of requests the grader runs at once, as reported
+// by its last language inventory. Graders that don't report it return 0.
+func (c *GraderClient) Capacity() int {
+	return int(c.capacity.Load())
+}
+
+// bearerInterceptor attaches the bearer token to both unary and streaming calls.
+type bearerInterceptor struct {
+	token string
+}
+
+func (b bearerInterceptor) WrapUnary(next connect.UnaryFunc) connect.UnaryFunc {
+	return func(ctx context.Context, req connect.AnyRequest) (connect.AnyResponse, error) {
+		if req.Spec().IsClient {
+			req.Header().Set("Authorization", "Bearer "+b.token)
 		}
+		return next(ctx, req)
+	}
+}
+
+func (b bearerInterceptor) WrapStreamingClient(next connect.StreamingClientFunc) connect.StreamingClientFunc {
+	return func(ctx context.Context, spec connect.Spec) connect.StreamingClientConn {
+		conn := next(ctx, spec)
+		conn.RequestHeader().Set("Authorization", "Bearer "+b.token)
+		return conn
 	}
 }
+
+func (b bearerInterceptor) WrapStreamingHandler(next connect.StreamingHandlerFunc) connect.StreamingHandlerFunc {
+	return next
+}
@@ -86,6 +86,10 @@ func statsToProto(s *eval.RunStats) *graderv1.RunStats {
 		Time:                s.Time,
 		InternalMessage:     s.InternalMessage,
 		MemoryLimitExceeded: s.MemoryLimitExceeded,
+		WallTime:            s.WallTime,
+		MaxRss:              int32(s.MaxRSS),
+		CswVoluntary:        int32(s.VoluntaryCSW),
+		CswForced:           int32(s.ForcedCSW),
 	}
 }
 
@@ -103,6 +107,10 @@ func statsFromProto(s *graderv1.RunStats) *eval.RunStats {
 		Time:                s.GetTime(),
 		InternalMessage:     s.GetInternalMessage(),
 		MemoryLimitExceeded: s.GetMemoryLimitExceeded(),
+		WallTime:            s.GetWallTime(),
+		MaxRSS:              int(s.GetMaxRss()),
+		VoluntaryCSW:        int(s.GetCswVoluntary()),
+		ForcedCSW:           int(s.GetCswForced()),
 	}
 }
 
@@ -164,6 +172,40 @@ func box3ResponseFromProto(r *graderv1.Box3Response) *eval.Box3Response {
 	return &eval.Box3Response{Stats: statsFromProto(r.GetStats()), Files: r.GetFiles()}
 }
 
+func box3EventKindToProto(k eval.Box3EventKind) graderv1.Box3EventKind {
+	switch k {
+	case eval.Box3Queued:
+		return graderv1.Box3EventKind_BOX3_EVENT_KIND_QUEUED
+	case eval.Box3Started:
+		return graderv1.Box3EventKind_BOX3_EVENT_KIND_STARTED
+	case eval.Box3Progress:
+		return graderv1.Box3EventKind_BOX3_EVENT_KIND_PROGRESS
+	default:
+		return graderv1.Box3EventKind_BOX3_EVENT_KIND_UNSPECIFIED
+	}
+}
+
+func box3EventKindFromProto(k graderv1.Box3EventKind) eval.Box3EventKind {
+	switch k {
+	case graderv1.Box3EventKind_BOX3_EVENT_KIND_QUEUED:
+		return eval.Box3Queued
+	case graderv1.Box3EventKind_BOX3_EVENT_KIND_STARTED:
+		return eval.Box3Started
+	case graderv1.Box3EventKind_BOX3_EVENT_KIND_PROGRESS:
+		return eval.Box3Progress
+	default:
+		return 0
+	}
+}
+
+func box3EventToProto(e *eval.Box3Event) *graderv1.RunBox3Event {
+	return &graderv1.RunBox3Event{Kind: box3EventKindToProto(e.Kind), Stats: statsToProto(e.Stats)}
+}
+
+func box3EventFromProto(e *graderv1.RunBox3Event) *eval.Box3Event {
+	return &eval.Box3Event{Kind: box3EventKindFromProto(e.GetKind()), Stats: statsFromProto(e.GetStats())}
+}
+
 func multibox3RequestToProto(r *eval.Multibox3Request) *graderv1.Multibox3Request {
 	if r == nil {
 		return nil
@@ -176,6 +218,7 @@ func multibox3RequestToProto(r *eval.Multibox3Request) *graderv1.Multibox3Reques
 		ManagerSandbox:     box3RequestToProto(r.ManagerSandbox),
 		UserSandboxConfigs: users,
 		UseStdin:           r.UseStdin,
+		TranscriptLimit:    r.TranscriptLimit,
 	}
 }
 
@@ -191,6 +234,7 @@ func multibox3RequestFromProto(r *graderv1.Multibox3Request) *eval.Multibox3Requ
 		ManagerSandbox:     box3RequestFromProto(r.GetManagerSandbox()),
 		UserSandboxConfigs: users,
 		UseStdin:           r.GetUseStdin(),
+		TranscriptLimit:    r.GetTranscriptLimit(),
 	}
 }
 
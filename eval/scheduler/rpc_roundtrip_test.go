@@ -109,3 +109,17 @@ func TestRunConfigConversionIsLossless(t *testing.T) {
 		t.Fatalf("RunConfig changed across conversion:\n in: %+v\ngot: %+v", in, got)
 	}
 }
+
+// TestRunStatsConversionIsLossless makes sure new isolate stats reach the platform.
+func TestRunStatsConversionIsLossless(t *testing.T) {
+	in := &eval.RunStats{
+		Memory: 1024, ExitCode: 1, ExitSignal: 9, Killed: true,
+		Message: "Time limit exceeded (wall clock)", Status: "TO", Time: 0.01, WallTime: 3.0,
+		InternalMessage: "isolate", MaxRSS: 2048, VoluntaryCSW: 3, ForcedCSW: 1,
+		MemoryLimitExceeded: true,
+	}
+	got := statsFromProto(statsToProto(in))
+	if !reflect.DeepEqual(in, got) {
+		t.Fatalf("RunStats changed across conversion:\n in: %+v\ngot: %+v", in, got)
+	}
+}
@@ -3,6 +3,7 @@ package scheduler
 import (
 	"context"
 	"errors"
+	"fmt"
 	"log/slog"
 	"net/http"
 	"strings"
@@ -45,6 +46,36 @@ func (s *GraderServer) RunBox3(ctx context.Context, req *connect.Request[graderv
 	return connect.NewResponse(&graderv1.RunBox3Response{Response: box3ResponseToProto(resp)}), nil
 }
 
+// RunBox3Stream runs the request like RunBox3, streaming its progress events
+// before the final FINISHED event. Schedulers that can't report progress only
+// send the final event.
+func (s *GraderServer) RunBox3Stream(ctx context.Context, req *connect.Request[graderv1.RunBox3Request], stream *connect.ServerStream[graderv1.RunBox3Event]) error {
+	var sendErr error
+	progress := func(ev *eval.Box3Event) {
+		if sendErr == nil {
+			sendErr = stream.Send(box3EventToProto(ev))
+		}
+	}
+
+	var resp *eval.Box3Response
+	var err error
+	if sched, ok := s.sched.(eval.Box3StreamScheduler); ok {
+		resp, err = sched.RunBox3Stream(ctx, box3RequestFromProto(req.Msg.GetRequest()), req.Msg.GetMemQuota(), progress)
+	} else {
+		resp, err = s.sched.RunBox3(ctx, box3RequestFromProto(req.Msg.GetRequest()), req.Msg.GetMemQuota())
+	}
+	if err != nil {
+		return connect.NewError(connect.CodeInternal, err)
+	}
+	if sendErr != nil {
+		return sendErr
+	}
+	return stream.Send(&graderv1.RunBox3Event{
+		Kind:     graderv1.Box3EventKind_BOX3_EVENT_KIND_FINISHED,
+		Response: box3ResponseToProto(resp),
+	})
+}
+
 func (s *GraderServer) RunMultibox3(ctx context.Context, req *connect.Request[graderv1.RunMultibox3Request]) (*connect.Response[graderv1.RunMultibox3Response], error) {
 	resp, stats, err := s.sched.RunMultibox3(ctx, multibox3RequestFromProto(req.Msg.GetRequest()), req.Msg.GetManagerMemQuota(), req.Msg.GetIndividualMemQuota())
 	if err != nil {
@@ -57,13 +88,18 @@ func (s *GraderServer) RunMultibox3(ctx context.Context, req *connect.Request[gr
 }
 
 func (s *GraderServer) Languages(ctx context.Context, req *connect.Request[graderv1.LanguagesRequest]) (*connect.Response[graderv1.LanguagesResponse], error) {
-	return connect.NewResponse(&graderv1.LanguagesResponse{Versions: s.langs.LanguageVersions(ctx)}), nil
+	resp := &graderv1.LanguagesResponse{Versions: s.langs.LanguageVersions(ctx)}
+	if sched, ok := s.sched.(eval.CapacityScheduler); ok {
+		resp.Capacity = int32(sched.Capacity())
+	}
+	return connect.NewResponse(resp), nil
 }
 
 // --- auth ---
 
 // ClientRegistry maps grader-minted bearer tokens to a named platform client.
-// Priority is reserved (documented but unconsumed by this change).
+// The client's priority decides the order in which the BoxManager serves
+// queued requests (see ParsePriority).
 type ClientRegistry struct {
 	byToken map[string]ClientIdentity
 }
@@ -71,6 +107,8 @@ type ClientRegistry struct {
 type ClientIdentity struct {
 	Name     string
 	Priority string
+
+	priority int
 }
 
 func NewClientRegistry() *ClientRegistry {
@@ -83,7 +121,11 @@ func (r *ClientRegistry) Add(token, name, priority string) error {
 	if token == "" {
 		return errors.New("client registry: empty token for client " + name)
 	}
-	r.byToken[token] = ClientIdentity{Name: name, Priority: priority}
+	prio, err := ParsePriority(priority)
+	if err != nil {
+		return fmt.Errorf("client registry: client %s: %w", name, err)
+	}
+	r.byToken[token] = ClientIdentity{Name: name, Priority: priority, priority: prio}
 	return nil
 }
 
@@ -104,28 +146,68 @@ func (r *ClientRegistry) authMiddleware(next http.Handler) http.Handler {
 	})
 }
 
-type clientNameKey struct{}
+type clientKey struct{}
 
 // ClientName returns the authenticated client name attached by the interceptor.
 func ClientName(ctx context.Context) string {
-	name, _ := ctx.Value(clientNameKey{}).(string)
-	return name
-}
-
-func newAuthInterceptor(reg *ClientRegistry) connect.UnaryInterceptorFunc {
-	return func(next connect.UnaryFunc) connect.UnaryFunc {
-		return func(ctx context.Context, req connect.AnyRequest) (connect.AnyResponse, error) {
-			token, ok := strings.CutPrefix(req.Header().Get("Authorization"), "Bearer ")
-			if !ok || token == "" {
-				return nil, connect.NewError(connect.CodeUnauthenticated, errMissingToken)
-			}
-			ident, ok := reg.byToken[token]
-			if !ok {
-				return nil, connect.NewError(connect.CodeUnauthenticated, errUnknownToken)
-			}
-			ctx = context.WithValue(ctx, clientNameKey{}, ident.Name)
-			slog.DebugContext(ctx, "Authenticated grader RPC", slog.String("client", ident.Name), slog.String("procedure", req.Spec().Procedure))
-			return next(ctx, req)
+	ident, _ := ctx.Value(clientKey{}).(ClientIdentity)
+	return ident.Name
+}
+
+// clientFromContext returns the authenticated client, falling back to
+// localClient for requests made in-process.
+func clientFromContext(ctx context.Context) ClientIdentity {
+	ident, ok := ctx.Value(clientKey{}).(ClientIdentity)
+	if !ok {
+		return localClient
+	}
+	return ident
+}
+
+// authInterceptor checks the bearer token of both unary and streaming RPCs, so
+// that no procedure can reach the scheduler unauthenticated.
+type authInterceptor struct {
+	reg *ClientRegistry
+}
+
+func newAuthInterceptor(reg *ClientRegistry) connect.Interceptor {
+	return &authInterceptor{reg: reg}
+}
+
+func (a *authInterceptor) authenticate(ctx context.Context, header http.Header, procedure string) (context.Context, error) {
+	token, ok := strings.CutPrefix(header.Get("Authorization"), "Bearer ")
+	if !ok || token == "" {
+		return nil, connect.NewError(connect.CodeUnauthenticated, errMissingToken)
+	}
+	ident, ok := a.reg.byToken[token]
+	if !ok {
+		return nil, connect.NewError(connect.CodeUnauthenticated, errUnknownToken)
+	}
+	ctx = context.WithValue(ctx, clientKey{}, ident)
+	slog.DebugContext(ctx, "Authenticated grader RPC", slog.String("client", ident.Name), slog.String("procedure", procedure))
+	return ctx, nil
+}
+
+func (a *authInterceptor) WrapUnary(next connect.UnaryFunc) connect.UnaryFunc {
+	return func(ctx context.Context, req connect.AnyRequest) (connect.AnyResponse, error) {
+		ctx, err := a.authenticate(ctx, req.Header(), req.Spec().Procedure)
+		if err != nil {
+			return nil, err
+		}
+		return next(ctx, req)
+	}
+}
+
+func (a *authInterceptor) WrapStreamingClient(next connect.StreamingClientFunc) connect.StreamingClientFunc {
+	return next
+}
+
+func (a *authInterceptor) WrapStreamingHandler(next connect.StreamingHandlerFunc) connect.StreamingHandlerFunc {
+	return func(ctx context.Context, conn connect.StreamingHandlerConn) error {
+		ctx, err := a.authenticate(ctx, conn.RequestHeader(), conn.Spec().Procedure)
+		if err != nil {
+			return err
 		}
+		return next(ctx, conn)
 	}
 }
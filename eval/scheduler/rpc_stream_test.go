@@ -0,0 +1,61 @@
+package scheduler
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"testing"
+
+	"github.com/KiloProjects/kilonova/eval"
+)
+
+// progressSched reports a fixed sequence of events before finishing.
+type progressSched struct{ recordingSched }
+
+func (p *progressSched) RunBox3Stream(ctx context.Context, req *eval.Box3Request, memQuota int64, progress eval.ProgressFunc) (*eval.Box3Response, error) {
+	progress(&eval.Box3Event{Kind: eval.Box3Queued})
+	progress(&eval.Box3Event{Kind: eval.Box3Started})
+	progress(&eval.Box3Event{Kind: eval.Box3Progress, Stats: &eval.RunStats{Time: 1.5}})
+	return p.RunBox3(ctx, req, memQuota)
+}
+
+func TestRunBox3StreamForwardsProgress(t *testing.T) {
+	sched := &progressSched{}
+	url := startTestGrader(t, sched, "good-token")
+	client := NewGraderClient(http.DefaultClient, url, "good-token")
+
+	var kinds []eval.Box3EventKind
+	var interim float64
+	resp, err := client.RunBox3Stream(context.Background(), &eval.Box3Request{Command: []string{"/bin/true"}}, 0, func(ev *eval.Box3Event) {
+		kinds = append(kinds, ev.Kind)
+		if ev.Kind == eval.Box3Progress {
+			interim = ev.Stats.Time
+		}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Stats.Status != "OK" {
+		t.Fatalf("got status %q, want OK", resp.Stats.Status)
+	}
+	if want := []eval.Box3EventKind{eval.Box3Queued, eval.Box3Started, eval.Box3Progress}; !slices.Equal(kinds, want) {
+		t.Fatalf("got events %v, want %v", kinds, want)
+	}
+	if interim != 1.5 {
+		t.Fatalf("got interim time %v, want 1.5", interim)
+	}
+}
+
+func TestRunBox3StreamFallsBackToUnary(t *testing.T) {
+	sched := &recordingSched{}
+	url := startTestGrader(t, sched, "good-token")
+	client := NewGraderClient(http.DefaultClient, url, "good-token")
+
+	var events int
+	if _, err := client.RunBox3Stream(context.Background(), &eval.Box3Request{Command: []string{"/bin/true"}}, 0, func(*eval.Box3Event) { events++ }); err != nil {
+		t.Fatal(err)
+	}
+	if !sched.ran || events != 0 {
+		t.Fatalf("got ran=%v, events=%d; want the unary path with no events", sched.ran, events)
+	}
+}
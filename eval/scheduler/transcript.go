@@ -0,0 +1,137 @@
+package scheduler
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// transcript records the lines flowing between a communication manager and the user sandboxes, up to a size limit.
+// Lines from different streams are interleaved in the order they were relayed.
+type transcript struct {
+	mu        sync.Mutex
+	buf       bytes.Buffer
+	limit     int
+	truncated bool
+}
+
+func newTranscript(limit int64) *transcript {
+	return &transcript{limit: int(limit)}
+}
+
+func (t *transcript) writeLine(prefix string, line []byte) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.truncated {
+		return
+	}
+	if t.buf.Len()+len(prefix)+len(line)+1 > t.limit {
+		t.truncated = true
+		t.buf.WriteString("[transcript truncated]\n")
+		return
+	}
+	t.buf.WriteString(prefix)
+	t.buf.Write(line)
+	t.buf.WriteByte('\n')
+}
+
+// Bytes returns the transcript. It must only be called after all streams were closed.
+func (t *transcript) Bytes() []byte {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.buf.Bytes()
+}
+
+// stream returns a writer recording the data sent in one direction, line by line
+func (t *transcript) stream(prefix string) *transcriptStream {
+	return &transcriptStream{t: t, prefix: prefix}
+}
+
+type transcriptStream struct {
+	t       *transcript
+	prefix  string
+	pending []byte
+}
+
+func (s *transcriptStream) Write(p []byte) (int, error) {
+	n := len(p)
+	for {
+		line, rest, found := bytes.Cut(p, []byte{'\n'})
+		if !found {
+			break
+		}
+		s.pending = append(s.pending, line...)
+		s.t.writeLine(s.prefix, s.pending)
+		s.pending = s.pending[:0]
+		p = rest
+	}
+	// Don't let a stream without newlines grow past the limit
+	if len(s.pending)+len(p) > s.t.limit {
+		p = p[:max(s.t.limit-len(s.pending), 0)]
+	}
+	s.pending = append(s.pending, p...)
+	return n, nil
+}
+
+// Close records the last line, if it didn't end with a newline
+func (s *transcriptStream) Close() error {
+	if len(s.pending) > 0 {
+		s.t.writeLine(s.prefix, append(s.pending, " (no newline)"...))
+		s.pending = nil
+	}
+	return nil
+}
+
+// relayFifo copies everything written to the src fifo into the dst fifo, recording it in rec.
+//
+// Both ends are opened concurrently, so the relay doesn't impose an order on how the sandboxes open their fifos.
+// Opening a fifo blocks until its other end is opened, so if a sandbox never opens its end, the relay waits until
+// unblockFifos is called once the sandboxes exit.
+func relayFifo(src, dst string, rec *transcriptStream) error {
+	defer rec.Close()
+
+	var in, out *os.File
+	var inErr, outErr error
+	var wg sync.WaitGroup
+	wg.Go(func() { in, inErr = os.OpenFile(src, os.O_RDONLY, 0) })
+	wg.Go(func() { out, outErr = os.OpenFile(dst, os.O_WRONLY, 0) })
+	wg.Wait()
+	if in != nil {
+		defer in.Close()
+	}
+	if out != nil {
+		defer out.Close()
+	}
+	if err := errors.Join(inErr, outErr); err != nil {
+		return err
+	}
+
+	_, err := io.Copy(out, io.TeeReader(in, rec))
+	if errors.Is(err, syscall.EPIPE) {
+		// The reader exited, just like it would without the relay
+		return nil
+	}
+	return err
+}
+
+// unblockFifos opens and closes the fifos until done is closed, so relays stuck opening their ends can finish.
+// A relay stuck opening its reading end then reads EOF, while one stuck opening its writing end gets EPIPE.
+func unblockFifos(paths []string, done <-chan struct{}) {
+	for {
+		for _, p := range paths {
+			// O_RDWR never blocks on a fifo, and counts as both a reader and a writer
+			if f, err := os.OpenFile(p, os.O_RDWR|syscall.O_NONBLOCK, 0); err == nil {
+				f.Close()
+			}
+		}
+		select {
+		case <-done:
+			return
+		case <-time.After(20 * time.Millisecond):
+		}
+	}
+}
@@ -0,0 +1,133 @@
+package scheduler
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+func makeTestFifos(t *testing.T, names ...string) []string {
+	t.Helper()
+	dir := t.TempDir()
+	paths := make([]string, len(names))
+	for i, name := range names {
+		paths[i] = path.Join(dir, name)
+		if err := unix.Mkfifo(paths[i], 0666); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return paths
+}
+
+func TestRelayRecordsTranscript(t *testing.T) {
+	fifos := makeTestFifos(t, "m_to_u", "r_to_u", "u_to_r", "u_to_m")
+	mToU, rToU, uToR, uToM := fifos[0], fifos[1], fifos[2], fifos[3]
+
+	tr := newTranscript(1024)
+	var relays sync.WaitGroup
+	relays.Go(func() {
+		if err := relayFifo(mToU, rToU, tr.stream("manager -> user: ")); err != nil {
+			t.Error(err)
+		}
+	})
+	relays.Go(func() {
+		if err := relayFifo(uToR, uToM, tr.stream("user -> manager: ")); err != nil {
+			t.Error(err)
+		}
+	})
+
+	var procs sync.WaitGroup
+	// The manager opens its input first, while the user opens its input first too
+	procs.Go(func() {
+		in, err := os.Open(uToM)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		defer in.Close()
+		out, err := os.OpenFile(mToU, os.O_WRONLY, 0)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		defer out.Close()
+		r := bufio.NewReader(in)
+		fmt.Fprintln(out, "3 5")
+		line, _ := r.ReadString('\n')
+		if line != "8\n" {
+			t.Errorf("manager got %q", line)
+		}
+		fmt.Fprint(out, "bye")
+	})
+	procs.Go(func() {
+		in, err := os.Open(rToU)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		defer in.Close()
+		out, err := os.OpenFile(uToR, os.O_WRONLY, 0)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		defer out.Close()
+		r := bufio.NewReader(in)
+		var a, b int
+		fmt.Fscan(r, &a, &b)
+		fmt.Fprintln(out, a+b)
+		r.ReadString('\n')
+		// Wait for the manager's EOF before exiting
+		r.ReadString('\n')
+	})
+	procs.Wait()
+
+	done := make(chan struct{})
+	go unblockFifos(fifos, done)
+	relays.Wait()
+	close(done)
+
+	want := "manager -> user: 3 5\nuser -> manager: 8\nmanager -> user: bye (no newline)\n"
+	if got := string(tr.Bytes()); got != want {
+		t.Errorf("got transcript %q, want %q", got, want)
+	}
+}
+
+func TestRelayUnblocksUnopenedFifos(t *testing.T) {
+	fifos := makeTestFifos(t, "src", "dst")
+
+	relayDone := make(chan struct{})
+	go func() {
+		relayFifo(fifos[0], fifos[1], newTranscript(1024).stream(""))
+		close(relayDone)
+	}()
+
+	// Neither sandbox ever opened its end
+	done := make(chan struct{})
+	go unblockFifos(fifos, done)
+	defer close(done)
+	select {
+	case <-relayDone:
+	case <-time.After(5 * time.Second):
+		t.Fatal("relay is still blocked")
+	}
+}
+
+func TestTranscriptTruncation(t *testing.T) {
+	tr := newTranscript(32)
+	s := tr.stream("> ")
+	s.Write([]byte(strings.Repeat("a\n", 20)))
+	s.Close()
+
+	got := string(tr.Bytes())
+	if !strings.HasSuffix(got, "[transcript truncated]\n") || strings.Count(got, "> a\n") != 8 {
+		t.Errorf("got transcript %q", got)
+	}
+}
@@ -24,6 +24,11 @@ type BatchResponse struct {
 	Time     float64
 	Memory   int
 	Comments string
+
+	WallTime     float64
+	MaxRSS       int
+	VoluntaryCSW int
+	ForcedCSW    int
 }
 
 type BatchRequest struct {
@@ -35,6 +40,8 @@ type BatchRequest struct {
 	TimeLimit   float64
 
 	CodeFilename string
+	// Args are passed to the executable, after its name
+	Args []string
 
 	Lang       language.GraderLang
 	ExecFile   *eval.BucketFile
@@ -64,7 +71,7 @@ func ExecuteBatch(ctx context.Context, mgr eval.BoxScheduler, memQuota int64, re
 			"/box/" + req.OutputName: req.OutputFile,
 		},
 
-		Command: req.Lang.RunCommand([]string{req.Lang.ExecuteName(req.CodeFilename)}, req.MemoryLimit),
+		Command: append(req.Lang.RunCommand([]string{req.Lang.ExecuteName(req.CodeFilename)}, req.MemoryLimit), req.Args...),
 	}
 
 	// if our specified language is not compiled, then it means that
@@ -124,6 +131,10 @@ type CommunicationRequest struct {
 	CheckerFilename string
 
 	NumUserSandboxes int64
+
+	// TranscriptFile, if set, receives up to TranscriptLimit bytes of what the manager and the user processes sent each other
+	TranscriptFile  *eval.BucketFile
+	TranscriptLimit int64
 }
 
 type CommunicationResponse struct {
@@ -202,6 +213,9 @@ func ExecuteCommunication(ctx context.Context, mgr eval.BoxScheduler, memQuota i
 		UserSandboxConfigs: userReqs,
 
 		UseStdin: req.UseStdin,
+
+		Transcript:      req.TranscriptFile,
+		TranscriptLimit: req.TranscriptLimit,
 	}, managerMemoryLimit, memQuota)
 	if bResp == nil || err != nil {
 		resp := &CommunicationResponse{}
@@ -216,6 +230,8 @@ func ExecuteCommunication(ctx context.Context, mgr eval.BoxScheduler, memQuota i
 	mgrResp := parseResponse(ctx, bResp.Stats, logger, strconv.Itoa(req.SubtestID))
 	mgrResp.Time = userResp.Time
 	mgrResp.Memory = userResp.Memory
+	mgrResp.WallTime, mgrResp.MaxRSS = userResp.WallTime, userResp.MaxRSS
+	mgrResp.VoluntaryCSW, mgrResp.ForcedCSW = userResp.VoluntaryCSW, userResp.ForcedCSW
 
 	if userResp.Comments != "" {
 		return &CommunicationResponse{
@@ -244,12 +260,15 @@ func parseResponse(ctx context.Context, stats *eval.RunStats, logger *slog.Logge
 	resp := BatchResponse{}
 
 	resp.Time, resp.Memory = stats.Time, stats.Memory
+	resp.WallTime, resp.MaxRSS = stats.WallTime, stats.MaxRSS
+	resp.VoluntaryCSW, resp.ForcedCSW = stats.VoluntaryCSW, stats.ForcedCSW
 
 	okExit := false
 	switch msg, status := stats.Message, stats.Status; status {
 	case "TO":
 		if strings.Contains(msg, "wall") {
-			resp.Comments = "translate:walltimeout"
+			// The CPU time limit wasn't hit, so the program spent its time waiting (for input, in sleep calls etc.)
+			resp.Comments = "translate:idleness"
 		} else {
 			resp.Comments = "translate:timeout"
 		}
@@ -285,17 +304,23 @@ func mergeStats(concurrent bool, stats ...*eval.RunStats) *eval.RunStats {
 		}
 		if concurrent {
 			mergedStats.Memory += stat.Memory
+			mergedStats.MaxRSS += stat.MaxRSS
 			mergedStats.Time = max(mergedStats.Time, stat.Time)
+			mergedStats.WallTime = max(mergedStats.WallTime, stat.WallTime)
 		} else {
 			mergedStats.Memory = max(mergedStats.Memory, stat.Memory)
+			mergedStats.MaxRSS = max(mergedStats.MaxRSS, stat.MaxRSS)
 			mergedStats.Time += stat.Time
+			mergedStats.WallTime += stat.WallTime
 		}
-		stat.Killed = cmp.Or(stat.Killed, mergedStats.Killed)
-		stat.ExitCode = cmp.Or(stat.ExitCode, mergedStats.ExitCode)
-		stat.ExitSignal = cmp.Or(stat.ExitSignal, mergedStats.ExitSignal)
-		stat.Message = cmp.Or(stat.Message, mergedStats.Message)
-		stat.Status = cmp.Or(stat.Status, mergedStats.Status)
-		stat.MemoryLimitExceeded = cmp.Or(stat.MemoryLimitExceeded, mergedStats.MemoryLimitExceeded)
+		mergedStats.VoluntaryCSW += stat.VoluntaryCSW
+		mergedStats.ForcedCSW += stat.ForcedCSW
+		mergedStats.Killed = cmp.Or(mergedStats.Killed, stat.Killed)
+		mergedStats.ExitCode = cmp.Or(mergedStats.ExitCode, stat.ExitCode)
+		mergedStats.ExitSignal = cmp.Or(mergedStats.ExitSignal, stat.ExitSignal)
+		mergedStats.Message = cmp.Or(mergedStats.Message, stat.Message)
+		mergedStats.Status = cmp.Or(mergedStats.Status, stat.Status)
+		mergedStats.MemoryLimitExceeded = cmp.Or(mergedStats.MemoryLimitExceeded, stat.MemoryLimitExceeded)
 	}
 
 	return &mergedStats
@@ -0,0 +1,49 @@
+package tasks
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+
+	"github.com/KiloProjects/kilonova/eval"
+)
+
+func TestParseResponseIdleness(t *testing.T) {
+	tests := []struct {
+		stats *eval.RunStats
+		want  string
+	}{
+		{&eval.RunStats{Status: "TO", Message: "Time limit exceeded", Time: 1.01, WallTime: 1.05}, "translate:timeout"},
+		{&eval.RunStats{Status: "TO", Message: "Time limit exceeded (wall clock)", Time: 0.002, WallTime: 3.0}, "translate:idleness"},
+		{&eval.RunStats{Time: 0.1, WallTime: 0.12}, ""},
+	}
+	for _, test := range tests {
+		resp := parseResponse(context.Background(), test.stats, slog.Default(), "1")
+		if resp.Comments != test.want {
+			t.Errorf("%+v: got verdict %q, want %q", test.stats, resp.Comments, test.want)
+		}
+		if resp.WallTime != test.stats.WallTime {
+			t.Errorf("%+v: wall time wasn't kept", test.stats)
+		}
+	}
+}
+
+func TestMergeStatsResources(t *testing.T) {
+	merged := mergeStats(true,
+		&eval.RunStats{Time: 0.1, WallTime: 3.0, Memory: 100, VoluntaryCSW: 2},
+		&eval.RunStats{Time: 0.002, WallTime: 3.0, Memory: 50, VoluntaryCSW: 1},
+	)
+	if merged.Time != 0.1 || merged.WallTime != 3.0 || merged.Memory != 150 || merged.VoluntaryCSW != 3 {
+		t.Errorf("got %+v", merged)
+	}
+}
+
+func TestMergeStatsKeepsStatus(t *testing.T) {
+	merged := mergeStats(true,
+		&eval.RunStats{Time: 0.1, WallTime: 3.0},
+		&eval.RunStats{Time: 0.002, WallTime: 3.0, Status: "TO", Message: "Time limit exceeded (wall clock)", Killed: true},
+	)
+	if merged.Status != "TO" || merged.Message != "Time limit exceeded (wall clock)" || !merged.Killed {
+		t.Errorf("status of the blocked sandbox was lost: %+v", merged)
+	}
+}
@@ -0,0 +1,148 @@
+package tasks
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+	"strings"
+
+	"github.com/KiloProjects/kilonova/eval"
+	"github.com/KiloProjects/kilonova/eval/language"
+)
+
+const (
+	twoStepManagerInput  = "/box/input.txt"
+	twoStepManagerFirst  = "/box/first.out"
+	twoStepManagerSecond = "/box/second.in"
+	twoStepManagerErr    = "/box/manager.err"
+)
+
+// TwoStepRequest runs the contestant's program twice. The first run reads the test input,
+// then the manager transforms the first run's output into the second run's input.
+// The fields of the embedded BatchRequest describe the contestant's program: InputFile is the
+// test input of the first run and OutputFile receives the output of the second run.
+type TwoStepRequest struct {
+	BatchRequest
+
+	// FirstOutputFile receives the output of the first run
+	FirstOutputFile *eval.BucketFile
+	// SecondInputFile receives the manager's output, which is the input of the second run
+	SecondInputFile *eval.BucketFile
+
+	ManagerLang     language.GraderLang
+	ManagerFile     *eval.BucketFile
+	ManagerFilename string
+}
+
+// ExecuteTwoStep runs a two-step subtest. Time is summed across both runs, memory is the maximum of the two.
+// The time limit applies to each run and to their sum.
+//
+// The manager is run with the test input in /box/input.txt and the first run's output in /box/first.out.
+// Whatever it writes to stdout becomes the second run's input. If it exits with a non-zero code,
+// the first output is rejected and the message written to stderr becomes the verdict.
+func ExecuteTwoStep(ctx context.Context, mgr eval.BoxScheduler, memQuota int64, req *TwoStepRequest, logger *slog.Logger) (*BatchResponse, error) {
+	logger.InfoContext(ctx, "Executing two-step subtest", slog.Any("output_file", req.OutputFile), slog.Any("sub_file", req.InputFile))
+
+	firstReq := req.BatchRequest
+	firstReq.OutputFile = req.FirstOutputFile
+	first, err := ExecuteBatch(ctx, mgr, memQuota, &firstReq, logger)
+	if err != nil {
+		return first, err
+	}
+	if first.Comments != "" || req.exceedsTimeLimit(first) {
+		return first, nil
+	}
+
+	if comments := runTwoStepManager(ctx, mgr, req, logger); comments != "" {
+		first.Comments = comments
+		return first, nil
+	}
+
+	secondReq := req.BatchRequest
+	secondReq.InputFile = req.SecondInputFile
+	second, err := ExecuteBatch(ctx, mgr, memQuota, &secondReq, logger)
+	if err != nil {
+		return nil, err
+	}
+	second.Time += first.Time
+	second.WallTime += first.WallTime
+	second.Memory = max(second.Memory, first.Memory)
+	second.MaxRSS = max(second.MaxRSS, first.MaxRSS)
+	second.VoluntaryCSW += first.VoluntaryCSW
+	second.ForcedCSW += first.ForcedCSW
+	req.exceedsTimeLimit(second)
+	return second, nil
+}
+
+// exceedsTimeLimit marks resp as timed out if its time is over the time limit
+func (req *TwoStepRequest) exceedsTimeLimit(resp *BatchResponse) bool {
+	if req.TimeLimit == 0 || resp.Time <= req.TimeLimit {
+		return false
+	}
+	resp.Time = req.TimeLimit
+	if resp.Comments == "" {
+		resp.Comments = "translate:timeout"
+	}
+	return true
+}
+
+// runTwoStepManager returns a non-empty verdict if the second run shouldn't happen
+func runTwoStepManager(ctx context.Context, mgr eval.BoxScheduler, req *TwoStepRequest, logger *slog.Logger) string {
+	managerReq := &eval.Box2Request{
+		InputBucketFiles: map[string]*eval.BucketFile{
+			// Test input
+			twoStepManagerInput: req.InputFile,
+			// First run output
+			twoStepManagerFirst: req.FirstOutputFile,
+			// Manager executable
+			req.ManagerLang.CompiledName(req.ManagerFilename): req.ManagerFile,
+		},
+
+		Command: req.ManagerLang.RunCommand([]string{req.ManagerLang.ExecuteName(req.ManagerFilename)}, managerMemoryLimit),
+		RunConfig: &eval.RunConfig{
+			OutputPath: twoStepManagerSecond,
+			StderrPath: twoStepManagerErr,
+
+			MemoryLimit:   managerMemoryLimit,
+			TimeLimit:     managerTimeLimit,
+			WallTimeLimit: 2*managerTimeLimit + 1,
+		},
+
+		OutputByteFiles: []string{twoStepManagerErr},
+		OutputBucketFiles: map[string]*eval.BucketFile{
+			twoStepManagerSecond: req.SecondInputFile,
+		},
+	}
+
+	// if our specified language is not compiled, then it means that
+	// the mounts specified should be added at runtime
+	if !req.ManagerLang.Compiled() {
+		managerReq.RunConfig.Directories = req.ManagerLang.Mounts()
+	}
+
+	bResp, err := mgr.RunBox2(ctx, managerReq, managerMemoryLimit)
+	if bResp == nil || err != nil {
+		comments := "translate:internal_error"
+		if err != nil {
+			comments += " (" + err.Error() + ")"
+		}
+		return comments
+	}
+
+	switch bResp.Stats.Status {
+	case "RE":
+		// The manager rejected the first output
+		if msg := strings.TrimSpace(string(bResp.ByteFiles[twoStepManagerErr])); msg != "" {
+			return msg
+		}
+		return "translate:wrong"
+	case "TO", "SG", "XX":
+		logger.WarnContext(ctx, "Two-step manager failed", slog.Any("metadata", bResp.Stats))
+		return "translate:internal_error (manager: " + bResp.Stats.Message + ")"
+	}
+
+	if !slices.Contains(bResp.BucketFiles, twoStepManagerSecond) {
+		return "translate:internal_error (manager produced no input)"
+	}
+	return ""
+}
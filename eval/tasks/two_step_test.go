@@ -0,0 +1,126 @@
+package tasks
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+
+	"github.com/KiloProjects/kilonova/domain/datastore"
+	"github.com/KiloProjects/kilonova/eval"
+	"github.com/KiloProjects/kilonova/eval/language"
+)
+
+// scriptedSched answers RunBox2 calls in order and records the requests it got.
+type scriptedSched struct {
+	responses []*eval.Box2Response
+	requests  []*eval.Box2Request
+}
+
+func (s *scriptedSched) RunBox2(_ context.Context, req *eval.Box2Request, _ int64) (*eval.Box2Response, error) {
+	resp := s.responses[len(s.requests)]
+	s.requests = append(s.requests, req)
+	return resp, nil
+}
+
+func (s *scriptedSched) RunMultibox2(context.Context, *eval.Multibox2Request, int64, int64) (*eval.Box2Response, []*eval.RunStats, error) {
+	return nil, nil, nil
+}
+
+func (s *scriptedSched) Close(context.Context) error { return nil }
+
+func twoStepTestRequest() *TwoStepRequest {
+	lang := language.Langs["cpp17"].GraderLang()
+	file := func(name string) *eval.BucketFile {
+		return &eval.BucketFile{Bucket: datastore.BucketTypeSubtests, Filename: name, Mode: 0644}
+	}
+	return &TwoStepRequest{
+		BatchRequest: BatchRequest{
+			InputName:    "stdin",
+			OutputName:   "stdout",
+			MemoryLimit:  1024,
+			TimeLimit:    1,
+			CodeFilename: "main.cpp",
+			Lang:         lang,
+			ExecFile:     file("sub.bin"),
+			InputFile:    file("1.in"),
+			OutputFile:   file("1"),
+		},
+		FirstOutputFile: file("1.first"),
+		SecondInputFile: file("1.second"),
+		ManagerLang:     lang,
+		ManagerFile:     file("manager.bin"),
+		ManagerFilename: "manager.cpp",
+	}
+}
+
+func TestExecuteTwoStepChainsRuns(t *testing.T) {
+	sched := &scriptedSched{responses: []*eval.Box2Response{
+		{Stats: &eval.RunStats{Time: 0.25, Memory: 100}, BucketFiles: []string{"/box/stdout"}},
+		{Stats: &eval.RunStats{}, BucketFiles: []string{twoStepManagerSecond}},
+		{Stats: &eval.RunStats{Time: 0.5, Memory: 50}, BucketFiles: []string{"/box/stdout"}},
+	}}
+	req := twoStepTestRequest()
+	resp, err := ExecuteTwoStep(context.Background(), sched, 1024, req, slog.Default())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Comments != "" || resp.Time != 0.75 || resp.Memory != 100 {
+		t.Fatalf("got %+v, want no comments, summed time and max memory", resp)
+	}
+
+	first, manager, second := sched.requests[0], sched.requests[1], sched.requests[2]
+	if first.OutputBucketFiles["/box/stdout"] != req.FirstOutputFile {
+		t.Error("first run didn't write the first output")
+	}
+	if manager.InputBucketFiles[twoStepManagerFirst] != req.FirstOutputFile || manager.OutputBucketFiles[twoStepManagerSecond] != req.SecondInputFile {
+		t.Error("manager isn't wired between the runs")
+	}
+	if second.InputBucketFiles["/box/stdin"] != req.SecondInputFile || second.OutputBucketFiles["/box/stdout"] != req.OutputFile {
+		t.Error("second run didn't read the manager's output")
+	}
+}
+
+func TestExecuteTwoStepManagerRejection(t *testing.T) {
+	sched := &scriptedSched{responses: []*eval.Box2Response{
+		{Stats: &eval.RunStats{Time: 0.25}, BucketFiles: []string{"/box/stdout"}},
+		{Stats: &eval.RunStats{Status: "RE", ExitCode: 1}, ByteFiles: map[string][]byte{twoStepManagerErr: []byte("Encoded message too long\n")}},
+	}}
+	resp, err := ExecuteTwoStep(context.Background(), sched, 1024, twoStepTestRequest(), slog.Default())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Comments != "Encoded message too long" {
+		t.Fatalf("got comments %q, want the manager's message", resp.Comments)
+	}
+	if len(sched.requests) != 2 {
+		t.Fatalf("ran %d boxes, want the second run to be skipped", len(sched.requests))
+	}
+}
+
+func TestExecuteTwoStepTimeLimit(t *testing.T) {
+	// The first run alone is over the time limit, so the manager and the second run are skipped
+	sched := &scriptedSched{responses: []*eval.Box2Response{
+		{Stats: &eval.RunStats{Time: 1.5}, BucketFiles: []string{"/box/stdout"}},
+	}}
+	resp, err := ExecuteTwoStep(context.Background(), sched, 1024, twoStepTestRequest(), slog.Default())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Comments != "translate:timeout" || resp.Time != 1 || len(sched.requests) != 1 {
+		t.Fatalf("got %+v after %d boxes, want a timeout after the first run", resp, len(sched.requests))
+	}
+
+	// Each run is within the time limit, but their sum isn't
+	sched = &scriptedSched{responses: []*eval.Box2Response{
+		{Stats: &eval.RunStats{Time: 0.75}, BucketFiles: []string{"/box/stdout"}},
+		{Stats: &eval.RunStats{}, BucketFiles: []string{twoStepManagerSecond}},
+		{Stats: &eval.RunStats{Time: 0.5}, BucketFiles: []string{"/box/stdout"}},
+	}}
+	resp, err = ExecuteTwoStep(context.Background(), sched, 1024, twoStepTestRequest(), slog.Default())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Comments != "translate:timeout" || resp.Time != 1 {
+		t.Fatalf("got %+v, want the summed time to time out", resp)
+	}
+}
@@ -0,0 +1,74 @@
+// Package pgtest gives tests their own migrated PostgreSQL database.
+//
+// Tests using it are skipped unless the KN_TEST_DSN environment variable points to a server
+// where the user may create databases (and the extensions used by the schema).
+package pgtest
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/KiloProjects/kilonova/domain/config"
+	"github.com/KiloProjects/kilonova/infra/postgres"
+	"github.com/jackc/pgx/v5"
+)
+
+// DSNVariable is the environment variable holding the connection string of the test server
+const DSNVariable = "KN_TEST_DSN"
+
+// New creates an empty database, runs the migrations on it and drops it once the test finishes.
+func New(t testing.TB, migrations postgres.MigrationConfig) *postgres.DB {
+	t.Helper()
+	dsn := os.Getenv(DSNVariable)
+	if dsn == "" {
+		t.Skipf("%s is not set, skipping database test", DSNVariable)
+	}
+	ctx := context.Background()
+
+	admin, err := pgx.Connect(ctx, dsn)
+	if err != nil {
+		t.Fatalf("couldn't connect to the test server: %v", err)
+	}
+	name := "kn_test_" + strings.ToLower(rand.Text())
+	if _, err := admin.Exec(ctx, "CREATE DATABASE "+name); err != nil {
+		admin.Close(ctx)
+		t.Fatalf("couldn't create test database: %v", err)
+	}
+	t.Cleanup(func() {
+		if _, err := admin.Exec(ctx, "DROP DATABASE "+name+" WITH (FORCE)"); err != nil {
+			t.Logf("couldn't drop test database %s: %v", name, err)
+		}
+		admin.Close(ctx)
+	})
+
+	// The query log goes to the test's temporary directory
+	config.Common.LogDir = t.TempDir()
+
+	db, err := postgres.NewDB(ctx, postgres.Config{DSN: withDatabase(dsn, name)})
+	if err != nil {
+		t.Fatalf("couldn't connect to the test database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	if err := postgres.RunMigrations(ctx, db, migrations); err != nil {
+		t.Fatalf("couldn't run migrations: %v", err)
+	}
+	return db
+}
+
+// withDatabase changes the database of a URL or key/value connection string
+func withDatabase(dsn, name string) string {
+	if strings.HasPrefix(dsn, "postgres://") || strings.HasPrefix(dsn, "postgresql://") {
+		u, err := url.Parse(dsn)
+		if err == nil {
+			u.Path = "/" + name
+			return u.String()
+		}
+	}
+	return fmt.Sprintf("%s dbname=%s", dsn, name)
+}
@@ -0,0 +1,15 @@
+package prometheus
+
+import (
+	prom "github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// GraderQueueDepth is the number of box requests waiting for a free sandbox,
+// labeled by the platform client that sent them ("local" for in-process runs).
+var GraderQueueDepth = promauto.NewGaugeVec(prom.GaugeOpts{
+	Namespace: "kilonova",
+	Subsystem: "grader",
+	Name:      "queue_depth",
+	Help:      "Number of box requests waiting for a free sandbox, per client.",
+}, []string{"client"})
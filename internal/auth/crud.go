@@ -3,8 +3,10 @@ package auth
 import (
 	"context"
 	"crypto/rand"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"golang.org/x/crypto/bcrypt"
 )
 
@@ -27,12 +29,12 @@ func (s *AuthStorage) CreateClient(ctx context.Context, name string, appType App
 	return id, secret, nil
 }
 
-func (s *AuthStorage) ApproveAuthRequest(ctx context.Context, reqID string, userID int) error {
+func (s *AuthStorage) ApproveAuthRequest(ctx context.Context, reqID string, userID int, scopes []string) error {
 	_, err := s.conn.Exec(ctx, `
 		UPDATE oauth_requests
-		SET request_done = true, user_id = $2, auth_time = NOW()
+		SET request_done = true, user_id = $2, scopes = $3, auth_time = NOW()
 		WHERE id = $1
-	`, reqID, userID)
+	`, reqID, userID, scopes)
 
 	return err
 }
@@ -40,3 +42,35 @@ func (s *AuthStorage) ApproveAuthRequest(ctx context.Context, reqID string, user
 func (s *AuthStorage) GetAccessToken(ctx context.Context, tokenID uuid.UUID) (*Token, error) {
 	return s.getAccessToken(ctx, tokenID)
 }
+
+// AuthorizedClient is a client that still holds valid tokens on behalf of a user
+type AuthorizedClient struct {
+	ID           uuid.UUID `db:"id"`
+	Name         string    `db:"name"`
+	Scopes       []string  `db:"scopes"`
+	AuthorizedAt time.Time `db:"authorized_at"`
+	LastUsedAt   time.Time `db:"last_used_at"`
+}
+
+// UserAuthorizedClients returns the clients with unexpired tokens for the given user.
+// Scopes are the ones of the most recently issued token.
+func (s *AuthStorage) UserAuthorizedClients(ctx context.Context, userID int) ([]*AuthorizedClient, error) {
+	rows, _ := s.conn.Query(ctx, `
+		SELECT clients.id, clients.name, MIN(tokens.created_at) AS authorized_at, MAX(tokens.created_at) AS last_used_at,
+			(SELECT latest.scopes FROM oauth_tokens latest
+				WHERE latest.application_id = clients.id AND latest.user_id = $1
+				ORDER BY latest.created_at DESC LIMIT 1) AS scopes
+		FROM oauth_tokens tokens
+		INNER JOIN oauth_clients clients ON clients.id = tokens.application_id
+		WHERE tokens.user_id = $1 AND tokens.expires_at > NOW()
+		GROUP BY clients.id, clients.name
+		ORDER BY last_used_at DESC
+	`, userID)
+	return pgx.CollectRows(rows, pgx.RowToAddrOfStructByName[AuthorizedClient])
+}
+
+// RevokeClient removes all the tokens the client holds on behalf of the user
+func (s *AuthStorage) RevokeClient(ctx context.Context, userID int, clientID uuid.UUID) error {
+	_, err := s.conn.Exec(ctx, "DELETE FROM oauth_tokens WHERE user_id = $1 AND application_id = $2", userID, clientID)
+	return err
+}
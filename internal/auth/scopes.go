@@ -1,13 +1,73 @@
 package auth
 
+import (
+	"slices"
+
+	"github.com/KiloProjects/kilonova"
+	"github.com/zitadel/oidc/v3/pkg/oidc"
+)
+
+const (
+	// ScopeAPI enables access to the API. Tokens without it can only be used for OpenID Connect.
+	ScopeAPI = "api"
+
+	// ScopeAdmin allows performing admin actions. It is only granted to admins.
+	ScopeAdmin = "admin"
+	// ScopeProposer allows performing proposer actions, such as creating problems. It is only granted to proposers.
+	ScopeProposer = "proposer"
+
+	// ScopeProfileRead allows reading the user's private profile data
+	ScopeProfileRead = "profile:read"
+	// ScopeProfileWrite allows changing the user's bio and preferences
+	ScopeProfileWrite = "profile:write"
+
+	// ScopeSubmissionsRead allows reading the user's submissions, including their source code
+	ScopeSubmissionsRead = "submissions:read"
+	// ScopeSubmissionsWrite allows sending submissions on the user's behalf
+	ScopeSubmissionsWrite = "submissions:write"
+
+	// ScopeProblemsEdit allows editing the problems, problem lists, tags and blog posts the user has access to
+	ScopeProblemsEdit = "problems:edit"
+
+	// ScopeContestsParticipate allows registering for contests and asking questions
+	ScopeContestsParticipate = "contests:participate"
+	// ScopeContestsManage allows creating contests and managing the ones the user can edit
+	ScopeContestsManage = "contests:manage"
+)
+
+// Scopes are the non-standard scopes clients may request
 var Scopes = []string{
-	// api enables access to the API
-	"api",
-
-	// admin allows performing admin actions
-	// TODO: strip scope from non-admins
-	"admin",
-	// proposer allows performing proposer actions
-	// TODO: strip scope from non-proposers
-	"proposer",
+	ScopeAPI,
+	ScopeAdmin,
+	ScopeProposer,
+	ScopeProfileRead,
+	ScopeProfileWrite,
+	ScopeSubmissionsRead,
+	ScopeSubmissionsWrite,
+	ScopeProblemsEdit,
+	ScopeContestsParticipate,
+	ScopeContestsManage,
+}
+
+// GrantableScopes returns the requested scopes the user can actually grant.
+// Role scopes are stripped from users that don't have the role.
+func GrantableScopes(requested []string, user *kilonova.UserBrief) []string {
+	return slices.DeleteFunc(slices.Clone(requested), func(scope string) bool {
+		switch scope {
+		case ScopeAdmin:
+			return !user.IsAdmin()
+		case ScopeProposer:
+			return !user.IsProposer()
+		}
+		return false
+	})
+}
+
+// ScopeKnown reports whether the scope has a description to show on the consent screen
+func ScopeKnown(scope string) bool {
+	switch scope {
+	case oidc.ScopeOpenID, oidc.ScopeProfile, oidc.ScopeEmail, oidc.ScopeOfflineAccess:
+		return true
+	}
+	return slices.Contains(Scopes, scope)
 }
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"log/slog"
 	"os"
+	"slices"
 	"strconv"
 	"time"
 
@@ -391,10 +392,36 @@ func (s *AuthStorage) GetRefreshTokenInfo(ctx context.Context, clientID string,
 // tokenOrTokenID will be the refresh token, not its ID.  RevokeToken depends upon GetRefreshTokenInfo
 // to get information from refresh tokens that are not either "<tokenID>:<userID>" strings
 // nor JWTs.
+//
+// As per RFC 7009, unknown tokens are not an error. Revoking a refresh token also revokes the access tokens
+// issued alongside it and from it, while revoking an access token cascades to the refresh token issued with it.
 func (s *AuthStorage) RevokeToken(ctx context.Context, tokenOrTokenID string, userID string, clientID string) *oidc.Error {
-	_, err := s.conn.Exec(ctx, "DELETE FROM oauth_tokens WHERE id = $1 AND user_id = $2 AND application_id = $3", tokenOrTokenID, userID, clientID)
+	tokenID, err := uuid.Parse(tokenOrTokenID)
+	if err != nil {
+		return nil
+	}
+	rows, _ := s.conn.Query(ctx, "SELECT * FROM oauth_tokens WHERE id = $1", tokenID)
+	token, err := pgx.CollectOneRow(rows, pgx.RowToAddrOfStructByName[Token])
 	if err != nil {
-		return oidc.ErrServerError()
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil
+		}
+		return oidc.ErrServerError().WithParent(err)
+	}
+	if token.ApplicationID.String() != clientID {
+		return oidc.ErrInvalidClient().WithDescription("token was not issued to this client")
+	}
+	if userID != "" && (token.UserID == nil || strconv.Itoa(*token.UserID) != userID) {
+		return nil
+	}
+
+	if token.TokenType == TokenTypeRefresh && token.ParentToken != nil {
+		_, err = s.conn.Exec(ctx, "DELETE FROM oauth_tokens WHERE id = $1 OR id = $2", token.ID, *token.ParentToken)
+	} else {
+		_, err = s.conn.Exec(ctx, "DELETE FROM oauth_tokens WHERE id = $1", token.ID)
+	}
+	if err != nil {
+		return oidc.ErrServerError().WithParent(err)
 	}
 	return nil
 }
@@ -535,6 +562,8 @@ func (s *AuthStorage) SetUserinfoFromToken(ctx context.Context, userinfo *oidc.U
 	return s.setUserInfo(ctx, userinfo, *token.UserID, token.ApplicationID.String(), token.Scopes)
 }
 
+// SetIntrospectionFromToken fills the RFC 7662 introspection response of an access token.
+// Returning an error marks the token as inactive, which is what happens with expired or revoked tokens.
 func (s *AuthStorage) SetIntrospectionFromToken(ctx context.Context, introspection *oidc.IntrospectionResponse, tokenID string, subject string, clientID string) error {
 	tokenUUID, err := uuid.Parse(tokenID)
 	if err != nil {
@@ -547,20 +576,26 @@ func (s *AuthStorage) SetIntrospectionFromToken(ctx context.Context, introspecti
 	if token.UserID == nil {
 		return fmt.Errorf("token has no user ID")
 	}
-	for _, aud := range token.Audience {
-		if aud == clientID {
-			userInfo := new(oidc.UserInfo)
-			err := s.setUserInfo(ctx, userInfo, *token.UserID, clientID, token.Scopes)
-			if err != nil {
-				return fmt.Errorf("failed to set user info: %w", err)
-			}
-			introspection.SetUserInfo(userInfo)
-			introspection.Scope = token.Scopes
-			introspection.ClientID = token.ApplicationID.String()
-			return nil
-		}
+	if token.ExpiresAt.Before(time.Now()) {
+		return fmt.Errorf("token expired")
+	}
+	if !slices.Contains(token.Audience, clientID) {
+		return fmt.Errorf("token is not valid for this client")
 	}
-	return fmt.Errorf("token is not valid for this client")
+
+	userInfo := new(oidc.UserInfo)
+	if err := s.setUserInfo(ctx, userInfo, *token.UserID, clientID, token.Scopes); err != nil {
+		return fmt.Errorf("failed to set user info: %w", err)
+	}
+	introspection.SetUserInfo(userInfo)
+	introspection.Scope = token.Scopes
+	introspection.ClientID = token.ApplicationID.String()
+	introspection.TokenType = oidc.BearerToken
+	introspection.Audience = token.Audience
+	introspection.IssuedAt = oidc.FromTime(token.CreatedAt)
+	introspection.Expiration = oidc.FromTime(token.ExpiresAt)
+	introspection.JWTID = token.ID.String()
+	return nil
 }
 
 func (s *AuthStorage) GetPrivateClaimsFromScopes(ctx context.Context, userID string, clientID string, scopes []string) (map[string]any, error) {
@@ -0,0 +1,250 @@
+// Package clics holds the objects of the CLICS Contest API (2023-06 version), used to export
+// contests to tools such as the ICPC Resolver or a Contest Data Server.
+//
+// Only the subset of endpoints needed to rebuild the scoreboard is implemented.
+package clics
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+)
+
+// AbsTime formats t as a CLICS ABSTIME.
+func AbsTime(t time.Time) string {
+	return t.Format("2006-01-02T15:04:05.000Z07:00")
+}
+
+// RelTime formats d as a CLICS RELTIME (h:mm:ss.uuu).
+func RelTime(d time.Duration) string {
+	sign := ""
+	if d < 0 {
+		sign = "-"
+		d = -d
+	}
+	ms := d.Milliseconds()
+	return fmt.Sprintf("%s%d:%02d:%02d.%03d", sign, ms/3_600_000, ms/60_000%60, ms/1000%60, ms%1000)
+}
+
+// ScoreboardType is either pass-fail (ICPC-style) or score (IOI-style)
+type ScoreboardType string
+
+const (
+	ScoreboardPassFail ScoreboardType = "pass-fail"
+	ScoreboardScore    ScoreboardType = "score"
+)
+
+type Contest struct {
+	ID                       string         `json:"id"`
+	Name                     string         `json:"name"`
+	FormalName               string         `json:"formal_name"`
+	StartTime                string         `json:"start_time"`
+	Duration                 string         `json:"duration"`
+	ScoreboardFreezeDuration *string        `json:"scoreboard_freeze_duration"`
+	ScoreboardType           ScoreboardType `json:"scoreboard_type"`
+	PenaltyTime              string         `json:"penalty_time"`
+}
+
+type State struct {
+	Started      *string `json:"started"`
+	Frozen       *string `json:"frozen"`
+	Ended        *string `json:"ended"`
+	Thawed       *string `json:"thawed"`
+	Finalized    *string `json:"finalized"`
+	EndOfUpdates *string `json:"end_of_updates"`
+}
+
+type JudgementType struct {
+	ID      string `json:"id"`
+	Name    string `json:"name"`
+	Penalty bool   `json:"penalty"`
+	Solved  bool   `json:"solved"`
+}
+
+// Judgement type IDs, as recommended by the CLICS specification
+const (
+	JudgementAccepted     = "AC"
+	JudgementWrongAnswer  = "WA"
+	JudgementTimeLimit    = "TLE"
+	JudgementMemoryLimit  = "MLE"
+	JudgementRuntimeError = "RTE"
+	JudgementOutputLimit  = "OLE"
+	JudgementCompileError = "CE"
+	JudgementJudgingError = "JE"
+)
+
+// JudgementTypes returns the judgement types used by exported contests.
+// Compile errors are the only rejections that don't incur penalty, just like on the site leaderboard.
+func JudgementTypes() []*JudgementType {
+	return []*JudgementType{
+		{ID: JudgementAccepted, Name: "Accepted", Solved: true},
+		{ID: JudgementWrongAnswer, Name: "Wrong Answer", Penalty: true},
+		{ID: JudgementTimeLimit, Name: "Time Limit Exceeded", Penalty: true},
+		{ID: JudgementMemoryLimit, Name: "Memory Limit Exceeded", Penalty: true},
+		{ID: JudgementRuntimeError, Name: "Run-Time Error", Penalty: true},
+		{ID: JudgementOutputLimit, Name: "Output Limit Exceeded", Penalty: true},
+		{ID: JudgementCompileError, Name: "Compile Error"},
+		{ID: JudgementJudgingError, Name: "Judging Error", Penalty: true},
+	}
+}
+
+type Language struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+type Problem struct {
+	ID      string `json:"id"`
+	Label   string `json:"label"`
+	Name    string `json:"name"`
+	Ordinal int    `json:"ordinal"`
+	// TestDataCount is required by the specification, but not relevant for the scoreboard
+	TestDataCount int `json:"test_data_count"`
+}
+
+type Group struct {
+	ID     string `json:"id"`
+	Name   string `json:"name"`
+	Hidden bool   `json:"hidden"`
+}
+
+type Team struct {
+	ID          string   `json:"id"`
+	Name        string   `json:"name"`
+	DisplayName string   `json:"display_name"`
+	GroupIDs    []string `json:"group_ids"`
+}
+
+type File struct {
+	Href     string `json:"href"`
+	Filename string `json:"filename"`
+	Mime     string `json:"mime"`
+}
+
+type Submission struct {
+	ID          string  `json:"id"`
+	LanguageID  string  `json:"language_id"`
+	ProblemID   string  `json:"problem_id"`
+	TeamID      string  `json:"team_id"`
+	Time        string  `json:"time"`
+	ContestTime string  `json:"contest_time"`
+	Files       []*File `json:"files"`
+}
+
+type Judgement struct {
+	ID               string   `json:"id"`
+	SubmissionID     string   `json:"submission_id"`
+	JudgementTypeID  *string  `json:"judgement_type_id"`
+	Score            *float64 `json:"score,omitempty"`
+	StartTime        string   `json:"start_time"`
+	StartContestTime string   `json:"start_contest_time"`
+	EndTime          *string  `json:"end_time"`
+	EndContestTime   *string  `json:"end_contest_time"`
+}
+
+type RowScore struct {
+	NumSolved int      `json:"num_solved"`
+	TotalTime *string  `json:"total_time,omitempty"`
+	Score     *float64 `json:"score,omitempty"`
+	Time      *string  `json:"time,omitempty"`
+}
+
+type ScoreboardProblem struct {
+	ProblemID  string   `json:"problem_id"`
+	NumJudged  int      `json:"num_judged"`
+	NumPending int      `json:"num_pending"`
+	Solved     bool     `json:"solved"`
+	Score      *float64 `json:"score,omitempty"`
+	Time       *string  `json:"time,omitempty"`
+}
+
+type ScoreboardRow struct {
+	Rank     int                  `json:"rank"`
+	TeamID   string               `json:"team_id"`
+	Score    RowScore             `json:"score"`
+	Problems []*ScoreboardProblem `json:"problems"`
+}
+
+type Scoreboard struct {
+	Time        string           `json:"time"`
+	ContestTime string           `json:"contest_time"`
+	State       *State           `json:"state"`
+	Rows        []*ScoreboardRow `json:"rows"`
+}
+
+// Package is the whole contest, in the layout of a CLICS contest package / CDS JSON dump.
+type Package struct {
+	Contest        *Contest         `json:"contest"`
+	State          *State           `json:"state"`
+	JudgementTypes []*JudgementType `json:"judgement-types"`
+	Languages      []*Language      `json:"languages"`
+	Problems       []*Problem       `json:"problems"`
+	Groups         []*Group         `json:"groups"`
+	Teams          []*Team          `json:"teams"`
+	Submissions    []*Submission    `json:"submissions"`
+	// Judgements are missing for pending or frozen submissions
+	Judgements []*Judgement `json:"judgements"`
+	Scoreboard *Scoreboard  `json:"scoreboard"`
+}
+
+type Event struct {
+	Type  string  `json:"type"`
+	ID    *string `json:"id"`
+	Data  any     `json:"data"`
+	Token string  `json:"token"`
+}
+
+// Events returns the event feed that builds the package from scratch.
+// Submissions and their judgements are ordered chronologically, so that the feed can be replayed.
+func (p *Package) Events() []*Event {
+	var events []*Event
+	add := func(typ string, id *string, data any) {
+		events = append(events, &Event{Type: typ, ID: id, Data: data, Token: fmt.Sprint(len(events) + 1)})
+	}
+
+	add("contest", nil, p.Contest)
+	for _, jt := range p.JudgementTypes {
+		add("judgement-types", &jt.ID, jt)
+	}
+	for _, lang := range p.Languages {
+		add("languages", &lang.ID, lang)
+	}
+	for _, pb := range p.Problems {
+		add("problems", &pb.ID, pb)
+	}
+	for _, group := range p.Groups {
+		add("groups", &group.ID, group)
+	}
+	for _, team := range p.Teams {
+		add("teams", &team.ID, team)
+	}
+
+	judgements := make(map[string]*Judgement, len(p.Judgements))
+	for _, j := range p.Judgements {
+		judgements[j.SubmissionID] = j
+	}
+	// Submissions are expected to already be sorted by time
+	for _, sub := range p.Submissions {
+		add("submissions", &sub.ID, sub)
+		if j, ok := judgements[sub.ID]; ok {
+			add("judgements", &j.ID, j)
+		}
+	}
+
+	add("state", nil, p.State)
+	return events
+}
+
+// WriteEventFeed writes the package's event feed as newline-delimited JSON.
+func (p *Package) WriteEventFeed(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	enc := json.NewEncoder(bw)
+	for _, event := range p.Events() {
+		if err := enc.Encode(event); err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
@@ -0,0 +1,61 @@
+package clics
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestRelTime(t *testing.T) {
+	for d, want := range map[time.Duration]string{
+		0:                                      "0:00:00.000",
+		5 * time.Hour:                          "5:00:00.000",
+		90*time.Minute + 1500*time.Millisecond: "1:30:01.500",
+		-20 * time.Minute:                      "-0:20:00.000",
+		30 * time.Hour:                         "30:00:00.000",
+	} {
+		if got := RelTime(d); got != want {
+			t.Errorf("RelTime(%v) = %q, want %q", d, got, want)
+		}
+	}
+}
+
+func TestEventFeed(t *testing.T) {
+	ac := JudgementAccepted
+	pkg := &Package{
+		Contest:        &Contest{ID: "1"},
+		State:          &State{},
+		JudgementTypes: JudgementTypes(),
+		Problems:       []*Problem{{ID: "10", Label: "A"}},
+		Teams:          []*Team{{ID: "5"}},
+		Submissions:    []*Submission{{ID: "100", ProblemID: "10", TeamID: "5"}, {ID: "101", ProblemID: "10", TeamID: "5"}},
+		// 101 is frozen, so it has no judgement
+		Judgements: []*Judgement{{ID: "100", SubmissionID: "100", JudgementTypeID: &ac}},
+	}
+
+	var buf bytes.Buffer
+	if err := pkg.WriteEventFeed(&buf); err != nil {
+		t.Fatal(err)
+	}
+	var types []string
+	dec := json.NewDecoder(&buf)
+	for dec.More() {
+		var ev struct {
+			Type  string `json:"type"`
+			Token string `json:"token"`
+		}
+		if err := dec.Decode(&ev); err != nil {
+			t.Fatal(err)
+		}
+		types = append(types, ev.Type)
+	}
+
+	n := len(types)
+	if types[0] != "contest" || types[n-1] != "state" {
+		t.Fatalf("feed must start with the contest and end with the state, got %v", types)
+	}
+	if got := types[n-4 : n-1]; got[0] != "submissions" || got[1] != "judgements" || got[2] != "submissions" {
+		t.Fatalf("judgements must follow their submission, got %v", got)
+	}
+}
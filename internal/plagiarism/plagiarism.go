@@ -0,0 +1,297 @@
+// Package plagiarism implements an in-process source code similarity checker, in the spirit of MOSS.
+//
+// Code is tokenized per language family, with identifiers and literals normalized away,
+// and documents are then compared by their winnowed k-gram fingerprints
+// (Schleimer, Wilkerson, Aiken - "Winnowing: Local Algorithms for Document Fingerprinting").
+package plagiarism
+
+import (
+	"cmp"
+	"hash/fnv"
+	"slices"
+)
+
+type Config struct {
+	// K is the length of the token k-grams that are hashed
+	K int
+	// Window is the winnowing window. Any match of at least K+Window-1 tokens is guaranteed to be detected.
+	Window int
+	// MaxOwners ignores fingerprints found in the code of more than this many owners, since they are most likely
+	// common boilerplate (fast IO, standard algorithms). 0 means no limit.
+	MaxOwners int
+	// MinSimilarity is the lowest similarity of a pair (on either side) that is reported
+	MinSimilarity float64
+	// ClusterSimilarity is the lowest similarity of a pair for it to be clustered with others
+	ClusterSimilarity float64
+}
+
+func DefaultConfig() Config {
+	return Config{
+		K:                 12,
+		Window:            8,
+		MaxOwners:         10,
+		MinSimilarity:     0.3,
+		ClusterSimilarity: 0.6,
+	}
+}
+
+type fingerprint struct {
+	hash uint64
+	// pos is the index of the first token of the k-gram
+	pos int
+}
+
+// Document is a tokenized and fingerprinted source file.
+type Document struct {
+	ID int
+	// Owner is the author of the document. Documents of the same owner are never compared.
+	Owner int
+
+	tokens []Token
+	// positions holds the first position of each distinct fingerprint
+	positions map[uint64]int
+}
+
+// NewDocument tokenizes and fingerprints code.
+func NewDocument(id, owner int, family *Family, code []byte, cfg Config) *Document {
+	doc := &Document{
+		ID:        id,
+		Owner:     owner,
+		tokens:    family.Tokenize(code),
+		positions: make(map[uint64]int),
+	}
+	for _, fp := range winnow(kgramHashes(doc.tokens, cfg.K), cfg.Window) {
+		if _, ok := doc.positions[fp.hash]; !ok {
+			doc.positions[fp.hash] = fp.pos
+		}
+	}
+	return doc
+}
+
+// NumFingerprints returns the number of distinct fingerprints of the document
+func (d *Document) NumFingerprints() int {
+	return len(d.positions)
+}
+
+func kgramHashes(tokens []Token, k int) []uint64 {
+	if len(tokens) < k {
+		return nil
+	}
+	hashes := make([]uint64, 0, len(tokens)-k+1)
+	for i := 0; i+k <= len(tokens); i++ {
+		h := fnv.New64a()
+		for _, tok := range tokens[i : i+k] {
+			h.Write([]byte(tok.Text))
+			h.Write([]byte{0})
+		}
+		hashes = append(hashes, h.Sum64())
+	}
+	return hashes
+}
+
+// winnow selects the minimum hash of every window of w consecutive hashes (the rightmost one on ties),
+// recording it only when it differs from the previously selected one.
+func winnow(hashes []uint64, w int) []fingerprint {
+	if len(hashes) == 0 {
+		return nil
+	}
+	if w <= 1 || len(hashes) < w {
+		// Short documents are fingerprinted by their minimum hash, just like a single window
+		w = min(max(w, 1), len(hashes))
+	}
+	var fps []fingerprint
+	last := -1
+	for start := 0; start+w <= len(hashes); start++ {
+		minPos := start
+		for i := start; i < start+w; i++ {
+			if hashes[i] <= hashes[minPos] {
+				minPos = i
+			}
+		}
+		if minPos != last {
+			fps = append(fps, fingerprint{hash: hashes[minPos], pos: minPos})
+			last = minPos
+		}
+	}
+	return fps
+}
+
+// Fragment is a pair of matching line ranges (1-indexed, inclusive) from the two documents of a match.
+type Fragment struct {
+	Start1 int `json:"start1"`
+	End1   int `json:"end1"`
+	Start2 int `json:"start2"`
+	End2   int `json:"end2"`
+}
+
+type Match struct {
+	Doc1, Doc2 *Document
+	// Similarity1 is the fraction of the fingerprints of Doc1 that are also in Doc2, and vice versa for Similarity2
+	Similarity1 float64
+	Similarity2 float64
+	// Shared is the number of shared fingerprints
+	Shared    int
+	Fragments []Fragment
+	// Cluster is the 1-indexed group of documents the match belongs to, or 0 if it isn't similar enough to be clustered.
+	Cluster int
+}
+
+// Similarity is the highest of the two similarities
+func (m *Match) Similarity() float64 {
+	return max(m.Similarity1, m.Similarity2)
+}
+
+// Compare finds the pairs of similar documents, ordered by decreasing similarity.
+func Compare(docs []*Document, cfg Config) []*Match {
+	matches := compare(docs, cfg, nil)
+	cluster(matches, cfg.ClusterSimilarity)
+	return matches
+}
+
+// CompareWith finds the documents similar to target, ordered by decreasing similarity.
+// The other documents are only used to rule out common code, they are never compared with each other.
+// Clustering isn't done, since all matches share the target.
+func CompareWith(target *Document, docs []*Document, cfg Config) []*Match {
+	all := append([]*Document{target}, docs...)
+	return compare(all, cfg, target)
+}
+
+// compare finds the pairs of similar documents. If target is not nil, only pairs containing it are considered.
+func compare(docs []*Document, cfg Config, target *Document) []*Match {
+	// Inverted index from fingerprints to the documents containing them
+	index := make(map[uint64][]int)
+	for i, doc := range docs {
+		for hash := range doc.positions {
+			index[hash] = append(index[hash], i)
+		}
+	}
+
+	shared := make(map[[2]int]int)
+	for _, docIdxs := range index {
+		if len(docIdxs) < 2 {
+			continue
+		}
+		if cfg.MaxOwners > 0 {
+			owners := make(map[int]bool)
+			for _, idx := range docIdxs {
+				owners[docs[idx].Owner] = true
+			}
+			if len(owners) > cfg.MaxOwners {
+				continue
+			}
+		}
+		for i, a := range docIdxs {
+			for _, b := range docIdxs[i+1:] {
+				if docs[a].Owner == docs[b].Owner {
+					continue
+				}
+				if target != nil && docs[a] != target && docs[b] != target {
+					continue
+				}
+				shared[[2]int{min(a, b), max(a, b)}]++
+			}
+		}
+	}
+
+	var matches []*Match
+	for pair, cnt := range shared {
+		doc1, doc2 := docs[pair[0]], docs[pair[1]]
+		match := &Match{
+			Doc1:        doc1,
+			Doc2:        doc2,
+			Similarity1: float64(cnt) / float64(doc1.NumFingerprints()),
+			Similarity2: float64(cnt) / float64(doc2.NumFingerprints()),
+			Shared:      cnt,
+		}
+		if match.Similarity() < cfg.MinSimilarity {
+			continue
+		}
+		match.Fragments = fragments(doc1, doc2, cfg)
+		matches = append(matches, match)
+	}
+	slices.SortFunc(matches, func(a, b *Match) int {
+		return cmp.Or(
+			cmp.Compare(b.Similarity(), a.Similarity()),
+			cmp.Compare(b.Shared, a.Shared),
+			cmp.Compare(a.Doc1.ID, b.Doc1.ID),
+			cmp.Compare(a.Doc2.ID, b.Doc2.ID),
+		)
+	})
+	return matches
+}
+
+// fragments groups the shared fingerprints of the two documents into matching line ranges.
+func fragments(doc1, doc2 *Document, cfg Config) []Fragment {
+	type pair struct{ pos1, pos2 int }
+	var pairs []pair
+	for hash, pos1 := range doc1.positions {
+		if pos2, ok := doc2.positions[hash]; ok {
+			pairs = append(pairs, pair{pos1, pos2})
+		}
+	}
+	slices.SortFunc(pairs, func(a, b pair) int {
+		return cmp.Or(cmp.Compare(a.pos1, b.pos1), cmp.Compare(a.pos2, b.pos2))
+	})
+
+	// Consecutive fingerprints are at most a window apart, so anything further away starts a new fragment
+	gap := cfg.K + cfg.Window
+	var frags []Fragment
+	var start, last pair
+	flush := func() {
+		frags = append(frags, Fragment{
+			Start1: doc1.tokens[start.pos1].Line,
+			End1:   doc1.tokens[min(last.pos1+cfg.K, len(doc1.tokens))-1].Line,
+			Start2: doc2.tokens[start.pos2].Line,
+			End2:   doc2.tokens[min(last.pos2+cfg.K, len(doc2.tokens))-1].Line,
+		})
+	}
+	for i, p := range pairs {
+		if i > 0 && (p.pos1-last.pos1 > gap || p.pos2 <= last.pos2 || p.pos2-last.pos2 > gap) {
+			flush()
+			start = p
+		} else if i == 0 {
+			start = p
+		}
+		last = p
+	}
+	if len(pairs) > 0 {
+		flush()
+	}
+	return frags
+}
+
+// cluster groups the documents connected by similar enough matches.
+// Clusters are numbered in the order of their most similar match.
+func cluster(matches []*Match, threshold float64) {
+	parent := make(map[*Document]*Document)
+	var find func(d *Document) *Document
+	find = func(d *Document) *Document {
+		p, ok := parent[d]
+		if !ok || p == d {
+			return d
+		}
+		root := find(p)
+		parent[d] = root
+		return root
+	}
+	for _, m := range matches {
+		if m.Similarity() < threshold {
+			continue
+		}
+		if a, b := find(m.Doc1), find(m.Doc2); a != b {
+			parent[a] = b
+		}
+	}
+
+	clusters := make(map[*Document]int)
+	for _, m := range matches {
+		if m.Similarity() < threshold {
+			continue
+		}
+		root := find(m.Doc1)
+		if _, ok := clusters[root]; !ok {
+			clusters[root] = len(clusters) + 1
+		}
+		m.Cluster = clusters[root]
+	}
+}
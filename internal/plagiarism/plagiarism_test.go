@@ -0,0 +1,146 @@
+package plagiarism
+
+import (
+	"slices"
+	"testing"
+)
+
+const original = `#include <bits/stdc++.h>
+using namespace std;
+
+int n, v[100005];
+
+int main() {
+	cin >> n;
+	for(int i = 1; i <= n; i++) {
+		cin >> v[i];
+	}
+	sort(v + 1, v + n + 1);
+	long long sum = 0;
+	for(int i = 1; i <= n; i++) {
+		if(v[i] % 2 == 0) {
+			sum += v[i] * i;
+		}
+	}
+	cout << sum << '\n';
+	return 0;
+}
+`
+
+// Same code, with renamed variables, different formatting and comments
+const renamed = `#include <iostream>
+#include <algorithm>
+using namespace std;
+int cnt, arr[100005]; // the numbers
+
+int main()
+{
+	cin >> cnt;
+	for(int j = 1; j <= cnt; j++) { cin >> arr[j]; }
+	/* sort them first */
+	sort(arr + 1, arr + cnt + 1);
+	long long total = 0;
+	for(int j = 1; j <= cnt; j++)
+	{
+		if(arr[j] % 2 == 0)
+		{
+			total += arr[j] * j;
+		}
+	}
+	cout << total << "\n";
+	return 0;
+}
+`
+
+const unrelated = `#include <cstdio>
+int a, b;
+int gcd(int x, int y) {
+	while(y) {
+		int r = x % y;
+		x = y;
+		y = r;
+	}
+	return x;
+}
+int main() {
+	scanf("%d %d", &a, &b);
+	printf("%d\n", gcd(a, b) * 3 - a / b);
+}
+`
+
+func TestTokenize(t *testing.T) {
+	tokens := CLike.Tokenize([]byte("#include <stdio.h>\nint x = 5; // comment\n/* a\nb */ x += \"str\";"))
+	var texts []string
+	for _, tok := range tokens {
+		texts = append(texts, tok.Text)
+	}
+	want := []string{"int", "V", "=", "N", ";", "V", "+=", "S", ";"}
+	if !slices.Equal(texts, want) {
+		t.Fatalf("got tokens %v, want %v", texts, want)
+	}
+	if tokens[0].Line != 2 || tokens[len(tokens)-1].Line != 4 {
+		t.Fatalf("wrong token lines: %v", tokens)
+	}
+
+	tokens = Pascal.Tokenize([]byte("BEGIN { comment } WriteLn(X) end."))
+	if tokens[0].Text != "begin" || tokens[2].Text != "(" || tokens[3].Text != "V" {
+		t.Fatalf("wrong pascal tokens: %v", tokens)
+	}
+}
+
+func TestCompare(t *testing.T) {
+	cfg := DefaultConfig()
+	docs := []*Document{
+		NewDocument(1, 1, CLike, []byte(original), cfg),
+		NewDocument(2, 2, CLike, []byte(renamed), cfg),
+		NewDocument(3, 3, CLike, []byte(unrelated), cfg),
+		// Same owner as the original, must not be compared with it
+		NewDocument(4, 1, CLike, []byte(renamed), cfg),
+	}
+
+	matches := Compare(docs, cfg)
+	for _, m := range matches {
+		if m.Doc1.ID == 3 || m.Doc2.ID == 3 {
+			t.Errorf("unrelated code matched with similarity %f", m.Similarity())
+		}
+		if m.Doc1.Owner == m.Doc2.Owner {
+			t.Errorf("documents of the same owner were compared")
+		}
+	}
+	if len(matches) != 2 {
+		t.Fatalf("expected 2 matches, got %d", len(matches))
+	}
+
+	m := matches[0]
+	if m.Similarity() < 0.9 {
+		t.Errorf("renamed code should be almost identical, got similarity %f", m.Similarity())
+	}
+	if m.Cluster != 1 || matches[1].Cluster != 1 {
+		t.Errorf("expected all matches to be in the same cluster, got %d and %d", m.Cluster, matches[1].Cluster)
+	}
+	if len(m.Fragments) == 0 {
+		t.Fatal("expected matching fragments")
+	}
+	if frag := m.Fragments[0]; frag.Start1 > frag.End1 || frag.Start2 > frag.End2 || frag.Start1 < 2 {
+		t.Errorf("invalid fragment %+v", frag)
+	}
+}
+
+func TestCompareWith(t *testing.T) {
+	cfg := DefaultConfig()
+	target := NewDocument(1, 1, CLike, []byte(original), cfg)
+	archive := []*Document{
+		NewDocument(2, 2, CLike, []byte(renamed), cfg),
+		NewDocument(3, 3, CLike, []byte(unrelated), cfg),
+		// Similar to the first archive document, but not to the target
+		NewDocument(4, 4, CLike, []byte(unrelated), cfg),
+	}
+
+	matches := CompareWith(target, archive, cfg)
+	if len(matches) != 1 {
+		t.Fatalf("expected 1 match, got %d", len(matches))
+	}
+	if m := matches[0]; m.Doc1 != target || m.Doc2.ID != 2 || m.Cluster != 0 {
+		t.Fatalf("unexpected match between %d and %d (cluster %d)", m.Doc1.ID, m.Doc2.ID, m.Cluster)
+	}
+}
@@ -0,0 +1,244 @@
+package plagiarism
+
+import (
+	"bytes"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Family describes the lexical syntax shared by a group of languages.
+// Identifiers that are not keywords are all normalized to the same token, so renaming variables doesn't hide copied code.
+type Family struct {
+	Name string
+
+	LineComments  []string
+	BlockComments [][2]string
+	// Backticks are raw strings in Go and template literals in JS
+	Backticks bool
+	// TripleQuotes are Python's docstrings
+	TripleQuotes bool
+	// SkipIncludes drops C preprocessor includes, which are mostly the same boilerplate everywhere
+	SkipIncludes    bool
+	CaseInsensitive bool
+
+	Keywords map[string]bool
+}
+
+// Token is a normalized lexical unit of a source file
+type Token struct {
+	Text string
+	// Line is 1-indexed
+	Line int
+}
+
+const (
+	identToken  = "V"
+	numberToken = "N"
+	stringToken = "S"
+)
+
+func keywords(s string) map[string]bool {
+	kw := make(map[string]bool)
+	for word := range strings.FieldsSeq(s) {
+		kw[word] = true
+	}
+	return kw
+}
+
+var (
+	CLike = &Family{
+		Name:          "c-like",
+		LineComments:  []string{"//"},
+		BlockComments: [][2]string{{"/*", "*/"}},
+		Backticks:     true,
+		SkipIncludes:  true,
+		// Common keywords from C, C++, Java, JavaScript, Go, Kotlin, Rust and PHP.
+		// Type names are kept as well, since changing an int to a long long is a meaningful edit.
+		Keywords: keywords(`if else for while do switch case default break continue return goto
+			struct class union enum typedef namespace using template typename public private protected
+			static const constexpr auto void int long short char bool float double unsigned signed
+			true false null nullptr new delete this try catch throw final var let val fun func fn
+			function import package extends implements interface go defer chan select range map
+			mut impl match loop pub use mod trait where when is in as of echo`),
+	}
+	Python = &Family{
+		Name:          "python",
+		LineComments:  []string{"#"},
+		BlockComments: nil,
+		TripleQuotes:  true,
+		Keywords: keywords(`and as assert async await break class continue def del elif else except
+			finally for from global if import in is lambda nonlocal not or pass raise return try while
+			with yield None True False print input range len int str float list dict set tuple`),
+	}
+	Pascal = &Family{
+		Name:            "pascal",
+		LineComments:    []string{"//"},
+		BlockComments:   [][2]string{{"{", "}"}, {"(*", "*)"}},
+		CaseInsensitive: true,
+		Keywords: keywords(`and array begin case const div do downto else end file for function goto
+			if in label mod nil not of or packed procedure program record repeat set then to type until
+			var while with uses integer longint int64 real boolean char string read readln write writeln`),
+	}
+	Haskell = &Family{
+		Name:          "haskell",
+		LineComments:  []string{"--"},
+		BlockComments: [][2]string{{"{-", "-}"}},
+		Keywords: keywords(`case class data default deriving do else if import in infix infixl infixr
+			instance let module newtype of then type where main`),
+	}
+)
+
+// FamilyFor returns the lexical family of the language with the given MOSS name.
+// Languages MOSS doesn't know about are assumed to have a C-like syntax.
+func FamilyFor(mossName string) *Family {
+	switch mossName {
+	case "python":
+		return Python
+	case "pascal":
+		return Pascal
+	case "haskell":
+		return Haskell
+	default:
+		return CLike
+	}
+}
+
+// multi-character operators, longest first
+var operators = []string{
+	"<<=", ">>=", "...", "**=", "//=",
+	"->", "=>", "++", "--", "<<", ">>", "<=", ">=", "==", "!=", "&&", "||",
+	"+=", "-=", "*=", "/=", "%=", "&=", "|=", "^=", "::", ":=", "**",
+}
+
+// Tokenize splits the code into normalized tokens, dropping whitespace and comments.
+func (f *Family) Tokenize(code []byte) []Token {
+	var tokens []Token
+	line := 1
+	i := 0
+	add := func(text string) {
+		tokens = append(tokens, Token{Text: text, Line: line})
+	}
+	// skip advances past s[i:j], counting lines
+	skip := func(j int) {
+		line += bytes.Count(code[i:j], []byte{'\n'})
+		i = j
+	}
+	// until returns the index right after the first occurrence of end, starting from start, or the end of the code
+	until := func(start int, end string) int {
+		if idx := bytes.Index(code[start:], []byte(end)); idx >= 0 {
+			return start + idx + len(end)
+		}
+		return len(code)
+	}
+
+outer:
+	for i < len(code) {
+		c := code[i]
+		if c == '\n' {
+			line++
+			i++
+			continue
+		}
+		if c == ' ' || c == '\t' || c == '\r' || c == '\f' || c == '\v' {
+			i++
+			continue
+		}
+
+		for _, comm := range f.LineComments {
+			if bytes.HasPrefix(code[i:], []byte(comm)) {
+				// The newline is left for the main loop
+				j := bytes.IndexByte(code[i:], '\n')
+				if j < 0 {
+					j = len(code) - i
+				}
+				skip(i + j)
+				continue outer
+			}
+		}
+		for _, comm := range f.BlockComments {
+			if bytes.HasPrefix(code[i:], []byte(comm[0])) {
+				skip(until(i+len(comm[0]), comm[1]))
+				continue outer
+			}
+		}
+
+		if f.SkipIncludes && c == '#' {
+			j := bytes.IndexByte(code[i:], '\n')
+			if j < 0 {
+				j = len(code) - i
+			}
+			if directive := strings.TrimSpace(strings.TrimPrefix(string(code[i:i+j]), "#")); strings.HasPrefix(directive, "include") || strings.HasPrefix(directive, "pragma") {
+				skip(i + j)
+				continue
+			}
+		}
+
+		switch {
+		case f.TripleQuotes && (bytes.HasPrefix(code[i:], []byte(`"""`)) || bytes.HasPrefix(code[i:], []byte(`'''`))):
+			quote := string(code[i : i+3])
+			add(stringToken)
+			skip(until(i+3, quote))
+		case c == '"' || c == '\'' || (c == '`' && f.Backticks):
+			add(stringToken)
+			j := i + 1
+			for j < len(code) && code[j] != c {
+				if code[j] == '\\' {
+					j++
+				} else if code[j] == '\n' && c != '`' {
+					// Unterminated literal, don't swallow the rest of the file
+					break
+				}
+				j++
+			}
+			skip(min(j+1, len(code)))
+		case c >= '0' && c <= '9':
+			j := i
+			for j < len(code) && (isIdentByte(code[j]) || code[j] == '.') {
+				j++
+			}
+			add(numberToken)
+			i = j
+		case isIdentStart(code[i:]):
+			j := i
+			for j < len(code) {
+				r, size := utf8.DecodeRune(code[j:])
+				if r != '_' && !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+					break
+				}
+				j += size
+			}
+			word := string(code[i:j])
+			if f.CaseInsensitive {
+				word = strings.ToLower(word)
+			}
+			if f.Keywords[word] {
+				add(word)
+			} else {
+				add(identToken)
+			}
+			i = j
+		default:
+			for _, op := range operators {
+				if bytes.HasPrefix(code[i:], []byte(op)) {
+					add(op)
+					i += len(op)
+					continue outer
+				}
+			}
+			_, size := utf8.DecodeRune(code[i:])
+			add(string(code[i : i+size]))
+			i += size
+		}
+	}
+	return tokens
+}
+
+func isIdentByte(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
+
+func isIdentStart(b []byte) bool {
+	r, _ := utf8.DecodeRune(b)
+	return r == '_' || unicode.IsLetter(r)
+}
@@ -0,0 +1,93 @@
+// Package pubsub holds the topic-based broker behind live updates (server-sent events).
+// Payloads are pre-encoded JSON so that the in-process broker can be swapped for a
+// Postgres LISTEN/NOTIFY-backed one without changing publishers or subscribers.
+package pubsub
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+)
+
+// Event is a single message published on a topic.
+type Event struct {
+	Type string          `json:"type"`
+	Data json.RawMessage `json:"data"`
+}
+
+// Broker delivers events published on a topic to all of its current subscribers.
+// Delivery is best-effort: slow subscribers may miss events and should refetch state.
+type Broker interface {
+	Publish(ctx context.Context, topic string, event Event) error
+	// Subscribe returns a channel that is closed once ctx is done or the returned cancel function is called.
+	Subscribe(ctx context.Context, topic string) (<-chan Event, func())
+}
+
+// SubscriberCounter is implemented by brokers that know how many subscribers a topic has,
+// letting publishers skip building events nobody would receive.
+type SubscriberCounter interface {
+	Subscribers(topic string) int
+}
+
+// subscriberBuffer is how many events can be queued for a subscriber before newer ones are dropped
+const subscriberBuffer = 32
+
+type subscriber struct {
+	ch   chan Event
+	once sync.Once
+}
+
+// MemoryBroker is a Broker that only reaches subscribers in the current process.
+type MemoryBroker struct {
+	mu     sync.RWMutex
+	topics map[string]map[*subscriber]struct{}
+}
+
+func NewMemoryBroker() *MemoryBroker {
+	return &MemoryBroker{topics: make(map[string]map[*subscriber]struct{})}
+}
+
+func (b *MemoryBroker) Publish(_ context.Context, topic string, event Event) error {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	for sub := range b.topics[topic] {
+		select {
+		case sub.ch <- event:
+		default:
+		}
+	}
+	return nil
+}
+
+func (b *MemoryBroker) Subscribe(ctx context.Context, topic string) (<-chan Event, func()) {
+	sub := &subscriber{ch: make(chan Event, subscriberBuffer)}
+
+	b.mu.Lock()
+	if b.topics[topic] == nil {
+		b.topics[topic] = make(map[*subscriber]struct{})
+	}
+	b.topics[topic][sub] = struct{}{}
+	b.mu.Unlock()
+
+	cancel := func() {
+		sub.once.Do(func() {
+			b.mu.Lock()
+			delete(b.topics[topic], sub)
+			if len(b.topics[topic]) == 0 {
+				delete(b.topics, topic)
+			}
+			// Closing under the write lock guarantees no Publish is sending on the channel
+			close(sub.ch)
+			b.mu.Unlock()
+		})
+	}
+	context.AfterFunc(ctx, cancel)
+	return sub.ch, cancel
+}
+
+// Subscribers returns the number of subscribers currently listening on topic.
+func (b *MemoryBroker) Subscribers(topic string) int {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return len(b.topics[topic])
+}
@@ -0,0 +1,57 @@
+package pubsub
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+)
+
+func TestMemoryBroker(t *testing.T) {
+	b := NewMemoryBroker()
+	ctx, cancel := context.WithCancel(context.Background())
+
+	ch, _ := b.Subscribe(ctx, "submission:1")
+	other, unsub := b.Subscribe(context.Background(), "submission:2")
+
+	if err := b.Publish(context.Background(), "submission:1", Event{Type: "submission", Data: json.RawMessage(`{"id":1}`)}); err != nil {
+		t.Fatal(err)
+	}
+	ev := <-ch
+	if ev.Type != "submission" || string(ev.Data) != `{"id":1}` {
+		t.Fatalf("Unexpected event %#v", ev)
+	}
+	select {
+	case ev := <-other:
+		t.Fatalf("Event leaked to another topic: %#v", ev)
+	default:
+	}
+
+	cancel()
+	if _, ok := <-ch; ok {
+		t.Fatal("Channel should be closed once the context is done")
+	}
+	if n := b.Subscribers("submission:1"); n != 0 {
+		t.Fatalf("Expected no subscribers left, got %d", n)
+	}
+
+	unsub()
+	unsub()
+	if _, ok := <-other; ok {
+		t.Fatal("Channel should be closed after unsubscribing")
+	}
+}
+
+func TestMemoryBrokerSlowSubscriber(t *testing.T) {
+	b := NewMemoryBroker()
+	ch, unsub := b.Subscribe(context.Background(), "contest:1")
+	defer unsub()
+
+	for range subscriberBuffer + 10 {
+		if err := b.Publish(context.Background(), "contest:1", Event{Type: "leaderboard"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(ch) != subscriberBuffer {
+		t.Fatalf("Expected %d queued events, got %d", subscriberBuffer, len(ch))
+	}
+}
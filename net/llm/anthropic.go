@@ -0,0 +1,128 @@
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/KiloProjects/kilonova/sudoapi/flags"
+)
+
+const (
+	anthropicBaseURL = "https://api.anthropic.com/v1"
+	anthropicVersion = "2023-06-01"
+	// Statements are long, especially transcribed ones, so leave plenty of room for the output
+	anthropicMaxTokens = 16384
+)
+
+// anthropicProvider uses the Anthropic Messages API.
+type anthropicProvider struct {
+	token   string
+	baseURL string
+}
+
+type anthropicContent struct {
+	Type   string                  `json:"type"`
+	Text   string                  `json:"text,omitempty"`
+	Source *anthropicContentSource `json:"source,omitempty"`
+}
+
+type anthropicContentSource struct {
+	Type      string `json:"type"`
+	MediaType string `json:"media_type"`
+	Data      string `json:"data"`
+}
+
+type anthropicMessage struct {
+	Role    string             `json:"role"`
+	Content []anthropicContent `json:"content"`
+}
+
+type anthropicRequest struct {
+	Model     string             `json:"model"`
+	MaxTokens int                `json:"max_tokens"`
+	System    string             `json:"system,omitempty"`
+	Messages  []anthropicMessage `json:"messages"`
+}
+
+type anthropicResponse struct {
+	Content []anthropicContent `json:"content"`
+	Error   *struct {
+		Type    string `json:"type"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+func (p *anthropicProvider) Name() string { return "anthropic" }
+
+func (p *anthropicProvider) DefaultModel(bool) string {
+	// All current models support PDF input
+	return flags.AnthropicDefaultModel.Value()
+}
+
+func (p *anthropicProvider) SupportsPDF() bool { return true }
+
+func (p *anthropicProvider) Generate(ctx context.Context, req *Request) (string, error) {
+	var content []anthropicContent
+	if req.PDF != nil {
+		content = append(content, anthropicContent{
+			Type: "document",
+			Source: &anthropicContentSource{
+				Type:      "base64",
+				MediaType: "application/pdf",
+				Data:      base64.StdEncoding.EncodeToString(req.PDF),
+			},
+		})
+	}
+	content = append(content, anthropicContent{Type: "text", Text: req.Prompt})
+
+	body, err := json.Marshal(anthropicRequest{
+		Model:     req.Model,
+		MaxTokens: anthropicMaxTokens,
+		System:    req.System,
+		Messages:  []anthropicMessage{{Role: "user", Content: content}},
+	})
+	if err != nil {
+		return "", err
+	}
+
+	hreq, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimSuffix(p.baseURL, "/")+"/messages", bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	hreq.Header.Set("Content-Type", "application/json")
+	hreq.Header.Set("X-Api-Key", p.token)
+	hreq.Header.Set("Anthropic-Version", anthropicVersion)
+
+	resp, err := defaultClient.Do(hreq)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	var out anthropicResponse
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return "", fmt.Errorf("couldn't decode Anthropic response (status %d): %w", resp.StatusCode, err)
+	}
+	if out.Error != nil {
+		if resp.StatusCode == http.StatusUnauthorized {
+			return "", ErrUnauthed
+		}
+		return "", fmt.Errorf("anthropic error (%s): %s", out.Error.Type, out.Error.Message)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("unexpected Anthropic response status %d", resp.StatusCode)
+	}
+
+	var sb strings.Builder
+	for _, block := range out.Content {
+		if block.Type == "text" {
+			sb.WriteString(block.Text)
+		}
+	}
+	return sb.String(), nil
+}
@@ -0,0 +1,46 @@
+package llm
+
+import (
+	"context"
+	"sync"
+)
+
+// Fake is a Provider that doesn't call any model, meant for tests.
+// It records the requests it receives and answers them with Response, or fails with Err.
+type Fake struct {
+	Response string
+	Err      error
+
+	mu       sync.Mutex
+	requests []*Request
+}
+
+var _ Provider = &Fake{}
+
+func (f *Fake) Name() string { return "fake" }
+
+func (f *Fake) DefaultModel(vision bool) string {
+	if vision {
+		return "fake-vision"
+	}
+	return "fake"
+}
+
+func (f *Fake) SupportsPDF() bool { return true }
+
+func (f *Fake) Generate(ctx context.Context, req *Request) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.requests = append(f.requests, req)
+	if f.Err != nil {
+		return "", f.Err
+	}
+	return f.Response, nil
+}
+
+// Requests returns the requests received so far
+func (f *Fake) Requests() []*Request {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]*Request(nil), f.requests...)
+}
@@ -0,0 +1,138 @@
+// Package llm implements the LLM-assisted statement tools (translation and PDF transcription)
+// on top of interchangeable model providers.
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/KiloProjects/kilonova/sudoapi/flags"
+
+	_ "embed"
+)
+
+var (
+	ErrUnauthed    = errors.New("unauthenticated to LLM provider")
+	ErrUnsupported = errors.New("LLM provider does not support PDF documents")
+)
+
+//go:embed prompts/translate.md
+var translateRoSystemPrompt string
+
+//go:embed prompts/transcribe.md
+var transcribeStatement string
+
+// Request is a single-turn prompt sent to a model
+type Request struct {
+	Model string
+	// System holds the instructions for the task
+	System string
+	Prompt string
+	// PDF is an optional document attached to the prompt
+	PDF []byte
+}
+
+// Provider is a backend that runs models.
+type Provider interface {
+	Name() string
+	// DefaultModel is the model used when the request doesn't specify one.
+	// vision is set when the request has a document attached.
+	DefaultModel(vision bool) string
+	// SupportsPDF reports whether requests may have a document attached
+	SupportsPDF() bool
+	Generate(ctx context.Context, req *Request) (string, error)
+}
+
+// NewProvider returns the provider with the given name, configured from the instance flags.
+func NewProvider(name string) (Provider, error) {
+	switch name {
+	case "openai":
+		if len(flags.OpenAIToken.Value()) < 2 {
+			return nil, ErrUnauthed
+		}
+		return &openAIProvider{token: flags.OpenAIToken.Value(), baseURL: flags.OpenAIBaseURL.Value()}, nil
+	case "anthropic":
+		if len(flags.AnthropicToken.Value()) < 2 {
+			return nil, ErrUnauthed
+		}
+		return &anthropicProvider{token: flags.AnthropicToken.Value(), baseURL: anthropicBaseURL}, nil
+	case "local":
+		if flags.LocalLLMBaseURL.Value() == "" {
+			return nil, errors.New("local LLM server URL is not configured")
+		}
+		if flags.LocalLLMModel.Value() == "" {
+			return nil, errors.New("local LLM model is not configured")
+		}
+		return &localProvider{token: flags.LocalLLMToken.Value(), baseURL: flags.LocalLLMBaseURL.Value()}, nil
+	default:
+		return nil, fmt.Errorf("unknown LLM provider %q", name)
+	}
+}
+
+// DefaultProvider returns the provider selected by the `integrations.llm.provider` flag.
+func DefaultProvider() (Provider, error) {
+	return NewProvider(flags.LLMProvider.Value())
+}
+
+// Available reports whether the statement tools can be used on this instance.
+func Available() bool {
+	_, err := DefaultProvider()
+	return err == nil
+}
+
+// PDFAvailable reports whether statements can be transcribed from PDFs on this instance.
+func PDFAvailable() bool {
+	p, err := DefaultProvider()
+	return err == nil && p.SupportsPDF()
+}
+
+// prompt returns the contents of the override file, if one is configured, or the built-in prompt otherwise.
+func prompt(path string, builtin string) (string, error) {
+	if path == "" {
+		return builtin, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("couldn't read prompt override: %w", err)
+	}
+	if strings.TrimSpace(string(data)) == "" {
+		return builtin, nil
+	}
+	return string(data), nil
+}
+
+func TranscribeStatement(ctx context.Context, p Provider, pdfFile []byte, model string) (string, error) {
+	system, err := prompt(flags.LLMTranscribePrompt.Value(), transcribeStatement)
+	if err != nil {
+		return "", err
+	}
+	if model == "" {
+		model = p.DefaultModel(true)
+	}
+	return p.Generate(ctx, &Request{
+		Model:  model,
+		System: system,
+		Prompt: "Transcribe this PDF task statement.",
+		PDF:    pdfFile,
+	})
+}
+
+// TranslateStatement translates a statement from Romanian to English.
+// English to Romanian and other language ordered pairs are still a TODO
+func TranslateStatement(ctx context.Context, p Provider, text string, model string) (string, error) {
+	system, err := prompt(flags.LLMTranslatePrompt.Value(), translateRoSystemPrompt)
+	if err != nil {
+		return "", err
+	}
+	if model == "" {
+		model = p.DefaultModel(false)
+	}
+	return p.Generate(ctx, &Request{
+		Model:  model,
+		System: system,
+		Prompt: text,
+	})
+}
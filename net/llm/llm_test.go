@@ -0,0 +1,118 @@
+package llm
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/KiloProjects/kilonova/sudoapi/flags"
+)
+
+func TestTranslateStatement(t *testing.T) {
+	fake := &Fake{Response: "# Statement"}
+	out, err := TranslateStatement(t.Context(), fake, "# Enunț", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out != "# Statement" {
+		t.Fatalf("got output %q", out)
+	}
+	reqs := fake.Requests()
+	if len(reqs) != 1 {
+		t.Fatalf("expected 1 request, got %d", len(reqs))
+	}
+	if req := reqs[0]; req.Model != "fake" || req.System != translateRoSystemPrompt || req.Prompt != "# Enunț" || req.PDF != nil {
+		t.Fatalf("unexpected request %+v", req)
+	}
+
+	// Per-instance prompt override
+	path := filepath.Join(t.TempDir(), "translate.md")
+	if err := os.WriteFile(path, []byte("Translate to English."), 0644); err != nil {
+		t.Fatal(err)
+	}
+	flags.LLMTranslatePrompt.Update(path)
+	defer flags.LLMTranslatePrompt.Update("")
+
+	if _, err := TranslateStatement(t.Context(), fake, "text", "other-model"); err != nil {
+		t.Fatal(err)
+	}
+	if req := fake.Requests()[1]; req.Model != "other-model" || req.System != "Translate to English." {
+		t.Fatalf("prompt override was not used: %+v", req)
+	}
+}
+
+func TestTranscribeStatement(t *testing.T) {
+	fake := &Fake{Response: "transcribed"}
+	pdf := []byte("%PDF-1.7")
+	if _, err := TranscribeStatement(t.Context(), fake, pdf, ""); err != nil {
+		t.Fatal(err)
+	}
+	if req := fake.Requests()[0]; req.Model != "fake-vision" || req.System != transcribeStatement || string(req.PDF) != string(pdf) {
+		t.Fatalf("unexpected request %+v", req)
+	}
+
+	if _, err := (&localProvider{baseURL: "http://localhost:0"}).Generate(t.Context(), &Request{Model: "m", PDF: pdf}); err != ErrUnsupported {
+		t.Fatalf("local provider should not accept PDFs, got %v", err)
+	}
+}
+
+func TestAnthropicProvider(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/messages" || r.Header.Get("X-Api-Key") != "key" || r.Header.Get("Anthropic-Version") == "" {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"type":"error","error":{"type":"authentication_error","message":"invalid x-api-key"}}`))
+			return
+		}
+		var req anthropicRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("invalid request: %v", err)
+		}
+		content := req.Messages[0].Content
+		if req.System != "system" || len(content) != 2 || content[0].Type != "document" || content[1].Text != "prompt" {
+			t.Errorf("unexpected request %+v", req)
+		}
+		w.Write([]byte(`{"content":[{"type":"text","text":"Hello, "},{"type":"text","text":"world"}]}`))
+	}))
+	defer srv.Close()
+
+	p := &anthropicProvider{token: "key", baseURL: srv.URL}
+	out, err := p.Generate(t.Context(), &Request{Model: "model", System: "system", Prompt: "prompt", PDF: []byte("%PDF")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out != "Hello, world" {
+		t.Fatalf("got output %q", out)
+	}
+
+	p.token = "wrong"
+	if _, err := p.Generate(t.Context(), &Request{Model: "model", Prompt: "prompt"}); err != ErrUnauthed {
+		t.Fatalf("expected ErrUnauthed, got %v", err)
+	}
+}
+
+func TestLocalProviderConfig(t *testing.T) {
+	provider := flags.LLMProvider.Value()
+	flags.LLMProvider.Update("local")
+	defer flags.LLMProvider.Update(provider)
+	if Available() {
+		t.Fatal("the local provider shouldn't be available without a server URL")
+	}
+
+	flags.LocalLLMBaseURL.Update("http://localhost:11434/v1")
+	defer flags.LocalLLMBaseURL.Update("")
+	if Available() {
+		t.Fatal("the local provider shouldn't be available without a model")
+	}
+
+	flags.LocalLLMModel.Update("llama3")
+	defer flags.LocalLLMModel.Update("")
+	if !Available() {
+		t.Fatal("the local provider should be available once configured")
+	}
+	if PDFAvailable() {
+		t.Fatal("the local provider can't transcribe PDFs")
+	}
+}
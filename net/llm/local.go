@@ -0,0 +1,61 @@
+package llm
+
+import (
+	"context"
+	"errors"
+
+	"github.com/KiloProjects/kilonova/sudoapi/flags"
+	"github.com/openai/openai-go/v3"
+	"github.com/openai/openai-go/v3/option"
+)
+
+// localProvider talks to a self-hosted server (Ollama, llama.cpp) through the OpenAI-compatible Chat Completions API,
+// since it's the only API surface they have in common.
+type localProvider struct {
+	token   string
+	baseURL string
+}
+
+func (p *localProvider) Name() string { return "local" }
+
+func (p *localProvider) DefaultModel(bool) string {
+	return flags.LocalLLMModel.Value()
+}
+
+// SupportsPDF is false, since the Chat Completions API of the local servers doesn't take documents
+func (p *localProvider) SupportsPDF() bool { return false }
+
+func (p *localProvider) Generate(ctx context.Context, req *Request) (string, error) {
+	if req.PDF != nil {
+		return "", ErrUnsupported
+	}
+	if req.Model == "" {
+		return "", errors.New("no model specified for the local LLM server")
+	}
+	opts := []option.RequestOption{
+		option.WithBaseURL(p.baseURL),
+		option.WithHTTPClient(defaultClient),
+	}
+	// Ollama doesn't check the key, llama.cpp only does when started with --api-key
+	if p.token != "" {
+		opts = append(opts, option.WithAPIKey(p.token))
+	} else {
+		opts = append(opts, option.WithAPIKey("local"))
+	}
+	client := openai.NewClient(opts...)
+
+	resp, err := client.Chat.Completions.New(ctx, openai.ChatCompletionNewParams{
+		Model: req.Model,
+		Messages: []openai.ChatCompletionMessageParamUnion{
+			openai.SystemMessage(req.System),
+			openai.UserMessage(req.Prompt),
+		},
+	})
+	if err != nil {
+		return "", err
+	}
+	if len(resp.Choices) == 0 {
+		return "", errors.New("local LLM server returned no output")
+	}
+	return resp.Choices[0].Message.Content, nil
+}
@@ -1,10 +1,9 @@
 package llm
 
 import (
+	"bytes"
 	"cmp"
 	"context"
-	"errors"
-	"io"
 	"net/http"
 
 	"github.com/KiloProjects/kilonova/sudoapi/flags"
@@ -14,111 +13,90 @@ import (
 	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
 
 	"github.com/openai/openai-go/v3"
-
-	_ "embed"
 )
 
-var (
-	ErrUnauthed = errors.New("unauthenticated to OpenAI endpoint")
-)
+var defaultClient = &http.Client{
+	Transport: otelhttp.NewTransport(http.DefaultTransport),
+}
 
-//go:embed prompts/translate.md
-var translateRoSystemPrompt string
+// openAIProvider uses the OpenAI Responses API. Other compatible endpoints (such as OpenRouter) can be used by changing the base URL.
+type openAIProvider struct {
+	token   string
+	baseURL string
+}
 
-//go:embed prompts/transcribe.md
-var transcribeStatement string
+func (p *openAIProvider) Name() string { return "openai" }
 
-func TranscribeStatement(ctx context.Context, pdfFile io.Reader, model string) (string, error) {
-	if len(flags.OpenAIToken.Value()) < 2 {
-		return "", ErrUnauthed
-	}
-	if model == "" {
-		model = flags.OpenAIVisionModel.Value()
+func (p *openAIProvider) DefaultModel(vision bool) string {
+	if vision {
+		return flags.OpenAIVisionModel.Value()
 	}
-	client := openai.NewClient(
-		option.WithAPIKey(flags.OpenAIToken.Value()),
-		//option.WithBaseURL(flags.OpenAIBaseURL.Value()),
-		option.WithHTTPClient(defaultClient),
-	)
+	return flags.OpenAIDefaultModel.Value()
+}
 
-	fResp, err := client.Files.New(ctx, openai.FileNewParams{
-		File:    openai.File(pdfFile, "statement.pdf", "application/pdf"),
-		Purpose: "user_data",
-	})
-	if err != nil {
-		return "", err
+func (p *openAIProvider) SupportsPDF() bool { return true }
+
+func (p *openAIProvider) client() openai.Client {
+	opts := []option.RequestOption{
+		option.WithAPIKey(p.token),
+		option.WithHTTPClient(defaultClient),
+	}
+	if p.baseURL != "" {
+		// App attribution headers, used by OpenRouter and ignored everywhere else
+		opts = append(opts,
+			option.WithBaseURL(p.baseURL),
+			option.WithHeader("HTTP-Referer", cmp.Or(flags.NavbarBranding.Value(), "Kilonova")),
+			option.WithHeader("X-Title", "Kilonova"),
+		)
 	}
+	return openai.NewClient(opts...)
+}
 
-	resp, err := client.Responses.New(ctx, responses.ResponseNewParams{
-		Model: model,
-		Input: responses.ResponseNewParamsInputUnion{OfInputItemList: responses.ResponseInputParam{
-			{
-				OfMessage: &responses.EasyInputMessageParam{
-					Role: responses.EasyInputMessageRoleDeveloper,
-					Content: responses.EasyInputMessageContentUnionParam{
-						OfString: param.NewOpt(transcribeStatement),
+func (p *openAIProvider) Generate(ctx context.Context, req *Request) (string, error) {
+	client := p.client()
+
+	userContent := responses.EasyInputMessageContentUnionParam{
+		OfString: param.NewOpt(req.Prompt),
+	}
+	if req.PDF != nil {
+		fResp, err := client.Files.New(ctx, openai.FileNewParams{
+			File:    openai.File(bytes.NewReader(req.PDF), "statement.pdf", "application/pdf"),
+			Purpose: "user_data",
+		})
+		if err != nil {
+			return "", err
+		}
+		userContent = responses.EasyInputMessageContentUnionParam{
+			OfInputItemContentList: responses.ResponseInputMessageContentListParam{
+				{
+					OfInputText: &responses.ResponseInputTextParam{
+						Text: req.Prompt,
 					},
 				},
-			},
-			{
-				OfMessage: &responses.EasyInputMessageParam{
-					Role: responses.EasyInputMessageRoleUser,
-					Content: responses.EasyInputMessageContentUnionParam{
-						OfInputItemContentList: responses.ResponseInputMessageContentListParam{
-							{
-								OfInputText: &responses.ResponseInputTextParam{
-									Text: "Transcribe this PDF task statement.",
-								},
-							},
-							{
-								OfInputFile: &responses.ResponseInputFileParam{
-									FileID: param.NewOpt(fResp.ID),
-								},
-							},
-						},
+				{
+					OfInputFile: &responses.ResponseInputFileParam{
+						FileID: param.NewOpt(fResp.ID),
 					},
 				},
 			},
-		}},
-	})
-	if err != nil {
-		return "", err
+		}
 	}
-	return resp.OutputText(), nil
-}
-
-// TranslateStatement translates a statement from Romanian to English.
-// English to Romanian and other language ordered pairs are still a TODO
-func TranslateStatement(ctx context.Context, text string, model string) (string, error) {
-	if len(flags.OpenAIToken.Value()) < 2 {
-		return "", ErrUnauthed
-	}
-	if model == "" {
-		model = flags.OpenAIDefaultModel.Value()
-	}
-	client := openai.NewClient(
-		option.WithAPIKey(flags.OpenAIToken.Value()),
-		//option.WithBaseURL(flags.OpenAIBaseURL.Value()),
-		option.WithHTTPClient(defaultClient),
-	)
 
 	resp, err := client.Responses.New(ctx, responses.ResponseNewParams{
-		Model: model,
+		Model: req.Model,
 		Input: responses.ResponseNewParamsInputUnion{OfInputItemList: responses.ResponseInputParam{
 			{
 				OfMessage: &responses.EasyInputMessageParam{
 					Role: responses.EasyInputMessageRoleDeveloper,
 					Content: responses.EasyInputMessageContentUnionParam{
-						OfString: param.NewOpt(translateRoSystemPrompt),
+						OfString: param.NewOpt(req.System),
 					},
 				},
 			},
 			{
 				OfMessage: &responses.EasyInputMessageParam{
-					Role: responses.EasyInputMessageRoleUser,
-					Content: responses.EasyInputMessageContentUnionParam{
-						OfString: param.NewOpt(text),
-					},
+					Role:    responses.EasyInputMessageRoleUser,
+					Content: userContent,
 				},
 			},
 		}},
@@ -126,21 +104,5 @@ func TranslateStatement(ctx context.Context, text string, model string) (string,
 	if err != nil {
 		return "", err
 	}
-
 	return resp.OutputText(), nil
 }
-
-var defaultClient = &http.Client{
-	Transport: &openRouterHeaderTransport{T: otelhttp.NewTransport(http.DefaultTransport)},
-}
-
-type openRouterHeaderTransport struct {
-	T http.RoundTripper
-}
-
-func (adt *openRouterHeaderTransport) RoundTrip(req *http.Request) (*http.Response, error) {
-	branding := cmp.Or(flags.NavbarBranding.Value(), "Kilonova")
-	req.Header.Add("HTTP-Referer", branding)
-	req.Header.Add("X-Title", "Kilonova")
-	return adt.T.RoundTrip(req)
-}
@@ -27,8 +27,55 @@ const (
 	TaskTypeBatch         TaskType = "batch"
 	TaskTypeCommunication TaskType = "communication"
 	TaskTypeAI            TaskType = "ai"
+	// TaskTypeOutputOnly problems are solved by submitting a zip archive of <test>.out files,
+	// which are checked directly, without running any code.
+	TaskTypeOutputOnly TaskType = "output_only"
+	// TaskTypeTwoStep problems run the contestant's program twice. A manager provided by the problem
+	// transforms the first run's output into the second run's input.
+	TaskTypeTwoStep TaskType = "two_step"
 )
 
+// CheckerProtocol is the way a custom checker receives its files and reports its verdict.
+// It is selected by the suffix of the checker attachment's stem (ie. checker_testlib.cpp).
+type CheckerProtocol string
+
+const (
+	// CheckerProtocolStandard is Kilonova's own format: a 0..1 score on stdout and the message on stderr
+	CheckerProtocolStandard CheckerProtocol = "standard"
+	// CheckerProtocolLegacy is the Romanian Olympiad format: "<0..100 score> <message>" on stdout
+	CheckerProtocolLegacy CheckerProtocol = "legacy"
+	// CheckerProtocolTestlib is upstream testlib, which reports its verdict through the exit code
+	CheckerProtocolTestlib CheckerProtocol = "testlib"
+	// CheckerProtocolCMS is the CMS standard manager output, where the checker must also exit successfully
+	CheckerProtocolCMS CheckerProtocol = "cms"
+)
+
+// CheckerMode is the comparison done by the built-in checker, used when the problem has no custom checker
+type CheckerMode string
+
+const (
+	CheckerModeNone CheckerMode = ""
+	// CheckerModeDiff compares the outputs line by line, ignoring whitespace changes
+	CheckerModeDiff CheckerMode = "diff"
+	// CheckerModeTokens compares the outputs token by token, ignoring all whitespace
+	CheckerModeTokens CheckerMode = "tokens"
+	// CheckerModeFloat compares tokens as floating point numbers, within the problem's checker epsilon
+	CheckerModeFloat CheckerMode = "float"
+	// CheckerModeCaseInsensitive compares tokens ignoring their case
+	CheckerModeCaseInsensitive CheckerMode = "case_insensitive"
+	// CheckerModeUnorderedLines compares the multiset of lines, each normalized to its tokens
+	CheckerModeUnorderedLines CheckerMode = "unordered_lines"
+)
+
+func ValidCheckerMode(mode CheckerMode) bool {
+	switch mode {
+	case CheckerModeDiff, CheckerModeTokens, CheckerModeFloat, CheckerModeCaseInsensitive, CheckerModeUnorderedLines:
+		return true
+	default:
+		return false
+	}
+}
+
 type Problem struct {
 	ID        int       `json:"id"`
 	CreatedAt time.Time `json:"created_at"`
@@ -62,6 +109,10 @@ type Problem struct {
 	// CommunicationProcesses is the number of processes that will be run in parallel for communication tasks
 	CommunicationProcesses int `json:"communication_processes"`
 
+	CheckerMode CheckerMode `json:"checker_mode"`
+	// CheckerEpsilon is the absolute or relative error allowed by the float checker mode
+	CheckerEpsilon float64 `json:"checker_epsilon"`
+
 	ReviewRequestedAt *time.Time `json:"review_requested_at"`
 	ReviewRequestedBy *int       `json:"review_requested_by"`
 }
@@ -83,12 +134,29 @@ type StatementVariant struct {
 	// Private is true if the attachment for this statement variant is private.
 	// it may be private if it's currently being worked on.
 	Private bool `json:"public"`
+	// Provenance tells if the statement is an original, a machine translation or a reviewed translation
+	Provenance StatementProvenance `json:"provenance"`
+	// TranslatedFrom is the attachment name of the statement this variant was translated from, if it's a translation
+	TranslatedFrom string `json:"translated_from,omitempty"`
 
 	LastUpdatedAt time.Time `json:"last_updated_at"`
 
 	AttachmentName string `json:"-"`
 }
 
+type StatementProvenance string
+
+const (
+	StatementOriginal          StatementProvenance = "original"
+	StatementMachineTranslated StatementProvenance = "machine_translated"
+	StatementHumanReviewed     StatementProvenance = "human_reviewed"
+)
+
+// NeedsReview is true for translations nobody has checked yet
+func (sv *StatementVariant) NeedsReview() bool {
+	return sv.Provenance == StatementMachineTranslated
+}
+
 // Used for comparing in templates if the right option is selected.
 func (sv *StatementVariant) Equals(other *StatementVariant) bool {
 	return sv.Language == other.Language && sv.Format == other.Format && sv.Type == other.Type
@@ -184,6 +252,9 @@ type ProblemUpdate struct {
 	TaskType TaskType `json:"task_type"`
 
 	CommunicationProcesses *int `json:"communication_processes"`
+
+	CheckerMode    CheckerMode `json:"checker_mode"`
+	CheckerEpsilon *float64    `json:"checker_epsilon"`
 }
 
 type Attachment struct {
@@ -196,6 +267,12 @@ type Attachment struct {
 	LastUpdatedAt time.Time `json:"last_updated_at"`
 	LastUpdatedBy *int      `json:"last_updated_by"`
 
+	// Provenance, TranslatedFrom, ReviewedBy and ReviewedAt are only relevant for statements
+	Provenance     StatementProvenance `json:"provenance"`
+	TranslatedFrom *string             `json:"translated_from"`
+	ReviewedBy     *int                `json:"reviewed_by"`
+	ReviewedAt     *time.Time          `json:"reviewed_at"`
+
 	Name string `json:"name"`
 	// Data []byte `json:"data,omitempty"`
 	Size int `json:"data_size"`
@@ -222,6 +299,9 @@ type AttachmentUpdate struct {
 	Private *bool   `json:"private"`
 	Exec    *bool   `json:"exec"`
 	Name    *string `json:"name"`
+
+	Provenance     *StatementProvenance `json:"provenance"`
+	TranslatedFrom *string              `json:"translated_from"`
 }
 
 type ProblemEvalSettings struct {
@@ -232,8 +312,14 @@ type ProblemEvalSettings struct {
 	// If the problem has a custom checker, this is non-empty.
 	// If the problem is of type Communication, CheckerName will have "manager" as stem instead of "checker"
 	CheckerName string `json:"has_checker"`
-	// If the problem has a custom checker marked as legacy
-	LegacyChecker bool `json:"legacy_checker"`
+	// The protocol of the custom checker, if there is one
+	CheckerProtocol CheckerProtocol `json:"checker_protocol"`
+	// The built-in checker's comparison, used if there is no custom checker
+	CheckerMode    CheckerMode `json:"checker_mode"`
+	CheckerEpsilon float64     `json:"checker_epsilon"`
+	// If the problem is of type TwoStep, the manager attachment (with "manager" as stem)
+	// which transforms the output of the first run into the input of the second one
+	ManagerName string `json:"manager_name"`
 
 	HasUv bool `json:"has_uv"`
 
@@ -255,6 +341,8 @@ type ProblemChecklist struct {
 	NumOtherTags  int `json:"num_other_tags" db:"num_other_tags"`
 
 	NumSolutions int `json:"num_sols" db:"num_sols"`
+
+	NumUnreviewedTranslations int `json:"num_unreviewed_translations" db:"num_unreviewed_translations"`
 }
 
 type ResourceType string
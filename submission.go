@@ -2,6 +2,7 @@ package kilonova
 
 import (
 	"net/netip"
+	"strings"
 	"time"
 
 	"github.com/shopspring/decimal"
@@ -28,6 +29,60 @@ const (
 	EvalTypeICPC    EvalType = "acm-icpc"
 )
 
+// ExpectedVerdict is the outcome a problem editor expects from a submission, such as the tagged solutions of a Polygon package
+type ExpectedVerdict string
+
+const (
+	ExpectedVerdictNone        ExpectedVerdict = ""
+	ExpectedVerdictAccepted    ExpectedVerdict = "accepted"
+	ExpectedVerdictWrongAnswer ExpectedVerdict = "wrong_answer"
+	ExpectedVerdictTimeLimit   ExpectedVerdict = "time_limit"
+)
+
+// Satisfied reports whether the finished submission, with the given subtests, got the expected verdict.
+func (v ExpectedVerdict) Satisfied(sub *Submission, subtests []*SubTest) bool {
+	if sub == nil || sub.Status != StatusFinished {
+		return false
+	}
+	accepted := sub.Score.Equal(decimal.NewFromInt(100))
+	if sub.SubmissionType == EvalTypeICPC && sub.ICPCVerdict != nil {
+		accepted = *sub.ICPCVerdict == "test_verdict.accepted"
+	}
+	switch v {
+	case ExpectedVerdictAccepted:
+		return accepted
+	case ExpectedVerdictWrongAnswer:
+		if accepted {
+			return false
+		}
+		for _, st := range subtests {
+			if st.wrongAnswer() {
+				return true
+			}
+		}
+		return false
+	case ExpectedVerdictTimeLimit:
+		for _, st := range subtests {
+			if st.Verdict == "translate:timeout" {
+				return true
+			}
+		}
+		return false
+	default:
+		return true
+	}
+}
+
+// wrongAnswer reports whether the checker rejected the output of the subtest.
+// Besides the built-in checkers' verdict, this recognizes the messages of testlib checkers
+func (st *SubTest) wrongAnswer() bool {
+	if !st.Done || st.Skipped {
+		return false
+	}
+	verdict := strings.ToLower(st.Verdict)
+	return verdict == "translate:wrong" || strings.HasPrefix(verdict, "wrong answer") || strings.HasPrefix(verdict, "wrong output format")
+}
+
 type Submission struct {
 	ID        int       `json:"id"`
 	CreatedAt time.Time `json:"created_at"`
@@ -41,6 +96,13 @@ type Submission struct {
 	CompileMessage *string `json:"compile_message,omitempty"`
 
 	ContestID *int `json:"contest_id"`
+	// TeamID is the team credited with the submission in team contests
+	TeamID *int `json:"team_id"`
+
+	// UpsolveContestID is the ended contest the submission was sent to, either while upsolving or during a virtual participation.
+	// Such submissions never count towards the contest itself
+	UpsolveContestID       *int `json:"upsolve_contest_id"`
+	VirtualParticipationID *int `json:"virtual_participation_id"`
 
 	MaxTime   float64 `json:"max_time"`
 	MaxMemory int     `json:"max_memory"`
@@ -56,6 +118,8 @@ type Submission struct {
 
 	SubmissionType EvalType `json:"submission_type"`
 	ICPCVerdict    *string  `json:"icpc_verdict"`
+
+	ExpectedVerdict ExpectedVerdict `json:"expected_verdict,omitempty"`
 }
 
 type SubmissionFile struct {
@@ -90,6 +154,8 @@ type SubmissionUpdate struct {
 
 	ChangeVerdict bool
 	ICPCVerdict   *string
+
+	ExpectedVerdict *ExpectedVerdict
 }
 
 type SubmissionFilter struct {
@@ -99,6 +165,10 @@ type SubmissionFilter struct {
 	ProblemID     *int  `json:"problem_id"`
 	ProblemListID *int  `json:"problem_list_id"`
 	ContestID     *int  `json:"contest_id"`
+	TeamID        *int  `json:"team_id"`
+
+	UpsolveContestID       *int `json:"upsolve_contest_id"`
+	VirtualParticipationID *int `json:"virtual_participation_id"`
 
 	Status Status `json:"status"`
 
@@ -137,6 +207,12 @@ type SubTest struct {
 	TestID       *int            `db:"test_id" json:"test_id"`
 	SubmissionID int             `db:"submission_id" json:"submission_id"`
 
+	// Resource usage reported by the sandbox, besides the CPU time and memory above
+	WallTime     float64 `db:"wall_time" json:"wall_time"`
+	MaxRSS       int     `db:"max_rss" json:"max_rss"`
+	VoluntaryCSW int     `db:"csw_voluntary" json:"csw_voluntary"`
+	ForcedCSW    int     `db:"csw_forced" json:"csw_forced"`
+
 	VisibleID int `db:"visible_id" json:"visible_id"`
 
 	Score decimal.Decimal `json:"score"`
@@ -149,6 +225,11 @@ type SubTestUpdate struct {
 	Verdict    *string
 	Done       *bool
 	Skipped    *bool
+
+	WallTime     *float64
+	MaxRSS       *int
+	VoluntaryCSW *int
+	ForcedCSW    *int
 }
 
 type SubmissionSubTask struct {
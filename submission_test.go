@@ -0,0 +1,36 @@
+package kilonova
+
+import "testing"
+
+func TestExpectedVerdictSatisfied(t *testing.T) {
+	sub := &Submission{Status: StatusFinished, SubmissionType: EvalTypeClassic}
+	subtests := []*SubTest{{Done: true, Verdict: "translate:success"}, {Done: true, Verdict: "translate:timeout"}}
+	if !ExpectedVerdictTimeLimit.Satisfied(sub, subtests) {
+		t.Fatal("a rejected submission with a timeout should satisfy the time limit verdict")
+	}
+	if ExpectedVerdictWrongAnswer.Satisfied(sub, subtests) {
+		t.Fatal("a submission rejected only by timeouts shouldn't satisfy the wrong answer verdict")
+	}
+	if ExpectedVerdictAccepted.Satisfied(sub, subtests) {
+		t.Fatal("a rejected submission can't be accepted")
+	}
+
+	for _, verdict := range []string{"translate:wrong", "wrong answer 1st numbers differ - expected: '3', found: '4'"} {
+		wrong := append(subtests, &SubTest{Done: true, Verdict: verdict})
+		if !ExpectedVerdictWrongAnswer.Satisfied(sub, wrong) {
+			t.Fatalf("a submission with the %q verdict should satisfy the wrong answer verdict", verdict)
+		}
+	}
+	if ExpectedVerdictWrongAnswer.Satisfied(sub, []*SubTest{{Done: true, Skipped: true, Verdict: "translate:wrong"}}) {
+		t.Fatal("skipped subtests shouldn't count as wrong answers")
+	}
+
+	sub.SubmissionType = EvalTypeICPC
+	sub.ICPCVerdict = new("test_verdict.accepted")
+	if !ExpectedVerdictAccepted.Satisfied(sub, nil) {
+		t.Fatal("an accepted ICPC submission should be accepted")
+	}
+	if ExpectedVerdictWrongAnswer.Satisfied(sub, []*SubTest{{Done: true, Verdict: "translate:wrong"}}) {
+		t.Fatal("an accepted ICPC submission can't be a wrong answer")
+	}
+}
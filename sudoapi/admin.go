@@ -492,6 +492,14 @@ func (s *BaseAPI) WakeGrader() {
 	}
 }
 
+// BoxScheduler returns the grader's sandbox scheduler, or nil if the grader isn't running
+func (s *BaseAPI) BoxScheduler() eval.BoxScheduler {
+	if s.grader == nil {
+		return nil
+	}
+	return s.grader.Runner()
+}
+
 func (s *BaseAPI) RegisterGrader(gr Grader) {
 	s.grader = gr
 }
@@ -0,0 +1,103 @@
+package sudoapi
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/KiloProjects/kilonova"
+	"github.com/KiloProjects/kilonova/db"
+	"github.com/KiloProjects/kilonova/internal/auth"
+)
+
+// apiTokenPrefix distinguishes personal access tokens from session IDs, since both are sent through the same headers
+const apiTokenPrefix = "kn_"
+
+type APIToken = db.APIToken
+
+// IsAPIToken reports whether the credential is a personal access token, as opposed to a session ID
+func IsAPIToken(credential string) bool {
+	return strings.HasPrefix(credential, apiTokenPrefix)
+}
+
+func hashAPIToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// CreateAPIToken creates a personal access token for the user and returns it.
+// Only its hash is stored, so the token can't be shown again.
+// The api scope is always granted, while role scopes are dropped if the user doesn't have the role.
+func (s *BaseAPI) CreateAPIToken(ctx context.Context, user *kilonova.UserBrief, name string, scopes []string, expiresAt *time.Time) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || len(name) > 100 {
+		return "", kilonova.Statusf(400, "Token name must have between 1 and 100 characters")
+	}
+	if expiresAt != nil && expiresAt.Before(time.Now()) {
+		return "", kilonova.Statusf(400, "Token expiry must be in the future")
+	}
+	for _, scope := range scopes {
+		if !slices.Contains(auth.Scopes, scope) {
+			return "", kilonova.Statusf(400, "Unknown scope %q", scope)
+		}
+	}
+	scopes = auth.GrantableScopes(scopes, user)
+	if !slices.Contains(scopes, auth.ScopeAPI) {
+		scopes = append([]string{auth.ScopeAPI}, scopes...)
+	}
+
+	token := apiTokenPrefix + kilonova.RandomString(40)
+	if _, err := s.db.CreateAPIToken(ctx, user.ID, name, hashAPIToken(token), scopes, expiresAt); err != nil {
+		slog.WarnContext(ctx, "Couldn't create API token", slog.Any("err", err))
+		return "", fmt.Errorf("failed to create API token: %w", err)
+	}
+	s.LogUserAction(ctx, "Created API token", slog.String("name", name), slog.Any("scopes", scopes))
+	return token, nil
+}
+
+// APITokenUser returns the user a personal access token belongs to, along with the token's scopes.
+// Just like with OAuth tokens, the returned user doesn't have the admin or proposer roles if the token wasn't granted the matching scope.
+func (s *BaseAPI) APITokenUser(ctx context.Context, token string) (*kilonova.UserFull, []string, error) {
+	apiToken, err := s.db.APITokenByHash(ctx, hashAPIToken(token))
+	if err != nil {
+		slog.WarnContext(ctx, "Couldn't get API token", slog.Any("err", err))
+		return nil, nil, fmt.Errorf("failed to get API token: %w", err)
+	}
+	if apiToken == nil {
+		return nil, nil, kilonova.Statusf(401, "Invalid or expired API token")
+	}
+
+	userFull, err := s.UserFull(ctx, apiToken.UserID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	go func() {
+		if err := s.db.MarkAPITokenUsed(context.WithoutCancel(ctx), apiToken.ID); err != nil {
+			slog.WarnContext(ctx, "Couldn't update API token usage", slog.Any("err", err))
+		}
+	}()
+
+	return scopedUser(userFull, apiToken.Scopes), apiToken.Scopes, nil
+}
+
+func (s *BaseAPI) UserAPITokens(ctx context.Context, userID int) ([]*APIToken, error) {
+	tokens, err := s.db.UserAPITokens(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("could not get user API tokens: %w", err)
+	}
+	return tokens, nil
+}
+
+func (s *BaseAPI) RemoveAPIToken(ctx context.Context, userID int, tokenID int) error {
+	if err := s.db.RemoveAPIToken(ctx, userID, tokenID); err != nil {
+		slog.WarnContext(ctx, "Failed to remove API token", slog.Any("err", err))
+		return fmt.Errorf("failed to remove API token: %w", err)
+	}
+	return nil
+}
@@ -104,6 +104,20 @@ func (s *BaseAPI) CreateBlogPostAttachment(ctx context.Context, att *kilonova.At
 }
 
 func (s *BaseAPI) UpdateAttachment(ctx context.Context, aid int, upd *kilonova.AttachmentUpdate) error {
+	// Only publishing a private machine translation is refused, already public ones can keep being saved as such
+	if upd.Private != nil && !*upd.Private {
+		att, err := s.Attachment(ctx, aid)
+		if err != nil {
+			return err
+		}
+		provenance := att.Provenance
+		if upd.Provenance != nil {
+			provenance = *upd.Provenance
+		}
+		if att.Private && provenance == kilonova.StatementMachineTranslated {
+			return Statusf(400, "Machine translations must be reviewed before being made public")
+		}
+	}
 	if err := s.db.UpdateAttachment(ctx, aid, upd); err != nil {
 		return fmt.Errorf("couldn't update attachment: %w", err)
 	}
@@ -225,15 +239,20 @@ func (s *BaseAPI) parseVariants(atts []*kilonova.Attachment, getPrivate bool) []
 		if att.Private && !getPrivate {
 			continue
 		}
-		variants = append(variants, &kilonova.StatementVariant{
-			Language: matches[1],
-			Type:     matches[2],
-			Format:   matches[3],
-			Private:  att.Private,
+		variant := &kilonova.StatementVariant{
+			Language:   matches[1],
+			Type:       matches[2],
+			Format:     matches[3],
+			Private:    att.Private,
+			Provenance: att.Provenance,
 
 			AttachmentName: att.Name,
 			LastUpdatedAt:  att.LastUpdatedAt,
-		})
+		}
+		if att.TranslatedFrom != nil {
+			variant.TranslatedFrom = *att.TranslatedFrom
+		}
+		variants = append(variants, variant)
 	}
 
 	return variants
@@ -249,6 +268,26 @@ func (s *BaseAPI) ProblemDescVariants(ctx context.Context, problemID int, getPri
 	return s.parseVariants(atts, getPrivate), nil
 }
 
+// ReviewStatement marks a machine-translated statement as checked by the reviewer and makes it public.
+func (s *BaseAPI) ReviewStatement(ctx context.Context, problemID int, name string, reviewer *kilonova.UserBrief) error {
+	if !statementRegex.MatchString(name) {
+		return Statusf(400, "Attachment is not a statement")
+	}
+	att, err := s.ProblemAttByName(ctx, problemID, name)
+	if err != nil {
+		return err
+	}
+	if att.Provenance != kilonova.StatementMachineTranslated {
+		return Statusf(400, "Statement is not an unreviewed translation")
+	}
+	if err := s.db.ReviewAttachment(ctx, att.ID, reviewer.ID); err != nil {
+		return fmt.Errorf("couldn't review statement: %w", err)
+	}
+	s.DelAttachmentRenders(att.ID)
+	s.LogUserAction(ctx, "Reviewed statement translation", slog.Int("problem_id", problemID), slog.String("name", name))
+	return nil
+}
+
 func (s *BaseAPI) BlogPostDescVariants(ctx context.Context, problemID int, getPrivate bool) ([]*kilonova.StatementVariant, error) {
 	atts, err := s.BlogPostAttachments(ctx, problemID)
 	if err != nil {
@@ -342,8 +381,28 @@ func (s *BaseAPI) RenderedBlogPostDesc(ctx context.Context, post *kilonova.BlogP
 	}
 }
 
+// checkerProtocol returns the protocol selected by the stem of a checker attachment
+func checkerProtocol(filename string, checkerStem string) (kilonova.CheckerProtocol, bool) {
+	if filename == checkerStem {
+		return kilonova.CheckerProtocolStandard, true
+	}
+	suffix, ok := strings.CutPrefix(filename, checkerStem+"_")
+	if !ok {
+		return "", false
+	}
+	switch protocol := kilonova.CheckerProtocol(suffix); protocol {
+	case kilonova.CheckerProtocolLegacy, kilonova.CheckerProtocolTestlib, kilonova.CheckerProtocolCMS:
+		return protocol, true
+	default:
+		return "", false
+	}
+}
+
 func (s *BaseAPI) ProblemSettings(ctx context.Context, problem *kilonova.Problem) (*kilonova.ProblemEvalSettings, error) {
-	var settings = &kilonova.ProblemEvalSettings{}
+	var settings = &kilonova.ProblemEvalSettings{
+		CheckerMode:    problem.CheckerMode,
+		CheckerEpsilon: problem.CheckerEpsilon,
+	}
 	atts, err := s.ProblemAttachments(ctx, problem.ID)
 	if err != nil {
 		slog.WarnContext(ctx, "Could not get problem settings", slog.Any("err", err))
@@ -363,14 +422,13 @@ func (s *BaseAPI) ProblemSettings(ctx context.Context, problem *kilonova.Problem
 		}
 		filename := path.Base(att.Name)
 		filename = strings.TrimSuffix(filename, path.Ext(filename))
-		if filename == checkerStem+"_legacy" && s.LanguageFromFilename(att.Name) != "" {
+		if protocol, ok := checkerProtocol(filename, checkerStem); ok && s.LanguageFromFilename(att.Name) != "" {
 			settings.CheckerName = att.Name
-			settings.LegacyChecker = true
+			settings.CheckerProtocol = protocol
 			continue
 		}
-		if filename == checkerStem && s.LanguageFromFilename(att.Name) != "" {
-			settings.CheckerName = att.Name
-			settings.LegacyChecker = false
+		if problem.TaskType == kilonova.TaskTypeTwoStep && filename == "manager" && s.LanguageFromFilename(att.Name) != "" {
+			settings.ManagerName = att.Name
 			continue
 		}
 
@@ -435,8 +493,11 @@ func (s *BaseAPI) ProblemSettings(ctx context.Context, problem *kilonova.Problem
 // ProblemLanguages wraps around ProblemSettings to provide a better interface to expose to the API
 // And deduplicate separate code that handles allowed submission languages
 func (s *BaseAPI) ProblemLanguages(ctx context.Context, problem *kilonova.Problem) ([]language.Lang, error) {
-	if problem.TaskType == kilonova.TaskTypeAI {
+	switch problem.TaskType {
+	case kilonova.TaskTypeAI:
 		return []language.Lang{language.AI()}, nil
+	case kilonova.TaskTypeOutputOnly:
+		return []language.Lang{language.OutputOnly()}, nil
 	}
 
 	settings, err := s.ProblemSettings(ctx, problem)
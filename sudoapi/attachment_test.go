@@ -0,0 +1,100 @@
+package sudoapi
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/KiloProjects/kilonova"
+)
+
+func newTestStatement(t *testing.T, base *BaseAPI, problemID int, name string, private bool, provenance kilonova.StatementProvenance) *kilonova.Attachment {
+	t.Helper()
+	att := &kilonova.Attachment{Name: name, Private: private, Provenance: provenance}
+	if err := base.CreateProblemAttachment(t.Context(), att, problemID, strings.NewReader("# Statement"), nil); err != nil {
+		t.Fatal(err)
+	}
+	att, err := base.ProblemAttByName(t.Context(), problemID, name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return att
+}
+
+func TestUpdateAttachmentMachineTranslation(t *testing.T) {
+	base := newTestAPI(t)
+	ctx := t.Context()
+	author := newTestUser(t, base, "author")
+	pb, err := base.CreateProblem(ctx, "Problem", author.Brief(), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	private := newTestStatement(t, base, pb.ID, "statement-en-llm.md", true, kilonova.StatementMachineTranslated)
+	if err := base.UpdateAttachment(ctx, private.ID, &kilonova.AttachmentUpdate{Private: new(false)}); err == nil {
+		t.Error("Expected publishing an unreviewed machine translation to fail")
+	}
+	if err := base.UpdateAttachment(ctx, private.ID, &kilonova.AttachmentUpdate{Private: new(false), Provenance: new(kilonova.StatementMachineTranslated)}); err == nil {
+		t.Error("Expected publishing while keeping the machine translated provenance to fail")
+	}
+	if err := base.UpdateAttachment(ctx, private.ID, &kilonova.AttachmentUpdate{Visible: new(true)}); err != nil {
+		t.Errorf("Expected updates that keep the translation private to work, got %v", err)
+	}
+
+	// Machine translations that were already public (such as those marked by migrations) can still be saved
+	public := newTestStatement(t, base, pb.ID, "statement-fr-llm.md", false, kilonova.StatementMachineTranslated)
+	if err := base.UpdateAttachment(ctx, public.ID, &kilonova.AttachmentUpdate{Private: new(false), Visible: new(true)}); err != nil {
+		t.Errorf("Expected saving a public machine translation to work, got %v", err)
+	}
+
+	original := newTestStatement(t, base, pb.ID, "statement-ro.md", true, kilonova.StatementOriginal)
+	if err := base.UpdateAttachment(ctx, original.ID, &kilonova.AttachmentUpdate{Private: new(false)}); err != nil {
+		t.Errorf("Expected publishing an original statement to work, got %v", err)
+	}
+}
+
+func TestReviewStatement(t *testing.T) {
+	base := newTestAPI(t)
+	ctx := t.Context()
+	author := newTestUser(t, base, "author")
+	reviewer := newTestUser(t, base, "reviewer")
+	pb, err := base.CreateProblem(ctx, "Problem", author.Brief(), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	newTestStatement(t, base, pb.ID, "checker.cpp", false, "")
+	if err := base.ReviewStatement(ctx, pb.ID, "checker.cpp", reviewer.Brief()); err == nil {
+		t.Error("Expected reviewing a non-statement to fail")
+	}
+	newTestStatement(t, base, pb.ID, "statement-ro.md", false, kilonova.StatementOriginal)
+	if err := base.ReviewStatement(ctx, pb.ID, "statement-ro.md", reviewer.Brief()); err == nil {
+		t.Error("Expected reviewing an original statement to fail")
+	}
+
+	att := newTestStatement(t, base, pb.ID, "statement-en-llm.md", true, kilonova.StatementMachineTranslated)
+	if err := base.ReviewStatement(ctx, pb.ID, att.Name, reviewer.Brief()); err != nil {
+		t.Fatal(err)
+	}
+	att, err = base.Attachment(ctx, att.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if att.Provenance != kilonova.StatementHumanReviewed || att.Private || att.ReviewedBy == nil || *att.ReviewedBy != reviewer.ID || att.ReviewedAt == nil {
+		t.Errorf("Expected a public statement reviewed by %d, got %+v", reviewer.ID, att)
+	}
+	if err := base.ReviewStatement(ctx, pb.ID, att.Name, reviewer.Brief()); err == nil {
+		t.Error("Expected reviewing a statement twice to fail")
+	}
+
+	// Changing the provenance invalidates the review
+	if err := base.UpdateAttachment(ctx, att.ID, &kilonova.AttachmentUpdate{Provenance: new(kilonova.StatementMachineTranslated), Private: new(true)}); err != nil {
+		t.Fatal(err)
+	}
+	att, err = base.Attachment(ctx, att.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if att.ReviewedBy != nil || att.ReviewedAt != nil {
+		t.Errorf("Expected the review to be cleared, got reviewer %v at %v", att.ReviewedBy, att.ReviewedAt)
+	}
+}
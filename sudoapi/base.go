@@ -16,6 +16,7 @@ import (
 	"github.com/KiloProjects/kilonova/eval"
 	"github.com/KiloProjects/kilonova/infra/postgres"
 	"github.com/KiloProjects/kilonova/internal/auth"
+	"github.com/KiloProjects/kilonova/internal/pubsub"
 	"github.com/KiloProjects/kilonova/net/email"
 	"github.com/KiloProjects/kilonova/sudoapi/flags"
 	"github.com/KiloProjects/kilonova/sudoapi/mdrenderer"
@@ -37,6 +38,8 @@ type Submissions struct {
 
 type Grader interface {
 	Wake()
+	// Runner returns the sandbox scheduler used by the grader
+	Runner() eval.BoxScheduler
 }
 
 type BaseAPI struct {
@@ -66,6 +69,8 @@ type BaseAPI struct {
 	avatarBucket          datastore.Bucket
 
 	oidcProvider *op.Provider
+
+	broker pubsub.Broker
 }
 
 func (s *BaseAPI) Start(ctx context.Context) {
@@ -76,6 +81,7 @@ func (s *BaseAPI) Start(ctx context.Context) {
 	go s.cleanupBucketsJob(ctx, 30*time.Minute)
 	go s.refreshProblemStatsJob(ctx, 5*time.Minute)
 	go s.refreshHotProblemsJob(ctx, 4*time.Hour)
+	go s.contestWebhooksJob(ctx, 30*time.Second)
 }
 
 func (s *BaseAPI) Close() error {
@@ -113,6 +119,8 @@ func GetBaseAPI(ctx context.Context, pgx *postgres.DB, mgr *datastore.Manager, m
 		attachmentCacheBucket: mgr.Attachments(),
 		subtestBucket:         mgr.Subtests(),
 		avatarBucket:          mgr.Avatars(),
+
+		broker: pubsub.NewMemoryBroker(),
 	}
 	sUserCache, err := theine.NewBuilder[string, *kilonova.UserFull](500).BuildWithLoader(func(ctx context.Context, sid string) (theine.Loaded[*kilonova.UserFull], error) {
 		user, err := base.sessionUser(ctx, sid)
@@ -0,0 +1,61 @@
+package sudoapi
+
+import (
+	"testing"
+	"time"
+
+	"github.com/KiloProjects/kilonova"
+	"github.com/KiloProjects/kilonova/sudoapi/sudoapitest"
+)
+
+// newTestAPI returns a BaseAPI backed by a fresh database and an in-memory data store.
+// The test is skipped if no test database server is configured.
+func newTestAPI(t *testing.T) *BaseAPI {
+	t.Helper()
+	pgx, mgr := sudoapitest.Deps(t)
+	base, err := GetBaseAPI(t.Context(), pgx, mgr, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return base
+}
+
+func newTestUser(t *testing.T, base *BaseAPI, name string) *kilonova.UserFull {
+	t.Helper()
+	user, err := base.GenerateUser(t.Context(), name, "password123", "en", kilonova.PreferredThemeNone, nil, nil, "")
+	if err != nil {
+		t.Fatalf("Couldn't create user %s: %v", name, err)
+	}
+	return user
+}
+
+// newTestContest creates a running individual contest with a single problem. The first user created is an admin.
+func newTestContest(t *testing.T, base *BaseAPI) (*kilonova.Contest, *kilonova.Problem, *kilonova.UserFull) {
+	t.Helper()
+	ctx := t.Context()
+	admin := newTestUser(t, base, "admin")
+
+	id, err := base.CreateContest(ctx, "Contest", kilonova.ContestTypeOfficial, admin.Brief())
+	if err != nil {
+		t.Fatal(err)
+	}
+	pb, err := base.CreateProblem(ctx, "Problem", admin.Brief(), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := base.UpdateContestProblems(ctx, id, []int{pb.ID}); err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	if err := base.UpdateContest(ctx, id, kilonova.ContestUpdate{
+		StartTime: new(now.Add(-time.Hour)),
+		EndTime:   new(now.Add(time.Hour)),
+	}); err != nil {
+		t.Fatal(err)
+	}
+	contest, err := base.Contest(ctx, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return contest, pb, admin
+}
@@ -4,13 +4,10 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"iter"
 	"log/slog"
 	"time"
 
 	"github.com/KiloProjects/kilonova"
-	"github.com/KiloProjects/kilonova/eval/language"
-	"github.com/KiloProjects/kilonova/net/moss"
 	"github.com/KiloProjects/kilonova/sudoapi/flags"
 	"github.com/KiloProjects/kilonova/util/slicealg"
 )
@@ -88,6 +85,9 @@ func (s *BaseAPI) CloneContest(ctx context.Context, contest *kilonova.Contest, n
 		SubmissionCooldown:        new(int(contest.SubmissionCooldown / time.Millisecond)),
 		QuestionCooldown:          new(int(contest.QuestionCooldown / time.Millisecond)),
 		PerUserTime:               new(contest.PerUserTime),
+		MaxTeamSize:               new(contest.MaxTeamSize),
+		Upsolving:                 new(contest.Upsolving),
+		VirtualParticipation:      new(contest.VirtualParticipation),
 	}
 
 	if author != nil && author.IsAdmin() {
@@ -225,23 +225,35 @@ func (s *BaseAPI) ProblemRunningContests(ctx context.Context, problemID int) ([]
 	})
 }
 
-func (s *BaseAPI) ContestLeaderboard(ctx context.Context, contest *kilonova.Contest, freezeTime *time.Time, generated *bool) (*kilonova.ContestLeaderboard, error) {
+func (s *BaseAPI) ContestLeaderboard(ctx context.Context, contest *kilonova.Contest, freezeTime *time.Time, filter kilonova.LeaderboardFilter) (*kilonova.ContestLeaderboard, error) {
+	var leaderboard *kilonova.ContestLeaderboard
+	var err error
 	switch contest.LeaderboardStyle {
 	case kilonova.LeaderboardTypeClassic:
-		leaderboard, err := s.db.ContestClassicLeaderboard(ctx, contest, freezeTime, generated)
-		if err != nil {
-			return nil, fmt.Errorf("couldn't generate leaderboard: %w", err)
-		}
-		return leaderboard, nil
+		leaderboard, err = s.db.ContestClassicLeaderboard(ctx, contest, freezeTime, filter)
 	case kilonova.LeaderboardTypeICPC:
-		leaderboard, err := s.db.ContestICPCLeaderboard(ctx, contest, freezeTime, generated)
-		if err != nil {
-			return nil, fmt.Errorf("couldn't generate leaderboard: %w", err)
-		}
-		return leaderboard, nil
+		leaderboard, err = s.db.ContestICPCLeaderboard(ctx, contest, freezeTime, filter)
 	default:
 		return nil, Statusf(400, "Invalid contest leaderboard type")
 	}
+	if err != nil {
+		return nil, fmt.Errorf("couldn't generate leaderboard: %w", err)
+	}
+
+	leaderboard.Categories, err = s.ContestCategories(ctx, contest.ID)
+	if err != nil {
+		return nil, err
+	}
+	// Ranks are computed after filtering, so that each category gets its own ranking
+	leaderboard.RankEntries(filter.CategoryID != nil)
+
+	if contest.Upsolving && contest.Ended() {
+		if err := s.addUpsolveScores(ctx, contest, leaderboard); err != nil {
+			return nil, err
+		}
+		leaderboard.Upsolving = true
+	}
+	return leaderboard, nil
 }
 
 func (s *BaseAPI) CanJoinContest(c *kilonova.Contest) bool {
@@ -356,96 +368,6 @@ func (s *BaseAPI) StripContestAccess(ctx context.Context, pbid int, uid int) err
 	return nil
 }
 
-func (s *BaseAPI) RunMOSS(ctx context.Context, contest *kilonova.Contest) error {
-	pbs, err := s.Problems(ctx, kilonova.ProblemFilter{ContestID: &contest.ID})
-	if err != nil {
-		return err
-	}
-
-	for _, pb := range pbs {
-		subs, err := s.RawSubmissions(ctx, kilonova.SubmissionFilter{
-			ProblemID: &pb.ID,
-			ContestID: &contest.ID,
-
-			Ordering:  "score",
-			Ascending: false,
-		})
-		if err != nil {
-			return err
-		}
-		if len(subs) == 0 {
-			continue
-		}
-		users := make(map[int]bool)
-		mossSubs := make(map[string][]*kilonova.Submission)
-		for _, sub := range subs {
-			if _, ok := users[sub.UserID]; ok {
-				continue
-			}
-			users[sub.UserID] = true
-
-			name := s.AnyLanguage(sub.Language).MOSSName()
-			// TODO: See if this can be simplified?
-			_, ok := mossSubs[name]
-			if !ok {
-				mossSubs[name] = []*kilonova.Submission{sub}
-			} else {
-				mossSubs[name] = append(mossSubs[name], sub)
-			}
-		}
-
-		for mossLang, subs := range mossSubs {
-			lang := s.LanguageFromMOSS(ctx, mossLang)
-
-			slog.InfoContext(ctx, "Running MOSS", slog.Any("problem", pb), slog.Any("lang", lang), slog.Int("sub_count", len(subs)))
-			mossID, err := s.db.InsertMossSubmission(ctx, contest.ID, pb.ID, lang.InternalName(), len(subs))
-			if err != nil {
-				return fmt.Errorf("could not add MOSS stub to DB: %w", err)
-			}
-
-			go func(mossID int, lang language.Lang, mossLang string, subs []*kilonova.Submission) {
-				conn, err := moss.New(ctx)
-				if err != nil {
-					slog.WarnContext(ctx, "Could not initialize MOSS", slog.Any("err", err))
-				}
-				defer conn.Close()
-
-				url, err := conn.Process(&moss.Options{
-					LanguageName: mossLang,
-					Comment:      fmt.Sprintf("%s - %s (%s)", contest.Name, pb.Name, lang.PrintableName()),
-
-					Files: iter.Seq[*moss.File](func(yield func(*moss.File) bool) {
-						for _, sub := range subs {
-							user, err := s.UserBrief(ctx, sub.UserID)
-							if err != nil {
-								slog.WarnContext(ctx, "Could not get user", slog.Any("err", err))
-								return
-							}
-
-							code, err := s.RawSubmissionCode(ctx, sub.ID)
-							if err != nil {
-								slog.WarnContext(ctx, "Could not get submission code", slog.Any("err", err))
-								return
-							}
-
-							if !yield(moss.NewFile(mossLang, user.Name, code)) {
-								return
-							}
-						}
-					}),
-				})
-				if err != nil {
-					slog.WarnContext(ctx, "Could not get MOSS result", slog.Any("err", err))
-				}
-				if err := s.db.SetMossURL(ctx, mossID, url); err != nil {
-					slog.WarnContext(ctx, "Could not set MOSS results URL", slog.Any("err", err))
-				}
-			}(mossID, lang, mossLang, subs)
-		}
-	}
-	return nil
-}
-
 func (s *BaseAPI) MOSSSubmissions(ctx context.Context, contestID int) ([]*kilonova.MOSSSubmission, error) {
 	subs, err := s.db.MossSubmissions(ctx, contestID)
 	if err != nil {
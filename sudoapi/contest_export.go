@@ -0,0 +1,280 @@
+package sudoapi
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/KiloProjects/kilonova"
+	"github.com/KiloProjects/kilonova/internal/clics"
+	"github.com/shopspring/decimal"
+)
+
+// ContestPackage exports the contest, its leaderboard and its submission history in the CLICS Contest API format.
+// Submissions made after freezeTime are exported without their judgements, just like on the frozen leaderboard.
+func (s *BaseAPI) ContestPackage(ctx context.Context, contest *kilonova.Contest, freezeTime *time.Time) (*clics.Package, error) {
+	ld, err := s.ContestLeaderboard(ctx, contest, freezeTime, kilonova.LeaderboardFilter{})
+	if err != nil {
+		return nil, err
+	}
+
+	subs, err := s.RawSubmissions(ctx, kilonova.SubmissionFilter{
+		ContestID: &contest.ID,
+		Ordering:  "id",
+		Ascending: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	contestTime := func(t time.Time) string {
+		return clics.RelTime(t.Sub(contest.StartTime))
+	}
+
+	pkg := &clics.Package{
+		Contest: &clics.Contest{
+			ID:             strconv.Itoa(contest.ID),
+			Name:           contest.Name,
+			FormalName:     contest.Name,
+			StartTime:      clics.AbsTime(contest.StartTime),
+			Duration:       clics.RelTime(contest.EndTime.Sub(contest.StartTime)),
+			ScoreboardType: clics.ScoreboardScore,
+			PenaltyTime:    clics.RelTime(time.Duration(contest.ICPCSubmissionPenalty) * time.Minute),
+		},
+		State:          &clics.State{},
+		JudgementTypes: clics.JudgementTypes(),
+		Languages:      []*clics.Language{},
+		Problems:       make([]*clics.Problem, 0, len(ld.ProblemOrder)),
+		Groups:         make([]*clics.Group, 0, len(ld.Categories)),
+		Teams:          make([]*clics.Team, 0, len(ld.Entries)),
+		Submissions:    make([]*clics.Submission, 0, len(subs)),
+		Judgements:     make([]*clics.Judgement, 0, len(subs)),
+	}
+	if contest.LeaderboardStyle == kilonova.LeaderboardTypeICPC {
+		pkg.Contest.ScoreboardType = clics.ScoreboardPassFail
+	}
+	if contest.LeaderboardFreeze != nil {
+		pkg.Contest.ScoreboardFreezeDuration = new(clics.RelTime(contest.EndTime.Sub(*contest.LeaderboardFreeze)))
+		if now.After(*contest.LeaderboardFreeze) {
+			pkg.State.Frozen = new(clics.AbsTime(*contest.LeaderboardFreeze))
+		}
+	}
+	if contest.Started() {
+		pkg.State.Started = new(clics.AbsTime(contest.StartTime))
+	}
+	if contest.Ended() {
+		pkg.State.Ended = new(clics.AbsTime(contest.EndTime))
+		pkg.State.Finalized = pkg.State.Ended
+		pkg.State.EndOfUpdates = pkg.State.Ended
+	}
+
+	for i, pbID := range ld.ProblemOrder {
+		pkg.Problems = append(pkg.Problems, &clics.Problem{
+			ID:      strconv.Itoa(pbID),
+			Label:   problemLabel(i),
+			Name:    ld.ProblemNames[pbID],
+			Ordinal: i,
+		})
+	}
+
+	for _, category := range ld.Categories {
+		pkg.Groups = append(pkg.Groups, &clics.Group{
+			ID:     strconv.Itoa(category.ID),
+			Name:   category.Name,
+			Hidden: !category.Official,
+		})
+	}
+
+	// Team contests are exported with their teams, individual contests with one team per contestant
+	userTeams := make(map[int]string)
+	for _, entry := range ld.Entries {
+		team := &clics.Team{
+			ID:          strconv.Itoa(entry.User.ID),
+			Name:        entry.User.Name,
+			DisplayName: entry.User.Name,
+			GroupIDs:    []string{},
+		}
+		if entry.User.DisplayName != "" {
+			team.DisplayName = entry.User.DisplayName
+		}
+		userTeams[entry.User.ID] = team.ID
+		if entry.Team != nil {
+			team.ID = strconv.Itoa(entry.Team.ID)
+			team.Name, team.DisplayName = entry.Team.Name, entry.Team.Name
+			for _, member := range entry.Members {
+				userTeams[member.ID] = team.ID
+			}
+		}
+		if entry.CategoryID != nil {
+			team.GroupIDs = append(team.GroupIDs, strconv.Itoa(*entry.CategoryID))
+		}
+		pkg.Teams = append(pkg.Teams, team)
+	}
+
+	type problemStats struct {
+		judged, pending int
+		solved          bool
+	}
+	stats := make(map[string]map[string]*problemStats)
+	languages := make(map[string]bool)
+	for _, sub := range subs {
+		teamID, ok := userTeams[sub.UserID]
+		if !ok || sub.CreatedAt.Before(contest.StartTime) || sub.CreatedAt.After(contest.EndTime) {
+			// Editors, testers and out of contest submissions are not exported
+			continue
+		}
+		subID, pbID := strconv.Itoa(sub.ID), strconv.Itoa(sub.ProblemID)
+
+		if !languages[sub.Language] {
+			languages[sub.Language] = true
+			lang := &clics.Language{ID: sub.Language, Name: sub.Language}
+			if l := s.AnyLanguage(sub.Language); l != nil {
+				lang.Name = l.PrintableName()
+			}
+			pkg.Languages = append(pkg.Languages, lang)
+		}
+
+		pkg.Submissions = append(pkg.Submissions, &clics.Submission{
+			ID:          subID,
+			LanguageID:  sub.Language,
+			ProblemID:   pbID,
+			TeamID:      teamID,
+			Time:        clics.AbsTime(sub.CreatedAt),
+			ContestTime: contestTime(sub.CreatedAt),
+			Files:       []*clics.File{},
+		})
+
+		if _, ok := stats[teamID]; !ok {
+			stats[teamID] = make(map[string]*problemStats)
+		}
+		pbStats, ok := stats[teamID][pbID]
+		if !ok {
+			pbStats = &problemStats{}
+			stats[teamID][pbID] = pbStats
+		}
+
+		frozen := freezeTime != nil && !sub.CreatedAt.Before(*freezeTime)
+		if frozen || (sub.Status != kilonova.StatusFinished && sub.Status != kilonova.StatusReevaling) {
+			if !pbStats.solved {
+				pbStats.pending++
+			}
+			continue
+		}
+
+		judgementType := submissionJudgementType(sub)
+		judgement := &clics.Judgement{
+			ID:               subID,
+			SubmissionID:     subID,
+			JudgementTypeID:  &judgementType,
+			StartTime:        clics.AbsTime(sub.CreatedAt),
+			StartContestTime: contestTime(sub.CreatedAt),
+			EndTime:          new(clics.AbsTime(sub.CreatedAt)),
+			EndContestTime:   new(contestTime(sub.CreatedAt)),
+		}
+		if contest.LeaderboardStyle == kilonova.LeaderboardTypeClassic {
+			judgement.Score = new(sub.Score.Mul(sub.ScoreScale).Div(decimal.NewFromInt(100)).InexactFloat64())
+		}
+		pkg.Judgements = append(pkg.Judgements, judgement)
+		if !pbStats.solved {
+			pbStats.judged++
+			pbStats.solved = judgementType == clics.JudgementAccepted
+		}
+	}
+
+	pkg.Scoreboard = &clics.Scoreboard{
+		Time:        clics.AbsTime(now),
+		ContestTime: contestTime(now),
+		State:       pkg.State,
+		Rows:        make([]*clics.ScoreboardRow, 0, len(ld.Entries)),
+	}
+	if contest.Ended() {
+		pkg.Scoreboard.ContestTime = contestTime(contest.EndTime)
+	}
+	for i, entry := range ld.Entries {
+		if entry.Rank == nil {
+			// Unofficial contestants are not ranked
+			continue
+		}
+		teamID := pkg.Teams[i].ID
+		row := &clics.ScoreboardRow{
+			Rank:     *entry.Rank,
+			TeamID:   teamID,
+			Problems: []*clics.ScoreboardProblem{},
+		}
+		for _, pbID := range ld.ProblemOrder {
+			score, hasScore := entry.ProblemScores[pbID]
+			if hasScore && score.Equal(decimal.NewFromInt(-1)) {
+				hasScore = false
+			}
+			pb := &clics.ScoreboardProblem{ProblemID: strconv.Itoa(pbID)}
+			if pbStats, ok := stats[teamID][pb.ProblemID]; ok {
+				pb.NumJudged, pb.NumPending = pbStats.judged, pbStats.pending
+			}
+			if !hasScore && pb.NumJudged+pb.NumPending == 0 {
+				continue
+			}
+			pb.Solved = hasScore && score.Equal(decimal.NewFromInt(100))
+			if pb.Solved {
+				row.Score.NumSolved++
+			}
+			if contest.LeaderboardStyle == kilonova.LeaderboardTypeICPC {
+				if t, ok := entry.ProblemTimes[pbID]; ok && pb.Solved {
+					pb.Time = new(clics.RelTime(time.Duration(t * float64(time.Minute))))
+				}
+			} else if hasScore {
+				pb.Score = new(score.InexactFloat64())
+			}
+			row.Problems = append(row.Problems, pb)
+		}
+		if contest.LeaderboardStyle == kilonova.LeaderboardTypeICPC {
+			row.Score.NumSolved = entry.NumSolved
+			row.Score.TotalTime = new(clics.RelTime(time.Duration(entry.Penalty) * time.Minute))
+		} else {
+			row.Score.Score = new(entry.TotalScore.InexactFloat64())
+		}
+		pkg.Scoreboard.Rows = append(pkg.Scoreboard.Rows, row)
+	}
+
+	return pkg, nil
+}
+
+// problemLabel returns A, B, ..., Z, AA, AB, ... for the i-th problem
+func problemLabel(i int) string {
+	label := ""
+	for i++; i > 0; i = (i - 1) / 26 {
+		label = string(rune('A'+(i-1)%26)) + label
+	}
+	return label
+}
+
+// submissionJudgementType maps the grader's verdict onto a CLICS judgement type.
+func submissionJudgementType(sub *kilonova.Submission) string {
+	if sub.CompileError != nil && *sub.CompileError {
+		return clics.JudgementCompileError
+	}
+	if sub.Score.Equal(decimal.NewFromInt(100)) {
+		return clics.JudgementAccepted
+	}
+	if sub.ICPCVerdict == nil {
+		return clics.JudgementWrongAnswer
+	}
+	verdict := *sub.ICPCVerdict
+	switch {
+	case strings.Contains(verdict, "test_verdict.timeout"), strings.Contains(verdict, "test_verdict.walltimeout"), strings.Contains(verdict, "test_verdict.idleness"):
+		return clics.JudgementTimeLimit
+	case strings.Contains(verdict, "test_verdict.memory_limit"):
+		return clics.JudgementMemoryLimit
+	case strings.Contains(verdict, "test_verdict.runtime_error"):
+		return clics.JudgementRuntimeError
+	case strings.Contains(verdict, "test_verdict.output_too_large"):
+		return clics.JudgementOutputLimit
+	case strings.Contains(verdict, "test_verdict.internal_error"):
+		return clics.JudgementJudgingError
+	case strings.Contains(verdict, "test_verdict.compile_error"):
+		return clics.JudgementCompileError
+	default:
+		return clics.JudgementWrongAnswer
+	}
+}
@@ -27,6 +27,9 @@ func (s *BaseAPI) CreateContestQuestion(ctx context.Context, contest *kilonova.C
 	if err != nil {
 		return -1, fmt.Errorf("couldn't ask question: %w", err)
 	}
+	if question, err := s.db.ContestQuestion(ctx, id); err == nil && question != nil {
+		s.dispatchWebhookEvent(ctx, WebhookQuestionAsked, nil, &question.ContestID, question)
+	}
 	return id, nil
 }
 
@@ -35,6 +38,10 @@ func (s *BaseAPI) CreateContestAnnouncement(ctx context.Context, contestID int,
 	if err != nil {
 		return -1, fmt.Errorf("couldn't create announcement: %w", err)
 	}
+	if announcement, err := s.db.ContestAnnouncement(ctx, id); err == nil && announcement != nil {
+		s.dispatchWebhookEvent(ctx, WebhookAnnouncementCreated, nil, &announcement.ContestID, announcement)
+		s.publishLive(ctx, ContestTopic(announcement.ContestID), LiveAnnouncement, announcement)
+	}
 	return id, nil
 }
 
@@ -88,6 +95,9 @@ func (s *BaseAPI) AnswerContestQuestion(ctx context.Context, id int, text string
 	if err := s.db.AnswerContestQuestion(ctx, id, text); err != nil {
 		return fmt.Errorf("couldn't answer question: %w", err)
 	}
+	if question, err := s.db.ContestQuestion(ctx, id); err == nil && question != nil {
+		s.dispatchWebhookEvent(ctx, WebhookQuestionAnswered, []int{question.AuthorID}, &question.ContestID, question)
+	}
 	return nil
 }
 
@@ -4,12 +4,16 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/KiloProjects/kilonova"
+	"github.com/KiloProjects/kilonova/db"
 )
 
-func (s *BaseAPI) RegisterContestUser(ctx context.Context, contest *kilonova.Contest, userID int, invitationID *string, force bool) error {
+// RegisterContestUser registers the user in the contest, optionally in a registration category.
+// Unless force is set, only self-assignable categories may be picked.
+func (s *BaseAPI) RegisterContestUser(ctx context.Context, contest *kilonova.Contest, userID int, invitationID *string, categoryID *int, force bool) error {
 	_, err := s.ContestRegistration(ctx, contest.ID, userID)
 	if err != nil && !errors.Is(err, ErrNotFound) {
 		return fmt.Errorf("user already registered: %w", err)
@@ -19,7 +23,24 @@ func (s *BaseAPI) RegisterContestUser(ctx context.Context, contest *kilonova.Con
 		return Statusf(400, "Regular joining is disallowed")
 	}
 
-	if err := s.db.InsertContestRegistration(ctx, contest.ID, userID, invitationID); err != nil {
+	if contest.TeamContest() && !force {
+		return Statusf(400, "This is a team contest, register with a team instead")
+	}
+
+	if categoryID != nil {
+		category, err := s.ContestCategory(ctx, contest.ID, *categoryID)
+		if err != nil {
+			return err
+		}
+		if !(force || category.SelfAssignable) {
+			return Statusf(400, "This registration category can't be picked by contestants")
+		}
+	}
+
+	if err := s.db.InsertContestRegistration(ctx, contest.ID, userID, invitationID, categoryID); err != nil {
+		if errors.Is(err, db.ErrInvitationLimit) {
+			return err
+		}
 		return fmt.Errorf("couldn't register user for contest: %w", err)
 	}
 	return nil
@@ -32,7 +53,7 @@ func (s *BaseAPI) StartContestRegistration(ctx context.Context, contest *kilonov
 
 	reg, err := s.ContestRegistration(ctx, contest.ID, userID)
 	if err != nil && !errors.Is(err, ErrNotFound) {
-		if err := s.RegisterContestUser(ctx, contest, userID, nil, false); err != nil {
+		if err := s.RegisterContestUser(ctx, contest, userID, nil, nil, false); err != nil {
 			return err
 		}
 	}
@@ -87,12 +108,17 @@ func (s *BaseAPI) KickUserFromContest(ctx context.Context, contestID, userID int
 	return nil
 }
 
-func (s *BaseAPI) CreateContestInvitation(ctx context.Context, contestID int, author *kilonova.UserBrief, maxUses *int) (string, error) {
+func (s *BaseAPI) CreateContestInvitation(ctx context.Context, contestID int, author *kilonova.UserBrief, maxUses *int, categoryID *int) (string, error) {
 	var id *int
 	if author != nil {
 		id = &author.ID
 	}
-	invID, err := s.db.CreateContestInvitation(ctx, contestID, id, maxUses)
+	if categoryID != nil {
+		if _, err := s.ContestCategory(ctx, contestID, *categoryID); err != nil {
+			return "", err
+		}
+	}
+	invID, err := s.db.CreateContestInvitation(ctx, contestID, id, maxUses, categoryID)
 	if err != nil {
 		return "", fmt.Errorf("couldn't create invitation: %w", err)
 	}
@@ -124,3 +150,97 @@ func (s *BaseAPI) ContestInvitation(ctx context.Context, id string) (*kilonova.C
 	}
 	return inv, nil
 }
+
+// Registration categories
+
+const maxContestCategoryNameLength = 64
+
+func (s *BaseAPI) ContestCategories(ctx context.Context, contestID int) ([]*kilonova.ContestCategory, error) {
+	categories, err := s.db.ContestCategories(ctx, contestID)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't get registration categories: %w", err)
+	}
+	return categories, nil
+}
+
+// ContestCategory returns the category only if it belongs to the given contest
+func (s *BaseAPI) ContestCategory(ctx context.Context, contestID, id int) (*kilonova.ContestCategory, error) {
+	category, err := s.db.ContestCategory(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't get registration category: %w", err)
+	}
+	if category == nil || category.ContestID != contestID {
+		return nil, Statusf(404, "Registration category not found")
+	}
+	return category, nil
+}
+
+func (s *BaseAPI) validateContestCategory(ctx context.Context, contestID int, id int, name string) error {
+	if name == "" || len(name) > maxContestCategoryNameLength {
+		return Statusf(400, "Category name must be between 1 and %d characters long", maxContestCategoryNameLength)
+	}
+	categories, err := s.ContestCategories(ctx, contestID)
+	if err != nil {
+		return err
+	}
+	for _, category := range categories {
+		if category.ID != id && strings.EqualFold(category.Name, name) {
+			return Statusf(400, "A category with this name already exists")
+		}
+	}
+	return nil
+}
+
+func (s *BaseAPI) CreateContestCategory(ctx context.Context, contestID int, name string, official, selfAssignable bool) (int, error) {
+	name = strings.TrimSpace(name)
+	if err := s.validateContestCategory(ctx, contestID, -1, name); err != nil {
+		return -1, err
+	}
+	id, err := s.db.CreateContestCategory(ctx, contestID, name, official, selfAssignable)
+	if err != nil {
+		return -1, fmt.Errorf("couldn't create registration category: %w", err)
+	}
+	return id, nil
+}
+
+func (s *BaseAPI) UpdateContestCategory(ctx context.Context, category *kilonova.ContestCategory, name string, official, selfAssignable bool) error {
+	name = strings.TrimSpace(name)
+	if err := s.validateContestCategory(ctx, category.ContestID, category.ID, name); err != nil {
+		return err
+	}
+	if err := s.db.UpdateContestCategory(ctx, category.ID, name, official, selfAssignable); err != nil {
+		return fmt.Errorf("couldn't update registration category: %w", err)
+	}
+	return nil
+}
+
+// DeleteContestCategory removes the category. Its contestants become uncategorized (and thus official)
+func (s *BaseAPI) DeleteContestCategory(ctx context.Context, category *kilonova.ContestCategory) error {
+	if err := s.db.DeleteContestCategory(ctx, category.ID); err != nil {
+		return fmt.Errorf("couldn't delete registration category: %w", err)
+	}
+	return nil
+}
+
+// SetContestRegistrationCategory moves a registered contestant to another category. A nil categoryID clears it.
+// Members of a team are moved together.
+func (s *BaseAPI) SetContestRegistrationCategory(ctx context.Context, contestID, userID int, categoryID *int) error {
+	reg, err := s.ContestRegistration(ctx, contestID, userID)
+	if err != nil {
+		return err
+	}
+	if categoryID != nil {
+		if _, err := s.ContestCategory(ctx, contestID, *categoryID); err != nil {
+			return err
+		}
+	}
+	if reg.TeamID != nil {
+		err = s.db.UpdateTeamRegistrationCategory(ctx, contestID, *reg.TeamID, categoryID)
+	} else {
+		err = s.db.UpdateContestRegistrationCategory(ctx, contestID, userID, categoryID)
+	}
+	if err != nil {
+		return fmt.Errorf("couldn't update registration category: %w", err)
+	}
+	return nil
+}
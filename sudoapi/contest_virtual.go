@@ -0,0 +1,229 @@
+package sudoapi
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/KiloProjects/kilonova"
+	"github.com/shopspring/decimal"
+)
+
+func (s *BaseAPI) VirtualParticipation(ctx context.Context, contest *kilonova.Contest, userID int) (*kilonova.VirtualParticipation, error) {
+	vp, err := s.db.VirtualParticipation(ctx, contest.ID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't get virtual participation: %w", err)
+	}
+	return vp, nil
+}
+
+// runningVirtualParticipation returns the participation of the user, if it's in progress
+func (s *BaseAPI) runningVirtualParticipation(ctx context.Context, contest *kilonova.Contest, userID int) *kilonova.VirtualParticipation {
+	if !contest.Ended() {
+		return nil
+	}
+	vp, err := s.db.VirtualParticipation(ctx, contest.ID, userID)
+	if err != nil {
+		slog.WarnContext(ctx, "Couldn't get virtual participation", slog.Any("err", err))
+		return nil
+	}
+	if !vp.Running() {
+		return nil
+	}
+	return vp
+}
+
+// CanStartVirtualParticipation checks if the user can replay the contest.
+// Only users that haven't seen the contest before (ie. not contestants or testers) can do it.
+func (s *BaseAPI) CanStartVirtualParticipation(ctx context.Context, user *kilonova.UserBrief, contest *kilonova.Contest) bool {
+	if !user.IsAuthed() || contest == nil {
+		return false
+	}
+	if !contest.VirtualParticipation || !contest.Ended() || !contest.Visible || contest.TeamContest() || contest.Type != kilonova.ContestTypeOfficial {
+		return false
+	}
+	if contest.IsTester(user) {
+		return false
+	}
+	reg, err := s.db.ContestRegistration(ctx, contest.ID, user.ID)
+	if err != nil || reg != nil {
+		return false
+	}
+	vp, err := s.db.VirtualParticipation(ctx, contest.ID, user.ID)
+	return err == nil && vp == nil
+}
+
+// StartVirtualParticipation starts replaying the contest for the user, with the duration of the original contest.
+// In USACO-style contests, the duration is the one every contestant had.
+func (s *BaseAPI) StartVirtualParticipation(ctx context.Context, contest *kilonova.Contest, user *kilonova.UserBrief) (*kilonova.VirtualParticipation, error) {
+	if !s.CanStartVirtualParticipation(ctx, user, contest) {
+		return nil, Statusf(400, "You can't start a virtual participation in this contest")
+	}
+
+	duration := contest.EndTime.Sub(contest.StartTime)
+	if contest.PerUserTime > 0 {
+		duration = time.Duration(contest.PerUserTime) * time.Second
+	}
+	startTime := time.Now()
+	if _, err := s.db.CreateVirtualParticipation(ctx, contest.ID, user.ID, startTime, startTime.Add(duration)); err != nil {
+		return nil, fmt.Errorf("couldn't start virtual participation: %w", err)
+	}
+	return s.VirtualParticipation(ctx, contest, user.ID)
+}
+
+// CanSubmitAfterContest checks if the user can send submissions to the ended contest,
+// either because upsolving is enabled or because they have a virtual participation in progress.
+func (s *BaseAPI) CanSubmitAfterContest(ctx context.Context, user *kilonova.UserBrief, contest *kilonova.Contest) bool {
+	if !user.IsAuthed() || contest == nil || !contest.Ended() {
+		return false
+	}
+	if s.runningVirtualParticipation(ctx, contest, user.ID) != nil {
+		return true
+	}
+	return contest.Upsolving && s.CanViewContestProblems(ctx, user, contest)
+}
+
+// VirtualStanding ranks the virtual participation against the leaderboard of the contest,
+// as it stood at the same time since the start of the contest.
+// The original leaderboard is never shown past its freeze time, unless the user is a contest editor.
+// In USACO-style contests, the original contestants all had different windows, so the comparison is only approximate.
+func (s *BaseAPI) VirtualStanding(ctx context.Context, contest *kilonova.Contest, vp *kilonova.VirtualParticipation, lookingUser *kilonova.UserBrief) (*kilonova.VirtualStanding, error) {
+	elapsed := vp.Elapsed()
+	historicalTime := contest.StartTime.Add(elapsed)
+	if freeze := s.UserContestFreezeTime(lookingUser, contest, false); freeze != nil && freeze.Before(historicalTime) {
+		historicalTime = *freeze
+	}
+
+	ld, err := s.ContestLeaderboard(ctx, contest, &historicalTime, kilonova.LeaderboardFilter{})
+	if err != nil {
+		return nil, err
+	}
+
+	entry, err := s.virtualEntry(ctx, contest, vp)
+	if err != nil {
+		return nil, err
+	}
+
+	standing := &kilonova.VirtualStanding{
+		Participation: vp,
+		Entry:         entry,
+	}
+	standing.Rank, standing.NumRanked = ld.VirtualRank(entry)
+	return standing, nil
+}
+
+// virtualEntry builds the leaderboard entry of the virtual participation, with its times shifted to the contest's timeline.
+func (s *BaseAPI) virtualEntry(ctx context.Context, contest *kilonova.Contest, vp *kilonova.VirtualParticipation) (*kilonova.LeaderboardEntry, error) {
+	user, err := s.UserBrief(ctx, vp.UserID)
+	if err != nil {
+		return nil, err
+	}
+	scores, err := s.db.VirtualParticipationScores(ctx, vp)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't get virtual participation scores: %w", err)
+	}
+
+	shift := func(t time.Time) time.Time {
+		return contest.StartTime.Add(t.Sub(vp.StartTime))
+	}
+
+	entry := &kilonova.LeaderboardEntry{
+		User:            user,
+		ProblemScores:   make(map[int]decimal.Decimal),
+		ProblemAttempts: make(map[int]int),
+		ProblemTimes:    make(map[int]float64),
+	}
+	for _, score := range scores {
+		entry.ProblemScores[score.ProblemID] = score.Score
+		if score.Score.IsPositive() {
+			entry.TotalScore = entry.TotalScore.Add(score.Score)
+			if score.MinTime != nil && (entry.LastTime == nil || entry.LastTime.Before(shift(*score.MinTime))) {
+				entry.LastTime = new(shift(*score.MinTime))
+			}
+		}
+	}
+
+	if contest.LeaderboardStyle != kilonova.LeaderboardTypeICPC {
+		return entry, nil
+	}
+
+	// ICPC leaderboards only count solved problems, so the last time and penalty are recomputed from them
+	subs, err := s.RawSubmissions(ctx, kilonova.SubmissionFilter{
+		VirtualParticipationID: &vp.ID,
+		Ordering:               "id",
+		Ascending:              true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	entry.LastTime = nil
+	for _, score := range scores {
+		if !score.Score.Equal(decimal.NewFromInt(100)) || score.MinTime == nil {
+			continue
+		}
+		solveTime := shift(*score.MinTime)
+		entry.NumSolved++
+		if entry.LastTime == nil || entry.LastTime.Before(solveTime) {
+			entry.LastTime = &solveTime
+		}
+		minutes := solveTime.Sub(contest.StartTime).Minutes()
+		entry.ProblemTimes[score.ProblemID] = minutes
+		for _, sub := range subs {
+			// Same rules as for the contest_icpc_view attempts
+			if sub.ProblemID == score.ProblemID && sub.CreatedAt.Before(*score.MinTime) &&
+				(sub.CompileError == nil || !*sub.CompileError) &&
+				(sub.Status == kilonova.StatusFinished || sub.Status == kilonova.StatusReevaling) {
+				entry.ProblemAttempts[score.ProblemID]++
+			}
+		}
+		entry.Penalty += int(minutes) + entry.ProblemAttempts[score.ProblemID]*contest.ICPCSubmissionPenalty
+	}
+	return entry, nil
+}
+
+// addUpsolveScores fills in the post-contest results of the leaderboard's contestants.
+// In team contests, the team gets the best upsolving score of its members.
+func (s *BaseAPI) addUpsolveScores(ctx context.Context, contest *kilonova.Contest, ld *kilonova.ContestLeaderboard) error {
+	scores, err := s.db.ContestUpsolveScores(ctx, contest.ID)
+	if err != nil {
+		return fmt.Errorf("couldn't get upsolving scores: %w", err)
+	}
+	userScores := make(map[int]map[int]decimal.Decimal)
+	for _, score := range scores {
+		if _, ok := userScores[score.UserID]; !ok {
+			userScores[score.UserID] = make(map[int]decimal.Decimal)
+		}
+		userScores[score.UserID][score.ProblemID] = score.Score
+	}
+
+	for _, entry := range ld.Entries {
+		members := []*kilonova.UserBrief{entry.User}
+		if entry.Team != nil {
+			members = entry.Members
+		}
+
+		var total decimal.Decimal
+		for _, pbID := range ld.ProblemOrder {
+			// Upsolving can only improve on the contest result
+			best, ok := entry.ProblemScores[pbID]
+			if !ok || best.IsNegative() {
+				best = decimal.Zero
+			}
+			for _, member := range members {
+				if score, ok := userScores[member.ID][pbID]; ok && score.GreaterThan(best) {
+					best = score
+				}
+			}
+			if ld.Type == kilonova.LeaderboardTypeICPC {
+				if best.Equal(decimal.NewFromInt(100)) {
+					total = total.Add(decimal.NewFromInt(1))
+				}
+				continue
+			}
+			total = total.Add(best)
+		}
+		entry.UpsolveTotal = &total
+	}
+	return nil
+}
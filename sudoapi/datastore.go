@@ -34,6 +34,11 @@ func (s *BaseAPI) SubtestReader(subtest int) (io.ReadCloser, error) {
 	return s.subtestBucket.Reader(strconv.Itoa(subtest))
 }
 
+// SubtestTranscriptReader returns what the manager and the submission sent each other on a communication subtest
+func (s *BaseAPI) SubtestTranscriptReader(subtest int) (io.ReadCloser, error) {
+	return s.subtestBucket.Reader(strconv.Itoa(subtest) + ".transcript")
+}
+
 func (s *BaseAPI) SaveTestInput(testID int, input io.Reader) error {
 	if err := s.testBucket.WriteFile(strconv.Itoa(testID)+".in", dos2unix.DOS2Unix(input), 0644); err != nil {
 		return fmt.Errorf("could not save test input: %w", err)
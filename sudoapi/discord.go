@@ -42,6 +42,7 @@ func (s *BaseAPI) initDiscord(ctx context.Context) error {
 
 func (s *BaseAPI) AnnounceProblemPublished(ctx context.Context, problemID int) {
 	slog.DebugContext(ctx, "Announcing problem publish", slog.Int("problem_id", problemID))
+	s.announceProblemPublishedWebhook(ctx, problemID)
 	if !flags.DiscordEnabled.Value() || flags.ProblemAnnouncementChannel.Value() == "" {
 		return // noop
 	}
@@ -11,6 +11,10 @@ var (
 	EmailBranding = config.GenFlag("admin.mailer.branding", "Kilonova", "Branding to use at the end of emails")
 
 	SignupEnabled = config.GenFlag("feature.platform.signup", true, "Manual signup")
+
+	WebhooksEnabled = config.GenFlag("feature.platform.webhooks", true, "Outgoing webhooks for platform events")
+
+	LiveUpdatesEnabled = config.GenFlag("feature.platform.live_updates", true, "Live submission and contest updates through server-sent events")
 )
 
 // DB
@@ -35,6 +39,8 @@ var (
 // grader
 var (
 	ForceSecureSandbox = config.GenFlag[bool]("feature.grader.force_secure_sandbox", true, "Force use of secure sandbox only. Should be always enabled in production environments")
+
+	CommunicationTranscriptSize = config.GenFlag[int]("feature.grader.communication_transcript_kb", 0, "Size (in KB) of the manager/user transcript saved for communication subtests. Transcripts are disabled while it is 0")
 )
 
 // virtual contests
@@ -35,11 +35,30 @@ var MossUserID = config.GenFlag("integrations.moss.user_id", -1, "User ID for MO
 
 var OtelEnabled = config.GenFlag("integrations.otel.enabled", false, "Enable OpenTelemetry collectors")
 
+// LLM statement tools
+var (
+	LLMProvider         = config.GenFlag("integrations.llm.provider", "openai", "LLM provider for statement translations and transcriptions (`openai`, `anthropic` or `local`)")
+	LLMTranslatePrompt  = config.GenFlag("integrations.llm.translate_prompt", "", "Path to a file replacing the built-in statement translation prompt")
+	LLMTranscribePrompt = config.GenFlag("integrations.llm.transcribe_prompt", "", "Path to a file replacing the built-in statement transcription prompt")
+)
+
 // openai
 var (
-	// New sdk doesnt support openrouter
-	// OpenAIBaseURL      = config.GenFlag("integrations.openai.base_url", "", "Base URL for OpenAI API (`https://openrouter.ai/api/v1` can be used for OpenRouter)")
+	OpenAIBaseURL      = config.GenFlag("integrations.openai.base_url", "", "Base URL for OpenAI API, must support the Responses API (`https://openrouter.ai/api/v1` can be used for OpenRouter)")
 	OpenAIToken        = config.GenFlag("integrations.openai.token", "", "API Key for OpenAI access (used in translating statements)")
 	OpenAIDefaultModel = config.GenFlag("integrations.openai.default_model", "gpt-5.4", "Default model for LLM translations")
 	OpenAIVisionModel  = config.GenFlag("integrations.openai.vision_model", "gpt-5.4", "Default model for LLM statement transcriptions")
 )
+
+// anthropic
+var (
+	AnthropicToken        = config.GenFlag("integrations.anthropic.token", "", "API Key for Anthropic access (used in translating statements)")
+	AnthropicDefaultModel = config.GenFlag("integrations.anthropic.default_model", "claude-sonnet-4-5", "Default model for LLM translations and transcriptions")
+)
+
+// local LLM server (Ollama, llama.cpp)
+var (
+	LocalLLMBaseURL = config.GenFlag("integrations.local_llm.base_url", "", "Base URL of the OpenAI-compatible API of the LLM server (`http://localhost:11434/v1` for Ollama)")
+	LocalLLMToken   = config.GenFlag("integrations.local_llm.token", "", "API Key for the LLM server, if it requires one")
+	LocalLLMModel   = config.GenFlag("integrations.local_llm.model", "", "Model for LLM translations, required by the local provider. PDF transcriptions are not supported")
+)
@@ -66,4 +66,6 @@ var (
 
 var (
 	DefaultSourceSize = config.GenFlag[int]("behavior.problem.default_source_size", 30000, "Default maximum source code size for problems")
+
+	OutputOnlyArchiveSize = config.GenFlag[int]("behavior.problem.output_only_archive_size", 64*1024*1024, "Maximum size (in bytes) of the output archives submitted to output only problems")
 )
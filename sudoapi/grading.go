@@ -13,8 +13,11 @@ import (
 
 // Language returns nil if the language is not supported by grader.
 func (s *BaseAPI) Language(name string) language.Lang {
-	if name == "ai" {
+	switch name {
+	case "ai":
 		return language.AI()
+	case language.OutputOnly().InternalName():
+		return language.OutputOnly()
 	}
 	if s.langMgr == nil {
 		for langName, lang := range language.Langs {
@@ -27,10 +30,21 @@ func (s *BaseAPI) Language(name string) language.Lang {
 	return s.langMgr.Language(name)
 }
 
+// GraderLanguage returns the language as provided by the grader, or nil if it isn't supported or the grader isn't running
+func (s *BaseAPI) GraderLanguage(name string) language.GraderLang {
+	if s.langMgr == nil {
+		return nil
+	}
+	return s.langMgr.Language(name)
+}
+
 // AnyLanguage returns nil if the language was not found
 func (s *BaseAPI) AnyLanguage(name string) language.Lang {
-	if name == "ai" {
+	switch name {
+	case "ai":
 		return language.AI()
+	case language.OutputOnly().InternalName():
+		return language.OutputOnly()
 	}
 	for langName, lang := range language.Langs {
 		if langName == name {
@@ -0,0 +1,165 @@
+package sudoapi
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"github.com/KiloProjects/kilonova"
+	"github.com/KiloProjects/kilonova/internal/pubsub"
+	"github.com/KiloProjects/kilonova/sudoapi/flags"
+	"github.com/shopspring/decimal"
+)
+
+// Live update event types, sent over the submission and contest topics
+const (
+	LiveSubmission   = "submission"
+	LiveSubTest      = "subtest"
+	LiveLeaderboard  = "leaderboard"
+	LiveAnnouncement = "announcement"
+)
+
+func SubmissionTopic(subID int) string {
+	return "submission:" + strconv.Itoa(subID)
+}
+
+// submissionEditorTopic carries the updates of a submission to its problem editors,
+// who also see the results hidden by a running contest
+func submissionEditorTopic(subID int) string {
+	return SubmissionTopic(subID) + ":editor"
+}
+
+func ContestTopic(contestID int) string {
+	return "contest:" + strconv.Itoa(contestID)
+}
+
+// LiveSubmissionUpdate only holds fields that are visible to anyone who can see the submission.
+// Clients should refetch the submission for everything else (compilation messages, code, etc.)
+type LiveSubmissionUpdate struct {
+	ID             int               `json:"id"`
+	Status         kilonova.Status   `json:"status"`
+	Score          decimal.Decimal   `json:"score"`
+	ScorePrecision int32             `json:"score_precision"`
+	CompileError   *bool             `json:"compile_error"`
+	MaxTime        float64           `json:"max_time"`
+	MaxMemory      int               `json:"max_memory"`
+	SubmissionType kilonova.EvalType `json:"submission_type"`
+	ICPCVerdict    *string           `json:"icpc_verdict"`
+}
+
+func newLiveSubmissionUpdate(sub *kilonova.Submission) *LiveSubmissionUpdate {
+	return &LiveSubmissionUpdate{
+		ID:             sub.ID,
+		Status:         sub.Status,
+		Score:          sub.Score,
+		ScorePrecision: sub.ScorePrecision,
+		CompileError:   sub.CompileError,
+		MaxTime:        sub.MaxTime,
+		MaxMemory:      sub.MaxMemory,
+		SubmissionType: sub.SubmissionType,
+		ICPCVerdict:    sub.ICPCVerdict,
+	}
+}
+
+// LiveLeaderboardUpdate is sent whenever a contest submission finishes.
+// Score is the user's new maximum score on the problem.
+type LiveLeaderboardUpdate struct {
+	UserID       int             `json:"user_id"`
+	ProblemID    int             `json:"problem_id"`
+	SubmissionID int             `json:"submission_id"`
+	Score        decimal.Decimal `json:"score"`
+	CreatedAt    time.Time       `json:"created_at"`
+}
+
+// liveSubscribed reports whether updates published on topic would reach anyone.
+// Brokers that can't count their subscribers are assumed to always have some.
+func (s *BaseAPI) liveSubscribed(topic string) bool {
+	if !flags.LiveUpdatesEnabled.Value() {
+		return false
+	}
+	if counter, ok := s.broker.(pubsub.SubscriberCounter); ok {
+		return counter.Subscribers(topic) > 0
+	}
+	return true
+}
+
+func (s *BaseAPI) publishLive(ctx context.Context, topic string, eventType string, data any) {
+	if !flags.LiveUpdatesEnabled.Value() {
+		return
+	}
+	body, err := json.Marshal(data)
+	if err != nil {
+		slog.WarnContext(ctx, "Couldn't encode live update", slog.String("topic", topic), slog.Any("err", err))
+		return
+	}
+	if err := s.broker.Publish(ctx, topic, pubsub.Event{Type: eventType, Data: body}); err != nil {
+		slog.WarnContext(ctx, "Couldn't publish live update", slog.String("topic", topic), slog.Any("err", err))
+	}
+}
+
+func (s *BaseAPI) publishSubmissionUpdate(ctx context.Context, sub *kilonova.Submission) {
+	if s.liveSubscribed(submissionEditorTopic(sub.ID)) {
+		s.publishLive(ctx, submissionEditorTopic(sub.ID), LiveSubmission, newLiveSubmissionUpdate(sub))
+	}
+	if s.liveSubscribed(SubmissionTopic(sub.ID)) {
+		// Everyone else only gets the results shown during the contest
+		shown := *sub
+		s.hideSubmissionScores(ctx, []*kilonova.Submission{&shown})
+		s.publishLive(ctx, SubmissionTopic(sub.ID), LiveSubmission, newLiveSubmissionUpdate(&shown))
+	}
+	// ContestMaxScore is a query of its own, only run it if someone watches the contest
+	if sub.Status == kilonova.StatusFinished && sub.ContestID != nil && s.liveSubscribed(ContestTopic(*sub.ContestID)) {
+		s.publishLive(ctx, ContestTopic(*sub.ContestID), LiveLeaderboard, &LiveLeaderboardUpdate{
+			UserID:       sub.UserID,
+			ProblemID:    sub.ProblemID,
+			SubmissionID: sub.ID,
+			Score:        s.ContestMaxScore(ctx, sub.UserID, sub.ProblemID, *sub.ContestID, nil),
+			CreatedAt:    sub.CreatedAt,
+		})
+	}
+}
+
+// SubscribeSubmission streams the status and subtest changes of a submission visible to lookingUser.
+func (s *BaseAPI) SubscribeSubmission(ctx context.Context, subID int, lookingUser *kilonova.UserBrief) (<-chan pubsub.Event, func(), error) {
+	if !flags.LiveUpdatesEnabled.Value() {
+		return nil, nil, kilonova.ErrFeatureDisabled
+	}
+	sub, err := s.db.SubmissionLookingUser(ctx, subID, lookingUser)
+	if err != nil || sub == nil {
+		return nil, nil, Statusf(404, "Submission not found or user may not have access")
+	}
+	problem, err := s.Problem(ctx, sub.ProblemID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !s.IsProblemVisible(lookingUser, problem) {
+		return nil, nil, Statusf(403, "Submission hidden because problem is not visible.")
+	}
+	topic := SubmissionTopic(sub.ID)
+	if s.IsProblemEditor(lookingUser, problem) {
+		topic = submissionEditorTopic(sub.ID)
+	}
+	ch, cancel := s.broker.Subscribe(ctx, topic)
+	return ch, cancel, nil
+}
+
+// SubscribeContest streams leaderboard changes and announcements of a contest.
+// Visibility of the contest must be checked beforehand, events must then be passed through LiveContestEventVisible.
+func (s *BaseAPI) SubscribeContest(ctx context.Context, contest *kilonova.Contest) (<-chan pubsub.Event, func(), error) {
+	if !flags.LiveUpdatesEnabled.Value() {
+		return nil, nil, kilonova.ErrFeatureDisabled
+	}
+	ch, cancel := s.broker.Subscribe(ctx, ContestTopic(contest.ID))
+	return ch, cancel, nil
+}
+
+// LiveContestEventVisible reports whether a contest event may be forwarded to lookingUser.
+// Leaderboard updates are withheld from people who can't see the leaderboard or only see its frozen version.
+func (s *BaseAPI) LiveContestEventVisible(ev pubsub.Event, lookingUser *kilonova.UserBrief, contest *kilonova.Contest) bool {
+	if ev.Type != LiveLeaderboard {
+		return true
+	}
+	return s.CanViewContestLeaderboard(lookingUser, contest) && s.UserContestFreezeTime(lookingUser, contest, false) == nil
+}
@@ -0,0 +1,72 @@
+package sudoapi
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/KiloProjects/kilonova"
+	"github.com/KiloProjects/kilonova/internal/pubsub"
+	"github.com/KiloProjects/kilonova/sudoapi/flags"
+	"github.com/shopspring/decimal"
+)
+
+// countlessBroker doesn't implement pubsub.SubscriberCounter
+type countlessBroker struct{ pubsub.Broker }
+
+func TestLiveSubscribed(t *testing.T) {
+	broker := pubsub.NewMemoryBroker()
+	base := &BaseAPI{broker: broker}
+	topic := SubmissionTopic(1)
+
+	if base.liveSubscribed(topic) {
+		t.Error("Topic without subscribers reported as subscribed")
+	}
+
+	_, cancel := broker.Subscribe(context.Background(), topic)
+	defer cancel()
+	if !base.liveSubscribed(topic) {
+		t.Error("Topic with a subscriber reported as unsubscribed")
+	}
+	if base.liveSubscribed(SubmissionTopic(2)) {
+		t.Error("Subscriber counted on another topic")
+	}
+
+	flags.LiveUpdatesEnabled.Update(false)
+	defer flags.LiveUpdatesEnabled.Update(true)
+	if base.liveSubscribed(topic) {
+		t.Error("Topic reported as subscribed while live updates are disabled")
+	}
+	flags.LiveUpdatesEnabled.Update(true)
+
+	if !(&BaseAPI{broker: countlessBroker{broker}}).liveSubscribed(SubmissionTopic(2)) {
+		t.Error("Brokers that can't count subscribers must be assumed to have some")
+	}
+}
+
+func TestPublishSubmissionUpdateTopics(t *testing.T) {
+	broker := pubsub.NewMemoryBroker()
+	base := &BaseAPI{broker: broker}
+	sub := &kilonova.Submission{ID: 1, Status: kilonova.StatusFinished, Score: decimal.NewFromInt(100)}
+
+	others, cancel := broker.Subscribe(context.Background(), SubmissionTopic(sub.ID))
+	defer cancel()
+	editors, cancel := broker.Subscribe(context.Background(), submissionEditorTopic(sub.ID))
+	defer cancel()
+
+	base.publishSubmissionUpdate(context.Background(), sub)
+	for name, ch := range map[string]<-chan pubsub.Event{"contestants": others, "editors": editors} {
+		select {
+		case ev := <-ch:
+			var upd LiveSubmissionUpdate
+			if err := json.Unmarshal(ev.Data, &upd); err != nil {
+				t.Fatal(err)
+			}
+			if ev.Type != LiveSubmission || !upd.Score.Equal(sub.Score) {
+				t.Errorf("Unexpected update for %s: %s %+v", name, ev.Type, upd)
+			}
+		default:
+			t.Errorf("No update published for %s", name)
+		}
+	}
+}
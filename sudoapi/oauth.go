@@ -2,8 +2,13 @@ package sudoapi
 
 import (
 	"context"
+	"fmt"
+	"log/slog"
 	"net/http"
+	"slices"
+	"strconv"
 
+	"github.com/KiloProjects/kilonova"
 	"github.com/KiloProjects/kilonova/internal/auth"
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
@@ -75,8 +80,13 @@ func (s *BaseAPI) RegisterOIDCRoutes(r chi.Router) {
 	})
 }
 
-func (s *BaseAPI) ApproveAuthRequest(ctx context.Context, reqID string, userID int) error {
-	return s.oidcProvider.Storage().(*auth.AuthStorage).ApproveAuthRequest(ctx, reqID, userID)
+// ApproveAuthRequest marks the request as approved by the user, granting only the scopes the user is allowed to grant
+func (s *BaseAPI) ApproveAuthRequest(ctx context.Context, reqID string, user *kilonova.UserBrief) error {
+	req, err := s.GetAuthRequest(ctx, reqID)
+	if err != nil {
+		return err
+	}
+	return s.oidcProvider.Storage().(*auth.AuthStorage).ApproveAuthRequest(ctx, reqID, user.ID, auth.GrantableScopes(req.Scopes, user))
 }
 
 func (s *BaseAPI) CreateClient(ctx context.Context, name string, appType auth.ApplicationType, authorID int, devMode bool, allowedRedirects []string, allowedPostLogoutRedirects []string) (uuid.UUID, string, error) {
@@ -102,3 +112,65 @@ func (s *BaseAPI) GetOAuthClient(ctx context.Context, clientID uuid.UUID) (*auth
 func (s *BaseAPI) GetAccessToken(ctx context.Context, tokenID uuid.UUID) (*auth.Token, error) {
 	return s.oidcProvider.Storage().(*auth.AuthStorage).GetAccessToken(ctx, tokenID)
 }
+
+// AccessTokenUser returns the user an OAuth access token was issued to, along with the token's scopes.
+// The returned user doesn't have the admin or proposer roles if the token wasn't granted the matching scope.
+func (s *BaseAPI) AccessTokenUser(ctx context.Context, accessToken string) (*kilonova.UserFull, []string, error) {
+	claims, err := op.VerifyAccessToken[*oidc.AccessTokenClaims](ctx, accessToken, s.oidcProvider.AccessTokenVerifier(ctx))
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid access token: %w", err)
+	}
+	userID, err := strconv.Atoi(claims.GetSubject())
+	if err != nil {
+		slog.ErrorContext(ctx, "Received access token with invalid subject", slog.Any("err", err))
+		return nil, nil, err
+	}
+
+	tokenID, err := uuid.Parse(claims.JWTID)
+	if err != nil {
+		slog.ErrorContext(ctx, "Received access token with invalid jwt id", slog.Any("err", err))
+		return nil, nil, err
+	}
+
+	token, err := s.GetAccessToken(ctx, tokenID)
+	if err != nil {
+		// The token is probably revoked
+		return nil, nil, err
+	}
+	if token.UserID == nil || userID != *token.UserID {
+		slog.ErrorContext(ctx, "Received access token with invalid user id", slog.Int("user_id", userID), slog.Any("token_user_id", token.UserID))
+		return nil, nil, fmt.Errorf("token user mismatch")
+	}
+
+	userFull, err := s.UserFull(ctx, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return scopedUser(userFull, token.Scopes), token.Scopes, nil
+}
+
+// scopedUser returns a copy of the user without the admin or proposer roles, unless the matching scope was granted
+func scopedUser(user *kilonova.UserFull, scopes []string) *kilonova.UserFull {
+	scoped := *user
+	scoped.Admin = scoped.Admin && slices.Contains(scopes, auth.ScopeAdmin)
+	scoped.Proposer = scoped.Proposer && slices.Contains(scopes, auth.ScopeProposer)
+	return &scoped
+}
+
+// UserAuthorizedClients returns the OAuth clients that can currently act on behalf of the user
+func (s *BaseAPI) UserAuthorizedClients(ctx context.Context, userID int) ([]*auth.AuthorizedClient, error) {
+	clients, err := s.oidcProvider.Storage().(*auth.AuthStorage).UserAuthorizedClients(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get authorized clients: %w", err)
+	}
+	return clients, nil
+}
+
+// RevokeAuthorizedClient revokes all the tokens issued to the client on behalf of the user
+func (s *BaseAPI) RevokeAuthorizedClient(ctx context.Context, userID int, clientID uuid.UUID) error {
+	if err := s.oidcProvider.Storage().(*auth.AuthStorage).RevokeClient(ctx, userID, clientID); err != nil {
+		return fmt.Errorf("failed to revoke client tokens: %w", err)
+	}
+	return nil
+}
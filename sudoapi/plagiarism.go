@@ -0,0 +1,427 @@
+package sudoapi
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"iter"
+	"log/slog"
+	"slices"
+
+	"github.com/KiloProjects/kilonova"
+	"github.com/KiloProjects/kilonova/eval/language"
+	"github.com/KiloProjects/kilonova/internal/plagiarism"
+	"github.com/KiloProjects/kilonova/net/moss"
+	"github.com/KiloProjects/kilonova/sudoapi/flags"
+	"github.com/shopspring/decimal"
+)
+
+// maxPlagiarismMatches mirrors the default number of results shown by MOSS
+const maxPlagiarismMatches = 250
+
+// plagiarismGroup is a set of submissions that are compared with each other: the same problem and the same MOSS language.
+type plagiarismGroup struct {
+	problem  *kilonova.Problem
+	mossLang string
+	subs     []*kilonova.Submission
+}
+
+// DefaultPlagiarismEngine is MOSS if it's configured, otherwise the local checker.
+func DefaultPlagiarismEngine() kilonova.PlagiarismEngine {
+	if flags.MossUserID.Value() > 0 {
+		return kilonova.PlagiarismEngineMOSS
+	}
+	return kilonova.PlagiarismEngineLocal
+}
+
+// RunPlagiarismCheck compares the submissions of the contest, either through MOSS or locally.
+// Each problem and language is checked separately, in the background, and gets its own MOSSSubmission record.
+func (s *BaseAPI) RunPlagiarismCheck(ctx context.Context, contest *kilonova.Contest, opts kilonova.PlagiarismOptions) error {
+	if opts.Engine == "" {
+		opts.Engine = DefaultPlagiarismEngine()
+	}
+	if opts.Engine != kilonova.PlagiarismEngineMOSS && opts.Engine != kilonova.PlagiarismEngineLocal {
+		return Statusf(400, "Invalid plagiarism checker")
+	}
+	if opts.Engine == kilonova.PlagiarismEngineMOSS && flags.MossUserID.Value() <= 0 {
+		return Statusf(400, "MOSS is not configured on this instance")
+	}
+
+	groups, err := s.plagiarismGroups(ctx, contest, opts)
+	if err != nil {
+		return err
+	}
+
+	for _, group := range groups {
+		lang := s.LanguageFromMOSS(ctx, group.mossLang)
+
+		slog.InfoContext(ctx, "Running plagiarism check", slog.Any("problem", group.problem), slog.Any("lang", lang), slog.Int("sub_count", len(group.subs)), slog.Any("engine", opts.Engine))
+		mossID, err := s.db.InsertMossSubmission(ctx, contest.ID, group.problem.ID, lang.InternalName(), len(group.subs), opts.Engine)
+		if err != nil {
+			return fmt.Errorf("could not add MOSS stub to DB: %w", err)
+		}
+
+		if opts.Engine == kilonova.PlagiarismEngineLocal {
+			go s.runLocalPlagiarism(ctx, contest, mossID, group)
+		} else {
+			go s.runMOSS(ctx, contest, mossID, lang, group, len(opts.SubmissionIDs) > 0 || opts.AllSubmissions)
+		}
+	}
+	return nil
+}
+
+// plagiarismGroups selects the submissions to be checked.
+// By default, only the best submission of each contestant is checked, just like MOSS used to.
+func (s *BaseAPI) plagiarismGroups(ctx context.Context, contest *kilonova.Contest, opts kilonova.PlagiarismOptions) ([]*plagiarismGroup, error) {
+	pbs, err := s.Problems(ctx, kilonova.ProblemFilter{ContestID: &contest.ID})
+	if err != nil {
+		return nil, err
+	}
+
+	var groups []*plagiarismGroup
+	for _, pb := range pbs {
+		if len(opts.ProblemIDs) > 0 && !slices.Contains(opts.ProblemIDs, pb.ID) {
+			continue
+		}
+		filter := kilonova.SubmissionFilter{
+			ProblemID: &pb.ID,
+			ContestID: &contest.ID,
+
+			Ordering:  "score",
+			Ascending: false,
+		}
+		if len(opts.SubmissionIDs) > 0 {
+			filter.IDs = opts.SubmissionIDs
+		}
+		subs, err := s.RawSubmissions(ctx, filter)
+		if err != nil {
+			return nil, err
+		}
+		if len(subs) == 0 {
+			continue
+		}
+		users := make(map[int]bool)
+		langSubs := make(map[string][]*kilonova.Submission)
+		var langOrder []string
+		for _, sub := range subs {
+			if len(opts.SubmissionIDs) == 0 && !opts.AllSubmissions {
+				if _, ok := users[sub.UserID]; ok {
+					continue
+				}
+				users[sub.UserID] = true
+			}
+
+			name := s.AnyLanguage(sub.Language).MOSSName()
+			if _, ok := langSubs[name]; !ok {
+				langOrder = append(langOrder, name)
+			}
+			langSubs[name] = append(langSubs[name], sub)
+		}
+
+		for _, mossLang := range langOrder {
+			groups = append(groups, &plagiarismGroup{problem: pb, mossLang: mossLang, subs: langSubs[mossLang]})
+		}
+	}
+	return groups, nil
+}
+
+func (s *BaseAPI) runMOSS(ctx context.Context, contest *kilonova.Contest, mossID int, lang language.Lang, group *plagiarismGroup, multipleSubs bool) {
+	conn, err := moss.New(ctx)
+	if err != nil {
+		slog.WarnContext(ctx, "Could not initialize MOSS", slog.Any("err", err))
+		return
+	}
+	defer conn.Close()
+
+	url, err := conn.Process(&moss.Options{
+		LanguageName: group.mossLang,
+		Comment:      fmt.Sprintf("%s - %s (%s)", contest.Name, group.problem.Name, lang.PrintableName()),
+
+		Files: iter.Seq[*moss.File](func(yield func(*moss.File) bool) {
+			for _, sub := range group.subs {
+				user, err := s.UserBrief(ctx, sub.UserID)
+				if err != nil {
+					slog.WarnContext(ctx, "Could not get user", slog.Any("err", err))
+					return
+				}
+
+				code, err := s.RawSubmissionCode(ctx, sub.ID)
+				if err != nil {
+					slog.WarnContext(ctx, "Could not get submission code", slog.Any("err", err))
+					return
+				}
+
+				// Users may have multiple submissions in the check, so they are told apart by ID
+				filename := user.Name
+				if multipleSubs {
+					filename = fmt.Sprintf("%s_%d", user.Name, sub.ID)
+				}
+				if !yield(moss.NewFile(group.mossLang, filename, code)) {
+					return
+				}
+			}
+		}),
+	})
+	if err != nil {
+		slog.WarnContext(ctx, "Could not get MOSS result", slog.Any("err", err))
+	}
+	if err := s.db.SetMossURL(ctx, mossID, url); err != nil {
+		slog.WarnContext(ctx, "Could not set MOSS results URL", slog.Any("err", err))
+	}
+}
+
+func (s *BaseAPI) runLocalPlagiarism(ctx context.Context, contest *kilonova.Contest, mossID int, group *plagiarismGroup) {
+	cfg := plagiarism.DefaultConfig()
+	family := plagiarism.FamilyFor(group.mossLang)
+
+	docs := make([]*plagiarism.Document, 0, len(group.subs))
+	for _, sub := range group.subs {
+		if doc := s.plagiarismDocument(ctx, sub, family, cfg); doc != nil {
+			docs = append(docs, doc)
+		}
+	}
+
+	s.savePlagiarismResults(ctx, mossID, plagiarism.Compare(docs, cfg), fmt.Sprintf("/contests/%d/manage/plagiarism/%d", contest.ID, mossID))
+}
+
+// plagiarismDocument fingerprints the code of the submission. It returns nil if the code couldn't be loaded.
+func (s *BaseAPI) plagiarismDocument(ctx context.Context, sub *kilonova.Submission, family *plagiarism.Family, cfg plagiarism.Config) *plagiarism.Document {
+	code, err := s.RawSubmissionCode(ctx, sub.ID)
+	if err != nil {
+		slog.WarnContext(ctx, "Could not get submission code", slog.Any("err", err))
+		return nil
+	}
+	// Team members share their code, so they must not be flagged against each other
+	owner := sub.UserID
+	if sub.TeamID != nil {
+		owner = -*sub.TeamID
+	}
+	return plagiarism.NewDocument(sub.ID, owner, family, code, cfg)
+}
+
+// savePlagiarismResults stores the most similar pairs and marks the check as done by setting its URL.
+func (s *BaseAPI) savePlagiarismResults(ctx context.Context, mossID int, results []*plagiarism.Match, url string) {
+	if len(results) > maxPlagiarismMatches {
+		results = results[:maxPlagiarismMatches]
+	}
+	matches := make([]*kilonova.PlagiarismMatch, 0, len(results))
+	for _, res := range results {
+		match := &kilonova.PlagiarismMatch{
+			Submission1ID: res.Doc1.ID,
+			Submission2ID: res.Doc2.ID,
+			Similarity1:   res.Similarity1,
+			Similarity2:   res.Similarity2,
+			Shared:        res.Shared,
+			Fragments:     make([]kilonova.PlagiarismFragment, 0, len(res.Fragments)),
+		}
+		if res.Cluster > 0 {
+			match.Cluster = &res.Cluster
+		}
+		for _, frag := range res.Fragments {
+			match.Fragments = append(match.Fragments, kilonova.PlagiarismFragment(frag))
+		}
+		matches = append(matches, match)
+	}
+
+	// The URL is set even if the matches couldn't be saved, so the check doesn't look pending forever
+	if err := s.db.InsertPlagiarismMatches(ctx, mossID, matches); err != nil {
+		slog.WarnContext(ctx, "Could not save plagiarism check results", slog.Any("err", err))
+	}
+	if err := s.db.SetMossURL(ctx, mossID, url); err != nil {
+		slog.WarnContext(ctx, "Could not set plagiarism check URL", slog.Any("err", err))
+	}
+}
+
+// CheckSubmissionPlagiarism compares the submission against the accepted submissions sent to its problem before it, in the background.
+// It returns the ID of the report.
+func (s *BaseAPI) CheckSubmissionPlagiarism(ctx context.Context, sub *kilonova.Submission, author *kilonova.UserBrief) (int, error) {
+	archive, err := s.RawSubmissions(ctx, kilonova.SubmissionFilter{
+		ProblemID: &sub.ProblemID,
+		Score:     new(decimal.NewFromInt(100)),
+		Ordering:  "id",
+		Ascending: true,
+	})
+	if err != nil {
+		return -1, err
+	}
+	mossLang := s.AnyLanguage(sub.Language).MOSSName()
+	archive = slices.DeleteFunc(archive, func(other *kilonova.Submission) bool {
+		return other.ID >= sub.ID || other.UserID == sub.UserID || s.AnyLanguage(other.Language).MOSSName() != mossLang
+	})
+
+	id, err := s.db.InsertPlagiarismReport(ctx, &kilonova.MOSSSubmission{
+		ProblemID:    &sub.ProblemID,
+		SubmissionID: &sub.ID,
+		AuthorID:     &author.ID,
+		Language:     sub.Language,
+		SubCount:     len(archive) + 1,
+		Engine:       kilonova.PlagiarismEngineLocal,
+	})
+	if err != nil {
+		return -1, fmt.Errorf("couldn't create plagiarism report: %w", err)
+	}
+
+	go func() {
+		ctx := context.WithoutCancel(ctx)
+		cfg := plagiarism.DefaultConfig()
+		family := plagiarism.FamilyFor(mossLang)
+		target := s.plagiarismDocument(ctx, sub, family, cfg)
+		if target == nil {
+			// The report is still marked as done, just without matches
+			s.savePlagiarismResults(ctx, id, nil, fmt.Sprintf("/plagiarism/%d", id))
+			return
+		}
+		docs := make([]*plagiarism.Document, 0, len(archive))
+		for _, other := range archive {
+			if doc := s.plagiarismDocument(ctx, other, family, cfg); doc != nil {
+				docs = append(docs, doc)
+			}
+		}
+		s.savePlagiarismResults(ctx, id, plagiarism.CompareWith(target, docs, cfg), fmt.Sprintf("/plagiarism/%d", id))
+	}()
+	return id, nil
+}
+
+// CheckUserPlagiarism compares the best submission of the user on every problem they tried
+// against the best submissions of everyone else on the same problem, in the background.
+// It returns the ID of the report.
+func (s *BaseAPI) CheckUserPlagiarism(ctx context.Context, target *kilonova.UserBrief, author *kilonova.UserBrief) (int, error) {
+	subs, err := s.RawSubmissions(ctx, kilonova.SubmissionFilter{
+		UserID:    &target.ID,
+		Ordering:  "score",
+		Ascending: false,
+	})
+	if err != nil {
+		return -1, err
+	}
+	problems := make(map[int]bool)
+	subs = slices.DeleteFunc(subs, func(sub *kilonova.Submission) bool {
+		if problems[sub.ProblemID] {
+			return true
+		}
+		problems[sub.ProblemID] = true
+		return false
+	})
+
+	id, err := s.db.InsertPlagiarismReport(ctx, &kilonova.MOSSSubmission{
+		UserID:   &target.ID,
+		AuthorID: &author.ID,
+		SubCount: len(subs),
+		Engine:   kilonova.PlagiarismEngineLocal,
+	})
+	if err != nil {
+		return -1, fmt.Errorf("couldn't create plagiarism report: %w", err)
+	}
+
+	go func() {
+		ctx := context.WithoutCancel(ctx)
+		cfg := plagiarism.DefaultConfig()
+		var results []*plagiarism.Match
+		for _, sub := range subs {
+			others, err := s.RawSubmissions(ctx, kilonova.SubmissionFilter{
+				ProblemID: &sub.ProblemID,
+				Ordering:  "score",
+				Ascending: false,
+			})
+			if err != nil {
+				slog.WarnContext(ctx, "Could not get problem submissions", slog.Any("err", err))
+				continue
+			}
+
+			mossLang := s.AnyLanguage(sub.Language).MOSSName()
+			family := plagiarism.FamilyFor(mossLang)
+			doc := s.plagiarismDocument(ctx, sub, family, cfg)
+			if doc == nil {
+				continue
+			}
+			users := map[int]bool{target.ID: true}
+			var docs []*plagiarism.Document
+			for _, other := range others {
+				if users[other.UserID] || s.AnyLanguage(other.Language).MOSSName() != mossLang {
+					continue
+				}
+				users[other.UserID] = true
+				if otherDoc := s.plagiarismDocument(ctx, other, family, cfg); otherDoc != nil {
+					docs = append(docs, otherDoc)
+				}
+			}
+			results = append(results, plagiarism.CompareWith(doc, docs, cfg)...)
+		}
+		slices.SortStableFunc(results, func(a, b *plagiarism.Match) int {
+			return cmp.Compare(b.Similarity(), a.Similarity())
+		})
+		s.savePlagiarismResults(ctx, id, results, fmt.Sprintf("/plagiarism/%d", id))
+	}()
+	return id, nil
+}
+
+// MOSSSubmission returns the plagiarism check with the given ID, if it belongs to the contest.
+func (s *BaseAPI) MOSSSubmission(ctx context.Context, contestID int, id int) (*kilonova.MOSSSubmission, error) {
+	sub, err := s.db.MossSubmission(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't fetch MOSS submission: %w", err)
+	}
+	if sub == nil || sub.ContestID == nil || *sub.ContestID != contestID {
+		return nil, Statusf(404, "Plagiarism check not found")
+	}
+	return sub, nil
+}
+
+func (s *BaseAPI) PlagiarismReport(ctx context.Context, id int) (*kilonova.MOSSSubmission, error) {
+	report, err := s.db.MossSubmission(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't fetch plagiarism report: %w", err)
+	}
+	if report == nil {
+		return nil, Statusf(404, "Plagiarism report not found")
+	}
+	return report, nil
+}
+
+func (s *BaseAPI) PlagiarismReports(ctx context.Context, filter kilonova.PlagiarismReportFilter) ([]*kilonova.MOSSSubmission, error) {
+	reports, err := s.db.PlagiarismReports(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't fetch plagiarism reports: %w", err)
+	}
+	return reports, nil
+}
+
+// CanViewPlagiarismReport checks if the user can see the report.
+// Contest reports are for contest editors, submission reports for problem editors and user reports only for admins.
+func (s *BaseAPI) CanViewPlagiarismReport(ctx context.Context, user *kilonova.UserBrief, report *kilonova.MOSSSubmission) bool {
+	if user.IsAdmin() {
+		return true
+	}
+	if !user.IsAuthed() || report.UserID != nil {
+		return false
+	}
+	if report.ContestID != nil {
+		contest, err := s.Contest(ctx, *report.ContestID)
+		return err == nil && contest.IsEditor(user)
+	}
+	if report.ProblemID != nil {
+		problem, err := s.Problem(ctx, *report.ProblemID)
+		return err == nil && s.IsProblemEditor(user, problem)
+	}
+	return false
+}
+
+func (s *BaseAPI) PlagiarismMatches(ctx context.Context, checkID int) ([]*kilonova.PlagiarismMatch, error) {
+	matches, err := s.db.PlagiarismMatches(ctx, checkID)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't fetch plagiarism matches: %w", err)
+	}
+	return matches, nil
+}
+
+// PlagiarismMatch returns the match with the given ID, if it belongs to the check.
+func (s *BaseAPI) PlagiarismMatch(ctx context.Context, checkID int, id int) (*kilonova.PlagiarismMatch, error) {
+	match, err := s.db.PlagiarismMatch(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't fetch plagiarism match: %w", err)
+	}
+	if match == nil || match.CheckID != checkID {
+		return nil, Statusf(404, "Plagiarism match not found")
+	}
+	return match, nil
+}
@@ -38,6 +38,12 @@ func (s *BaseAPI) UpdateProblem(ctx context.Context, id int, args kilonova.Probl
 	if args.ScoringStrategy != kilonova.ScoringTypeNone && args.ScoringStrategy != kilonova.ScoringTypeMaxSub && args.ScoringStrategy != kilonova.ScoringTypeSumSubtasks && args.ScoringStrategy != kilonova.ScoringTypeICPC {
 		return Statusf(400, "Invalid scoring strategy!")
 	}
+	if args.CheckerMode != kilonova.CheckerModeNone && !kilonova.ValidCheckerMode(args.CheckerMode) {
+		return Statusf(400, "Invalid checker mode!")
+	}
+	if args.CheckerEpsilon != nil && (*args.CheckerEpsilon < 0 || *args.CheckerEpsilon >= 1) {
+		return Statusf(400, "Checker epsilon must be between 0 and 1!")
+	}
 
 	newlyPublished, newlyRequested, err := s.db.UpdateProblem(ctx, id, args)
 	if err != nil {
@@ -1,6 +1,8 @@
 package sudoapi
 
 import (
+	"archive/zip"
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
@@ -100,6 +102,13 @@ func (s *BaseAPI) fillSubmissions(ctx context.Context, cnt int, subs []*kilonova
 		}
 	}
 
+	if look {
+		s.hideSubmissionScores(ctx, slices.DeleteFunc(slices.Clone(subs), func(sub *kilonova.Submission) bool {
+			pb, ok := problemsMap[sub.ProblemID]
+			return sub.ContestID == nil || (ok && s.IsProblemEditor(lookingUser, pb))
+		}))
+	}
+
 	return &Submissions{
 		Submissions: subs,
 		Count:       cnt,
@@ -298,14 +307,94 @@ func (s *BaseAPI) getSubmission(ctx context.Context, subid int, lookingUser *kil
 		return nil, fmt.Errorf("couldn't fetch subtasks: %w", err)
 	}
 
+	if isLooking && !rez.ProblemEditor {
+		s.hideSubmissionResults(ctx, rez)
+	}
+
 	return rez, nil
 }
 
+// hideSubmissionScores caps the scores of the submissions sent to a running contest at what their public subtasks earned.
+// Their ICPC verdicts are removed as well, since they may come from the tests of hidden subtasks
+func (s *BaseAPI) hideSubmissionScores(ctx context.Context, subs []*kilonova.Submission) {
+	ids := make([]int, 0, len(subs))
+	for _, sub := range subs {
+		if sub.ContestID != nil {
+			ids = append(ids, sub.ID)
+		}
+	}
+	if len(ids) == 0 {
+		return
+	}
+	scores, err := s.db.ShownSubmissionScores(ctx, ids)
+	if err != nil {
+		slog.WarnContext(ctx, "Couldn't get shown submission scores", slog.Any("err", err))
+		return
+	}
+	for _, sub := range subs {
+		if score, ok := scores[sub.ID]; ok {
+			sub.Score = score
+			sub.ICPCVerdict = nil
+		}
+	}
+}
+
+// hideSubmissionResults removes the results of hidden subtasks (and of the tests only they contain)
+// from a submission sent to a contest that is still running.
+func (s *BaseAPI) hideSubmissionResults(ctx context.Context, sub *FullSubmission) {
+	if sub.ContestID == nil {
+		return
+	}
+	s.hideSubmissionScores(ctx, []*kilonova.Submission{&sub.Submission})
+	contest, err := s.Contest(ctx, *sub.ContestID)
+	if err != nil || contest.Ended() {
+		return
+	}
+	subtasks, err := s.SubTasks(ctx, sub.ProblemID)
+	if err != nil {
+		slog.WarnContext(ctx, "Couldn't get problem subtasks", slog.Any("err", err))
+		return
+	}
+	hidden := make(map[int]bool)
+	for _, stk := range subtasks {
+		if !stk.Public {
+			hidden[stk.ID] = true
+		}
+	}
+	if len(hidden) == 0 {
+		return
+	}
+
+	shownSubtests := make(map[int]bool)
+	sub.SubTasks = slices.DeleteFunc(sub.SubTasks, func(stk *kilonova.SubmissionSubTask) bool {
+		if stk.SubtaskID != nil && hidden[*stk.SubtaskID] {
+			return true
+		}
+		for _, id := range stk.Subtests {
+			shownSubtests[id] = true
+		}
+		return false
+	})
+	sub.SubTests = slices.DeleteFunc(sub.SubTests, func(st *kilonova.SubTest) bool {
+		return !shownSubtests[st.ID]
+	})
+}
+
 func (s *BaseAPI) UpdateSubmission(ctx context.Context, id int, status kilonova.SubmissionUpdate) error {
 	if err := s.db.UpdateSubmission(ctx, id, status); err != nil {
 		slog.WarnContext(ctx, "Couldn't update submission", slog.Any("err", err), slog.Int("subID", id))
 		return fmt.Errorf("couldn't update submission: %w", err)
 	}
+	// The submission is only read back for live updates and, once it finishes, for webhooks
+	if status.Status != kilonova.StatusFinished && !s.liveSubscribed(SubmissionTopic(id)) && !s.liveSubscribed(submissionEditorTopic(id)) {
+		return nil
+	}
+	if sub, err := s.RawSubmission(ctx, id); err == nil {
+		s.publishSubmissionUpdate(ctx, sub)
+		if sub.Status == kilonova.StatusFinished {
+			s.dispatchWebhookEvent(ctx, WebhookSubmissionFinished, []int{sub.UserID}, sub.ContestID, sub)
+		}
+	}
 	return nil
 }
 
@@ -316,11 +405,26 @@ func (s *BaseAPI) RemainingSubmissionCount(ctx context.Context, contest *kilonov
 	if contest.MaxSubs < 0 {
 		return 1, false, nil
 	}
-	cnt, err := s.db.SubmissionCount(ctx, kilonova.SubmissionFilter{
+	filter := kilonova.SubmissionFilter{
 		ContestID: &contest.ID,
 		ProblemID: &problemID,
 		UserID:    &userID,
-	}, -1)
+	}
+	if contest.Ended() {
+		// Virtual participations have the same limit as the original contest, while upsolving has none
+		vp := s.runningVirtualParticipation(ctx, contest, userID)
+		if vp == nil {
+			return 1, false, nil
+		}
+		filter.ContestID = nil
+		filter.VirtualParticipationID = &vp.ID
+	}
+	// In team contests, the limit is shared by the team
+	if teamID := s.contestTeamID(ctx, contest, userID); teamID != nil {
+		filter.UserID = nil
+		filter.TeamID = teamID
+	}
+	cnt, err := s.db.SubmissionCount(ctx, filter, -1)
 	if err != nil {
 		return -1, true, fmt.Errorf("couldn't get submission count: %w", err)
 	}
@@ -343,7 +447,12 @@ func (s *BaseAPI) CreateSubmission(ctx context.Context, author *kilonova.UserFul
 	if problem == nil {
 		return -1, Statusf(400, "Invalid submission problem")
 	}
-	if len(code) > problem.SourceSize { // Maximum admitted by problem
+	if problem.TaskType == kilonova.TaskTypeOutputOnly {
+		// Output archives are not source code, they have their own limit
+		if len(code) > flags.OutputOnlyArchiveSize.Value() {
+			return -1, Statusf(400, "Output archive exceeds %d bytes", flags.OutputOnlyArchiveSize.Value())
+		}
+	} else if len(code) > problem.SourceSize { // Maximum admitted by problem
 		return -1, Statusf(400, "Code exceeds %d characters", problem.SourceSize)
 	}
 	if !s.IsProblemVisible(author.Brief(), problem) {
@@ -383,13 +492,28 @@ func (s *BaseAPI) CreateSubmission(ctx context.Context, author *kilonova.UserFul
 		}
 	}
 
+	var subContest db.SubmissionContest
 	if contestID != nil {
 		contest, err := s.Contest(ctx, *contestID)
 		if err != nil || !s.IsContestVisible(author.Brief(), contest) {
 			return -1, Statusf(404, "Couldn't find contest")
 		}
-		if !s.CanSubmitInContest(author.Brief(), contest) {
-			return -1, Statusf(400, "Submitter cannot submit to contest")
+		if contest.Ended() {
+			// Post-contest submissions are recorded separately, so that they never count towards the contest
+			if !s.CanSubmitAfterContest(ctx, author.Brief(), contest) {
+				return -1, Statusf(400, "Submitter cannot submit to contest")
+			}
+			subContest.UpsolveContestID = &contest.ID
+			if vp := s.runningVirtualParticipation(ctx, contest, author.ID); vp != nil {
+				subContest.VirtualParticipationID = &vp.ID
+			}
+		} else {
+			if !s.CanSubmitInContest(author.Brief(), contest) {
+				return -1, Statusf(400, "Submitter cannot submit to contest")
+			}
+			subContest.ContestID = &contest.ID
+			// In team contests, the submission is credited to the author's team
+			subContest.TeamID = s.contestTeamID(ctx, contest, author.ID)
 		}
 		if pb, err := s.ContestProblem(ctx, contest, author.Brief(), problem.ID); err != nil || pb == nil {
 			return -1, Statusf(400, "Problem is not in contest")
@@ -401,7 +525,7 @@ func (s *BaseAPI) CreateSubmission(ctx context.Context, author *kilonova.UserFul
 		if cnt <= 0 {
 			return -1, Statusf(http.StatusTooManyRequests, "Max submission count for problem reached")
 		}
-		if !contest.IsTester(author.Brief()) && contest.SubmissionCooldown > 0 {
+		if !contest.IsTester(author.Brief()) && contest.SubmissionCooldown > 0 && subContest.UpsolveContestID == nil {
 			t, err := s.LastSubmissionTime(ctx, kilonova.SubmissionFilter{
 				ContestID: &contest.ID,
 				UserID:    &author.ID,
@@ -438,6 +562,11 @@ func (s *BaseAPI) CreateSubmission(ctx context.Context, author *kilonova.UserFul
 	if lang.InternalName() == "java" && codeFilename != "" {
 		fname = codeFilename
 	}
+	if problem.TaskType == kilonova.TaskTypeOutputOnly {
+		if _, err := zip.NewReader(bytes.NewReader(code), int64(len(code))); err != nil {
+			return -1, Statusf(400, "Output only submissions must be a zip archive of test outputs")
+		}
+	}
 
 	files := []db.SubmissionUploadFile{
 		{
@@ -453,7 +582,7 @@ func (s *BaseAPI) CreateSubmission(ctx context.Context, author *kilonova.UserFul
 	}
 
 	// Add submission
-	id, err := s.db.CreateSubmission(ctx, author.ID, problem, lang.InternalName(), files, contestID)
+	id, err := s.db.CreateSubmission(ctx, author.ID, problem, lang.InternalName(), files, subContest)
 	if err != nil {
 		slog.WarnContext(ctx, "Couldn't create submission", slog.Any("err", err))
 		return -1, fmt.Errorf("couldn't create submission")
@@ -506,6 +635,10 @@ func (s *BaseAPI) subVisibleRegardless(ctx context.Context, sub *kilonova.Submis
 		return true
 	}
 
+	if s.isTeamSubmission(ctx, sub, user) {
+		return true
+	}
+
 	if subProblem != nil && s.IsProblemEditor(user, subProblem) {
 		return true
 	}
@@ -559,8 +692,8 @@ func (s *BaseAPI) isSubmissionVisible(ctx context.Context, sub *kilonova.Submiss
 					// If submission not in one of the contests mentioned, block
 					return false
 				}
-				if sub.UserID == userBrief.ID {
-					// If submission is from the user themselves, allow
+				if sub.UserID == userBrief.ID || s.isTeamSubmission(ctx, sub, userBrief) {
+					// If submission is from the user themselves (or their team), allow
 					return true
 				}
 				// Otherwise, block
@@ -0,0 +1,99 @@
+package sudoapi
+
+import (
+	"testing"
+	"time"
+
+	"github.com/KiloProjects/kilonova"
+	"github.com/KiloProjects/kilonova/db"
+	"github.com/shopspring/decimal"
+)
+
+func TestHiddenSubtaskScores(t *testing.T) {
+	base := newTestAPI(t)
+	ctx := t.Context()
+	contest, pb, admin := newTestContest(t, base)
+	alice := newTestUser(t, base, "alice")
+	if err := base.RegisterContestUser(ctx, contest, alice.ID, nil, nil, true); err != nil {
+		t.Fatal(err)
+	}
+
+	// A public subtask worth 40 points and a hidden one worth 60
+	for i, public := range []bool{true, false} {
+		test := &kilonova.Test{ProblemID: pb.ID, VisibleID: i + 1}
+		if err := base.CreateTest(ctx, test); err != nil {
+			t.Fatal(err)
+		}
+		if err := base.CreateSubTask(ctx, &kilonova.SubTask{
+			ProblemID: pb.ID, VisibleID: i + 1, Score: decimal.NewFromInt(40 + 20*int64(i)),
+			Tests: []int{test.ID}, Public: public,
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	subID, err := base.db.CreateSubmission(ctx, alice.ID, pb, "cpp17", []db.SubmissionUploadFile{{Filename: "main.cpp", Data: []byte("int main() {}")}}, db.SubmissionContest{ContestID: &contest.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := base.db.InitSubmission(ctx, subID); err != nil {
+		t.Fatal(err)
+	}
+	stks, err := base.db.SubmissionSubTasksBySubID(ctx, subID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, stk := range stks {
+		if err := base.db.UpdateSubmissionSubtaskPercentage(ctx, stk.ID, decimal.NewFromInt(100)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := base.db.UpdateSubmission(ctx, subID, kilonova.SubmissionUpdate{Status: kilonova.StatusFinished, Score: new(decimal.NewFromInt(100))}); err != nil {
+		t.Fatal(err)
+	}
+
+	checkScores := func(expected int64) {
+		t.Helper()
+		sub, err := base.Submission(ctx, subID, alice.Brief())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !sub.Score.Equal(decimal.NewFromInt(expected)) {
+			t.Errorf("Expected the submission page to show %d points, got %s", expected, sub.Score)
+		}
+
+		subs, err := base.Submissions(ctx, kilonova.SubmissionFilter{ContestID: &contest.ID}, true, alice.Brief())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(subs.Submissions) != 1 || !subs.Submissions[0].Score.Equal(decimal.NewFromInt(expected)) {
+			t.Errorf("Expected the submission list to show %d points, got %+v", expected, subs.Submissions)
+		}
+
+		ld, err := base.ContestLeaderboard(ctx, contest, nil, kilonova.LeaderboardFilter{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(ld.Entries) != 1 || !ld.Entries[0].TotalScore.Equal(decimal.NewFromInt(expected)) {
+			t.Errorf("Expected the leaderboard to show %d points, got %+v", expected, ld.Entries)
+		}
+	}
+
+	// While the contest runs, only the public subtask counts
+	checkScores(40)
+	sub, err := base.Submission(ctx, subID, admin.Brief())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !sub.Score.Equal(decimal.NewFromInt(100)) {
+		t.Errorf("Expected problem editors to see the full score, got %s", sub.Score)
+	}
+
+	if err := base.UpdateContest(ctx, contest.ID, kilonova.ContestUpdate{EndTime: new(time.Now().Add(-time.Minute))}); err != nil {
+		t.Fatal(err)
+	}
+	if contest, err = base.Contest(ctx, contest.ID); err != nil {
+		t.Fatal(err)
+	}
+	checkScores(100)
+}
@@ -25,11 +25,29 @@ func (s *BaseAPI) SubTests(ctx context.Context, submissionID int) ([]*kilonova.S
 	return stests, nil
 }
 
-func (s *BaseAPI) UpdateSubTest(ctx context.Context, id int, upd kilonova.SubTestUpdate) error {
+// UpdateSubTest updates the subtest with the given ID, belonging to the given submission.
+func (s *BaseAPI) UpdateSubTest(ctx context.Context, submissionID, id int, upd kilonova.SubTestUpdate) error {
 	if err := s.db.UpdateSubTest(ctx, id, upd); err != nil {
 		slog.WarnContext(ctx, "couldn't update subtest", slog.Int("subtestID", id), slog.Any("err", err))
 		return fmt.Errorf("couldn't update subtest: %w", err)
 	}
+	editors, others := s.liveSubscribed(submissionEditorTopic(submissionID)), s.liveSubscribed(SubmissionTopic(submissionID))
+	if !editors && !others {
+		return nil
+	}
+	stest, err := s.db.SubTest(ctx, id)
+	if err != nil || stest == nil {
+		return nil
+	}
+	if editors {
+		s.publishLive(ctx, submissionEditorTopic(submissionID), LiveSubTest, stest)
+	}
+	// Like on the submission page, the tests of hidden subtasks aren't shown while the contest runs
+	if others {
+		if hidden, err := s.db.SubTestHidden(ctx, id); err == nil && !hidden {
+			s.publishLive(ctx, SubmissionTopic(submissionID), LiveSubTest, stest)
+		}
+	}
 	return nil
 }
 
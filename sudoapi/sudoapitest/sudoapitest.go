@@ -0,0 +1,32 @@
+// Package sudoapitest sets up what tests need to build a BaseAPI.
+//
+// It doesn't depend on sudoapi itself, so the tests inside the sudoapi package can use it as well.
+package sudoapitest
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/KiloProjects/kilonova/db"
+	"github.com/KiloProjects/kilonova/domain/datastore"
+	"github.com/KiloProjects/kilonova/infra/postgres"
+	"github.com/KiloProjects/kilonova/infra/postgres/pgtest"
+	"github.com/spf13/afero"
+)
+
+// dataStore is shared by all tests of the process, since the buckets can only be initialized once
+var dataStore = sync.OnceValues(func() (*datastore.Manager, error) {
+	return datastore.New(afero.NewMemMapFs())
+})
+
+// Deps returns a fresh migrated database and the in-memory data store, to be passed to sudoapi.GetBaseAPI.
+// The test is skipped if no test database server is configured.
+func Deps(t testing.TB) (*postgres.DB, *datastore.Manager) {
+	t.Helper()
+	pgx := pgtest.New(t, db.Migrations)
+	mgr, err := dataStore()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pgx, mgr
+}
@@ -0,0 +1,244 @@
+package sudoapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/KiloProjects/kilonova"
+	"github.com/KiloProjects/kilonova/db"
+	"github.com/KiloProjects/kilonova/util/slicealg"
+)
+
+const maxTeamNameLength = 64
+
+// ErrTeamHasHistory is returned when deleting a team referenced by contest registrations or submissions
+var ErrTeamHasHistory = Statusf(400, "Teams that participated in contests cannot be deleted")
+
+func (s *BaseAPI) Team(ctx context.Context, id int) (*kilonova.Team, error) {
+	team, err := s.db.Team(ctx, id)
+	if err != nil || team == nil {
+		return nil, fmt.Errorf("team not found: %w", errors.Join(ErrNotFound, err))
+	}
+	return team, nil
+}
+
+func (s *BaseAPI) TeamByJoinCode(ctx context.Context, code string) (*kilonova.Team, error) {
+	team, err := s.db.TeamByJoinCode(ctx, code)
+	if err != nil || team == nil {
+		return nil, fmt.Errorf("team not found: %w", errors.Join(ErrNotFound, err))
+	}
+	return team, nil
+}
+
+// UserTeams returns the teams the user is currently a member of
+func (s *BaseAPI) UserTeams(ctx context.Context, userID int) ([]*kilonova.Team, error) {
+	teams, err := s.db.UserTeams(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't get teams: %w", err)
+	}
+	return teams, nil
+}
+
+func (s *BaseAPI) TeamMembers(ctx context.Context, team *kilonova.Team) ([]*kilonova.UserBrief, error) {
+	members, err := s.db.TeamMembers(ctx, team.ID)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't get team members: %w", err)
+	}
+	return slicealg.Map(members, (*kilonova.UserFull).Brief), nil
+}
+
+func (s *BaseAPI) IsTeamMember(ctx context.Context, team *kilonova.Team, user *kilonova.UserBrief) bool {
+	if team == nil || !user.IsAuthed() {
+		return false
+	}
+	members, err := s.TeamMembers(ctx, team)
+	if err != nil {
+		return false
+	}
+	return slices.ContainsFunc(members, func(member *kilonova.UserBrief) bool { return member.ID == user.ID })
+}
+
+// CanManageTeam returns whether the user can rename the team or remove its members.
+// The creator loses this right after leaving the team.
+func (s *BaseAPI) CanManageTeam(ctx context.Context, team *kilonova.Team, user *kilonova.UserBrief) bool {
+	if team == nil || !user.IsAuthed() {
+		return false
+	}
+	if user.IsAdmin() {
+		return true
+	}
+	return team.CreatorID != nil && *team.CreatorID == user.ID && s.IsTeamMember(ctx, team, user)
+}
+
+func validateTeamName(name string) error {
+	if name == "" || len(name) > maxTeamNameLength {
+		return Statusf(400, "Team name must be between 1 and %d characters long", maxTeamNameLength)
+	}
+	return nil
+}
+
+func (s *BaseAPI) CreateTeam(ctx context.Context, name string, creator *kilonova.UserBrief) (int, error) {
+	if !creator.IsAuthed() {
+		return -1, Statusf(400, "Invalid team creator")
+	}
+	name = strings.TrimSpace(name)
+	if err := validateTeamName(name); err != nil {
+		return -1, err
+	}
+	id, err := s.db.CreateTeam(ctx, name, creator.ID)
+	if err != nil {
+		return -1, fmt.Errorf("couldn't create team: %w", err)
+	}
+	return id, nil
+}
+
+func (s *BaseAPI) RenameTeam(ctx context.Context, team *kilonova.Team, name string) error {
+	name = strings.TrimSpace(name)
+	if err := validateTeamName(name); err != nil {
+		return err
+	}
+	if err := s.db.UpdateTeamName(ctx, team.ID, name); err != nil {
+		return fmt.Errorf("couldn't rename team: %w", err)
+	}
+	return nil
+}
+
+func (s *BaseAPI) JoinTeam(ctx context.Context, code string, user *kilonova.UserBrief) (*kilonova.Team, error) {
+	team, err := s.TeamByJoinCode(ctx, code)
+	if err != nil {
+		return nil, Statusf(404, "Invalid team code")
+	}
+	if err := s.db.AddTeamMember(ctx, team.ID, user.ID); err != nil {
+		return nil, fmt.Errorf("couldn't join team: %w", err)
+	}
+	return team, nil
+}
+
+// RemoveTeamMember removes the user from the team.
+// Teams left without members are deleted if they don't have any contest history.
+// Past and ongoing contest registrations of the user are not affected.
+func (s *BaseAPI) RemoveTeamMember(ctx context.Context, team *kilonova.Team, userID int) error {
+	if err := s.db.RemoveTeamMember(ctx, team.ID, userID); err != nil {
+		return fmt.Errorf("couldn't remove team member: %w", err)
+	}
+	members, err := s.TeamMembers(ctx, team)
+	if err != nil || len(members) > 0 {
+		return err
+	}
+	if err := s.DeleteTeam(ctx, team); err != nil && !errors.Is(err, ErrTeamHasHistory) {
+		return err
+	}
+	return nil
+}
+
+func (s *BaseAPI) DeleteTeam(ctx context.Context, team *kilonova.Team) error {
+	hasHistory, err := s.db.TeamHasContestHistory(ctx, team.ID)
+	if err != nil {
+		return fmt.Errorf("couldn't check team contest history: %w", err)
+	}
+	if hasHistory {
+		return ErrTeamHasHistory
+	}
+	if err := s.db.DeleteTeam(ctx, team.ID); err != nil {
+		return fmt.Errorf("couldn't delete team: %w", err)
+	}
+	return nil
+}
+
+// RegisterContestTeam registers all current members of the team in the contest.
+// The members are stored with the registration, later changes to the team don't affect it.
+func (s *BaseAPI) RegisterContestTeam(ctx context.Context, contest *kilonova.Contest, team *kilonova.Team, invitationID *string, categoryID *int, force bool) error {
+	if !contest.TeamContest() {
+		return Statusf(400, "Contest is not a team contest")
+	}
+
+	if !(force || s.CanJoinContest(contest) || invitationID != nil) {
+		return Statusf(400, "Regular joining is disallowed")
+	}
+
+	members, err := s.TeamMembers(ctx, team)
+	if err != nil {
+		return err
+	}
+	if len(members) == 0 {
+		return Statusf(400, "Team has no members")
+	}
+	if len(members) > contest.MaxTeamSize {
+		return Statusf(400, "Teams can have at most %d members in this contest", contest.MaxTeamSize)
+	}
+	for _, member := range members {
+		if contest.IsTester(member) {
+			return Statusf(400, "Contest staff (%s) cannot participate", member.Name)
+		}
+		reg, err := s.db.ContestRegistration(ctx, contest.ID, member.ID)
+		if err != nil {
+			return fmt.Errorf("couldn't get registration: %w", err)
+		}
+		if reg != nil {
+			return Statusf(400, "%s is already registered in this contest", member.Name)
+		}
+	}
+
+	if categoryID != nil {
+		category, err := s.ContestCategory(ctx, contest.ID, *categoryID)
+		if err != nil {
+			return err
+		}
+		if !(force || category.SelfAssignable) {
+			return Statusf(400, "This registration category can't be picked by contestants")
+		}
+	}
+
+	if err := s.db.InsertTeamRegistration(ctx, contest.ID, team.ID, slicealg.Map(members, func(member *kilonova.UserBrief) int { return member.ID }), invitationID, categoryID); err != nil {
+		if errors.Is(err, db.ErrInvitationLimit) {
+			return err
+		}
+		return fmt.Errorf("couldn't register team for contest: %w", err)
+	}
+	return nil
+}
+
+// ContestTeams returns the teams registered in the contest
+func (s *BaseAPI) ContestTeams(ctx context.Context, contestID int) ([]*kilonova.Team, error) {
+	teams, err := s.db.ContestTeams(ctx, contestID)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't get contest teams: %w", err)
+	}
+	return teams, nil
+}
+
+// ContestTeamMembers returns the members of the team, as registered in the contest
+func (s *BaseAPI) ContestTeamMembers(ctx context.Context, contestID, teamID int) ([]*kilonova.UserBrief, error) {
+	members, err := s.db.ContestTeamMembers(ctx, contestID, teamID)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't get contest team members: %w", err)
+	}
+	return slicealg.Map(members, (*kilonova.UserFull).Brief), nil
+}
+
+// contestTeamID returns the team the user is registered with in a team contest, if any
+func (s *BaseAPI) contestTeamID(ctx context.Context, contest *kilonova.Contest, userID int) *int {
+	if !contest.TeamContest() {
+		return nil
+	}
+	reg, err := s.db.ContestRegistration(ctx, contest.ID, userID)
+	if err != nil || reg == nil {
+		return nil
+	}
+	return reg.TeamID
+}
+
+// isTeamSubmission returns whether the submission was credited to the team the user was registered with
+func (s *BaseAPI) isTeamSubmission(ctx context.Context, sub *kilonova.Submission, user *kilonova.UserBrief) bool {
+	if sub.TeamID == nil || sub.ContestID == nil || !user.IsAuthed() {
+		return false
+	}
+	reg, err := s.db.ContestRegistration(ctx, *sub.ContestID, user.ID)
+	if err != nil || reg == nil || reg.TeamID == nil {
+		return false
+	}
+	return *reg.TeamID == *sub.TeamID
+}
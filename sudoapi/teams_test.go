@@ -0,0 +1,212 @@
+package sudoapi
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/KiloProjects/kilonova"
+	"github.com/KiloProjects/kilonova/db"
+	"github.com/shopspring/decimal"
+)
+
+// newTestTeamContest creates a running team contest with a single problem. The first user created is an admin.
+func newTestTeamContest(t *testing.T, base *BaseAPI) (*kilonova.Contest, *kilonova.Problem, *kilonova.UserFull) {
+	t.Helper()
+	ctx := t.Context()
+	contest, pb, admin := newTestContest(t, base)
+	if err := base.UpdateContest(ctx, contest.ID, kilonova.ContestUpdate{MaxTeamSize: new(3)}); err != nil {
+		t.Fatal(err)
+	}
+	contest, err := base.Contest(ctx, contest.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return contest, pb, admin
+}
+
+func newTestTeam(t *testing.T, base *BaseAPI, name string, creator *kilonova.UserFull, others ...*kilonova.UserFull) *kilonova.Team {
+	t.Helper()
+	ctx := t.Context()
+	id, err := base.CreateTeam(ctx, name, creator.Brief())
+	if err != nil {
+		t.Fatal(err)
+	}
+	team, err := base.Team(ctx, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, user := range others {
+		if _, err := base.JoinTeam(ctx, team.JoinCode, user.Brief()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return team
+}
+
+// newTestContestSubmission inserts a finished submission, skipping evaluation
+func newTestContestSubmission(t *testing.T, base *BaseAPI, author *kilonova.UserFull, pb *kilonova.Problem, contest *kilonova.Contest, teamID *int, score int64) int {
+	t.Helper()
+	ctx := t.Context()
+	id, err := base.db.CreateSubmission(ctx, author.ID, pb, "cpp17", []db.SubmissionUploadFile{{Filename: "main.cpp", Data: []byte("int main() {}")}}, db.SubmissionContest{
+		ContestID: &contest.ID,
+		TeamID:    teamID,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := base.db.UpdateSubmission(ctx, id, kilonova.SubmissionUpdate{Status: kilonova.StatusFinished, Score: new(decimal.NewFromInt(score))}); err != nil {
+		t.Fatal(err)
+	}
+	return id
+}
+
+func TestRegisterContestTeamInvitation(t *testing.T) {
+	base := newTestAPI(t)
+	ctx := t.Context()
+	contest, _, admin := newTestTeamContest(t, base)
+	alice, bob, carol := newTestUser(t, base, "alice"), newTestUser(t, base, "bob"), newTestUser(t, base, "carol")
+	teamA := newTestTeam(t, base, "Team A", alice, bob)
+	teamB := newTestTeam(t, base, "Team B", carol)
+
+	invID, err := base.CreateContestInvitation(ctx, contest.ID, admin.Brief(), new(1), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := base.RegisterContestTeam(ctx, contest, teamA, &invID, nil, true); err != nil {
+		t.Fatal(err)
+	}
+
+	inv, err := base.ContestInvitation(ctx, invID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if inv.RedeemCount != 1 {
+		t.Errorf("Expected a two member team to redeem the invitation once, got %d redeems", inv.RedeemCount)
+	}
+	for _, user := range []*kilonova.UserFull{alice, bob} {
+		reg, err := base.ContestRegistration(ctx, contest.ID, user.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if reg.TeamID == nil || *reg.TeamID != teamA.ID {
+			t.Errorf("Expected %s to be registered with team %d, got %v", user.Name, teamA.ID, reg.TeamID)
+		}
+	}
+
+	if err := base.RegisterContestTeam(ctx, contest, teamB, &invID, nil, true); !errors.Is(err, db.ErrInvitationLimit) {
+		t.Errorf("Expected the used up invitation to be rejected, got %v", err)
+	}
+	if _, err := base.ContestRegistration(ctx, contest.ID, carol.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected carol not to be registered, got %v", err)
+	}
+
+	// Team members can't be registered twice
+	if err := base.RegisterContestTeam(ctx, contest, newTestTeam(t, base, "Team C", bob), nil, nil, true); err == nil {
+		t.Error("Expected registering a member of another registered team to fail")
+	}
+}
+
+func TestCanManageTeam(t *testing.T) {
+	base := newTestAPI(t)
+	ctx := t.Context()
+	admin := newTestUser(t, base, "admin")
+	alice, bob := newTestUser(t, base, "alice"), newTestUser(t, base, "bob")
+	team := newTestTeam(t, base, "Team", alice, bob)
+
+	if !base.CanManageTeam(ctx, team, alice.Brief()) {
+		t.Error("Creator should manage the team")
+	}
+	if base.CanManageTeam(ctx, team, bob.Brief()) {
+		t.Error("Regular members shouldn't manage the team")
+	}
+	if !base.CanManageTeam(ctx, team, admin.Brief()) {
+		t.Error("Admins should manage the team")
+	}
+
+	if err := base.RemoveTeamMember(ctx, team, alice.ID); err != nil {
+		t.Fatal(err)
+	}
+	if base.CanManageTeam(ctx, team, alice.Brief()) {
+		t.Error("Creator shouldn't manage the team after leaving it")
+	}
+}
+
+func TestTeamLeaderboard(t *testing.T) {
+	base := newTestAPI(t)
+	ctx := t.Context()
+	contest, pb, _ := newTestTeamContest(t, base)
+	alice, bob, carol := newTestUser(t, base, "alice"), newTestUser(t, base, "bob"), newTestUser(t, base, "carol")
+	teamA := newTestTeam(t, base, "Team A", alice, bob)
+	teamB := newTestTeam(t, base, "Team B", carol)
+	for _, team := range []*kilonova.Team{teamA, teamB} {
+		if err := base.RegisterContestTeam(ctx, contest, team, nil, nil, true); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	newTestContestSubmission(t, base, alice, pb, contest, &teamA.ID, 40)
+	newTestContestSubmission(t, base, bob, pb, contest, &teamA.ID, 70)
+	newTestContestSubmission(t, base, carol, pb, contest, &teamB.ID, 50)
+
+	ld, err := base.ContestLeaderboard(ctx, contest, nil, kilonova.LeaderboardFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ld.Entries) != 2 {
+		t.Fatalf("Expected one entry per team, got %d entries", len(ld.Entries))
+	}
+	for i, expected := range []struct {
+		team  *kilonova.Team
+		score int64
+	}{{teamA, 70}, {teamB, 50}} {
+		entry := ld.Entries[i]
+		if entry.Team == nil || entry.Team.ID != expected.team.ID {
+			t.Errorf("Entry %d: expected team %s, got %+v", i, expected.team.Name, entry.Team)
+		}
+		if !entry.TotalScore.Equal(decimal.NewFromInt(expected.score)) {
+			t.Errorf("Entry %d: expected score %d, got %s", i, expected.score, entry.TotalScore)
+		}
+		if entry.Rank == nil || *entry.Rank != i+1 {
+			t.Errorf("Entry %d: expected rank %d, got %v", i, i+1, entry.Rank)
+		}
+	}
+	if len(ld.Entries[0].Members) != 2 {
+		t.Errorf("Expected team A to list 2 members, got %d", len(ld.Entries[0].Members))
+	}
+}
+
+func TestTeamSubmissionVisibility(t *testing.T) {
+	base := newTestAPI(t)
+	ctx := t.Context()
+	contest, pb, _ := newTestTeamContest(t, base)
+	alice, bob, carol := newTestUser(t, base, "alice"), newTestUser(t, base, "bob"), newTestUser(t, base, "carol")
+	teamA := newTestTeam(t, base, "Team A", alice, bob)
+	teamB := newTestTeam(t, base, "Team B", carol)
+	for _, team := range []*kilonova.Team{teamA, teamB} {
+		if err := base.RegisterContestTeam(ctx, contest, team, nil, nil, true); err != nil {
+			t.Fatal(err)
+		}
+	}
+	subID := newTestContestSubmission(t, base, alice, pb, contest, &teamA.ID, 100)
+
+	for _, tc := range []struct {
+		user    *kilonova.UserFull
+		visible bool
+	}{{alice, true}, {bob, true}, {carol, false}} {
+		sub, err := base.db.SubmissionLookingUser(ctx, subID, tc.user.Brief())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if visible := sub != nil; visible != tc.visible {
+			t.Errorf("Submission visible to %s: expected %t, got %t", tc.user.Name, tc.visible, visible)
+		}
+	}
+
+	// Members that left the team keep seeing the submissions sent during the contest they were registered for
+	if err := base.RemoveTeamMember(ctx, teamA, bob.ID); err != nil {
+		t.Fatal(err)
+	}
+	if sub, err := base.db.SubmissionLookingUser(ctx, subID, bob.Brief()); err != nil || sub == nil {
+		t.Errorf("Expected the submission to stay visible to bob after leaving the team, got %v, %v", sub, err)
+	}
+}
@@ -369,7 +369,7 @@ func (s *BaseAPI) GenerateUserFlow(ctx context.Context, args UserGenerationReque
 	}
 
 	if contest != nil {
-		if err := s.RegisterContestUser(ctx, contest, userFull.ID, nil, true); err != nil {
+		if err := s.RegisterContestUser(ctx, contest, userFull.ID, nil, nil, true); err != nil {
 			return args.Password, userFull, err
 		}
 	}
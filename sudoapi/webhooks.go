@@ -0,0 +1,378 @@
+package sudoapi
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"slices"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/KiloProjects/kilonova"
+	"github.com/KiloProjects/kilonova/db"
+	"github.com/KiloProjects/kilonova/sudoapi/flags"
+	"github.com/google/uuid"
+)
+
+type Webhook = db.Webhook
+
+type WebhookEvent string
+
+const (
+	// WebhookSubmissionFinished is sent to the submission author and to the submission's contest
+	WebhookSubmissionFinished WebhookEvent = "submission_finished"
+	// WebhookContestStarted and WebhookContestEnded are only sent to contest webhooks
+	WebhookContestStarted WebhookEvent = "contest_started"
+	WebhookContestEnded   WebhookEvent = "contest_ended"
+	// WebhookQuestionAsked is only sent to contest webhooks
+	WebhookQuestionAsked WebhookEvent = "question_asked"
+	// WebhookQuestionAnswered is sent to the question author and to the contest
+	WebhookQuestionAnswered WebhookEvent = "question_answered"
+	// WebhookAnnouncementCreated is only sent to contest webhooks
+	WebhookAnnouncementCreated WebhookEvent = "announcement_created"
+	// WebhookProblemPublished is sent to the problem editors
+	WebhookProblemPublished WebhookEvent = "problem_published"
+)
+
+var WebhookEvents = []WebhookEvent{
+	WebhookSubmissionFinished,
+	WebhookContestStarted,
+	WebhookContestEnded,
+	WebhookQuestionAsked,
+	WebhookQuestionAnswered,
+	WebhookAnnouncementCreated,
+	WebhookProblemPublished,
+}
+
+const (
+	maxWebhooksPerOwner = 10
+	// webhookAttempts is the number of delivery attempts, with exponential backoff between them
+	webhookAttempts       = 6
+	webhookInitialBackoff = 10 * time.Second
+	// webhooks are disabled after this many consecutive failed deliveries
+	webhookMaxFailures = 20
+)
+
+// WebhookPayload is the JSON body of webhook requests.
+//
+// Requests are signed with the webhook secret: the X-Kilonova-Signature header is "sha256=" followed by
+// the hex-encoded HMAC-SHA256 of the X-Kilonova-Timestamp header, a dot and the request body.
+// Receivers should check the signature and reject old timestamps, to prevent replays.
+//
+// Failed deliveries are retried from memory, so the retries still pending when the server restarts are lost.
+type WebhookPayload struct {
+	ID        uuid.UUID    `json:"id"`
+	Event     WebhookEvent `json:"event"`
+	CreatedAt time.Time    `json:"created_at"`
+	ContestID *int         `json:"contest_id,omitempty"`
+	Data      any          `json:"data"`
+}
+
+// CreateWebhook creates an outgoing webhook and returns its ID and signing secret.
+// If contestID is nil, the webhook receives the events of the owner, otherwise the events of the contest.
+func (s *BaseAPI) CreateWebhook(ctx context.Context, ownerID int, contestID *int, rawURL string, events []WebhookEvent) (int, string, error) {
+	if !flags.WebhooksEnabled.Value() {
+		return -1, "", Statusf(403, "Webhooks are disabled")
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return -1, "", Statusf(400, "Invalid webhook URL")
+	}
+	if len(events) == 0 {
+		return -1, "", Statusf(400, "Webhooks must be subscribed to at least one event")
+	}
+	strEvents := make([]string, 0, len(events))
+	for _, event := range events {
+		if !slices.Contains(WebhookEvents, event) {
+			return -1, "", Statusf(400, "Unknown webhook event %q", event)
+		}
+		strEvents = append(strEvents, string(event))
+	}
+
+	var existing []*Webhook
+	if contestID != nil {
+		existing, err = s.db.ContestWebhooks(ctx, *contestID)
+	} else {
+		existing, err = s.db.UserWebhooks(ctx, ownerID)
+	}
+	if err != nil {
+		return -1, "", fmt.Errorf("couldn't get existing webhooks: %w", err)
+	}
+	if len(existing) >= maxWebhooksPerOwner {
+		return -1, "", Statusf(400, "There can be at most %d webhooks", maxWebhooksPerOwner)
+	}
+
+	secret := kilonova.RandomString(32)
+	id, err := s.db.CreateWebhook(ctx, ownerID, contestID, u.String(), secret, strEvents)
+	if err != nil {
+		slog.WarnContext(ctx, "Couldn't create webhook", slog.Any("err", err))
+		return -1, "", fmt.Errorf("couldn't create webhook: %w", err)
+	}
+	return id, secret, nil
+}
+
+func (s *BaseAPI) Webhook(ctx context.Context, id int) (*Webhook, error) {
+	hook, err := s.db.Webhook(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't get webhook: %w", err)
+	}
+	if hook == nil {
+		return nil, fmt.Errorf("couldn't get webhook: %w", ErrNotFound)
+	}
+	return hook, nil
+}
+
+func (s *BaseAPI) UserWebhooks(ctx context.Context, userID int) ([]*Webhook, error) {
+	hooks, err := s.db.UserWebhooks(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't get webhooks: %w", err)
+	}
+	return hooks, nil
+}
+
+func (s *BaseAPI) ContestWebhooks(ctx context.Context, contestID int) ([]*Webhook, error) {
+	hooks, err := s.db.ContestWebhooks(ctx, contestID)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't get webhooks: %w", err)
+	}
+	return hooks, nil
+}
+
+func (s *BaseAPI) DeleteWebhook(ctx context.Context, id int) error {
+	if err := s.db.DeleteWebhook(ctx, id); err != nil {
+		return fmt.Errorf("couldn't delete webhook: %w", err)
+	}
+	return nil
+}
+
+// dispatchWebhookEvent asynchronously delivers the event to the webhooks of the given users and contest.
+func (s *BaseAPI) dispatchWebhookEvent(ctx context.Context, event WebhookEvent, userIDs []int, contestID *int, data any) {
+	if !flags.WebhooksEnabled.Value() {
+		return
+	}
+	ctx = context.WithoutCancel(ctx)
+	go func() {
+		hooks, err := s.db.EventWebhooks(ctx, string(event), userIDs, contestID)
+		if err != nil {
+			slog.WarnContext(ctx, "Couldn't get webhooks for event", slog.Any("event", event), slog.Any("err", err))
+			return
+		}
+		if len(hooks) == 0 {
+			return
+		}
+		body, err := json.Marshal(WebhookPayload{
+			ID:        uuid.New(),
+			Event:     event,
+			CreatedAt: time.Now(),
+			ContestID: contestID,
+			Data:      data,
+		})
+		if err != nil {
+			slog.WarnContext(ctx, "Couldn't marshal webhook payload", slog.Any("event", event), slog.Any("err", err))
+			return
+		}
+		for _, hook := range hooks {
+			go s.deliverWebhook(ctx, hook, event, body)
+		}
+	}()
+}
+
+// deliverWebhook sends the payload, retrying with exponential backoff on network errors and non-2xx responses
+func (s *BaseAPI) deliverWebhook(ctx context.Context, hook *Webhook, event WebhookEvent, body []byte) {
+	status, deliveryErr := retryWebhook(ctx, webhookInitialBackoff, func() (*int, error) {
+		status, err := sendWebhook(ctx, webhookClient, hook, event, body)
+		if err != nil {
+			slog.DebugContext(ctx, "Webhook delivery failed", slog.Int("webhook_id", hook.ID), slog.Any("err", err))
+		}
+		return status, err
+	})
+	if ctx.Err() != nil {
+		return
+	}
+
+	var errStr *string
+	if deliveryErr != nil {
+		errStr = new(deliveryErr.Error())
+	}
+	if err := s.db.MarkWebhookDelivery(ctx, hook.ID, status, errStr, webhookMaxFailures); err != nil {
+		slog.WarnContext(ctx, "Couldn't save webhook delivery", slog.Int("webhook_id", hook.ID), slog.Any("err", err))
+	}
+}
+
+// retryWebhook calls send until it succeeds, at most webhookAttempts times, doubling the wait between attempts.
+// It returns the result of the last attempt.
+func retryWebhook(ctx context.Context, backoff time.Duration, send func() (*int, error)) (*int, error) {
+	var status *int
+	var err error
+	for attempt := range webhookAttempts {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return status, ctx.Err()
+			case <-time.After(backoff):
+			}
+			backoff *= 2
+		}
+		status, err = send()
+		if err == nil {
+			return status, nil
+		}
+	}
+	return status, err
+}
+
+func sendWebhook(ctx context.Context, client *http.Client, hook *Webhook, event WebhookEvent, body []byte) (*int, error) {
+	ctx, cancel := context.WithTimeout(ctx, 15*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, hook.URL, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "Kilonova-Webhooks")
+	req.Header.Set("X-Kilonova-Event", string(event))
+	req.Header.Set("X-Kilonova-Timestamp", timestamp)
+	req.Header.Set("X-Kilonova-Signature", "sha256="+SignWebhook(hook.Secret, timestamp, body))
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return &resp.StatusCode, fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+	return &resp.StatusCode, nil
+}
+
+// SignWebhook returns the hex-encoded signature of a webhook request
+func SignWebhook(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte{'.'})
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+var errPrivateAddress = errors.New("webhooks can't be sent to private addresses")
+
+// blockedWebhookPrefixes are the internal ranges that IsPrivate doesn't cover
+var blockedWebhookPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("100.64.0.0/10"), // Carrier-grade NAT (RFC 6598)
+}
+
+// webhookAddressAllowed reports whether webhooks may connect to addr
+func webhookAddressAllowed(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if !addr.IsGlobalUnicast() || addr.IsPrivate() {
+		return false
+	}
+	for _, prefix := range blockedWebhookPrefixes {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+	return true
+}
+
+// webhookDialControl refuses connections to loopback, private, link-local and CGNAT addresses (outside of debug mode),
+// so webhooks can't be used to reach internal services. The check happens at dial time to also cover DNS rebinding.
+func webhookDialControl(_, address string, _ syscall.RawConn) error {
+	if kilonova.DebugMode() {
+		return nil
+	}
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return err
+	}
+	if !webhookAddressAllowed(addrPort.Addr()) {
+		return errPrivateAddress
+	}
+	return nil
+}
+
+var webhookClient = &http.Client{
+	Timeout: 15 * time.Second,
+	Transport: &http.Transport{
+		Proxy: nil,
+		DialContext: (&net.Dialer{
+			Timeout: 5 * time.Second,
+			Control: webhookDialControl,
+		}).DialContext,
+		MaxIdleConns:        20,
+		IdleConnTimeout:     90 * time.Second,
+		TLSHandshakeTimeout: 10 * time.Second,
+	},
+	CheckRedirect: func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	},
+}
+
+func (s *BaseAPI) contestWebhooksJob(ctx context.Context, interval time.Duration) error {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	lastCheck := time.Now()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case now := <-t.C:
+			if !flags.WebhooksEnabled.Value() {
+				lastCheck = now
+				continue
+			}
+			started, ended, err := s.db.WebhookContestsBetween(ctx, lastCheck, now)
+			if err != nil {
+				slog.WarnContext(ctx, "Couldn't check contest webhook events", slog.Any("err", err))
+				continue
+			}
+			lastCheck = now
+			for _, id := range started {
+				s.announceContestTransition(ctx, id, WebhookContestStarted)
+			}
+			for _, id := range ended {
+				s.announceContestTransition(ctx, id, WebhookContestEnded)
+			}
+		}
+	}
+}
+
+func (s *BaseAPI) announceContestTransition(ctx context.Context, contestID int, event WebhookEvent) {
+	contest, err := s.Contest(ctx, contestID)
+	if err != nil {
+		slog.WarnContext(ctx, "Couldn't get contest for webhook", slog.Int("contest_id", contestID), slog.Any("err", err))
+		return
+	}
+	s.dispatchWebhookEvent(ctx, event, nil, &contest.ID, contest)
+}
+
+func (s *BaseAPI) announceProblemPublishedWebhook(ctx context.Context, problemID int) {
+	problem, err := s.Problem(ctx, problemID)
+	if err != nil {
+		slog.WarnContext(ctx, "Couldn't get problem for webhook", slog.Int("problem_id", problemID), slog.Any("err", err))
+		return
+	}
+	editors, err := s.ProblemEditors(ctx, problemID)
+	if err != nil {
+		slog.WarnContext(ctx, "Couldn't get problem editors for webhook", slog.Int("problem_id", problemID), slog.Any("err", err))
+		return
+	}
+	editorIDs := make([]int, 0, len(editors))
+	for _, editor := range editors {
+		editorIDs = append(editorIDs, editor.ID)
+	}
+	s.dispatchWebhookEvent(ctx, WebhookProblemPublished, editorIDs, nil, problem)
+}
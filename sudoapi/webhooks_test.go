@@ -0,0 +1,230 @@
+package sudoapi
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+	"time"
+)
+
+func TestSendWebhookSignature(t *testing.T) {
+	const secret = "s3cret"
+	body := []byte(`{"event":"contest_started"}`)
+
+	var gotEvent, gotTimestamp, gotSignature string
+	var gotBody []byte
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotEvent = r.Header.Get("X-Kilonova-Event")
+		gotTimestamp = r.Header.Get("X-Kilonova-Timestamp")
+		gotSignature = r.Header.Get("X-Kilonova-Signature")
+		gotBody, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	status, err := sendWebhook(t.Context(), srv.Client(), &Webhook{URL: srv.URL, Secret: secret}, WebhookContestStarted, body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status == nil || *status != http.StatusNoContent {
+		t.Fatalf("Expected status 204, got %v", status)
+	}
+	if gotEvent != string(WebhookContestStarted) {
+		t.Errorf("Expected event header %q, got %q", WebhookContestStarted, gotEvent)
+	}
+	if string(gotBody) != string(body) {
+		t.Errorf("Expected body %q, got %q", body, gotBody)
+	}
+
+	// Verify the signature the way a receiver would
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(gotTimestamp + "." + string(gotBody)))
+	if expected := "sha256=" + hex.EncodeToString(mac.Sum(nil)); gotSignature != expected {
+		t.Errorf("Expected signature %q, got %q", expected, gotSignature)
+	}
+	if gotSignature != "sha256="+SignWebhook(secret, gotTimestamp, gotBody) {
+		t.Error("SignWebhook doesn't match the sent signature")
+	}
+}
+
+func TestSendWebhookErrorStatus(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+
+	status, err := sendWebhook(t.Context(), srv.Client(), &Webhook{URL: srv.URL}, WebhookContestStarted, []byte("{}"))
+	if err == nil {
+		t.Error("Expected an error for a 500 response")
+	}
+	if status == nil || *status != http.StatusInternalServerError {
+		t.Errorf("Expected status 500, got %v", status)
+	}
+}
+
+func TestWebhookAddressAllowed(t *testing.T) {
+	tests := []struct {
+		addr    string
+		allowed bool
+	}{
+		{"127.0.0.1", false},
+		{"::1", false},
+		{"::ffff:127.0.0.1", false},
+		{"0.0.0.0", false},
+		{"10.1.2.3", false},
+		{"172.16.0.1", false},
+		{"172.31.255.255", false},
+		{"192.168.1.1", false},
+		{"100.64.0.1", false},
+		{"100.127.255.254", false},
+		{"169.254.169.254", false},
+		{"fd00::1", false},
+		{"fe80::1", false},
+		{"ff02::1", false},
+		{"93.184.216.34", true},
+		{"100.128.0.1", true},
+		{"172.32.0.1", true},
+		{"2606:4700::1", true},
+	}
+	for _, test := range tests {
+		if got := webhookAddressAllowed(netip.MustParseAddr(test.addr)); got != test.allowed {
+			t.Errorf("webhookAddressAllowed(%s) = %t, expected %t", test.addr, got, test.allowed)
+		}
+	}
+}
+
+func TestWebhookDialControl(t *testing.T) {
+	for _, address := range []string{"127.0.0.1:80", "[::1]:443", "10.0.0.1:8080", "100.64.1.1:80"} {
+		if err := webhookDialControl("tcp", address, nil); !errors.Is(err, errPrivateAddress) {
+			t.Errorf("Expected %s to be rejected, got %v", address, err)
+		}
+	}
+	if err := webhookDialControl("tcp", "93.184.216.34:443", nil); err != nil {
+		t.Errorf("Expected public address to be allowed, got %v", err)
+	}
+}
+
+func TestRetryWebhook(t *testing.T) {
+	t.Run("gives up after all attempts", func(t *testing.T) {
+		calls := 0
+		_, err := retryWebhook(t.Context(), time.Millisecond, func() (*int, error) {
+			calls++
+			return new(500), errors.New("server error")
+		})
+		if err == nil {
+			t.Error("Expected the last error to be returned")
+		}
+		if calls != webhookAttempts {
+			t.Errorf("Expected %d attempts, got %d", webhookAttempts, calls)
+		}
+	})
+	t.Run("stops on success", func(t *testing.T) {
+		calls := 0
+		status, err := retryWebhook(t.Context(), time.Millisecond, func() (*int, error) {
+			calls++
+			if calls < 3 {
+				return nil, errors.New("connection refused")
+			}
+			return new(200), nil
+		})
+		if err != nil || status == nil || *status != 200 {
+			t.Errorf("Expected success, got %v, %v", status, err)
+		}
+		if calls != 3 {
+			t.Errorf("Expected 3 attempts, got %d", calls)
+		}
+	})
+	t.Run("backs off exponentially", func(t *testing.T) {
+		var times []time.Time
+		retryWebhook(t.Context(), 5*time.Millisecond, func() (*int, error) {
+			times = append(times, time.Now())
+			return nil, errors.New("fail")
+		})
+		for i := 1; i < len(times); i++ {
+			minWait := 5 * time.Millisecond << (i - 1)
+			if wait := times[i].Sub(times[i-1]); wait < minWait {
+				t.Errorf("Attempt %d came after %s, expected at least %s", i+1, wait, minWait)
+			}
+		}
+	})
+	t.Run("stops when cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(t.Context())
+		calls := 0
+		_, err := retryWebhook(ctx, time.Hour, func() (*int, error) {
+			calls++
+			cancel()
+			return nil, errors.New("fail")
+		})
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("Expected context.Canceled, got %v", err)
+		}
+		if calls != 1 {
+			t.Errorf("Expected 1 attempt, got %d", calls)
+		}
+	})
+}
+
+func TestWebhookAutoDisable(t *testing.T) {
+	base := newTestAPI(t)
+	ctx := t.Context()
+	user := newTestUser(t, base, "hookowner")
+
+	id, err := base.db.CreateWebhook(ctx, user.ID, nil, "https://example.com/hook", "secret", []string{string(WebhookProblemPublished)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	failure := "server error"
+
+	for i := range webhookMaxFailures - 1 {
+		if err := base.db.MarkWebhookDelivery(ctx, id, new(500), &failure, webhookMaxFailures); err != nil {
+			t.Fatal(err)
+		}
+		hook, err := base.Webhook(ctx, id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !hook.Active || hook.ConsecutiveFailures != i+1 {
+			t.Fatalf("After %d failures: active=%t, failures=%d", i+1, hook.Active, hook.ConsecutiveFailures)
+		}
+	}
+
+	// A success resets the counter
+	if err := base.db.MarkWebhookDelivery(ctx, id, new(200), nil, webhookMaxFailures); err != nil {
+		t.Fatal(err)
+	}
+	hook, err := base.Webhook(ctx, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !hook.Active || hook.ConsecutiveFailures != 0 {
+		t.Fatalf("Expected success to reset the failures, got active=%t, failures=%d", hook.Active, hook.ConsecutiveFailures)
+	}
+
+	for range webhookMaxFailures {
+		if err := base.db.MarkWebhookDelivery(ctx, id, nil, &failure, webhookMaxFailures); err != nil {
+			t.Fatal(err)
+		}
+	}
+	hook, err = base.Webhook(ctx, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hook.Active {
+		t.Errorf("Expected webhook to be disabled after %d consecutive failures", webhookMaxFailures)
+	}
+
+	hooks, err := base.db.EventWebhooks(ctx, string(WebhookProblemPublished), []int{user.ID}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(hooks) > 0 {
+		t.Error("Disabled webhook still receives events")
+	}
+}
@@ -0,0 +1,15 @@
+package kilonova
+
+import "time"
+
+// Team is a group of users that registers together in team contests.
+// The members keep their own accounts, submissions sent during a team contest are credited to the team.
+type Team struct {
+	ID        int       `json:"id" db:"id"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	Name      string    `json:"name" db:"name"`
+	CreatorID *int      `json:"creator_id" db:"creator_id"`
+
+	// JoinCode is shared by the members in order to invite others
+	JoinCode string `json:"-" db:"join_code"`
+}
@@ -26,9 +26,12 @@ type SubTask struct {
 	VisibleID int             `json:"visible_id"`
 	Score     decimal.Decimal `json:"score"`
 	Tests     []int           `json:"tests"`
+	// Public subtasks always show their results, while the results of hidden ones are only shown to contestants after the contest ends
+	Public bool `json:"public"`
 }
 
 type SubTaskUpdate struct {
 	VisibleID *int             `json:"visible_id"`
 	Score     *decimal.Decimal `json:"score"`
+	Public    *bool            `json:"public"`
 }
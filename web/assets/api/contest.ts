@@ -2,9 +2,20 @@
 
 import { getCall, postCall } from "./client";
 import { apiToast } from "../toast";
+import { confirm } from "../components/modal";
+import getText from "../translation";
 
-export async function registerForContest(contestID: number) {
-	const res = await postCall(`/contest/${contestID}/register`, {});
+export async function registerForContest(contestID: number, categoryID?: string) {
+	const res = await postCall(`/contest/${contestID}/register`, { category_id: categoryID });
+	if (res.status === "error") {
+		apiToast(res);
+		return;
+	}
+	window.location.reload();
+}
+
+export async function registerTeamForContest(contestID: number, teamID: string, categoryID?: string) {
+	const res = await postCall(`/contest/${contestID}/registerTeam`, { team_id: teamID, category_id: categoryID });
 	if (res.status === "error") {
 		apiToast(res);
 		return;
@@ -25,6 +36,27 @@ export async function startContestRegistration(contestID: number) {
 	}
 }
 
+export async function startVirtualParticipation(contestID: number) {
+	if (!(await confirm(getText("virtual.confirm_start")))) {
+		return;
+	}
+	const res = await postCall(`/contest/${contestID}/startVirtual`, {});
+	if (res.status === "error") {
+		apiToast(res);
+		return;
+	}
+	window.location.reload();
+}
+
+export async function loadVirtualStanding(contestID: number, target: HTMLElement) {
+	const res = await getCall<{ rank: number; num_ranked: number }>(`/contest/${contestID}/virtualStanding`, {});
+	if (res.status === "error") {
+		target.innerText = "N/A";
+		return;
+	}
+	target.innerText = `${res.data.rank} / ${res.data.num_ranked + 1}`;
+}
+
 export async function answerQuestion(q: Question, text: string) {
 	let res = await postCall(`/contest/${q.contest_id}/answerQuestion`, { questionID: q.id, text });
 	apiToast(res);
@@ -112,6 +144,26 @@ export function reloadAnnouncements() {
 	document.dispatchEvent(new CustomEvent("kn-contest-announcement-reload"));
 }
 
+let contestSources = new Map<number, EventSource>();
+
+// contestEvents returns the live updates stream of a contest, shared by all components on the page.
+// It returns null if the browser doesn't support server-sent events.
+export function contestEvents(contestID: number): EventSource | null {
+	if (typeof EventSource === "undefined") {
+		return null;
+	}
+	let source = contestSources.get(contestID);
+	if (typeof source === "undefined") {
+		source = new EventSource(`/api/contest/${contestID}/events`);
+		contestSources.set(contestID, source);
+	}
+	return source;
+}
+
+export function watchAnnouncements(contestID: number) {
+	contestEvents(contestID)?.addEventListener("announcement", () => reloadAnnouncements());
+}
+
 var x: number | undefined;
 
 export function startReloadingQnA(interval_ms: number) {
@@ -78,6 +78,7 @@ declare global {
 
 		submission_type: "classic" | "acm-icpc";
 		icpc_verdict: string | null;
+		expected_verdict?: "accepted" | "wrong_answer" | "time_limit";
 	};
 	type SubTest = {
 		id: number;
@@ -91,6 +92,11 @@ declare global {
 		test_id?: number;
 		submission_id: number;
 
+		wall_time: number;
+		max_rss: number;
+		csw_voluntary: number;
+		csw_forced: number;
+
 		visible_id: number;
 		score: number;
 	};
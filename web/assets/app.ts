@@ -18,7 +18,7 @@ export { getFileIcon } from "./cdn_mgr";
 
 export { default as debounce } from "lodash-es/debounce";
 
-export { makeSubWaiter } from "./sub_waiter";
+export { makeSubWaiter, watchSubmission } from "./sub_waiter";
 
 export * from "./time";
 export * from "./lit";
@@ -4,8 +4,10 @@ import {Reducer, useEffect, useMemo, useReducer, useState} from "preact/hooks";
 import {dayjs, getGradient} from "../util";
 import getText from "../translation";
 import {fromBase64} from "js-base64";
+import debounce from "lodash-es/debounce";
 import {
 	answerQuestion,
+	contestEvents,
 	deleteAnnouncement,
 	getAllQuestions,
 	getAnnouncements,
@@ -76,12 +78,22 @@ type LeaderboardResponse = {
 		freeze_time: string | null;
 		last_times: Record<number, number>;
 		attempts: Record<number, number>; // TODO: check if will still be null once finished
+
+		category_id: number | null;
+		rank: number | null;
+
+		team?: { id: number; name: string };
+		members?: UserBrief[];
+
+		upsolve_total?: number;
 	}[];
+	categories: ContestCategory[];
 
 	advanced_filter: boolean;
 
 	freeze_time?: string;
 	type: "classic" | "acm-icpc";
+	upsolving: boolean;
 };
 
 export function ContestLeaderboard({ contestID, editor }: { contestID: number; editor: boolean }) {
@@ -91,6 +103,8 @@ export function ContestLeaderboard({ contestID, editor }: { contestID: number; e
 	let [lastUpdated, setLastUpdated] = useState<string | null>(null);
 
 	let [generated, setGenerated] = useState<boolean | null>(null);
+	// "" means every participant, "official" only official ones, otherwise a category ID
+	let [category, setCategory] = useState<string>("");
 
 	const firstSolves = useMemo(() => {
 		let firstSolves: Record<number, { minTime: number; userID: number }> = {};
@@ -113,10 +127,23 @@ export function ContestLeaderboard({ contestID, editor }: { contestID: number; e
 
 	console.log(firstSolves);
 
-	async function loadLeaderboard() {
-		setLoading(true);
+	function categoryParams(): { category_id?: string; official_only?: boolean } {
+		if (category == "") {
+			return {};
+		}
+		if (category == "official") {
+			return { official_only: true };
+		}
+		return { category_id: category };
+	}
+
+	async function loadLeaderboard(silent: boolean = false) {
+		if (!silent) {
+			setLoading(true);
+		}
 		const res = await getCall<LeaderboardResponse>(`/contest/${contestID}/leaderboard`, {
 			generated_acc: generated == null ? undefined : generated,
+			...categoryParams(),
 		});
 		if (res.status === "error") {
 			apiToast(res);
@@ -141,7 +168,21 @@ export function ContestLeaderboard({ contestID, editor }: { contestID: number; e
 
 	useEffect(() => {
 		loadLeaderboard().catch(console.error);
-	}, [contestID, generated]);
+	}, [contestID, generated, category]);
+
+	useEffect(() => {
+		const source = contestEvents(contestID);
+		if (source == null) {
+			return;
+		}
+		// Finished submissions come in bursts during contests, so reloads are batched
+		const reload = debounce(() => loadLeaderboard(true).catch(console.error), 2000, { maxWait: 10000 });
+		source.addEventListener("leaderboard", reload);
+		return () => {
+			source.removeEventListener("leaderboard", reload);
+			reload.cancel();
+		};
+	}, [contestID, generated, category]);
 
 	if (loading || leaderboard == null) {
 		return (
@@ -175,6 +216,20 @@ export function ContestLeaderboard({ contestID, editor }: { contestID: number; e
 					</select>
 				</label>
 			)}
+			{leaderboard.categories?.length > 0 && (
+				<label class="block mb-2">
+					<span class="form-label">{getText("contest_category")}:</span>
+					<select class="form-select" value={category} onChange={(e) => setCategory(e.currentTarget.value)}>
+						<option value="">{getText("participants.all")}</option>
+						<option value="official">{getText("participants.official")}</option>
+						{leaderboard.categories.map((cat) => (
+							<option value={cat.id.toString()} key={cat.id}>
+								{cat.name}
+							</option>
+						))}
+					</select>
+				</label>
+			)}
 			<div class="mb-2">
 				<p>
 					{getText("last_updated_at")}: {lastUpdated ? dayjs(lastUpdated).format("DD/MM/YYYY HH:mm") : "-"}
@@ -214,16 +269,36 @@ export function ContestLeaderboard({ contestID, editor }: { contestID: number; e
 								{getText("total")}
 							</th>
 						)}
+						{leaderboard.upsolving && (
+							<th class="kn-table-cell w-1/12" style={{ wordBreak: "break-all" }} scope="col">
+								{getText("upsolved")}
+							</th>
+						)}
 					</tr>
 				</thead>
 				<tbody>
-					{leaderboard.entries.map((entry, idx) => (
+					{leaderboard.entries.map((entry) => (
 						<tr class="kn-table-row" key={entry.user.id}>
-							<td class="kn-table-cell">{idx + 1}.</td>
+							<td class="kn-table-cell">{entry.rank != null ? `${entry.rank}.` : "-"}</td>
 							<td class="kn-table-cell">
-								<a href={`/profile/${entry.user.name}`}>
-									{entry.user.display_name.length > 0 ? `${entry.user.display_name} (${entry.user.name})` : entry.user.name}
-								</a>
+								{entry.team ? (
+									<>
+										<span class="font-semibold">{entry.team.name}</span>
+										<br />
+										<span class="text-sm">
+											{(entry.members ?? []).map((member, idx) => (
+												<>
+													{idx > 0 && ", "}
+													<a href={`/profile/${member.name}`}>{member.name}</a>
+												</>
+											))}
+										</span>
+									</>
+								) : (
+									<a href={`/profile/${entry.user.name}`}>
+										{entry.user.display_name.length > 0 ? `${entry.user.display_name} (${entry.user.name})` : entry.user.name}
+									</a>
+								)}
 							</td>
 							{leaderboard?.type == "acm-icpc" && (
 								<>
@@ -272,19 +347,41 @@ export function ContestLeaderboard({ contestID, editor }: { contestID: number; e
 								)
 							)}
 							{leaderboard?.type == "classic" && <td class="kn-table-cell">{entry.total}</td>}
+							{leaderboard.upsolving && <td class="kn-table-cell">{entry.upsolve_total ?? "-"}</td>}
 						</tr>
 					))}
 					{leaderboard.entries.length == 0 && (
 						<tr class="kn-table-row">
 							{/* TODO: Update here if header changes */}
-							<td class="kn-table-cell" colSpan={2 + (leaderboard.type === "acm-icpc" ? 2 : 1) + problems.length}>
+							<td class="kn-table-cell" colSpan={2 + (leaderboard.type === "acm-icpc" ? 2 : 1) + problems.length + (leaderboard.upsolving ? 1 : 0)}>
 								<h1>{getText("no_users")}</h1>
 							</td>
 						</tr>
 					)}
 				</tbody>
 			</table>
-			<a href={`/assets/contest/${contestID}/leaderboard.csv${generated != null ? "?generated_acc=" + generated : ""}`}>Download CSV</a>
+			<a
+				href={`/assets/contest/${contestID}/leaderboard.csv?${new URLSearchParams({
+					...(generated != null ? { generated_acc: generated.toString() } : {}),
+					...Object.fromEntries(Object.entries(categoryParams()).map(([k, v]) => [k, String(v)])),
+				}).toString()}`}
+			>
+				Download CSV
+			</a>
+			{editor && (
+				<>
+					{" | "}
+					<a href={`/assets/contest/${contestID}/event-feed.ndjson`}>CLICS event feed</a>
+					{" | "}
+					<a href={`/assets/contest/${contestID}/contest.json`}>CLICS JSON</a>
+					{leaderboard.freeze_time && (
+						<>
+							{" | "}
+							<a href={`/assets/contest/${contestID}/contest.json?frozen=true`}>CLICS JSON ({getText("frozen")})</a>
+						</>
+					)}
+				</>
+			)}
 		</>
 	);
 }
@@ -629,6 +726,15 @@ type ContestRegistration = {
 	user_id: number;
 	individual_start?: string;
 	individual_end?: string;
+	category_id?: number;
+};
+
+export type ContestCategory = {
+	id: number;
+	contest_id: number;
+	name: string;
+	official: boolean;
+	self_assignable: boolean;
 };
 
 type ContestRegRez = {
@@ -648,6 +754,7 @@ function ContestRegistrations(params: { contestid: string; usacomode: string })
 	let [numPages, setNumPages] = useState<number>(1);
 	let [cnt, setCnt] = useState<number>(-1);
 	let [name, setName] = useState<string>("");
+	let [categories, setCategories] = useState<ContestCategory[]>([]);
 
 	async function poll() {
 		let res = await getCall(`/contest/${contestID}/registrations`, { offset: 50 * (page - 1), limit: 50, name_fuzzy: name.length > 0 ? name : undefined });
@@ -665,10 +772,29 @@ function ContestRegistrations(params: { contestid: string; usacomode: string })
 		setName(newName);
 	}
 
+	async function setCategory(userID: number, categoryID: string) {
+		let res = await postCall(`/contest/${contestID}/setRegistrationCategory`, {
+			user_id: userID,
+			category_id: categoryID.length > 0 ? categoryID : undefined,
+		});
+		apiToast(res);
+		if (res.status === "success") {
+			await poll();
+		}
+	}
+
 	useEffect(() => {
 		poll().catch(console.error);
 	}, [page, name]);
 
+	useEffect(() => {
+		getCall<ContestCategory[]>(`/contest/${contestID}/categories`, {}).then((res) => {
+			if (res.status === "success") {
+				setCategories(res.data);
+			}
+		});
+	}, [contestID]);
+
 	return (
 		<div class="my-4">
 			<label class="block my-2">
@@ -709,6 +835,11 @@ function ContestRegistrations(params: { contestid: string; usacomode: string })
 									{getText("started_at")}
 								</th>
 							)}
+							{categories.length > 0 && (
+								<th class="kn-table-cell" scope="col">
+									{getText("contest_category")}
+								</th>
+							)}
 							<th class="kn-table-cell" scope="col">
 								{getText("action")}
 							</th>
@@ -739,6 +870,22 @@ function ContestRegistrations(params: { contestid: string; usacomode: string })
 										)}
 									</td>
 								)}
+								{categories.length > 0 && (
+									<td class="kn-table-cell">
+										<select
+											class="form-select"
+											value={user.registration.category_id?.toString() ?? ""}
+											onChange={(e) => setCategory(user.user.id, e.currentTarget.value).catch(console.error)}
+										>
+											<option value="">{getText("uncategorized")}</option>
+											{categories.map((cat) => (
+												<option value={cat.id.toString()} key={cat.id}>
+													{cat.name}
+												</option>
+											))}
+										</select>
+									</td>
+								)}
 								<td class="kn-table-cell">
 									<button
 										class="btn btn-red"
@@ -101,7 +101,9 @@ export function TestTable({
 									</>
 								) : subtest.done ? (
 									<>
-										<td>{Math.floor(subtest.time * 1000)} ms</td>
+										<td title={subtest.wall_time > 0 ? getText("wallTime", Math.floor(subtest.wall_time * 1000)) : undefined}>
+											{Math.floor(subtest.time * 1000)} ms
+										</td>
 										<td>{sizeFormatter(subtest.memory * 1024, 1, true)}</td>
 										<td>{testVerdictString(subtest.verdict)}</td>
 										{subType == "classic" && (
@@ -121,10 +123,11 @@ export function TestTable({
 									</>
 								) : (
 									<>
-										<td></td>
+										<td>{subtest.time > 0 && <>{Math.floor(subtest.time * 1000)} ms</>}</td>
 										<td></td>
 										<td>
-											<div class="fas fa-spinner animate-spin" role="status"></div> {getText("waiting")}
+											<div class="fas fa-spinner animate-spin" role="status"></div>{" "}
+											{subtest.verdict != "" ? testVerdictString(subtest.verdict) : getText("waiting")}
 										</td>
 										{subType == "classic" && <td>-</td>}
 									</>
@@ -1,47 +1,110 @@
+import debounce from "lodash-es/debounce";
 import { getCall } from "./api/client";
 import { formatScoreStr, icpcVerdictString } from "./components";
 import { createToast } from "./toast";
 import getText from "./translation";
 
-let loadingIDs = new Map<number, string>();
+type LiveSubmission = Pick<Submission, "id" | "status" | "score" | "score_precision" | "submission_type" | "icpc_verdict">;
 
-export function makeSubWaiter(id: number): string {
-	if (loadingIDs.has(id)) {
-		return `Will not watch ${id}`;
+let watchedIDs = new Set<number>();
+
+// Subtest updates can come in bursts, so page reloads triggered by them are throttled
+const pollSubtests = debounce(() => document.dispatchEvent(new CustomEvent("kn-poll")), 500, { maxWait: 1000 });
+
+// watchSubmission dispatches kn-poll whenever the submission changes, until it finishes.
+// Updates are received through server-sent events, falling back to polling if they are not available.
+export function watchSubmission(id: number, onUpdate: (sub: LiveSubmission) => void = () => {}): boolean {
+	if (watchedIDs.has(id)) {
+		return false;
+	}
+	watchedIDs.add(id);
+
+	let lastStatus = "";
+	// Returns true once the submission has finished
+	function update(sub: LiveSubmission): boolean {
+		if (sub.status !== lastStatus) {
+			lastStatus = sub.status;
+			document.dispatchEvent(new CustomEvent("kn-poll"));
+			onUpdate(sub);
+		}
+		return sub.status == "finished";
 	}
-	loadingIDs.set(id, "reserved");
-	let interv = setInterval(async () => {
+
+	async function fetchSubmission(): Promise<boolean> {
 		let res = await getCall<FullSubmission>("/submissions/getByID", { id: id });
 		if (res.status == "error") {
 			console.error(res);
-			return;
+			return false;
 		}
-		var lastStatus = loadingIDs.get(id);
-		if (res.data.status !== lastStatus) {
-			document.dispatchEvent(new CustomEvent("kn-poll"));
+		return update(res.data);
+	}
+
+	function poll() {
+		let interv = setInterval(async () => {
+			if (await fetchSubmission()) {
+				clearInterval(interv);
+				watchedIDs.delete(id);
+			}
+		}, 500);
+	}
+
+	if (typeof EventSource === "undefined") {
+		poll();
+		return true;
+	}
+
+	const source = new EventSource(`/api/submissions/events?id=${id}`);
+	function finish() {
+		source.close();
+		watchedIDs.delete(id);
+	}
+	source.addEventListener("open", async () => {
+		// The submission may have changed before the stream was opened
+		if (await fetchSubmission()) {
+			finish();
 		}
-		if (res.data.status == "finished") {
-			let rezStr = "";
-			let statusVal: "success" | "error" = "success";
-			if (res.data.submission_type == "classic") {
-				rezStr = getText("finalScore", id) + " " + formatScoreStr(res.data.score.toFixed(res.data.score_precision));
+	});
+	source.addEventListener("submission", (e) => {
+		if (update(JSON.parse(e.data))) {
+			finish();
+		}
+	});
+	source.addEventListener("subtest", () => pollSubtests());
+	source.addEventListener("error", () => {
+		if (source.readyState === EventSource.CLOSED) {
+			// Live updates are unavailable (or disabled), fall back to polling
+			poll();
+		}
+	});
+	return true;
+}
+
+export function makeSubWaiter(id: number): string {
+	const watching = watchSubmission(id, (sub) => {
+		if (sub.status != "finished") {
+			return;
+		}
+		let rezStr = "";
+		let statusVal: "success" | "error" = "success";
+		if (sub.submission_type == "classic") {
+			rezStr = getText("finalScore", id) + " " + formatScoreStr(sub.score.toFixed(sub.score_precision));
+		} else {
+			if (sub.score == 100) {
+				rezStr = `<i class="fas fa-fw fa-check"></i> ${getText("accepted")}`;
 			} else {
-				if (res.data.score == 100) {
-					rezStr = `<i class="fas fa-fw fa-check"></i> ${getText("accepted")}`;
-				} else {
-					statusVal = "error";
-					rezStr = res.data.icpc_verdict ? icpcVerdictString(res.data.icpc_verdict) : getText("rejected");
-				}
+				statusVal = "error";
+				rezStr = sub.icpc_verdict ? icpcVerdictString(sub.icpc_verdict) : getText("rejected");
 			}
-
-			createToast({
-				title: getText("finishedEval"),
-				description: rezStr,
-				status: statusVal,
-			});
-			clearInterval(interv);
 		}
-		loadingIDs.set(id, res.data.status);
-	}, 500);
+
+		createToast({
+			title: getText("finishedEval"),
+			description: rezStr,
+			status: statusVal,
+		});
+	});
+	if (!watching) {
+		return `Will not watch ${id}`;
+	}
 	return `Watching ${id}...`;
 }
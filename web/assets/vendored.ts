@@ -128,6 +128,7 @@ export type KNEditorOptions = {
 	dynamicSize?: boolean;
 	autoFocus?: boolean;
 	vimMode?: boolean;
+	readOnly?: boolean;
 }
 
 export class KNEditor {
@@ -161,6 +162,9 @@ export class KNEditor {
 		if (opts.vimMode) {
 			cmSettings.keyMap = "vim";
 		}
+		if (opts.readOnly) {
+			cmSettings.readOnly = true;
+		}
 		if(bundled.isDarkMode()) {
 			cmSettings.theme = "monokai";
 		}
@@ -9,6 +9,7 @@ import (
 
 	"github.com/KiloProjects/kilonova"
 	"github.com/KiloProjects/kilonova/domain/user"
+	"github.com/KiloProjects/kilonova/internal/auth"
 	"github.com/KiloProjects/kilonova/internal/util"
 	"github.com/KiloProjects/kilonova/web/views/authviews"
 	"github.com/KiloProjects/kilonova/web/views/utilviews"
@@ -48,7 +49,7 @@ func (rt *Web) getLogin(w http.ResponseWriter, r *http.Request) {
 	rt.runLayout(w, r, &LayoutParams{
 		Title:   kilonova.GetText(util.Language(r), "auth.oauth_grant"),
 		Head:    utilviews.CanonicalURL("/login"),
-		Content: authviews.OAuthGrant(request, client),
+		Content: authviews.OAuthGrant(request, client, auth.GrantableScopes(request.Scopes, user.UserBrief(r))),
 	})
 
 }
@@ -144,14 +145,14 @@ func (rt *Web) postLogin(w http.ResponseWriter, r *http.Request) {
 	rt.runLayout(w, r, &LayoutParams{
 		Title:   kilonova.GetText(util.Language(r), "auth.oauth_grant"),
 		Head:    utilviews.CanonicalURL("/login"),
-		Content: authviews.OAuthGrant(request, client),
+		Content: authviews.OAuthGrant(request, client, auth.GrantableScopes(request.Scopes, user.UserBrief(r))),
 	})
 }
 
 func (rt *Web) postOAuthGrant(w http.ResponseWriter, r *http.Request) {
 	id := r.FormValue("authRequestID")
 
-	if err := rt.base.ApproveAuthRequest(r.Context(), id, user.UserBrief(r).ID); err != nil {
+	if err := rt.base.ApproveAuthRequest(r.Context(), id, user.UserBrief(r)); err != nil {
 		slog.ErrorContext(r.Context(), "Failed to approve auth request", slog.Any("error", err))
 		rt.statusPage(w, r, http.StatusInternalServerError, "Invalid auth request")
 		return
@@ -159,3 +160,17 @@ func (rt *Web) postOAuthGrant(w http.ResponseWriter, r *http.Request) {
 
 	http.Redirect(w, r, op.AuthCallbackURL(rt.base.OIDCProvider())(r.Context(), id), http.StatusFound)
 }
+
+func (rt *Web) authorizedClients(w http.ResponseWriter, r *http.Request) {
+	clients, err := rt.base.UserAuthorizedClients(r.Context(), user.UserBrief(r).ID)
+	if err != nil {
+		slog.WarnContext(r.Context(), "Couldn't get authorized clients", slog.Any("err", err))
+		rt.statusPage(w, r, 500, "")
+		return
+	}
+
+	rt.runLayout(w, r, &LayoutParams{
+		Title:   kilonova.GetText(util.Language(r), "authorized_applications"),
+		Content: authviews.AuthorizedClients(clients),
+	})
+}
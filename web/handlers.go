@@ -24,6 +24,7 @@ import (
 
 	"github.com/KiloProjects/kilonova/domain/datastore"
 	"github.com/KiloProjects/kilonova/domain/user"
+	"github.com/KiloProjects/kilonova/internal/auth"
 	"github.com/KiloProjects/kilonova/web/tutils"
 	"github.com/KiloProjects/kilonova/web/views/adminviews"
 	"github.com/KiloProjects/kilonova/web/views/authviews"
@@ -655,6 +656,21 @@ func (rt *Web) submission(w http.ResponseWriter, r *http.Request) {
 		subFiles = []*kilonova.SubmissionFile{}
 	}
 
+	// Plagiarism reports are only shown to problem editors, since they contain other people's submissions
+	var plagiarismReports []*kilonova.MOSSSubmission
+	canCheckPlagiarism := rt.base.IsProblemEditor(user.UserBrief(r), sub.Problem)
+	if canCheckPlagiarism {
+		reports, err := rt.base.PlagiarismReports(r.Context(), kilonova.PlagiarismReportFilter{SubmissionID: &sub.ID})
+		if err != nil {
+			slog.WarnContext(r.Context(), "Could not get plagiarism reports", slog.Any("err", err))
+		}
+		for _, report := range reports {
+			if report.Engine == kilonova.PlagiarismEngineLocal && rt.base.CanViewPlagiarismReport(r.Context(), user.UserBrief(r), report) {
+				plagiarismReports = append(plagiarismReports, report)
+			}
+		}
+	}
+
 	rt.runLayout(w, r, &LayoutParams{
 		Title: fmt.Sprintf("%s %d", kilonova.GetText(util.Language(r), "sub"), sub.ID),
 		Head:  utilviews.NoRobotsHead(),
@@ -669,6 +685,9 @@ func (rt *Web) submission(w http.ResponseWriter, r *http.Request) {
 			},
 			OlderSubmissions: olderSubs,
 			SubmissionFiles:  subFiles,
+
+			CanCheckPlagiarism: canCheckPlagiarism && sub.Language != "outputOnly" && sub.Language != "ai",
+			PlagiarismReports:  plagiarismReports,
 		}),
 	})
 }
@@ -687,6 +706,9 @@ func (rt *Web) downloadSubmission(w http.ResponseWriter, r *http.Request) {
 	if extension == ".outputOnly" {
 		extension = ".txt"
 	}
+	if util.Submission(r).Problem.TaskType == kilonova.TaskTypeOutputOnly {
+		extension = ".zip"
+	}
 	filename := fmt.Sprintf("%d-%s%s", util.Submission(r).ID, kilonova.MakeSlug(util.Submission(r).Problem.Name), extension)
 	w.Header().Add("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": filename}))
 	http.ServeContent(w, r, filename, util.Submission(r).CreatedAt, bytes.NewReader(code))
@@ -856,6 +878,9 @@ func (rt *Web) downloadPaste() http.HandlerFunc {
 		if extension == ".outputOnly" {
 			extension = ".txt"
 		}
+		if fullSub.Problem.TaskType == kilonova.TaskTypeOutputOnly {
+			extension = ".zip"
+		}
 		filename := fmt.Sprintf("%s-%s%s", util.Paste(r).ID, kilonova.MakeSlug(fullSub.Problem.Name), extension)
 		w.Header().Add("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": filename}))
 		http.ServeContent(w, r, filename, fullSub.CreatedAt, bytes.NewReader(code))
@@ -1642,6 +1667,12 @@ func (rt *Web) contestEdit() http.HandlerFunc {
 			invitations = []*kilonova.ContestInvitation{}
 		}
 
+		categories, err := rt.base.ContestCategories(r.Context(), util.Contest(r).ID)
+		if err != nil {
+			slog.WarnContext(r.Context(), "Couldn't get contest categories", slog.Any("err", err))
+			categories = []*kilonova.ContestCategory{}
+		}
+
 		mossSubs, err := rt.base.MOSSSubmissions(r.Context(), util.Contest(r).ID)
 		if err != nil {
 			slog.WarnContext(r.Context(), "Couldn't get MOSS submissions", slog.Any("err", err))
@@ -1654,6 +1685,7 @@ func (rt *Web) contestEdit() http.HandlerFunc {
 			Contest: util.Contest(r),
 
 			ContestInvitations: invitations,
+			ContestCategories:  categories,
 			MOSSResults:        mossSubs,
 		})
 	}
@@ -2157,9 +2189,10 @@ func (rt *Web) linkStatus() http.HandlerFunc {
 			rt.statusPage(w, r, 404, "")
 			return
 		}
-		// Only admins and that specific user can view their sessions
+		// Only admins and that specific user can view their link status
 		if !(user.UserBrief(r).IsAdmin() || user.UserBrief(r).ID == userFull.ID) {
 			rt.statusPage(w, r, 403, "")
+			return
 		}
 
 		rt.linkStatusPage(w, r, parsedTempl, userFull)
@@ -2174,9 +2207,19 @@ func (rt *Web) userSessionsPage(w http.ResponseWriter, r *http.Request, templ *t
 		return
 	}
 
+	apiTokens, err := rt.base.UserAPITokens(r.Context(), user.ID)
+	if err != nil {
+		slog.WarnContext(r.Context(), "Couldn't get API tokens", slog.Any("err", err))
+		rt.statusPage(w, r, 500, err.Error())
+		return
+	}
+
 	rt.runTempl(w, r, templ, &SessionsParams{
 		ContentUser: user,
 		Sessions:    sessions,
+
+		APITokens:   apiTokens,
+		TokenScopes: auth.GrantableScopes(slices.DeleteFunc(slices.Clone(auth.Scopes), func(scope string) bool { return scope == auth.ScopeAPI }), user.Brief()),
 	})
 }
 
@@ -2190,7 +2233,14 @@ func (rt *Web) selfSessions() http.HandlerFunc {
 func (rt *Web) userSessions() http.HandlerFunc {
 	parsedTempl := rt.parse("auth/sessions.html")
 	return func(w http.ResponseWriter, r *http.Request) {
-		userFull, err := rt.base.UserFullByName(r.Context(), strings.TrimSpace(r.PathValue("user")))
+		name := strings.TrimSpace(r.PathValue("user"))
+		// Only admins and that specific user can view their sessions.
+		// Other users are turned away before the lookup, so they can't tell whether the account exists
+		if !user.UserBrief(r).IsAdmin() && !strings.EqualFold(user.UserBrief(r).Name, name) {
+			rt.statusPage(w, r, 403, "")
+			return
+		}
+		userFull, err := rt.base.UserFullByName(r.Context(), name)
 		if err != nil && !errors.Is(err, kilonova.ErrNotFound) {
 			slog.WarnContext(r.Context(), "Could not get user", slog.Any("err", err))
 			rt.statusPage(w, r, 500, "")
@@ -2200,9 +2250,9 @@ func (rt *Web) userSessions() http.HandlerFunc {
 			rt.statusPage(w, r, 404, "")
 			return
 		}
-		// Only admins and that specific user can view their sessions
 		if !(user.UserBrief(r).IsAdmin() || user.UserBrief(r).ID == userFull.ID) {
 			rt.statusPage(w, r, 403, "")
+			return
 		}
 
 		rt.userSessionsPage(w, r, parsedTempl, userFull)
@@ -2502,6 +2552,12 @@ func (rt *Web) runTemplate(w io.Writer, r *http.Request, hTempl *template.Templa
 			default:
 				b.WriteString(fmt.Format)
 			}
+
+			if fmt.NeedsReview() {
+				b.WriteString(" (")
+				b.WriteString(kilonova.GetText(lang, "stmt_provenance.machine_translated"))
+				b.WriteString(")")
+			}
 			return b.String()
 		},
 		"reqPath": func() string {
@@ -2560,6 +2616,56 @@ func (rt *Web) runTemplate(w io.Writer, r *http.Request, hTempl *template.Templa
 			}
 			return reg
 		},
+		"selfAssignableCategories": func(c *kilonova.Contest) []*kilonova.ContestCategory {
+			if c == nil {
+				return nil
+			}
+			categories, err := rt.base.ContestCategories(r.Context(), c.ID)
+			if err != nil {
+				slog.WarnContext(r.Context(), "Couldn't get contest categories", slog.Any("err", err))
+				return nil
+			}
+			return slices.DeleteFunc(categories, func(cat *kilonova.ContestCategory) bool { return !cat.SelfAssignable })
+		},
+		"authedUserTeams": func() []*kilonova.Team {
+			if authedUser == nil {
+				return nil
+			}
+			teams, err := rt.base.UserTeams(r.Context(), authedUser.ID)
+			if err != nil {
+				slog.WarnContext(r.Context(), "Couldn't get user teams", slog.Any("err", err))
+				return nil
+			}
+			return teams
+		},
+		"canSubmitAfterContest": func(c *kilonova.Contest) bool {
+			return rt.base.CanSubmitAfterContest(r.Context(), authedUser, c)
+		},
+		"canStartVirtualParticipation": func(c *kilonova.Contest) bool {
+			return rt.base.CanStartVirtualParticipation(r.Context(), authedUser, c)
+		},
+		"virtualParticipation": func(c *kilonova.Contest) *kilonova.VirtualParticipation {
+			if authedUser == nil || c == nil {
+				return nil
+			}
+			vp, err := rt.base.VirtualParticipation(r.Context(), c, authedUser.ID)
+			if err != nil {
+				slog.WarnContext(r.Context(), "Couldn't get virtual participation", slog.Any("err", err))
+				return nil
+			}
+			return vp
+		},
+		"userPlagiarismReports": func(userID int) []*kilonova.MOSSSubmission {
+			if !authedUser.IsAdmin() {
+				return nil
+			}
+			reports, err := rt.base.PlagiarismReports(r.Context(), kilonova.PlagiarismReportFilter{UserID: &userID})
+			if err != nil {
+				slog.WarnContext(r.Context(), "Couldn't get plagiarism reports", slog.Any("err", err))
+				return nil
+			}
+			return reports
+		},
 		"problemFullyVisible": func() bool {
 			return rt.base.IsProblemFullyVisible(user.UserBrief(r), util.Problem(r))
 		},
@@ -136,6 +136,7 @@ type ContestParams struct {
 	Contest *kilonova.Contest
 
 	ContestInvitations []*kilonova.ContestInvitation
+	ContestCategories  []*kilonova.ContestCategory
 	MOSSResults        []*kilonova.MOSSSubmission
 }
 
@@ -342,6 +343,10 @@ type SessionsParams struct {
 	ContentUser *kilonova.UserFull
 	Sessions    []*sudoapi.Session
 
+	APITokens []*sudoapi.APIToken
+	// TokenScopes are the scopes the authed user may grant to new API tokens
+	TokenScopes []string
+
 	Pagination templ.Component
 }
 
@@ -1,6 +1,7 @@
 package web
 
 import (
+	"cmp"
 	"context"
 	"errors"
 	"fmt"
@@ -23,6 +24,19 @@ type StatementEditorParams struct {
 	APIPrefix string
 }
 
+// TranslationEditorParams holds a statement translation next to the statement it was translated from
+type TranslationEditorParams struct {
+	Variant         *kilonova.StatementVariant
+	Translation     *kilonova.Attachment
+	TranslationData string
+
+	// Source is nil if the original statement is missing
+	Source     *kilonova.Attachment
+	SourceData string
+
+	APIPrefix string
+}
+
 type AttachmentEditorParams struct {
 	Attachments []*kilonova.Attachment
 	Problem     *kilonova.Problem
@@ -38,8 +52,9 @@ type ProblemEditParams struct {
 	Diagnostics []*sudoapi.ProblemDiagnostic
 	Checklist   *kilonova.ProblemChecklist
 
-	AttachmentEditor *AttachmentEditorParams
-	StatementEditor  *StatementEditorParams
+	AttachmentEditor  *AttachmentEditorParams
+	StatementEditor   *StatementEditorParams
+	TranslationEditor *TranslationEditorParams
 }
 
 func (rt *Web) editIndex() func(w http.ResponseWriter, r *http.Request) {
@@ -112,6 +127,68 @@ func (rt *Web) editDesc() func(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+func (rt *Web) editTranslation() func(w http.ResponseWriter, r *http.Request) {
+	tmpl := rt.parse("problem/edit/translation.html", "problem/topbar.html")
+	return func(w http.ResponseWriter, r *http.Request) {
+		variants, err := rt.base.ProblemDescVariants(r.Context(), util.Problem(r).ID, true)
+		if err != nil {
+			slog.WarnContext(r.Context(), "Couldn't get statement variants", slog.Any("err", err))
+			http.Error(w, "Couldn't get statement variants", 500)
+			return
+		}
+		var variant *kilonova.StatementVariant
+		for _, v := range variants {
+			if v.AttachmentName == r.FormValue("name") && v.Format == "md" {
+				variant = v
+			}
+		}
+		if variant == nil {
+			rt.statusPage(w, r, 404, "Markdown statement not found")
+			return
+		}
+
+		params := &TranslationEditorParams{
+			Variant:   variant,
+			APIPrefix: fmt.Sprintf("/problem/%d", util.Problem(r).ID),
+		}
+		params.Translation, err = rt.base.ProblemAttByName(r.Context(), util.Problem(r).ID, variant.AttachmentName)
+		if err != nil {
+			rt.statusPage(w, r, kilonova.ErrorCode(err), err.Error())
+			return
+		}
+		data, err := rt.base.AttachmentData(r.Context(), params.Translation.ID)
+		if err != nil {
+			slog.WarnContext(r.Context(), "Couldn't get problem statement", slog.Any("err", err))
+			http.Error(w, "Couldn't get problem statement", 500)
+			return
+		}
+		params.TranslationData = string(data)
+
+		// Translations made before provenance was tracked were all from the Romanian statement
+		sourceName := cmp.Or(variant.TranslatedFrom, "statement-ro.md")
+		params.Source, err = rt.base.ProblemAttByName(r.Context(), util.Problem(r).ID, sourceName)
+		if err != nil && !errors.Is(err, kilonova.ErrNotFound) {
+			slog.WarnContext(r.Context(), "Couldn't get source statement", slog.Any("err", err))
+		}
+		if params.Source != nil {
+			data, err := rt.base.AttachmentData(r.Context(), params.Source.ID)
+			if err != nil {
+				slog.WarnContext(r.Context(), "Couldn't get source statement", slog.Any("err", err))
+				http.Error(w, "Couldn't get source statement", 500)
+				return
+			}
+			params.SourceData = string(data)
+		}
+
+		rt.runTempl(w, r, tmpl, &ProblemEditParams{
+			Problem: util.Problem(r),
+			Topbar:  rt.problemTopbar(r, "desc", -1),
+
+			TranslationEditor: params,
+		})
+	}
+}
+
 func (rt *Web) editAttachments() func(w http.ResponseWriter, r *http.Request) {
 	tmpl := rt.parse("problem/edit/attachments.html", "modals/att_manager.html", "problem/topbar.html")
 	return func(w http.ResponseWriter, r *http.Request) {
@@ -189,6 +266,7 @@ func (rt *Web) subtaskEdit() func(w http.ResponseWriter, r *http.Request) {
 func (rt *Web) ProblemEditRouter(r chi.Router) {
 	r.Get("/", rt.editIndex())
 	r.Get("/desc", rt.editDesc())
+	r.Get("/translation", rt.editTranslation())
 	r.Get("/attachments", rt.editAttachments())
 	r.Get("/access", rt.editAccessControl())
 
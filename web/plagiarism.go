@@ -0,0 +1,189 @@
+package web
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/KiloProjects/kilonova"
+	"github.com/KiloProjects/kilonova/domain/user"
+	"github.com/KiloProjects/kilonova/internal/util"
+	"github.com/KiloProjects/kilonova/web/views/plagiarismviews"
+)
+
+// plagiarismReportGetter returns the report in the URL, writing the status page if it's not valid or can't be seen
+type plagiarismReportGetter func(w http.ResponseWriter, r *http.Request) *plagiarismviews.ReportInfo
+
+// contestPlagiarismInfo gets a plagiarism check of the current contest
+func (rt *Web) contestPlagiarismInfo(w http.ResponseWriter, r *http.Request) *plagiarismviews.ReportInfo {
+	checkID, err := strconv.Atoi(r.PathValue("checkID"))
+	if err != nil {
+		rt.statusPage(w, r, 400, "Invalid plagiarism check ID")
+		return nil
+	}
+	check, err := rt.base.MOSSSubmission(r.Context(), util.Contest(r).ID, checkID)
+	if err != nil {
+		rt.statusPage(w, r, kilonova.ErrorCode(err), err.Error())
+		return nil
+	}
+	if check.Engine != kilonova.PlagiarismEngineLocal {
+		rt.statusPage(w, r, 404, "MOSS results are only available on its website")
+		return nil
+	}
+	info := &plagiarismviews.ReportInfo{
+		Check:   check,
+		URL:     fmt.Sprintf("/contests/%d/manage/plagiarism/%d", util.Contest(r).ID, check.ID),
+		Contest: util.Contest(r),
+	}
+	if check.ProblemID != nil {
+		info.Problem, _ = rt.base.Problem(r.Context(), *check.ProblemID)
+	}
+	return info
+}
+
+// plagiarismInfo gets any plagiarism report the user can see, such as checks of a submission against the archive
+func (rt *Web) plagiarismInfo(w http.ResponseWriter, r *http.Request) *plagiarismviews.ReportInfo {
+	checkID, err := strconv.Atoi(r.PathValue("checkID"))
+	if err != nil {
+		rt.statusPage(w, r, 400, "Invalid plagiarism report ID")
+		return nil
+	}
+	check, err := rt.base.PlagiarismReport(r.Context(), checkID)
+	if err != nil {
+		rt.statusPage(w, r, kilonova.ErrorCode(err), err.Error())
+		return nil
+	}
+	if !rt.base.CanViewPlagiarismReport(r.Context(), user.UserBrief(r), check) {
+		rt.statusPage(w, r, 403, "You are not allowed to view this plagiarism report")
+		return nil
+	}
+	if check.Engine != kilonova.PlagiarismEngineLocal {
+		rt.statusPage(w, r, 404, "MOSS results are only available on its website")
+		return nil
+	}
+	info := &plagiarismviews.ReportInfo{
+		Check: check,
+		URL:   fmt.Sprintf("/plagiarism/%d", check.ID),
+	}
+	if check.ContestID != nil {
+		info.Contest, _ = rt.base.Contest(r.Context(), *check.ContestID)
+	}
+	if check.ProblemID != nil {
+		info.Problem, _ = rt.base.Problem(r.Context(), *check.ProblemID)
+	}
+	if check.UserID != nil {
+		info.User, _ = rt.base.UserBrief(r.Context(), *check.UserID)
+	}
+	return info
+}
+
+func (rt *Web) plagiarismReport(getReport plagiarismReportGetter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		info := getReport(w, r)
+		if info == nil {
+			return
+		}
+
+		matches, err := rt.base.PlagiarismMatches(r.Context(), info.Check.ID)
+		if err != nil {
+			slog.WarnContext(r.Context(), "Couldn't get plagiarism matches", slog.Any("err", err))
+			rt.statusPage(w, r, 500, "")
+			return
+		}
+
+		users := make(map[int]*kilonova.UserBrief)
+		getUser := func(id int) *kilonova.UserBrief {
+			if user, ok := users[id]; ok {
+				return user
+			}
+			user, err := rt.base.UserBrief(r.Context(), id)
+			if err != nil {
+				slog.WarnContext(r.Context(), "Couldn't get user", slog.Any("err", err))
+			}
+			users[id] = user
+			return user
+		}
+		entries := make([]*plagiarismviews.MatchEntry, 0, len(matches))
+		for _, match := range matches {
+			entries = append(entries, &plagiarismviews.MatchEntry{
+				Match: match,
+				User1: getUser(match.User1ID),
+				User2: getUser(match.User2ID),
+			})
+		}
+
+		rt.runLayout(w, r, &LayoutParams{
+			Title:   kilonova.GetText(util.Language(r), "plagiarism.report"),
+			Content: plagiarismviews.Report(info, entries),
+		})
+	}
+}
+
+func (rt *Web) plagiarismCompare(getReport plagiarismReportGetter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		info := getReport(w, r)
+		if info == nil {
+			return
+		}
+		matchID, err := strconv.Atoi(r.PathValue("matchID"))
+		if err != nil {
+			rt.statusPage(w, r, 400, "Invalid match ID")
+			return
+		}
+		match, err := rt.base.PlagiarismMatch(r.Context(), info.Check.ID, matchID)
+		if err != nil {
+			rt.statusPage(w, r, kilonova.ErrorCode(err), err.Error())
+			return
+		}
+
+		sub1, ok := rt.plagiarismSubmission(w, r, match.Submission1ID)
+		if !ok {
+			return
+		}
+		sub2, ok := rt.plagiarismSubmission(w, r, match.Submission2ID)
+		if !ok {
+			return
+		}
+
+		rt.runLayout(w, r, &LayoutParams{
+			Title:   kilonova.GetText(util.Language(r), "plagiarism.compare"),
+			Content: plagiarismviews.Compare(info, match, sub1, sub2),
+		})
+	}
+}
+
+func (rt *Web) plagiarismSubmission(w http.ResponseWriter, r *http.Request, id int) (*plagiarismviews.Submission, bool) {
+	sub, err := rt.base.RawSubmission(r.Context(), id)
+	if err != nil {
+		rt.statusPage(w, r, kilonova.ErrorCode(err), err.Error())
+		return nil, false
+	}
+	code, err := rt.base.RawSubmissionCode(r.Context(), id)
+	if err != nil {
+		slog.WarnContext(r.Context(), "Couldn't get submission code", slog.Any("err", err))
+		rt.statusPage(w, r, 500, "")
+		return nil, false
+	}
+	user, err := rt.base.UserBrief(r.Context(), sub.UserID)
+	if err != nil {
+		slog.WarnContext(r.Context(), "Couldn't get user", slog.Any("err", err))
+	}
+	return &plagiarismviews.Submission{Sub: sub, User: user, Code: code}, true
+}
+
+func (rt *Web) checkSubmissionPlagiarism(w http.ResponseWriter, r *http.Request) {
+	sub := util.Submission(r)
+	if !rt.base.IsProblemEditor(user.UserBrief(r), sub.Problem) {
+		rt.statusPage(w, r, 403, "You can't check this submission for plagiarism!")
+		return
+	}
+
+	id, err := rt.base.CheckSubmissionPlagiarism(context.WithoutCancel(r.Context()), &sub.Submission, user.UserBrief(r))
+	if err != nil {
+		rt.statusPage(w, r, kilonova.ErrorCode(err), err.Error())
+		return
+	}
+	http.Redirect(w, r, fmt.Sprintf("/plagiarism/%d", id), http.StatusFound)
+}
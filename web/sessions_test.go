@@ -0,0 +1,35 @@
+package web
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/KiloProjects/kilonova"
+	"github.com/KiloProjects/kilonova/domain/user"
+	"github.com/KiloProjects/kilonova/sudoapi"
+	"github.com/KiloProjects/kilonova/web/components/layout"
+)
+
+func TestUserSessionsForbiddenToOtherUsers(t *testing.T) {
+	// The API has no database, so the handler must stop before loading anything about the user
+	rt := NewWeb(&sudoapi.BaseAPI{})
+	handler := rt.userSessions()
+
+	r := httptest.NewRequest(http.MethodGet, "/profile/bob/sessions", nil)
+	r.SetPathValue("user", "bob")
+	ctx := context.WithValue(r.Context(), layout.MiddlewareStartKey, time.Now())
+	r = r.WithContext(context.WithValue(ctx, user.AuthedUserKey, &kilonova.UserFull{
+		UserBrief: kilonova.UserBrief{ID: 1, Name: "alice"},
+	}))
+	w := httptest.NewRecorder()
+	handler(w, r)
+
+	// Status pages are rendered with a 200 status code
+	if !strings.Contains(w.Body.String(), "403: Forbidden") {
+		t.Fatalf("expected the forbidden page for another user's sessions, got %q", w.Body.String())
+	}
+}
@@ -0,0 +1,41 @@
+package web
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/KiloProjects/kilonova"
+	"github.com/KiloProjects/kilonova/domain/user"
+	"github.com/KiloProjects/kilonova/internal/util"
+	"github.com/KiloProjects/kilonova/web/views/teamviews"
+)
+
+func (rt *Web) userTeams(w http.ResponseWriter, r *http.Request) {
+	self := user.UserBrief(r)
+	teams, err := rt.base.UserTeams(r.Context(), self.ID)
+	if err != nil {
+		slog.WarnContext(r.Context(), "Couldn't get user teams", slog.Any("err", err))
+		rt.statusPage(w, r, 500, "")
+		return
+	}
+
+	entries := make([]*teamviews.TeamEntry, 0, len(teams))
+	for _, team := range teams {
+		members, err := rt.base.TeamMembers(r.Context(), team)
+		if err != nil {
+			slog.WarnContext(r.Context(), "Couldn't get team members", slog.Any("err", err))
+			rt.statusPage(w, r, 500, "")
+			return
+		}
+		entries = append(entries, &teamviews.TeamEntry{
+			Team:      team,
+			Members:   members,
+			CanManage: rt.base.CanManageTeam(r.Context(), team, self),
+		})
+	}
+
+	rt.runLayout(w, r, &LayoutParams{
+		Title:   kilonova.GetText(util.Language(r), "teams"),
+		Content: teamviews.Teams(entries, self),
+	})
+}
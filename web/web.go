@@ -29,6 +29,7 @@ import (
 	"github.com/KiloProjects/kilonova"
 	"github.com/KiloProjects/kilonova/domain/config"
 	"github.com/KiloProjects/kilonova/infra/maxmind"
+	"github.com/KiloProjects/kilonova/net/llm"
 	"github.com/KiloProjects/kilonova/sudoapi"
 	"github.com/KiloProjects/kilonova/sudoapi/flags"
 	"github.com/KiloProjects/kilonova/web/tutils"
@@ -199,8 +200,12 @@ func (rt *Web) Handler() http.Handler {
 		r.With(rt.mustBeAuthed).Get("/profile/{user}/linked", rt.linkStatus())
 		r.With(rt.mustBeAuthed).Get("/profile/{user}/sessions", rt.userSessions())
 		r.With(rt.mustBeAuthed).Get("/settings", rt.userSettings())
+		r.With(rt.mustBeAuthed).Get("/settings/applications", rt.authorizedClients)
+		r.With(rt.mustBeAuthed).Get("/settings/teams", rt.userTeams)
 		r.With(rt.checkFlag(flags.DonationsEnabled)).Get("/donate", rt.donationPage())
 		r.Get("/grader", rt.graderInfo())
+		r.With(rt.mustBeAuthed).Get("/plagiarism/{checkID}", rt.plagiarismReport(rt.plagiarismInfo))
+		r.With(rt.mustBeAuthed).Get("/plagiarism/{checkID}/{matchID}", rt.plagiarismCompare(rt.plagiarismInfo))
 
 		r.Route("/problems", func(r chi.Router) {
 			r.Get("/", rt.problems())
@@ -242,6 +247,8 @@ func (rt *Web) Handler() http.Handler {
 					r.Use(rt.mustBeContestEditor)
 					r.Get("/edit", rt.contestEdit())
 					r.Get("/registrations", rt.contestRegistrations())
+					r.Get("/plagiarism/{checkID}", rt.plagiarismReport(rt.contestPlagiarismInfo))
+					r.Get("/plagiarism/{checkID}/{matchID}", rt.plagiarismCompare(rt.contestPlagiarismInfo))
 				})
 				r.Route("/problems/{pbid}", rt.problemRouter(true))
 			})
@@ -256,6 +263,7 @@ func (rt *Web) Handler() http.Handler {
 				r.Post("/paste", rt.createPaste)
 				r.Delete("/", rt.deleteSubmission)
 				r.Post("/reevaluate", rt.reevaluateSubmission)
+				r.Post("/plagiarismCheck", rt.checkSubmissionPlagiarism)
 			})
 		})
 
@@ -360,9 +368,12 @@ func NewWeb(base *sudoapi.BaseAPI) *Web {
 			}
 			return pbs
 		},
-		"problemFromList": func(pbs []*kilonova.ScoredProblem, id int) *kilonova.ScoredProblem {
+		"problemFromList": func(pbs []*kilonova.ScoredProblem, id *int) *kilonova.ScoredProblem {
+			if id == nil {
+				return nil
+			}
 			for _, pb := range pbs {
-				if pb.ID == id {
+				if pb.ID == *id {
 					return pb
 				}
 			}
@@ -652,6 +663,15 @@ func NewWeb(base *sudoapi.BaseAPI) *Web {
 			}
 			return val
 		},
+		"llmAvailable":    llm.Available,
+		"llmPDFAvailable": llm.PDFAvailable,
+		"llmDefaultModel": func() string {
+			provider, err := llm.DefaultProvider()
+			if err != nil {
+				return ""
+			}
+			return provider.DefaultModel(false)
+		},
 		"stringFlag": func(name string) string {
 			val, ok := config.GetFlagVal[string](name)
 			if !ok {
@@ -767,6 +787,17 @@ func NewWeb(base *sudoapi.BaseAPI) *Web {
 		"usacoDuration": func(c *kilonova.Contest) string {
 			return (time.Duration(c.PerUserTime) * time.Second).String()
 		},
+		"categoryName": func(categories []*kilonova.ContestCategory, id *int) string {
+			if id == nil {
+				return ""
+			}
+			for _, category := range categories {
+				if category.ID == *id {
+					return category.Name
+				}
+			}
+			return ""
+		},
 		"add1": func(i int) int { return i + 1 },
 		"formatResourceURL": func(orgURL string) string {
 			u, err := url.Parse(orgURL)
@@ -850,6 +881,30 @@ func NewWeb(base *sudoapi.BaseAPI) *Web {
 			slog.ErrorContext(ctx, "Uninitialized `contestRegistration`")
 			return nil
 		},
+		"selfAssignableCategories": func(c *kilonova.Contest) []*kilonova.ContestCategory {
+			slog.ErrorContext(ctx, "Uninitialized `selfAssignableCategories`")
+			return nil
+		},
+		"authedUserTeams": func() []*kilonova.Team {
+			slog.ErrorContext(ctx, "Uninitialized `authedUserTeams`")
+			return nil
+		},
+		"canSubmitAfterContest": func(c *kilonova.Contest) bool {
+			slog.ErrorContext(ctx, "Uninitialized `canSubmitAfterContest`")
+			return false
+		},
+		"canStartVirtualParticipation": func(c *kilonova.Contest) bool {
+			slog.ErrorContext(ctx, "Uninitialized `canStartVirtualParticipation`")
+			return false
+		},
+		"virtualParticipation": func(c *kilonova.Contest) *kilonova.VirtualParticipation {
+			slog.ErrorContext(ctx, "Uninitialized `virtualParticipation`")
+			return nil
+		},
+		"userPlagiarismReports": func(userID int) []*kilonova.MOSSSubmission {
+			slog.ErrorContext(ctx, "Uninitialized `userPlagiarismReports`")
+			return nil
+		},
 		"problemFullyVisible": func() bool {
 			slog.ErrorContext(ctx, "Uninitialized `problemFullyVisible`")
 			return false